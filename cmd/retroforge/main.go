@@ -9,10 +9,12 @@ import (
 	"image"
 	"image/color"
 	"image/png"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/AndrewDonelson/retroforge-engine/internal/capture"
 	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
 	"github.com/AndrewDonelson/retroforge-engine/internal/engine"
 	"github.com/AndrewDonelson/retroforge-engine/internal/sdlrun"
@@ -33,6 +35,7 @@ func packDir(dir, out string) error {
 	var sfx cartio.SFXMap = make(cartio.SFXMap)
 	var music cartio.MusicMap = make(cartio.MusicMap)
 	var sprites cartio.SpriteMap = make(cartio.SpriteMap)
+	var anims cartio.AnimationMap
 
 	// walk assets/
 	assetsDir := filepath.Join(dir, "assets")
@@ -70,6 +73,7 @@ func packDir(dir, out string) error {
 					// If invalid, use empty map
 					sprites = make(cartio.SpriteMap)
 				}
+				anims, _ = cartio.ParseAnimations(b)
 			}
 			return nil // Don't include in assets
 		}
@@ -82,8 +86,14 @@ func packDir(dir, out string) error {
 		assets = append(assets, cartio.Asset{Name: rel, Data: b})
 		return nil
 	})
+
+	// Convert Tiled maps into native maps, tiles and sprites
+	assets, err = importTiled(assetsDir, m, assets, sprites)
+	if err != nil {
+		return err
+	}
 	var buf bytes.Buffer
-	if err := cartio.Write(&buf, m, assets, sfx, music, sprites); err != nil {
+	if err := cartio.Write(&buf, m, assets, sfx, music, sprites, anims); err != nil {
 		return err
 	}
 	return os.WriteFile(out, buf.Bytes(), 0644)
@@ -100,16 +110,93 @@ func savePNG(path string, w, h int, rgba []uint8) error {
 	return png.Encode(f, img)
 }
 
+// clipOptions are the headless recording outputs
+type clipOptions struct {
+	gif, apng, seq string
+	scale, fps     int
+}
+
+// runHeadless runs frames, recording them when a clip output is set
+func runHeadless(e *engine.Engine, frames int, clip clipOptions) error {
+	if clip.gif == "" && clip.apng == "" && clip.seq == "" {
+		e.RunFrames(frames)
+		return nil
+	}
+	rec := capture.NewRecorder(e.Ren.Width(), e.Ren.Height(), float64(frames)/capture.TickRate, clip.fps)
+	for i := 0; i < frames; i++ {
+		e.RunFrames(1)
+		rec.Add(e.Ren.Indexed())
+	}
+	recorded, fps := rec.Take(), rec.FPS()
+	write := func(path string, enc func(w io.Writer, frames []capture.Frame, scale, fps int) error) error {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := enc(f, recorded, clip.scale, fps); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		println("wrote:", path)
+		return nil
+	}
+	if clip.gif != "" {
+		if err := write(clip.gif, capture.WriteGIF); err != nil {
+			return err
+		}
+	}
+	if clip.apng != "" {
+		if err := write(clip.apng, capture.WriteAPNG); err != nil {
+			return err
+		}
+	}
+	if clip.seq != "" {
+		if err := capture.WriteFrames(clip.seq, recorded, clip.scale); err != nil {
+			return err
+		}
+		println("wrote:", clip.seq)
+	}
+	return nil
+}
+
 func main() {
 	pack := flag.String("pack", "", "pack cart directory into .rfs (specify input dir)")
 	cart := flag.String("cart", "", "run .rfs cart (specify file path)")
 	folder := flag.String("folder", "", "run cart from folder (development mode with hot reload)")
 	frames := flag.Int("frames", 1, "frames to run when executing a cart (headless)")
-	out := flag.String("out", "", "output PNG path (headless). Omit to disable.")
+	out := flag.String("out", "", "output PNG path (headless), or palette file for -palette-import/-palette-export. Omit to disable.")
 	window := flag.Bool("window", false, "open window and run until ESC/Close")
 	scale := flag.Int("scale", 2, "window scale (integer)")
+	var clip clipOptions
+	flag.StringVar(&clip.gif, "gif", "", "record the headless frames as an animated GIF (output path)")
+	flag.StringVar(&clip.apng, "apng", "", "record the headless frames as an animated PNG (output path)")
+	flag.StringVar(&clip.seq, "seq", "", "record the headless frames as numbered PNGs (output directory)")
+	flag.IntVar(&clip.scale, "clip-scale", 2, "scale of recorded frames (integer)")
+	flag.IntVar(&clip.fps, "clip-fps", capture.DefaultFPS, "frames per second recorded (at most 60)")
+	paletteImport := flag.String("palette-import", "", "convert a .gpl, .hex, .pal, .txt or .png palette to palette JSON (stdout, or -out)")
+	paletteExport := flag.String("palette-export", "", "write a palette (palette JSON file or built-in name) to -out as .gpl, .hex, .pal, .txt, .png or .json")
+	paletteName := flag.String("name", "", "palette name for -palette-import/-palette-export")
 	flag.Parse()
 
+	if *paletteImport != "" {
+		if err := convertPalette(*paletteImport, *paletteName, *out); err != nil {
+			panic(err)
+		}
+		return
+	}
+	if *paletteExport != "" {
+		if *out == "" {
+			panic("-palette-export needs -out")
+		}
+		if err := convertPalette(*paletteExport, *paletteName, *out); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	if *pack != "" {
 		outFile := *pack + ".rf"
 		if err := packDir(*pack, outFile); err != nil {
@@ -132,7 +219,9 @@ func main() {
 			return
 		}
 		// headless
-		e.RunFrames(*frames)
+		if err := runHeadless(e, *frames, clip); err != nil {
+			panic(err)
+		}
 		if *out != "" {
 			if err := savePNG(*out, e.Ren.Width(), e.Ren.Height(), e.Ren.Pixels()); err != nil {
 				panic(err)
@@ -159,7 +248,9 @@ func main() {
 			return
 		}
 		// headless
-		e.RunFrames(*frames)
+		if err := runHeadless(e, *frames, clip); err != nil {
+			panic(err)
+		}
 		if *out != "" {
 			if err := savePNG(*out, e.Ren.Width(), e.Ren.Height(), e.Ren.Pixels()); err != nil {
 				panic(err)
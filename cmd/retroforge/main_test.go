@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
 )
 
 func TestPackDir(t *testing.T) {
@@ -48,6 +50,62 @@ end`
 	}
 }
 
+func TestPackDirImportsTiled(t *testing.T) {
+	tmpDir := t.TempDir()
+	assetsDir := filepath.Join(tmpDir, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	os.WriteFile(filepath.Join(tmpDir, "manifest.json"), []byte(`{"title": "Tiled", "entry": "main.lua"}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "main.lua"), []byte("-- test"), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "maps.json"), []byte(`{"hub": {"width": 1, "height": 1}}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "sprites.json"), []byte(`{"animations": {"idle": {"frames": ["hero"]}}}`), 0644)
+	if err := savePNG(filepath.Join(assetsDir, "tiles.png"), 1, 1, []uint8{255, 255, 255, 255}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(assetsDir, "cave.tmj"), []byte(`{
+		"width": 1, "height": 1, "tilewidth": 1, "tileheight": 1,
+		"tilesets": [{"firstgid": 1, "name": "cave", "tilewidth": 1, "tileheight": 1, "image": "tiles.png"}],
+		"layers": [{"type": "tilelayer", "name": "main", "data": [1]}]
+	}`), 0644)
+
+	outFile := filepath.Join(tmpDir, "tiled.rf")
+	if err := packDir(tmpDir, outFile); err != nil {
+		t.Fatalf("packDir failed: %v", err)
+	}
+	f, err := os.Open(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	st, _ := f.Stat()
+	result, err := cartio.Read(f, st.Size())
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	maps, err := cartio.ParseMaps(result.Files[cartio.MapsFile], false)
+	if err != nil {
+		t.Fatalf("maps.json: %v", err)
+	}
+	if _, ok := maps["hub"]; !ok || maps["cave"].Layers[0].Tiles[0] != 1 {
+		t.Fatalf("expected the cart's and the imported maps, got %v", maps)
+	}
+	ts, err := cartio.ParseTileset(result.Files[cartio.TilesetFile])
+	if err != nil || ts.Tiles[1].Sprite != "cave:0" {
+		t.Fatalf("expected tile 1 to draw cave:0, got %v %v", ts.Tiles, err)
+	}
+	if _, ok := result.Sprites["cave:0"]; !ok {
+		t.Fatal("expected the tile sprite in sprites.json")
+	}
+	if _, ok := result.Animations["idle"]; !ok {
+		t.Fatal("expected animations to be kept")
+	}
+	for _, name := range []string{"assets/cave.tmj", "assets/tiles.png"} {
+		if _, ok := result.Files[name]; ok {
+			t.Errorf("%s should not be packed", name)
+		}
+	}
+}
+
 func TestSavePNG(t *testing.T) {
 	tmpFile := filepath.Join(t.TempDir(), "test.png")
 
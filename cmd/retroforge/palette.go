@@ -0,0 +1,103 @@
+//go:build !js && !wasm
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// loadPalette reads a palette from a file in any supported format, fitting
+// it to the RetroForge layout, or finds a built-in palette by name. name
+// renames the palette; for a palette.json holding several it picks one.
+func loadPalette(src, name string) (palette.Palette, error) {
+	if _, err := os.Stat(src); err != nil {
+		for _, p := range palette.Builtins() {
+			if strings.EqualFold(p.Name, src) {
+				if name != "" {
+					p.Name = name
+				}
+				return p, nil
+			}
+		}
+		return palette.Palette{}, fmt.Errorf("%s is neither a palette file nor a built-in palette", src)
+	}
+
+	format, err := palette.Format(src)
+	if err != nil {
+		return palette.Palette{}, err
+	}
+	if format == palette.FormatJSON {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return palette.Palette{}, err
+		}
+		palettes, err := cartio.ParsePalettes(data)
+		if err != nil {
+			return palette.Palette{}, err
+		}
+		for _, p := range palettes {
+			if name == "" || strings.EqualFold(p.Name, name) || len(palettes) == 1 {
+				if name != "" {
+					p.Name = name
+				}
+				return p, nil
+			}
+		}
+		return palette.Palette{}, fmt.Errorf("%s has no palette named %q", src, name)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return palette.Palette{}, err
+	}
+	defer f.Close()
+	colors, stored, err := palette.Decode(format, f)
+	if err != nil {
+		return palette.Palette{}, fmt.Errorf("%s: %w", src, err)
+	}
+	if len(colors) == 0 {
+		return palette.Palette{}, fmt.Errorf("%s has no colors", src)
+	}
+	if name == "" {
+		name = stored
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	}
+	return palette.Fit(name, colors), nil
+}
+
+// convertPalette loads src (see loadPalette) and writes it to out in the
+// format of out's extension, or as palette JSON to stdout when out is empty.
+func convertPalette(src, name, out string) error {
+	p, err := loadPalette(src, name)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		return palette.Encode(palette.FormatJSON, os.Stdout, p)
+	}
+	format, err := palette.Format(out)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := palette.Encode(format, f, p); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	println("wrote:", out)
+	return nil
+}
@@ -0,0 +1,103 @@
+//go:build !js && !wasm
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	"github.com/AndrewDonelson/retroforge-engine/internal/tiled"
+)
+
+// importTiled converts the cart's Tiled maps (.tmx/.tmj) into maps.json (or
+// maps.bin), tileset.json and sprites. The Tiled files themselves are left
+// out of the packed assets.
+func importTiled(assetsDir string, m cartio.Manifest, assets []cartio.Asset, sprites cartio.SpriteMap) ([]cartio.Asset, error) {
+	files, err := tiled.FindMaps(assetsDir)
+	if err != nil || len(files) == 0 {
+		return assets, err
+	}
+
+	palette := pal.NewManager()
+	for _, a := range assets {
+		if "assets/"+filepath.ToSlash(a.Name) != cartio.PalettesFile {
+			continue
+		}
+		palettes, err := cartio.ParsePalettes(a.Data)
+		if err != nil {
+			return assets, fmt.Errorf("palette.json: %w", err)
+		}
+		for _, p := range palettes {
+			if err := palette.Register(p); err != nil {
+				return assets, err
+			}
+		}
+	}
+	if m.Palette != "" {
+		if err := palette.Set(m.Palette); err != nil {
+			return assets, err
+		}
+	}
+	imp := tiled.NewImporter(tiled.ReadFolder(assetsDir), palette.Colors())
+	imp.Sprites = sprites
+
+	// Start from the cart's own maps and tiles; maps.json wins over maps.bin
+	// like it does when the engine loads a cart
+	mapsFile, binFile := path.Base(cartio.MapsFile), path.Base(cartio.MapsBinFile)
+	tilesetFile := path.Base(cartio.TilesetFile)
+	existing := make(map[string][]byte)
+	for _, a := range assets {
+		existing[filepath.ToSlash(a.Name)] = a.Data
+	}
+	compressed := false
+	if data, ok := existing[mapsFile]; ok {
+		if imp.Maps, err = cartio.ParseMaps(data, false); err != nil {
+			return nil, err
+		}
+	} else if data, ok := existing[binFile]; ok {
+		if imp.Maps, err = cartio.ParseMaps(data, true); err != nil {
+			return nil, err
+		}
+		compressed = true
+	}
+	if data, ok := existing[tilesetFile]; ok {
+		if imp.Tileset, err = cartio.ParseTileset(data); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range files {
+		if err := imp.Import(file); err != nil {
+			return nil, err
+		}
+	}
+
+	skip := map[string]bool{mapsFile: true, binFile: true, tilesetFile: true}
+	for _, f := range imp.Files {
+		skip[f] = true
+	}
+	var out []cartio.Asset
+	for _, a := range assets {
+		if !skip[filepath.ToSlash(a.Name)] {
+			out = append(out, a)
+		}
+	}
+	mapsData, err := cartio.EncodeMaps(imp.Maps, compressed)
+	if err != nil {
+		return nil, err
+	}
+	if compressed {
+		mapsFile = binFile
+	}
+	tilesetData, err := json.MarshalIndent(imp.Tileset, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out,
+		cartio.Asset{Name: mapsFile, Data: mapsData},
+		cartio.Asset{Name: tilesetFile, Data: tilesetData},
+	), nil
+}
@@ -187,8 +187,10 @@ function update_spectator_mode()
   end
   prev_tab_pressed = tab_pressed
   
-  -- EXIT button: Hold Down (3) + Press X (5)
-  if rf.btn(3) and rf.btnp(5) then
+  -- EXIT button: Hold Down (3) + Press X (5), or click/tap it
+  local mx, my, _, _, clicked = rf.mouse()
+  exit_button_hover = mx >= 224 and mx <= 256 and my >= 248 and my <= 260
+  if (rf.btn(3) and rf.btnp(5)) or (exit_button_hover and clicked % 2 == 1) then
     rf.sfx("select")
     state = "menu"
     game_over = false
@@ -373,9 +375,9 @@ function update_host()
       local old_y = p.y
       
       -- Apply inputs (5/sec, but smooth with interpolation)
-      if rf.btn(id, 0) then p.vx = -3 end    -- left
-      if rf.btn(id, 1) then p.vx = 3 end     -- right
-      if rf.btn(id, 4) and p.on_ground then  -- jump
+      if rf.network_btn(id, 0) then p.vx = -3 end    -- left
+      if rf.network_btn(id, 1) then p.vx = 3 end     -- right
+      if rf.network_btn(id, 4) and p.on_ground then  -- jump
         p.vy = -5  -- Reduced jump strength by 50% (was -10)
         p.on_ground = false
         rf.sfx("jump")  -- Host plays sounds for all players
@@ -645,7 +647,7 @@ function _DRAW()
     local exit_h = 12
     
     -- Check if hovering/pressing exit button
-    local exit_active = rf.btn(3) and rf.btnp(5)  -- Down + X pressed
+    local exit_active = (rf.btn(3) and rf.btnp(5)) or exit_button_hover  -- Down + X pressed, or pointer over it
     
     -- Button background (highlighted if active)
     local bg_color = exit_active and 35 or 37  -- Light blue if active, dark blue otherwise
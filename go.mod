@@ -4,10 +4,10 @@ go 1.25.3
 
 require github.com/yuin/gopher-lua v1.1.1
 
-require github.com/veandco/go-sdl2 v0.4.33
-
 require (
-	github.com/ByteArena/box2d v1.0.2 // indirect
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
-	golang.org/x/sys v0.13.0 // indirect
+	github.com/ByteArena/box2d v1.0.2
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/veandco/go-sdl2 v0.4.33
 )
+
+require golang.org/x/sys v0.13.0 // indirect
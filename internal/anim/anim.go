@@ -0,0 +1,144 @@
+package anim
+
+import "github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+
+// DefaultFrameDuration is used when neither a frame nor its clip sets one (ms)
+const DefaultFrameDuration = 100
+
+// EndEvent is fired when a "once" clip reaches its last frame
+const EndEvent = "end"
+
+// Playback modes
+const (
+	ModeLoop     = "loop"
+	ModePingPong = "pingpong"
+	ModeOnce     = "once"
+)
+
+// Animator plays one clip at a time. Step advances it; Sprite names the
+// sprite to draw for the current frame.
+type Animator struct {
+	clips    cartio.AnimationMap
+	clipName string
+	clip     cartio.AnimClip
+	frame    int
+	dir      int     // 1 forward, -1 backward (ping-pong)
+	elapsed  float64 // Milliseconds spent in the current frame
+	playing  bool
+	Speed    float64 // Playback rate multiplier (1 = normal)
+
+	// OnEvent is called for frame events and EndEvent
+	OnEvent func(event string)
+}
+
+// New creates an animator playing clip from clips. ok is false if the clip
+// doesn't exist; the animator is still usable with Play.
+func New(clips cartio.AnimationMap, clip string) (*Animator, bool) {
+	a := &Animator{clips: clips, Speed: 1}
+	return a, a.Play(clip, true)
+}
+
+// Play switches to clip and resumes playback. The clip restarts from its
+// first frame if it differs from the current one or restart is true.
+func (a *Animator) Play(clip string, restart bool) bool {
+	c, ok := a.clips[clip]
+	if !ok || len(c.Frames) == 0 {
+		return false
+	}
+	if clip != a.clipName || restart {
+		a.clipName, a.clip = clip, c
+		a.frame, a.dir, a.elapsed = 0, 1, 0
+		a.playing = true
+		a.fire(c.Frames[0].Event)
+		return true
+	}
+	a.playing = true
+	return true
+}
+
+// Stop pauses playback on the current frame.
+func (a *Animator) Stop() {
+	a.playing = false
+}
+
+// Playing reports whether the animator is advancing.
+func (a *Animator) Playing() bool {
+	return a.playing
+}
+
+// Clip returns the current clip name.
+func (a *Animator) Clip() string {
+	return a.clipName
+}
+
+// Frame returns the current frame index (0-based).
+func (a *Animator) Frame() int {
+	return a.frame
+}
+
+// Sprite returns the sprite to draw for the current frame ("" if none).
+func (a *Animator) Sprite() string {
+	if a.frame < len(a.clip.Frames) {
+		return a.clip.Frames[a.frame].Sprite
+	}
+	return ""
+}
+
+// Step advances playback by dt seconds, firing events for every frame entered.
+func (a *Animator) Step(dt float64) {
+	if !a.playing || len(a.clip.Frames) == 0 {
+		return
+	}
+	a.elapsed += dt * 1000 * a.Speed
+	for a.playing {
+		d := float64(a.duration(a.frame))
+		if a.elapsed < d {
+			return
+		}
+		a.elapsed -= d
+		a.advance()
+	}
+}
+
+// duration returns frame i's length in milliseconds
+func (a *Animator) duration(i int) int {
+	if d := a.clip.Frames[i].Duration; d > 0 {
+		return d
+	}
+	if a.clip.Duration > 0 {
+		return a.clip.Duration
+	}
+	return DefaultFrameDuration
+}
+
+// advance moves to the next frame according to the clip's mode
+func (a *Animator) advance() {
+	n := len(a.clip.Frames)
+	next := a.frame + a.dir
+	switch a.clip.Mode {
+	case ModeOnce:
+		if next >= n {
+			a.playing, a.elapsed = false, 0
+			a.fire(EndEvent)
+			return
+		}
+	case ModePingPong:
+		if next >= n || next < 0 {
+			a.dir = -a.dir
+			next = a.frame + a.dir
+			if next < 0 || next >= n {
+				next = a.frame // Single-frame clip
+			}
+		}
+	default:
+		next = (next%n + n) % n
+	}
+	a.frame = next
+	a.fire(a.clip.Frames[next].Event)
+}
+
+func (a *Animator) fire(event string) {
+	if event != "" && a.OnEvent != nil {
+		a.OnEvent(event)
+	}
+}
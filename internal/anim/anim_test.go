@@ -0,0 +1,90 @@
+package anim
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+var testClips = cartio.AnimationMap{
+	"walk": {Duration: 100, Frames: []cartio.AnimFrame{
+		{Sprite: "w1"}, {Sprite: "w2", Event: "step"}, {Sprite: "w3", Duration: 200},
+	}},
+	"bounce": {Duration: 100, Mode: ModePingPong, Frames: []cartio.AnimFrame{
+		{Sprite: "b1"}, {Sprite: "b2"}, {Sprite: "b3"},
+	}},
+	"die": {Mode: ModeOnce, Frames: []cartio.AnimFrame{{Sprite: "d1"}, {Sprite: "d2"}}},
+}
+
+func TestLoop(t *testing.T) {
+	a, ok := New(testClips, "walk")
+	if !ok {
+		t.Fatal("expected walk clip")
+	}
+	var events []string
+	a.OnEvent = func(e string) { events = append(events, e) }
+
+	want := []string{"w1", "w2", "w3", "w3", "w1", "w2"}
+	for i, sprite := range want {
+		if a.Sprite() != sprite {
+			t.Fatalf("step %d: expected %s, got %s", i, sprite, a.Sprite())
+		}
+		a.Step(0.1)
+	}
+	if len(events) != 2 || events[0] != "step" {
+		t.Fatalf("expected step event twice, got %v", events)
+	}
+}
+
+func TestPingPongAndOnce(t *testing.T) {
+	a, _ := New(testClips, "bounce")
+	var got []int
+	for i := 0; i < 6; i++ {
+		got = append(got, a.Frame())
+		a.Step(0.1)
+	}
+	for i, want := range []int{0, 1, 2, 1, 0, 1} {
+		if got[i] != want {
+			t.Fatalf("ping-pong frames: expected %v, got %v", []int{0, 1, 2, 1, 0, 1}, got)
+		}
+	}
+
+	ended := false
+	a.OnEvent = func(e string) { ended = ended || e == EndEvent }
+	if !a.Play("die", false) {
+		t.Fatal("expected die clip")
+	}
+	a.Step(1) // Several frames' worth at once
+	if !ended || a.Playing() || a.Sprite() != "d2" {
+		t.Fatalf("once clip should hold its last frame and end (ended=%v playing=%v sprite=%s)", ended, a.Playing(), a.Sprite())
+	}
+}
+
+func TestPlayAndStop(t *testing.T) {
+	a, _ := New(testClips, "walk")
+	a.Step(0.15)
+	a.Play("walk", false) // Same clip keeps its frame
+	if a.Frame() != 1 {
+		t.Fatalf("expected frame 1, got %d", a.Frame())
+	}
+	a.Stop()
+	a.Step(1)
+	if a.Frame() != 1 {
+		t.Fatal("stopped animator should not advance")
+	}
+	a.Play("walk", true)
+	if a.Frame() != 0 || !a.Playing() {
+		t.Fatal("restart should return to the first frame and play")
+	}
+	a.Speed = 2
+	a.Step(0.05)
+	if a.Frame() != 1 {
+		t.Fatalf("double speed should advance a frame, got %d", a.Frame())
+	}
+	if a.Play("missing", false) || a.Clip() != "walk" {
+		t.Fatal("unknown clip should be rejected")
+	}
+	if _, ok := New(testClips, "missing"); ok {
+		t.Fatal("New should report unknown clips")
+	}
+}
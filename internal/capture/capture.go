@@ -0,0 +1,114 @@
+// Package capture records frames for clips: a rolling buffer of the last
+// seconds of indexed frames, written out as an animated GIF, an APNG or a
+// sequence of PNGs, scaled up by an integer factor.
+package capture
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Defaults for window recording
+const (
+	DefaultSeconds = 10 // Length of the rolling buffer
+	DefaultFPS     = 30 // Frames kept per second (GIF delays are in 1/100 s, so not 60)
+	TickRate       = 60 // Engine ticks per second
+)
+
+// Frame is a captured W×H frame: palette indices and the color of each index
+type Frame struct {
+	W, H    int
+	Pix     []uint8
+	Palette color.Palette
+}
+
+// Recorder keeps the most recent frames, one every TickRate/fps ticks
+type Recorder struct {
+	w, h   int
+	every  int     // Ticks per kept frame
+	tick   int     // Ticks since the last kept frame
+	frames []Frame // Ring buffer
+	start  int     // Oldest frame
+	n      int     // Frames held
+}
+
+// NewRecorder creates a recorder for w×h frames holding the last seconds
+// (at least one frame) at about fps frames per second: every TickRate/fps-th
+// tick, so FPS may be higher
+func NewRecorder(w, h int, seconds float64, fps int) *Recorder {
+	every := TickRate / min(max(fps, 1), TickRate)
+	size := max(1, int(math.Ceil(seconds*float64(TickRate/every))))
+	return &Recorder{w: w, h: h, every: every, frames: make([]Frame, size)}
+}
+
+// Size returns the frame size
+func (r *Recorder) Size() (w, h int) { return r.w, r.h }
+
+// FPS returns the frames kept per second
+func (r *Recorder) FPS() int { return TickRate / r.every }
+
+// Len returns the number of frames held
+func (r *Recorder) Len() int { return r.n }
+
+// Add is called once per tick with the frame's indices and colors (as from
+// graphics.Renderer.Indexed); every TickRate/fps-th frame is copied into the
+// buffer, replacing the oldest when full.
+func (r *Recorder) Add(idx []uint8, colors []color.RGBA) {
+	r.tick++
+	if r.tick < r.every && r.n > 0 {
+		return
+	}
+	r.tick = 0
+
+	i := (r.start + r.n) % len(r.frames)
+	if r.n == len(r.frames) {
+		r.start = (r.start + 1) % len(r.frames)
+	} else {
+		r.n++
+	}
+	f := &r.frames[i]
+	f.W, f.H = r.w, r.h
+	if len(f.Pix) != r.w*r.h {
+		f.Pix = make([]uint8, r.w*r.h)
+	}
+	copy(f.Pix, idx)
+	if len(f.Palette) != len(colors) {
+		f.Palette = make(color.Palette, len(colors))
+	}
+	for j, c := range colors {
+		f.Palette[j] = c
+	}
+}
+
+// Take returns the frames held, oldest first, and empties the buffer. The
+// frames are the caller's; later frames use new memory.
+func (r *Recorder) Take() []Frame {
+	out := make([]Frame, r.n)
+	for i := range out {
+		j := (r.start + i) % len(r.frames)
+		out[i] = r.frames[j]
+		r.frames[j] = Frame{}
+	}
+	r.start, r.n, r.tick = 0, 0, 0
+	return out
+}
+
+// scaled returns f as a paletted image scale times its size
+func (f Frame) scaled(scale int) *image.Paletted {
+	w, h := f.W, f.H
+	img := image.NewPaletted(image.Rect(0, 0, w*scale, h*scale), f.Palette)
+	for y := 0; y < h; y++ {
+		src := f.Pix[y*w : (y+1)*w]
+		row := img.Pix[y*scale*img.Stride : y*scale*img.Stride+w*scale]
+		for x, c := range src {
+			for k := 0; k < scale; k++ {
+				row[x*scale+k] = c
+			}
+		}
+		for k := 1; k < scale; k++ {
+			copy(img.Pix[(y*scale+k)*img.Stride:], row)
+		}
+	}
+	return img
+}
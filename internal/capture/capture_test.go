@@ -0,0 +1,140 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testColors = []color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {200, 40, 40, 255}}
+
+// record adds n ticks of 2×1 frames whose first pixel is the tick number mod 3
+func record(r *Recorder, n int) {
+	for i := 0; i < n; i++ {
+		r.Add([]uint8{uint8(i % 3), 1}, testColors)
+	}
+}
+
+func TestRecorderKeepsLastSeconds(t *testing.T) {
+	r := NewRecorder(2, 1, 1, 30)
+	if r.FPS() != 30 {
+		t.Fatalf("FPS = %d, want 30", r.FPS())
+	}
+	record(r, 100) // Keeps ticks 0, 2, 4, ... 98
+	if r.Len() != 30 {
+		t.Fatalf("Len = %d, want 30", r.Len())
+	}
+	frames := r.Take()
+	if len(frames) != 30 || r.Len() != 0 {
+		t.Fatalf("Take returned %d frames, %d left", len(frames), r.Len())
+	}
+	// Oldest first: tick 40 is the oldest of the last 30 kept
+	for i, f := range frames {
+		if want := uint8((40 + 2*i) % 3); f.Pix[0] != want || f.W != 2 || f.H != 1 {
+			t.Fatalf("frame %d = %+v, want first pixel %d", i, f, want)
+		}
+	}
+
+	// Taken frames are not overwritten by later ones
+	record(r, 10)
+	if frames[0].Pix[0] != 1 || r.Len() != 5 {
+		t.Fatalf("taken frame changed to %d, %d frames held", frames[0].Pix[0], r.Len())
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	r := NewRecorder(2, 1, 1, 30)
+	record(r, 6)
+	var buf bytes.Buffer
+	if err := WriteGIF(&buf, r.Take(), 3, 30); err != nil {
+		t.Fatalf("WriteGIF: %v", err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(g.Image) != 3 || g.Config.Width != 6 || g.Config.Height != 3 {
+		t.Fatalf("got %d frames of %dx%d", len(g.Image), g.Config.Width, g.Config.Height)
+	}
+	if g.Delay[0]+g.Delay[1]+g.Delay[2] != 10 {
+		t.Errorf("delays %v don't add up to 1/10 s", g.Delay)
+	}
+	// Frame 1 was tick 2: color 2 over the left 3×3 block, white on the right
+	img := g.Image[1]
+	if c := img.At(2, 2); c != color.Color(testColors[2]) {
+		t.Errorf("left block %v", c)
+	}
+	if c := img.At(3, 0); c != color.Color(testColors[1]) {
+		t.Errorf("right block %v", c)
+	}
+
+	if err := WriteGIF(&buf, nil, 2, 30); err != ErrNoFrames {
+		t.Errorf("expected ErrNoFrames, got %v", err)
+	}
+}
+
+func TestWriteAPNG(t *testing.T) {
+	r := NewRecorder(2, 1, 1, 60)
+	record(r, 3)
+	frames := r.Take()
+	frames[2].Palette = append(frames[2].Palette[:0:0], frames[2].Palette...)
+	frames[2].Palette[0] = color.RGBA{0, 0, 255, 255} // Palette change: frames become RGB
+
+	for name, frames := range map[string][]Frame{"paletted": frames[:2], "rgb": frames} {
+		var buf bytes.Buffer
+		if err := WriteAPNG(&buf, frames, 2, 60); err != nil {
+			t.Fatalf("%s: WriteAPNG: %v", name, err)
+		}
+		// Decoders without APNG support see the first frame
+		img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if r, g, b, _ := img.At(1, 1).RGBA(); r != 0 || g != 0 || b != 0 || img.Bounds().Dx() != 4 {
+			t.Errorf("%s: first frame pixel %d,%d,%d", name, r>>8, g>>8, b>>8)
+		}
+		chunks, err := readChunks(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts := map[string]int{}
+		for _, c := range chunks {
+			counts[c.kind]++
+			if c.kind == "acTL" && binary.BigEndian.Uint32(c.data) != uint32(len(frames)) {
+				t.Errorf("%s: acTL frame count %d", name, binary.BigEndian.Uint32(c.data))
+			}
+		}
+		if counts["fcTL"] != len(frames) || counts["fdAT"] < len(frames)-1 || counts["IEND"] != 1 {
+			t.Errorf("%s: chunks %v", name, counts)
+		}
+		if (counts["PLTE"] == 1) != (name == "paletted") {
+			t.Errorf("%s: PLTE chunks %d", name, counts["PLTE"])
+		}
+	}
+}
+
+func TestWriteFrames(t *testing.T) {
+	r := NewRecorder(2, 1, 1, 60)
+	record(r, 2)
+	dir := filepath.Join(t.TempDir(), "clip")
+	if err := WriteFrames(dir, r.Take(), 2); err != nil {
+		t.Fatalf("WriteFrames: %v", err)
+	}
+	f, err := os.Open(filepath.Join(dir, "frame-00001.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := color.RGBAModel.Convert(img.At(0, 0)); c != testColors[1] || img.Bounds().Dx() != 4 {
+		t.Errorf("frame 1 pixel %v, width %d", c, img.Bounds().Dx())
+	}
+}
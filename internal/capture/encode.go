@@ -0,0 +1,214 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrNoFrames is returned when there is nothing to write
+var ErrNoFrames = errors.New("capture: no frames")
+
+// delays returns each frame's duration in 1/100 s at fps, rounded so the
+// clip keeps time (e.g. 3, 4, 3 at 30 fps)
+func delays(n, fps int) []int {
+	d := make([]int, n)
+	for i := range d {
+		d[i] = (100*(i+1)+fps/2)/fps - (100*i+fps/2)/fps
+	}
+	return d
+}
+
+// WriteGIF writes frames as a looping animated GIF at fps frames per second,
+// scale times their size
+func WriteGIF(w io.Writer, frames []Frame, scale, fps int) error {
+	if len(frames) == 0 {
+		return ErrNoFrames
+	}
+	scale = max(scale, 1)
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, len(frames)),
+		Delay: delays(len(frames), fps),
+		Config: image.Config{
+			ColorModel: frames[0].Palette, // Global color table; frames with another palette get their own
+			Width:      frames[0].W * scale,
+			Height:     frames[0].H * scale,
+		},
+	}
+	for i, f := range frames {
+		g.Image[i] = f.scaled(scale)
+	}
+	return gif.EncodeAll(w, g)
+}
+
+// WriteAPNG writes frames as a looping animated PNG at fps frames per second,
+// scale times their size. Frames are paletted when they share a palette and
+// RGB otherwise.
+func WriteAPNG(w io.Writer, frames []Frame, scale, fps int) error {
+	if len(frames) == 0 {
+		return ErrNoFrames
+	}
+	scale = max(scale, 1)
+	shared := true
+	for _, f := range frames[1:] {
+		shared = shared && samePalette(f.Palette, frames[0].Palette)
+	}
+
+	var seq uint32
+	var buf bytes.Buffer
+	cw := &chunkWriter{w: w}
+	cw.write([]byte("\x89PNG\r\n\x1a\n"))
+	for i, f := range frames {
+		var img image.Image = f.scaled(scale)
+		if !shared {
+			rgba := image.NewRGBA(img.Bounds())
+			draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+			img = rgba
+		}
+		buf.Reset()
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		chunks, err := readChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			// Header and palette from the first frame, then the animation control
+			for _, c := range chunks {
+				if c.kind == "IHDR" || c.kind == "PLTE" || c.kind == "tRNS" {
+					cw.chunk(c.kind, c.data)
+				}
+				if c.kind == "IHDR" {
+					actl := make([]byte, 8)
+					binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+					cw.chunk("acTL", actl) // num_plays 0 = loop forever
+				}
+			}
+		}
+
+		b := img.Bounds()
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		binary.BigEndian.PutUint16(fctl[20:], 1)           // delay_num
+		binary.BigEndian.PutUint16(fctl[22:], uint16(fps)) // delay_den
+		seq++
+		cw.chunk("fcTL", fctl) // Offsets 0, dispose and blend ops 0 (none, source)
+
+		for _, c := range chunks {
+			if c.kind != "IDAT" {
+				continue
+			}
+			if i == 0 {
+				cw.chunk("IDAT", c.data)
+				continue
+			}
+			fdat := make([]byte, 4+len(c.data))
+			binary.BigEndian.PutUint32(fdat, seq)
+			copy(fdat[4:], c.data)
+			seq++
+			cw.chunk("fdAT", fdat)
+		}
+	}
+	cw.chunk("IEND", nil)
+	return cw.err
+}
+
+// WriteFrames writes frames to dir as frame-00000.png, frame-00001.png, ...
+// scale times their size, creating dir if needed
+func WriteFrames(dir string, frames []Frame, scale int) error {
+	if len(frames) == 0 {
+		return ErrNoFrames
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	scale = max(scale, 1)
+	for i, f := range frames {
+		out, err := os.Create(filepath.Join(dir, fmt.Sprintf("frame-%05d.png", i)))
+		if err != nil {
+			return err
+		}
+		err = png.Encode(out, f.scaled(scale))
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func samePalette(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type chunk struct {
+	kind string
+	data []byte
+}
+
+// readChunks splits an encoded PNG into its chunks
+func readChunks(data []byte) ([]chunk, error) {
+	if len(data) < 8 {
+		return nil, errors.New("capture: short PNG")
+	}
+	var chunks []chunk
+	for p := 8; p+12 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[p:]))
+		if p+12+n > len(data) {
+			return nil, errors.New("capture: truncated PNG chunk")
+		}
+		chunks = append(chunks, chunk{kind: string(data[p+4 : p+8]), data: data[p+8 : p+8+n]})
+		p += 12 + n
+	}
+	return chunks, nil
+}
+
+// chunkWriter writes PNG chunks, keeping the first error
+type chunkWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (cw *chunkWriter) write(b []byte) {
+	if cw.err == nil {
+		_, cw.err = cw.w.Write(b)
+	}
+}
+
+func (cw *chunkWriter) chunk(kind string, data []byte) {
+	var head [8]byte
+	binary.BigEndian.PutUint32(head[:4], uint32(len(data)))
+	copy(head[4:], kind)
+	crc := crc32.NewIEEE()
+	crc.Write(head[4:])
+	crc.Write(data)
+	var tail [4]byte
+	binary.BigEndian.PutUint32(tail[:], crc.Sum32())
+	cw.write(head[:])
+	cw.write(data)
+	cw.write(tail[:])
+}
@@ -0,0 +1,48 @@
+package cartio
+
+import "encoding/json"
+
+// AnimationsKey is the sprites.json key holding animation clips; it is not a sprite.
+const AnimationsKey = "animations"
+
+// AnimFrame is one frame of an animation clip
+type AnimFrame struct {
+	Sprite   string `json:"sprite"`             // Sprite drawn for this frame
+	Duration int    `json:"duration,omitempty"` // Milliseconds (0 = the clip's duration)
+	Event    string `json:"event,omitempty"`    // Event fired when the frame is entered
+}
+
+// UnmarshalJSON accepts either a frame object or just a sprite name
+func (f *AnimFrame) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*f = AnimFrame{Sprite: name}
+		return nil
+	}
+	type frame AnimFrame // Avoid recursing into this method
+	return json.Unmarshal(data, (*frame)(f))
+}
+
+// AnimClip is a named sequence of sprite frames
+type AnimClip struct {
+	Frames   []AnimFrame `json:"frames"`
+	Duration int         `json:"duration"` // Default frame duration in milliseconds (0 = 100)
+	Mode     string      `json:"mode"`     // "loop" (default), "pingpong" or "once"
+}
+
+// AnimationMap maps clip names to clips
+type AnimationMap map[string]AnimClip
+
+// ParseAnimations reads the animations section of sprites.json
+func ParseAnimations(data []byte) (AnimationMap, error) {
+	var file struct {
+		Animations AnimationMap `json:"animations"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Animations == nil {
+		file.Animations = make(AnimationMap)
+	}
+	return file.Animations, nil
+}
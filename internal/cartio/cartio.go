@@ -11,14 +11,30 @@ import (
 
 // Manifest is the minimal metadata stored in an .rfs
 type Manifest struct {
-	Title       string   `json:"title"`
-	Author      string   `json:"author"`
-	Description string   `json:"description"`
-	Genre       string   `json:"genre"`
-	Tags        []string `json:"tags"`
-	Entry       string   `json:"entry"`             // e.g. main.lua
-	Palette     string   `json:"palette,omitempty"` // Optional palette name (e.g., "RetroForge 50")
-	Scale       *int     `json:"scale,omitempty"`   // Optional default scale for cart display
+	Title       string     `json:"title"`
+	Author      string     `json:"author"`
+	Description string     `json:"description"`
+	Genre       string     `json:"genre"`
+	Tags        []string   `json:"tags"`
+	Entry       string     `json:"entry"`             // e.g. main.lua
+	Palette     string     `json:"palette,omitempty"` // Optional palette name (e.g., "RetroForge 50")
+	Scale       *int       `json:"scale,omitempty"`   // Optional default scale for cart display
+	Fonts       []FontSpec `json:"fonts,omitempty"`   // Optional bitmap fonts shipped in assets/
+	// Optional named actions and their bindings, e.g. "jump": ["btn:O", "key:space", "pad:a"]
+	Actions map[string][]string `json:"actions,omitempty"`
+}
+
+// FontSpec declares a cart font: a PNG glyph grid or a BDF file under assets/.
+type FontSpec struct {
+	Name       string         `json:"name"`
+	File       string         `json:"file"`                  // e.g. fonts/title.png
+	CellW      int            `json:"cell_w,omitempty"`      // PNG cell width
+	CellH      int            `json:"cell_h,omitempty"`      // PNG cell height
+	Chars      string         `json:"chars,omitempty"`       // PNG cell order (default ASCII 32..126)
+	Spacing    *int           `json:"spacing,omitempty"`     // Extra advance in pixels (default 1 for PNG, 0 for BDF)
+	Monospace  bool           `json:"monospace,omitempty"`   // PNG: fixed advance of one cell
+	LineHeight int            `json:"line_height,omitempty"` // Default: glyph height + 1
+	Kerning    map[string]int `json:"kerning,omitempty"`     // e.g. {"AV": -1}
 }
 
 // Asset represents a file to be packed.
@@ -28,8 +44,9 @@ type Asset struct {
 }
 
 // Write packs a manifest and assets into an .rfs (zip) archive.
-// Optionally includes sfx.json, music.json, and sprites.json if provided.
-func Write(w io.Writer, m Manifest, assets []Asset, sfx SFXMap, music MusicMap, sprites SpriteMap) error {
+// sfx.json, music.json and sprites.json are always written; animation clips,
+// if any, go in the animations section of sprites.json.
+func Write(w io.Writer, m Manifest, assets []Asset, sfx SFXMap, music MusicMap, sprites SpriteMap, anims AnimationMap) error {
 	zw := zip.NewWriter(w)
 	defer zw.Close()
 
@@ -72,7 +89,16 @@ func Write(w io.Writer, m Manifest, assets []Asset, sfx SFXMap, music MusicMap,
 	}
 	enc = json.NewEncoder(spritesFile)
 	enc.SetIndent("", "  ")
-	if err = enc.Encode(sprites); err != nil {
+	var spritesJSON interface{} = sprites
+	if len(anims) > 0 {
+		withAnims := make(map[string]interface{}, len(sprites)+1)
+		for name, sprite := range sprites {
+			withAnims[name] = sprite
+		}
+		withAnims[AnimationsKey] = anims
+		spritesJSON = withAnims
+	}
+	if err = enc.Encode(spritesJSON); err != nil {
 		return err
 	}
 
@@ -92,11 +118,12 @@ func Write(w io.Writer, m Manifest, assets []Asset, sfx SFXMap, music MusicMap,
 
 // ReadResult contains all data read from a cart
 type ReadResult struct {
-	Manifest Manifest
-	SFX      SFXMap
-	Music    MusicMap
-	Sprites  SpriteMap
-	Files    map[string][]byte
+	Manifest   Manifest
+	SFX        SFXMap
+	Music      MusicMap
+	Sprites    SpriteMap
+	Animations AnimationMap // Clips from the animations section of sprites.json
+	Files      map[string][]byte
 }
 
 // Read unpacks an .rfs archive into a manifest, sfx, music, and asset map.
@@ -109,6 +136,7 @@ func Read(r io.ReaderAt, size int64) (ReadResult, error) {
 	var sfxMap SFXMap
 	var musicMap MusicMap
 	var spriteMap SpriteMap
+	var animations AnimationMap
 	files := make(map[string][]byte)
 
 	for _, f := range zr.File {
@@ -146,6 +174,9 @@ func Read(r io.ReaderAt, size int64) (ReadResult, error) {
 				// If sprites.json is invalid, use empty map
 				spriteMap = make(SpriteMap)
 			}
+			if animations, err = ParseAnimations(buf.Bytes()); err != nil {
+				animations = make(AnimationMap)
+			}
 			continue
 		}
 		files[f.Name] = buf.Bytes()
@@ -161,13 +192,17 @@ func Read(r io.ReaderAt, size int64) (ReadResult, error) {
 	if spriteMap == nil {
 		spriteMap = make(SpriteMap)
 	}
+	if animations == nil {
+		animations = make(AnimationMap)
+	}
 
 	return ReadResult{
-		Manifest: m,
-		SFX:      sfxMap,
-		Music:    musicMap,
-		Sprites:  spriteMap,
-		Files:    files,
+		Manifest:   m,
+		SFX:        sfxMap,
+		Music:      musicMap,
+		Sprites:    spriteMap,
+		Animations: animations,
+		Files:      files,
 	}, nil
 }
 
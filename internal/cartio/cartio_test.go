@@ -10,7 +10,7 @@ func TestWriteReadRoundTrip(t *testing.T) {
 	assets := []Asset{{Name: "main.lua", Data: []byte("print('hi')")}, {Name: "sprites.png", Data: []byte{1, 2, 3}}}
 
 	var buf bytes.Buffer
-	if err := Write(&buf, m, assets, make(SFXMap), make(MusicMap), make(SpriteMap)); err != nil {
+	if err := Write(&buf, m, assets, make(SFXMap), make(MusicMap), make(SpriteMap), nil); err != nil {
 		t.Fatalf("write: %v", err)
 	}
 
@@ -29,6 +29,27 @@ func TestWriteReadRoundTrip(t *testing.T) {
 	}
 }
 
+func TestWriteAnimationsRoundTrip(t *testing.T) {
+	m := Manifest{Title: "Anim", Entry: "main.lua"}
+	sprites := SpriteMap{"walk1": {Width: 1, Height: 1, Pixels: [][]int{{2}}}}
+	anims := AnimationMap{"walk": {Frames: []AnimFrame{{Sprite: "walk1"}}, Mode: "loop"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, m, nil, make(SFXMap), make(MusicMap), sprites, anims); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	result, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if _, ok := result.Sprites["walk1"]; !ok || len(result.Sprites) != 1 {
+		t.Fatalf("expected only the walk1 sprite, got %v", result.Sprites)
+	}
+	if clip, ok := result.Animations["walk"]; !ok || clip.Frames[0].Sprite != "walk1" {
+		t.Fatalf("expected the walk clip to survive packing, got %v", result.Animations)
+	}
+}
+
 func TestSortedAssetNames(t *testing.T) {
 	m := map[string][]byte{
 		"zebra":  []byte{1, 2, 3},
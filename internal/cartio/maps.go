@@ -0,0 +1,124 @@
+package cartio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Map asset files. maps.bin is maps.json compressed with gzip, for large maps.
+const (
+	MapsFile    = "assets/maps.json"
+	MapsBinFile = "assets/maps.bin"
+)
+
+// Layer types
+const (
+	LayerTiles   = "tiles"
+	LayerObjects = "objects"
+)
+
+// Object shapes. Points of polygons and polylines are x, y pairs relative to the object.
+const (
+	ShapeRect     = "rect"
+	ShapeEllipse  = "ellipse"
+	ShapePoint    = "point"
+	ShapePolygon  = "polygon"
+	ShapePolyline = "polyline"
+)
+
+// CollisionType is the object type whose shapes become static physics geometry
+// when the map is loaded.
+const CollisionType = "collision"
+
+// MapObject is a typed object placed on an object layer (spawn points, triggers, ...)
+type MapObject struct {
+	ID         int                    `json:"id,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Shape      string                 `json:"shape,omitempty"` // "rect" (default), "ellipse", "point", "polygon" or "polyline"
+	X          float64                `json:"x"`
+	Y          float64                `json:"y"`
+	W          float64                `json:"w,omitempty"`
+	H          float64                `json:"h,omitempty"`
+	Points     []float64              `json:"points,omitempty"`     // Polygon/polyline x, y pairs
+	Tile       int                    `json:"tile,omitempty"`       // Tile drawn by the object (0 = none)
+	Properties map[string]interface{} `json:"properties,omitempty"` // Strings, numbers and booleans
+}
+
+// MapLayer is a tile layer or an object layer
+type MapLayer struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type,omitempty"`    // "tiles" (default) or "objects"
+	Tiles   []int       `json:"tiles,omitempty"`   // Row-major width×height tile indices (empty = all 0)
+	Objects []MapObject `json:"objects,omitempty"` // Objects on an object layer
+}
+
+// IsObjects reports whether the layer holds objects rather than tiles
+func (l MapLayer) IsObjects() bool {
+	return l.Type == LayerObjects
+}
+
+// MapData is one named map
+type MapData struct {
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	TileW  int        `json:"tile_w,omitempty"` // Tile size in pixels (0 = tileset cell size)
+	TileH  int        `json:"tile_h,omitempty"`
+	Layers []MapLayer `json:"layers"`
+}
+
+// MapSet maps names to maps
+type MapSet map[string]MapData
+
+// ParseMaps reads maps.json, or maps.bin when compressed is true, and checks
+// that every tile layer matches its map's size.
+func ParseMaps(data []byte, compressed bool) (MapSet, error) {
+	if compressed {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("maps.bin: %w", err)
+		}
+		defer zr.Close()
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("maps.bin: %w", err)
+		}
+	}
+	var maps MapSet
+	if err := json.Unmarshal(data, &maps); err != nil {
+		return nil, err
+	}
+	if maps == nil {
+		maps = make(MapSet)
+	}
+	for name, m := range maps {
+		if m.Width <= 0 || m.Height <= 0 {
+			return nil, fmt.Errorf("map %s: width and height must be positive", name)
+		}
+		for _, l := range m.Layers {
+			if !l.IsObjects() && len(l.Tiles) != 0 && len(l.Tiles) != m.Width*m.Height {
+				return nil, fmt.Errorf("map %s: layer %s has %d tiles, expected %d", name, l.Name, len(l.Tiles), m.Width*m.Height)
+			}
+		}
+	}
+	return maps, nil
+}
+
+// EncodeMaps writes maps as indented maps.json, or as maps.bin when compressed is true.
+func EncodeMaps(maps MapSet, compressed bool) ([]byte, error) {
+	data, err := json.MarshalIndent(maps, "", "  ")
+	if err != nil || !compressed {
+		return data, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
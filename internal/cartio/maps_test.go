@@ -0,0 +1,60 @@
+package cartio
+
+import "testing"
+
+const testMaps = `{
+	"level1": {
+		"width": 2, "height": 2, "tile_w": 16, "tile_h": 16,
+		"layers": [
+			{"name": "ground", "tiles": [1, 2, 3, 4]},
+			{"name": "deco"},
+			{"name": "spawns", "type": "objects", "objects": [
+				{"id": 1, "name": "p1", "type": "player", "x": 8, "y": 16,
+				 "properties": {"hp": 3, "boss": false, "tag": "start"}}
+			]}
+		]
+	}
+}`
+
+func TestParseMaps(t *testing.T) {
+	maps, err := ParseMaps([]byte(testMaps), false)
+	if err != nil {
+		t.Fatalf("ParseMaps: %v", err)
+	}
+	m, ok := maps["level1"]
+	if !ok || m.Width != 2 || m.TileW != 16 || len(m.Layers) != 3 {
+		t.Fatalf("unexpected map %+v", m)
+	}
+	if m.Layers[0].IsObjects() || !m.Layers[2].IsObjects() {
+		t.Fatal("expected the third layer to be the only object layer")
+	}
+	obj := m.Layers[2].Objects[0]
+	if obj.Type != "player" || obj.X != 8 || obj.Properties["hp"] != float64(3) || obj.Properties["boss"] != false {
+		t.Fatalf("unexpected object %+v", obj)
+	}
+
+	// maps.bin round-trips through gzip
+	bin, err := EncodeMaps(maps, true)
+	if err != nil {
+		t.Fatalf("EncodeMaps: %v", err)
+	}
+	again, err := ParseMaps(bin, true)
+	if err != nil {
+		t.Fatalf("ParseMaps(bin): %v", err)
+	}
+	if got := again["level1"].Layers[0].Tiles; len(got) != 4 || got[3] != 4 {
+		t.Fatalf("unexpected tiles after round trip %v", got)
+	}
+}
+
+func TestParseMapsErrors(t *testing.T) {
+	if _, err := ParseMaps([]byte(`{"a": {"width": 0, "height": 2}}`), false); err == nil {
+		t.Fatal("expected error for zero width")
+	}
+	if _, err := ParseMaps([]byte(`{"a": {"width": 2, "height": 2, "layers": [{"name": "x", "tiles": [1]}]}}`), false); err == nil {
+		t.Fatal("expected error for wrong tile count")
+	}
+	if _, err := ParseMaps([]byte(testMaps), true); err == nil {
+		t.Fatal("expected error for uncompressed maps.bin")
+	}
+}
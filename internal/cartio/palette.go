@@ -0,0 +1,46 @@
+package cartio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// PalettesFile holds the cart's own named palettes
+const PalettesFile = "assets/palette.json"
+
+// paletteDef is a palette as written in palette.json
+type paletteDef struct {
+	Name   string   `json:"name"`
+	Colors []string `json:"colors"`
+}
+
+// ParsePalettes reads palette.json: a single {"name", "colors"} object or an
+// array of them, each with a name and 50 #RRGGBB colors
+func ParsePalettes(data []byte) ([]palette.Palette, error) {
+	var defs []paletteDef
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var def paletteDef
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	} else if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, err
+	}
+
+	out := make([]palette.Palette, 0, len(defs))
+	for i, def := range defs {
+		if def.Name == "" {
+			return nil, fmt.Errorf("palette %d has no name", i)
+		}
+		p, err := palette.New(def.Name, def.Colors)
+		if err != nil {
+			return nil, fmt.Errorf("palette %s: %w", def.Name, err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package cartio
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func paletteJSON(name string, n int) string {
+	colors := make([]string, n)
+	for i := range colors {
+		colors[i] = fmt.Sprintf("%q", fmt.Sprintf("#%02X0000", i))
+	}
+	return fmt.Sprintf(`{"name": %q, "colors": [%s]}`, name, strings.Join(colors, ","))
+}
+
+func TestParsePalettes(t *testing.T) {
+	one, err := ParsePalettes([]byte(paletteJSON("Dusk", 50)))
+	if err != nil {
+		t.Fatalf("ParsePalettes: %v", err)
+	}
+	if len(one) != 1 || one[0].Name != "Dusk" || one[0].Colors[10] != "#0a0000" {
+		t.Errorf("unexpected palettes %+v", one)
+	}
+
+	many, err := ParsePalettes([]byte("[" + paletteJSON("A", 50) + "," + paletteJSON("B", 50) + "]"))
+	if err != nil || len(many) != 2 || many[1].Name != "B" {
+		t.Fatalf("ParsePalettes array = %v, %v", many, err)
+	}
+
+	for name, data := range map[string]string{
+		"short":   paletteJSON("X", 49),
+		"no name": paletteJSON("", 50),
+		"bad hex": strings.Replace(paletteJSON("X", 50), "#05", "05", 1),
+		"invalid": `{"name": 5}`,
+	} {
+		if _, err := ParsePalettes([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cartio
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ParticlesFile holds the cart's particle emitter definitions
+const ParticlesFile = "assets/particles.json"
+
+// Particle shapes
+const (
+	ShapePixel  = "pixel"
+	ShapeCircle = "circle"
+	ShapeSprite = "sprite"
+)
+
+// Range is a min..max interval values are picked from. In JSON it is either
+// [min, max] or a single number.
+type Range struct {
+	Min, Max float64
+}
+
+// UnmarshalJSON accepts either [min, max] or a number
+func (r *Range) UnmarshalJSON(data []byte) error {
+	var v float64
+	if err := json.Unmarshal(data, &v); err == nil {
+		*r = Range{v, v}
+		return nil
+	}
+	var pair []float64
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("range must be a number or [min, max]")
+	}
+	*r = Range{pair[0], pair[1]}
+	return nil
+}
+
+// MarshalJSON writes a range as [min, max]
+func (r Range) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]float64{r.Min, r.Max})
+}
+
+// EmitterDef describes a particle emitter
+type EmitterDef struct {
+	Rate    float64    `json:"rate,omitempty"`   // Particles per second (0 = bursts only)
+	Burst   int        `json:"burst,omitempty"`  // Particles emitted when the emitter is created
+	Max     int        `json:"max,omitempty"`    // Live particle limit (0 = 512)
+	Life    Range      `json:"life"`             // Lifetime in seconds
+	Speed   Range      `json:"speed"`            // Pixels per second
+	Angle   Range      `json:"angle"`            // Degrees, 0 = right, clockwise
+	Spread  Range      `json:"spread"`           // Spawn distance from the emitter in pixels
+	Gravity [2]float64 `json:"gravity"`          // Acceleration in pixels per second²
+	Drag    float64    `json:"drag,omitempty"`   // Fraction of velocity lost per second
+	Colors  []int      `json:"colors,omitempty"` // Palette indices ramped over the lifetime (default white)
+	Sizes   []float64  `json:"sizes,omitempty"`  // Sizes interpolated over the lifetime (default 1)
+	Shape   string     `json:"shape,omitempty"`  // "pixel" (default), "circle" or "sprite"
+	Sprite  string     `json:"sprite,omitempty"` // Sprite drawn by "sprite" particles
+	Layer   int        `json:"layer,omitempty"`  // Layer drawn by rf.particles_draw(layer)
+}
+
+// ParticleSet maps emitter names to definitions
+type ParticleSet map[string]EmitterDef
+
+// Validate checks an emitter definition
+func (d EmitterDef) Validate() error {
+	switch d.Shape {
+	case "", ShapePixel, ShapeCircle:
+	case ShapeSprite:
+		if d.Sprite == "" {
+			return fmt.Errorf("sprite particles need a sprite")
+		}
+	default:
+		return fmt.Errorf("unknown shape %q", d.Shape)
+	}
+	if d.Rate < 0 || d.Burst < 0 || d.Max < 0 || d.Drag < 0 {
+		return fmt.Errorf("rate, burst, max and drag must not be negative")
+	}
+	if d.Life.Max <= 0 || d.Life.Min > d.Life.Max {
+		return fmt.Errorf("life must be a positive range")
+	}
+	return nil
+}
+
+// ParseEmitter reads one emitter definition
+func ParseEmitter(data []byte) (EmitterDef, error) {
+	var def EmitterDef
+	if err := json.Unmarshal(data, &def); err != nil {
+		return def, err
+	}
+	return def, def.Validate()
+}
+
+// ParseParticles reads particles.json and checks every emitter
+func ParseParticles(data []byte) (ParticleSet, error) {
+	var set ParticleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	if set == nil {
+		set = make(ParticleSet)
+	}
+	for name, def := range set {
+		if err := def.Validate(); err != nil {
+			return nil, fmt.Errorf("emitter %s: %w", name, err)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,34 @@
+package cartio
+
+import "testing"
+
+func TestParseParticles(t *testing.T) {
+	set, err := ParseParticles([]byte(`{
+		"spark": {"burst": 20, "life": [0.2, 0.6], "speed": 40, "angle": [0, 360],
+		          "gravity": [0, 50], "colors": [10, 9, 8], "sizes": [2, 0], "shape": "circle"},
+		"puff": {"rate": 30, "life": 1, "shape": "sprite", "sprite": "smoke", "layer": 2}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseParticles: %v", err)
+	}
+	spark := set["spark"]
+	if spark.Burst != 20 || spark.Life != (Range{0.2, 0.6}) || spark.Speed != (Range{40, 40}) || spark.Gravity[1] != 50 {
+		t.Errorf("unexpected spark %+v", spark)
+	}
+	if puff := set["puff"]; puff.Sprite != "smoke" || puff.Layer != 2 || puff.Life != (Range{1, 1}) {
+		t.Errorf("unexpected puff %+v", puff)
+	}
+
+	for name, data := range map[string]string{
+		"no life":     `{"x": {}}`,
+		"bad shape":   `{"x": {"life": 1, "shape": "cube"}}`,
+		"no sprite":   `{"x": {"life": 1, "shape": "sprite"}}`,
+		"bad range":   `{"x": {"life": [1, 2, 3]}}`,
+		"negative":    `{"x": {"life": 1, "rate": -1}}`,
+		"not objects": `[]`,
+	} {
+		if _, err := ParseParticles([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
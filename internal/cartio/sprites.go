@@ -1,5 +1,7 @@
 package cartio
 
+import "encoding/json"
+
 // MountPoint represents a point within a sprite where projectiles/thrusters originate
 type MountPoint struct {
 	X    int    `json:"x"`              // X coordinate within sprite bounds
@@ -21,3 +23,25 @@ type SpriteData struct {
 
 // SpriteMap maps sprite names to their data
 type SpriteMap map[string]SpriteData
+
+// UnmarshalJSON reads sprites.json, skipping the animations section
+func (m *SpriteMap) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = make(SpriteMap)
+	}
+	for name, v := range raw {
+		if name == AnimationsKey {
+			continue
+		}
+		var sprite SpriteData
+		if err := json.Unmarshal(v, &sprite); err != nil {
+			return err
+		}
+		(*m)[name] = sprite
+	}
+	return nil
+}
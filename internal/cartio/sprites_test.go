@@ -1,6 +1,7 @@
 package cartio
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -54,3 +55,36 @@ func TestSpriteMap(t *testing.T) {
 		t.Error("Sprite MaxSpawn not set correctly")
 	}
 }
+
+func TestSpritesJSONAnimations(t *testing.T) {
+	data := []byte(`{
+		"hero1": {"width": 1, "height": 1, "pixels": [[2]]},
+		"hero2": {"width": 1, "height": 1, "pixels": [[3]]},
+		"animations": {
+			"run": {"duration": 80, "mode": "pingpong", "frames": ["hero1", {"sprite": "hero2", "duration": 120, "event": "step"}]}
+		}
+	}`)
+
+	var sprites SpriteMap
+	if err := json.Unmarshal(data, &sprites); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(sprites) != 2 {
+		t.Fatalf("expected 2 sprites (animations skipped), got %d", len(sprites))
+	}
+
+	anims, err := ParseAnimations(data)
+	if err != nil {
+		t.Fatalf("ParseAnimations: %v", err)
+	}
+	run, ok := anims["run"]
+	if !ok || run.Mode != "pingpong" || run.Duration != 80 || len(run.Frames) != 2 {
+		t.Fatalf("unexpected clip %+v", run)
+	}
+	if run.Frames[0] != (AnimFrame{Sprite: "hero1"}) {
+		t.Fatalf("string frame should become a sprite name, got %+v", run.Frames[0])
+	}
+	if run.Frames[1] != (AnimFrame{Sprite: "hero2", Duration: 120, Event: "step"}) {
+		t.Fatalf("unexpected frame %+v", run.Frames[1])
+	}
+}
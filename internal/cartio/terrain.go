@@ -0,0 +1,38 @@
+package cartio
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TerrainsFile names the cart's voxel terrains
+const TerrainsFile = "assets/terrains.json"
+
+// TerrainDef pairs a heightmap with a colormap, both images under assets/ of
+// the same size. A heightmap pixel's palette index (or gray level) is the
+// ground height there; a colormap pixel's palette index (or nearest cart
+// palette color) is the ground color.
+type TerrainDef struct {
+	Heightmap string `json:"heightmap"`
+	Colormap  string `json:"colormap"`
+}
+
+// TerrainSet maps terrain names to definitions
+type TerrainSet map[string]TerrainDef
+
+// ParseTerrains reads terrains.json and checks every terrain names both images
+func ParseTerrains(data []byte) (TerrainSet, error) {
+	var set TerrainSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	if set == nil {
+		set = make(TerrainSet)
+	}
+	for name, def := range set {
+		if def.Heightmap == "" || def.Colormap == "" {
+			return nil, fmt.Errorf("terrain %s: needs a heightmap and a colormap", name)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,23 @@
+package cartio
+
+import "testing"
+
+func TestParseTerrains(t *testing.T) {
+	set, err := ParseTerrains([]byte(`{"valley": {"heightmap": "h.png", "colormap": "c.png"}}`))
+	if err != nil {
+		t.Fatalf("ParseTerrains: %v", err)
+	}
+	if def := set["valley"]; def.Heightmap != "h.png" || def.Colormap != "c.png" {
+		t.Errorf("unexpected valley %+v", def)
+	}
+
+	for name, data := range map[string]string{
+		"no heightmap": `{"x": {"colormap": "c.png"}}`,
+		"no colormap":  `{"x": {"heightmap": "h.png"}}`,
+		"not objects":  `[]`,
+	} {
+		if _, err := ParseTerrains([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
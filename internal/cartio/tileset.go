@@ -0,0 +1,37 @@
+package cartio
+
+import "encoding/json"
+
+// TilesetFile is the cart's tile definitions (assets/tileset.json)
+const TilesetFile = "assets/tileset.json"
+
+// TileDef describes what a tile index draws
+type TileDef struct {
+	Sprite string      `json:"sprite,omitempty"` // Sprite drawn for the tile
+	Cell   *int        `json:"cell,omitempty"`   // Cell of the sheet sprite (used when sprite is empty)
+	Flags  uint8       `json:"flags,omitempty"`  // Flag bits for rf.fget and rf.map filters
+	Anim   []TileFrame `json:"anim,omitempty"`   // Animated tiles cycle through these tiles
+}
+
+// TileFrame is one frame of an animated tile
+type TileFrame struct {
+	Tile     int `json:"tile"`     // Tile index drawn for this frame
+	Duration int `json:"duration"` // Milliseconds
+}
+
+// TilesetData maps tile indices to sprites or sheet cells
+type TilesetData struct {
+	Sheet string          `json:"sheet,omitempty"` // Sprite cut into tile_w×tile_h cells
+	TileW int             `json:"tile_w"`          // Cell and default map tile width (0 = 8)
+	TileH int             `json:"tile_h"`          // Cell and default map tile height (0 = 8)
+	Tiles map[int]TileDef `json:"tiles"`           // Tile index -> definition
+}
+
+// ParseTileset reads tileset.json
+func ParseTileset(data []byte) (TilesetData, error) {
+	var ts TilesetData
+	if err := json.Unmarshal(data, &ts); err != nil {
+		return TilesetData{}, err
+	}
+	return ts, nil
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+)
+
+// loadActions declares the manifest's actions and applies the player's saved
+// rebinding for this cart
+func (e *Engine) loadActions(m cartio.Manifest) error {
+	if err := input.SetActions(m.Actions); err != nil {
+		return err
+	}
+	e.bindings = bindingsFile(m)
+	if e.bindings == "" {
+		return nil
+	}
+	data, err := os.ReadFile(e.bindings)
+	if err != nil {
+		return nil // Nothing rebound yet
+	}
+	var saved map[string][]string
+	if err := json.Unmarshal(data, &saved); err != nil {
+		// A damaged file only loses the rebinding
+		e.warn(fmt.Sprintf("Ignoring saved bindings %s: %v", e.bindings, err))
+		return nil
+	}
+	names := make([]string, 0, len(saved))
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// Actions the cart no longer declares are dropped at the next save
+		if !input.HasAction(name) {
+			e.warn(fmt.Sprintf("Ignoring saved bindings for %q: the cart has no such action", name))
+			continue
+		}
+		bindings, err := input.ParseBindings(saved[name])
+		if err != nil {
+			e.warn(fmt.Sprintf("Ignoring saved bindings for %q: %v", name, err))
+			continue
+		}
+		input.Bind(name, bindings)
+	}
+	return nil
+}
+
+// warn reports a problem that doesn't stop the cart, in the dev mode log when
+// the cart runs from a folder
+func (e *Engine) warn(msg string) {
+	if e.devMode != nil {
+		e.devMode.AddDebugLog(msg)
+		return
+	}
+	log.Print(msg)
+}
+
+// saveBindings writes the actions the player rebound, or removes the file
+// when none are
+func (e *Engine) saveBindings() error {
+	if e.bindings == "" {
+		return nil
+	}
+	rebound := input.Rebound()
+	if len(rebound) == 0 {
+		if err := os.Remove(e.bindings); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(rebound, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.bindings), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(e.bindings, data, 0644)
+}
+
+// bindingsFile is where a cart's rebinding is kept: in the user's config
+// directory, named after the cart's title and author so carts sharing a title
+// keep their own. Carts without actions have none.
+func bindingsFile(m cartio.Manifest) string {
+	if len(m.Actions) == 0 {
+		return ""
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	id := slug(m.Title)
+	if id == "" {
+		id = "untitled"
+	}
+	if author := slug(m.Author); author != "" {
+		id += "-by-" + author
+	}
+	return filepath.Join(dir, "retroforge", "bindings", id+".json")
+}
+
+// slug lowercases s and turns everything but letters and digits into dashes
+func slug(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, strings.TrimSpace(s))
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+)
+
+func TestActionsSavedPerCart(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer input.SetActions(nil)
+	defer input.Reset()
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "assets"), 0755)
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Jump Man!", "author": "Ann", "entry": "main.lua",
+		"actions": {"jump": ["btn:O", "key:space", "pad:a"], "duck": ["key:s"]}}`), 0644)
+	os.WriteFile(filepath.Join(dir, "assets", "main.lua"), []byte(`function _update() jumping = rf.action("jump") end`), 0644)
+
+	e := New(60)
+	defer e.Close()
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder: %v", err)
+	}
+	if filepath.Base(e.bindings) != "jump-man--by-ann.json" {
+		t.Errorf("bindings file = %s", e.bindings)
+	}
+
+	w, _ := input.ParseBinding("key:w")
+	input.Rebind("jump", 0, w)
+	if err := e.saveBindings(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Loading the cart again brings back the player's bindings
+	input.ResetBindings()
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder: %v", err)
+	}
+	input.SetKey("w", true)
+	if !input.Action("jump", 0) {
+		t.Error("saved binding key:w not restored")
+	}
+
+	// Saved actions the cart no longer declares, and bindings that don't
+	// parse, are reported and skipped
+	os.WriteFile(e.bindings, []byte(`{"jump": ["key:w"], "dash": ["key:x"], "duck": ["button:O"]}`), 0644)
+	input.ResetBindings()
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder with stale bindings: %v", err)
+	}
+	if !input.Action("jump", 0) {
+		t.Error("saved binding key:w not restored next to stale entries")
+	}
+	logs := strings.Join(e.devMode.GetDebugLogs(), "\n")
+	for _, want := range []string{`"dash": the cart has no such action`, `"duck": `} {
+		if !strings.Contains(logs, "Ignoring saved bindings for "+want) {
+			t.Errorf("%s not reported in %q", want, logs)
+		}
+	}
+	if got := input.Bindings("duck")[0].String(); got != "key:s" {
+		t.Errorf("duck = %s after a bad saved binding, want the default key:s", got)
+	}
+
+	// Back to the defaults, the file goes away
+	input.ResetBindings()
+	if err := e.saveBindings(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(e.bindings); !os.IsNotExist(err) {
+		t.Errorf("bindings file left behind: %v", err)
+	}
+
+	// Bad bindings in the manifest are an error
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Jump", "entry": "main.lua",
+		"actions": {"jump": ["button:O"]}}`), 0644)
+	if err := e.LoadCartFolder(dir); err == nil {
+		t.Error("expected an error for a bad binding")
+	}
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// loadCartAssets loads what a cart's manifest and data files describe: its
+// palettes, actions, fonts, maps, particle emitters and voxel terrains. read
+// reads a file by its path in the cart ("assets/..." for the cart's assets)
+// and fails with os.ErrNotExist for missing files. The tileset definition is
+// returned unbuilt, so a cart folder can add its Tiled maps to it first.
+func (e *Engine) loadCartAssets(m cartio.Manifest, read func(file string) ([]byte, error)) (cartio.TilesetData, error) {
+	// Register cart palettes and set the manifest's palette
+	if err := e.loadPalettes(m.Palette, read); err != nil {
+		return cartio.TilesetData{}, err
+	}
+
+	// Declare the manifest's actions
+	if err := e.loadActions(m); err != nil {
+		return cartio.TilesetData{}, err
+	}
+
+	// Load fonts listed in the manifest
+	fonts, err := loadFonts(m.Fonts, func(file string) ([]byte, error) {
+		return read("assets/" + file)
+	})
+	if err != nil {
+		return cartio.TilesetData{}, err
+	}
+	e.fonts = fonts
+
+	// Load the tileset (optional)
+	tilesetData, _ := read(cartio.TilesetFile)
+	tilesetDef, err := parseTileset(tilesetData)
+	if err != nil {
+		return cartio.TilesetData{}, err
+	}
+
+	// Load maps (optional)
+	if e.maps, e.mapsBin, err = loadMaps(read); err != nil {
+		return cartio.TilesetData{}, err
+	}
+
+	// Load particle emitters (optional)
+	if e.particles, err = loadParticles(read); err != nil {
+		return cartio.TilesetData{}, err
+	}
+
+	// Load voxel terrains (optional)
+	if e.terrains, err = loadTerrains(read, e.Pal.Colors()); err != nil {
+		return cartio.TilesetData{}, err
+	}
+	return tilesetDef, nil
+}
+
+// loadCartFolderAssets loads a cart folder's assets (see loadCartAssets),
+// sounds, music and sprites, imports its Tiled maps and returns the entry
+// script, for LoadCartFolder and ReloadCart.
+func (e *Engine) loadCartFolderAssets(cartPath string, m cartio.Manifest) ([]byte, error) {
+	tilesetDef, err := e.loadCartAssets(m, func(file string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(cartPath, filepath.FromSlash(file)))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Load main.lua
+	entryPath := filepath.Join(cartPath, "assets", m.Entry)
+	src, err := os.ReadFile(entryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entry file %s: %w", entryPath, err)
+	}
+
+	// Load SFX
+	sfxPath := filepath.Join(cartPath, "assets", "sfx.json")
+	e.sfxMap = make(cartio.SFXMap)
+	if b, err := os.ReadFile(sfxPath); err == nil {
+		json.Unmarshal(b, &e.sfxMap)
+	}
+
+	// Load Music
+	musicPath := filepath.Join(cartPath, "assets", "music.json")
+	e.musicMap = make(cartio.MusicMap)
+	if b, err := os.ReadFile(musicPath); err == nil {
+		json.Unmarshal(b, &e.musicMap)
+	}
+
+	// Load Sprites
+	spritesPath := filepath.Join(cartPath, "assets", "sprites.json")
+	e.spritesMap = make(cartio.SpriteMap)
+	e.animations = make(cartio.AnimationMap)
+	if b, err := os.ReadFile(spritesPath); err == nil {
+		json.Unmarshal(b, &e.spritesMap)
+		if anims, err := cartio.ParseAnimations(b); err == nil {
+			e.animations = anims
+		}
+	}
+
+	// Import Tiled maps (.tmx/.tmj) into the maps, sprites and tileset
+	if err := e.importTiled(cartPath, &tilesetDef); err != nil {
+		return nil, err
+	}
+	e.tileset = buildTileset(tilesetDef)
+	return src, nil
+}
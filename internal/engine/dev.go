@@ -216,37 +216,10 @@ func (e *Engine) LoadCartFolder(cartPath string) error {
 		return fmt.Errorf("failed to parse manifest.json: %w", err)
 	}
 
-	// Set palette from manifest if specified
-	if m.Palette != "" {
-		e.Pal.Set(m.Palette)
-	}
-
-	// Load main.lua
-	entryPath := filepath.Join(cartPath, "assets", m.Entry)
-	src, err := os.ReadFile(entryPath)
+	// Load the cart's assets and entry script
+	src, err := e.loadCartFolderAssets(cartPath, m)
 	if err != nil {
-		return fmt.Errorf("failed to read entry file %s: %w", entryPath, err)
-	}
-
-	// Load SFX
-	sfxPath := filepath.Join(cartPath, "assets", "sfx.json")
-	e.sfxMap = make(cartio.SFXMap)
-	if b, err := os.ReadFile(sfxPath); err == nil {
-		json.Unmarshal(b, &e.sfxMap)
-	}
-
-	// Load Music
-	musicPath := filepath.Join(cartPath, "assets", "music.json")
-	e.musicMap = make(cartio.MusicMap)
-	if b, err := os.ReadFile(musicPath); err == nil {
-		json.Unmarshal(b, &e.musicMap)
-	}
-
-	// Load Sprites
-	spritesPath := filepath.Join(cartPath, "assets", "sprites.json")
-	e.spritesMap = make(cartio.SpriteMap)
-	if b, err := os.ReadFile(spritesPath); err == nil {
-		json.Unmarshal(b, &e.spritesMap)
+		return err
 	}
 
 	// Register Lua bindings first (creates rf table)
@@ -323,35 +296,10 @@ func (e *Engine) ReloadCart() error {
 		return fmt.Errorf("failed to parse manifest.json: %w", err)
 	}
 
-	// Set palette from manifest if specified
-	if m.Palette != "" {
-		e.Pal.Set(m.Palette)
-	}
-
-	// Load main.lua
-	entryPath := filepath.Join(cartPath, "assets", m.Entry)
-	src, err := os.ReadFile(entryPath)
+	// Load the cart's assets and entry script
+	src, err := e.loadCartFolderAssets(cartPath, m)
 	if err != nil {
-		return fmt.Errorf("failed to read entry file %s: %w", entryPath, err)
-	}
-
-	// Reload SFX, Music, Sprites
-	sfxPath := filepath.Join(cartPath, "assets", "sfx.json")
-	e.sfxMap = make(cartio.SFXMap)
-	if b, err := os.ReadFile(sfxPath); err == nil {
-		json.Unmarshal(b, &e.sfxMap)
-	}
-
-	musicPath := filepath.Join(cartPath, "assets", "music.json")
-	e.musicMap = make(cartio.MusicMap)
-	if b, err := os.ReadFile(musicPath); err == nil {
-		json.Unmarshal(b, &e.musicMap)
-	}
-
-	spritesPath := filepath.Join(cartPath, "assets", "sprites.json")
-	e.spritesMap = make(cartio.SpriteMap)
-	if b, err := os.ReadFile(spritesPath); err == nil {
-		json.Unmarshal(b, &e.spritesMap)
+		return err
 	}
 
 	// Register Lua bindings first (creates rf table)
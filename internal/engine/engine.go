@@ -8,6 +8,7 @@ import (
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
 	"github.com/AndrewDonelson/retroforge-engine/internal/eventbus"
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
 	"github.com/AndrewDonelson/retroforge-engine/internal/gamestate"
 	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
 	"github.com/AndrewDonelson/retroforge-engine/internal/lua"
@@ -18,6 +19,7 @@ import (
 	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
 	"github.com/AndrewDonelson/retroforge-engine/internal/runner"
 	"github.com/AndrewDonelson/retroforge-engine/internal/scheduler"
+	"github.com/AndrewDonelson/retroforge-engine/internal/voxel"
 )
 
 // Engine wires together bus, scheduler/runner, and Lua VM for headless runs.
@@ -34,7 +36,17 @@ type Engine struct {
 	sfxMap     cartio.SFXMap
 	musicMap   cartio.MusicMap
 	spritesMap cartio.SpriteMap
-	devMode    *DevMode // Development mode (only when loading from folder)
+	fonts      map[string]*font.Font
+	tileset    *graphics.Tileset
+	maps       cartio.MapSet
+	mapsBin    bool            // Maps came from maps.bin (rf.map_save writes it back)
+	tiledMaps  map[string]bool // Maps imported from Tiled in dev mode (not saved by rf.map_save)
+	animations cartio.AnimationMap
+	particles  cartio.ParticleSet // Emitter definitions from particles.json
+	terrains   voxel.Terrains     // Voxel terrains from terrains.json
+	luaState   *luabind.State     // Binding state (particles and palette effects are ticked by the engine)
+	devMode    *DevMode           // Development mode (only when loading from folder)
+	bindings   string             // Where the player's rebinding of the cart's actions is saved
 }
 
 func New(targetFPS int) *Engine {
@@ -82,6 +94,11 @@ func New(targetFPS int) *Engine {
 			// Update network frame (for multiplayer sync)
 			e.Network.UpdateFrame(dt)
 
+			// Advance animators, particles and palette fades, cycles and flashes
+			if e.luaState != nil {
+				e.luaState.Tick(dtSec, e.Ren)
+			}
+
 			// Use state machine if it has active states, otherwise fall back to direct Lua calls
 			if e.GSM != nil {
 				_, hasActiveState := e.GSM.GetActiveState()
@@ -103,6 +120,11 @@ func New(targetFPS int) *Engine {
 				_ = e.VM.CallDraw()
 			}
 
+			// Light the frame unless the cart already did (rf.light_apply)
+			if e.luaState != nil {
+				e.luaState.EndFrame(e.Ren)
+			}
+
 			// Update debug stats (development mode only)
 			if e.devMode != nil && e.devMode.IsEnabled() {
 				fps := 1.0 / dtSec
@@ -173,34 +195,53 @@ func (e *Engine) registerLuaBindings() {
 	if e.GSM != nil {
 		e.GSM.SetRenderer(e.Ren)
 		e.GSM.SetPalette(e.Pal)
+		e.GSM.SetBindingSaver(e.saveBindings)
 	}
 
+	e.luaState = luabind.NewState()
+	e.luaState.SetFonts(e.fonts)
+	if e.tileset != nil {
+		e.luaState.SetTileset(e.tileset)
+	}
+	e.luaState.SetMaps(e.maps)
+	if e.devMode != nil && e.devMode.IsEnabled() {
+		e.luaState.SetMapSaver(e.saveMaps)
+	}
+	e.luaState.SetAnimations(e.animations)
+	e.luaState.SetParticles(e.particles)
+	e.luaState.SetTerrains(e.terrains)
 	if e.devMode != nil && e.devMode.IsEnabled() {
 		// Create adapter that implements DevModeHandler interface
 		devAdapter := &devModeAdapter{devMode: e.devMode}
-		luabind.RegisterWithDev(e.VM.L, e.Ren, func(i int) (c [4]uint8) {
+		luabind.RegisterWithDevMode(e.VM.L, e.Ren, func(i int) (c [4]uint8) {
 			col := e.Pal.Color(i)
 			c[0] = col.R
 			c[1] = col.G
 			c[2] = col.B
 			c[3] = col.A
 			return
-		}, e.Pal.Set, e.sfxMap, e.musicMap, e.spritesMap, e.Physics, devAdapter, e.Network)
+		}, e.Pal.Set, e.sfxMap, e.musicMap, e.spritesMap, e.Physics, e.luaState, devAdapter, e.Network)
 	} else {
-		luabind.Register(e.VM.L, e.Ren, func(i int) (c [4]uint8) {
+		luabind.RegisterWithState(e.VM.L, e.Ren, func(i int) (c [4]uint8) {
 			col := e.Pal.Color(i)
 			c[0] = col.R
 			c[1] = col.G
 			c[2] = col.B
 			c[3] = col.A
 			return
-		}, e.Pal.Set, e.sfxMap, e.musicMap, e.spritesMap, e.Physics, e.Network)
+		}, e.Pal.Set, e.sfxMap, e.musicMap, e.spritesMap, e.Physics, e.luaState, e.Network)
 	}
 
 	// Register state machine (needed for game.* API)
 	luabind.RegisterStateMachine(e.VM.L, e.GSM)
 }
 
+// DrawsCursor reports whether the cart draws its own mouse cursor
+// (rf.mouse_cursor), so the window should hide the system one.
+func (e *Engine) DrawsCursor() bool {
+	return e.luaState != nil && e.luaState.HasPointer()
+}
+
 // RunFrames advances N frames headlessly.
 func (e *Engine) RunFrames(n int) {
 	for i := 0; i < n; i++ {
@@ -215,10 +256,18 @@ func (e *Engine) LoadCartFromReader(r io.ReaderAt, size int64) error {
 		return err
 	}
 
-	// Set palette from manifest if specified
-	if result.Manifest.Palette != "" {
-		e.Pal.Set(result.Manifest.Palette)
+	// Load the cart's assets
+	tilesetDef, err := e.loadCartAssets(result.Manifest, func(file string) ([]byte, error) {
+		data, ok := result.Files[file]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
 	}
+	e.tileset = buildTileset(tilesetDef)
 
 	src, ok := result.Files["assets/"+result.Manifest.Entry]
 	if !ok {
@@ -229,6 +278,7 @@ func (e *Engine) LoadCartFromReader(r io.ReaderAt, size int64) error {
 	e.sfxMap = result.SFX
 	e.musicMap = result.Music
 	e.spritesMap = result.Sprites
+	e.animations = result.Animations
 
 	// Register Lua bindings first (creates rf table)
 	e.registerLuaBindings()
@@ -29,7 +29,7 @@ func TestHelloCartRendersCenteredText(t *testing.T) {
         end
     `
 	var buf bytes.Buffer
-	if err := cartio.Write(&buf, m, []cartio.Asset{{Name: "main.lua", Data: []byte(lua)}}, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap)); err != nil {
+	if err := cartio.Write(&buf, m, []cartio.Asset{{Name: "main.lua", Data: []byte(lua)}}, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -63,7 +63,7 @@ func TestLoadCartFile(t *testing.T) {
 	`
 
 	var buf bytes.Buffer
-	if err := cartio.Write(&buf, m, []cartio.Asset{{Name: "main.lua", Data: []byte(lua)}}, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap)); err != nil {
+	if err := cartio.Write(&buf, m, []cartio.Asset{{Name: "main.lua", Data: []byte(lua)}}, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil); err != nil {
 		t.Fatalf("Write failed: %v", err)
 	}
 
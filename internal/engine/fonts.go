@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
+)
+
+// loadFonts loads the fonts declared in the manifest. read returns the
+// contents of a file relative to the cart's assets/ folder.
+func loadFonts(specs []cartio.FontSpec, read func(file string) ([]byte, error)) (map[string]*font.Font, error) {
+	fonts := make(map[string]*font.Font)
+	for _, spec := range specs {
+		if spec.Name == "" || spec.File == "" {
+			return nil, fmt.Errorf("font entries need a name and a file")
+		}
+		file, err := assetPath(spec.File)
+		if err != nil {
+			return nil, err
+		}
+		data, err := read(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read font %s: %w", spec.File, err)
+		}
+
+		opts := font.Options{
+			CellW:      spec.CellW,
+			CellH:      spec.CellH,
+			Chars:      spec.Chars,
+			Monospace:  spec.Monospace,
+			LineHeight: spec.LineHeight,
+			Kerning:    spec.Kerning,
+		}
+		if spec.Spacing != nil {
+			opts.Spacing = *spec.Spacing
+		} else if strings.ToLower(path.Ext(spec.File)) == ".png" {
+			opts.Spacing = 1 // Grid cells are usually drawn without a gap
+		}
+
+		f, err := font.Load(spec.Name, file, data, opts)
+		if err != nil {
+			return nil, err
+		}
+		fonts[spec.Name] = f
+	}
+	return fonts, nil
+}
+
+// assetPath cleans a slash-separated path from the manifest, relative to the
+// cart's assets/ folder, rejecting any that would leave it.
+func assetPath(file string) (string, error) {
+	clean := path.Clean(file)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || strings.Contains(file, "\\") {
+		return "", fmt.Errorf("asset path %q leaves the cart's assets", file)
+	}
+	return clean, nil
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+const tinyBDF = `STARTFONT 2.1
+FONTBOUNDINGBOX 2 2 0 0
+STARTCHAR A
+ENCODING 65
+DWIDTH 3 0
+BBX 2 2 0 0
+BITMAP
+C0
+C0
+ENDCHAR
+ENDFONT
+`
+
+func TestLoadFonts(t *testing.T) {
+	files := map[string][]byte{"fonts/tiny.bdf": []byte(tinyBDF)}
+	read := func(file string) ([]byte, error) {
+		data, ok := files[file]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return data, nil
+	}
+
+	fonts, err := loadFonts([]cartio.FontSpec{{Name: "tiny", File: "fonts/tiny.bdf"}}, read)
+	if err != nil {
+		t.Fatalf("loadFonts: %v", err)
+	}
+	if f := fonts["tiny"]; f == nil || f.TextWidth("AA") != 6 {
+		t.Fatalf("expected tiny font with advance 3, got %+v", f)
+	}
+
+	if _, err := loadFonts([]cartio.FontSpec{{Name: "gone", File: "fonts/gone.bdf"}}, read); err == nil {
+		t.Fatal("expected error for missing font file")
+	}
+	if _, err := loadFonts([]cartio.FontSpec{{File: "fonts/tiny.bdf"}}, read); err == nil {
+		t.Fatal("expected error for unnamed font")
+	}
+	for _, file := range []string{"../secret.bdf", "fonts/../../secret.bdf", "/etc/font.bdf", "..\\secret.bdf"} {
+		if _, err := loadFonts([]cartio.FontSpec{{Name: "x", File: file}}, read); err == nil {
+			t.Errorf("expected error for font path %s", file)
+		}
+	}
+	if fonts, err := loadFonts([]cartio.FontSpec{{Name: "tiny", File: "./fonts/x/../tiny.bdf"}}, read); err != nil || fonts["tiny"] == nil {
+		t.Errorf("cleaned path should load: %v", err)
+	}
+}
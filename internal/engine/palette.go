@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// loadPalettes registers the palettes in palette.json (optional), then makes
+// the manifest's palette current. The name may be a built-in or a cart
+// palette; an unknown name is an error.
+func (e *Engine) loadPalettes(name string, read func(file string) ([]byte, error)) error {
+	if data, err := read(cartio.PalettesFile); err == nil {
+		palettes, err := cartio.ParsePalettes(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse palette.json: %w", err)
+		}
+		for _, p := range palettes {
+			if err := e.Pal.Register(p); err != nil {
+				return err
+			}
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	if err := e.Pal.Set(name); err != nil {
+		return fmt.Errorf("manifest palette: %w", err)
+	}
+	return nil
+}
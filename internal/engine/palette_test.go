@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPalettesLoadedFromCart(t *testing.T) {
+	e := New(60)
+	defer e.Close()
+
+	dir := t.TempDir()
+	assetsDir := filepath.Join(dir, "assets")
+	os.MkdirAll(assetsDir, 0755)
+
+	colors := make([]string, 50)
+	for i := range colors {
+		colors[i] = fmt.Sprintf(`"#%02x%02x%02x"`, i, i, i)
+	}
+	os.WriteFile(filepath.Join(assetsDir, "palette.json"),
+		[]byte(`{"name": "Dusk", "colors": [`+strings.Join(colors, ",")+`]}`), 0644)
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Palette", "entry": "main.lua", "palette": "Dusk"}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "main.lua"), []byte(`
+		r, g, b = rf.palette_get(7)
+		rf.palette_set("SNES 50")
+	`), 0644)
+
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder: %v", err)
+	}
+	if e.VM.L.GetGlobal("r").String() != "7" {
+		t.Fatalf("palette_get(7) red = %v, want 7", e.VM.L.GetGlobal("r"))
+	}
+	if e.Pal.Name() != "SNES 50" {
+		t.Fatalf("palette = %q, want SNES 50", e.Pal.Name())
+	}
+
+	// The manifest's palette must exist
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Palette", "entry": "main.lua", "palette": "Nope"}`), 0644)
+	if err := e.LoadCartFolder(dir); err == nil {
+		t.Fatal("expected an error for an unknown palette")
+	}
+}
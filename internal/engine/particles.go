@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// loadParticles reads the emitter definitions in particles.json (optional)
+func loadParticles(read func(file string) ([]byte, error)) (cartio.ParticleSet, error) {
+	data, err := read(cartio.ParticlesFile)
+	if err != nil {
+		return make(cartio.ParticleSet), nil
+	}
+	set, err := cartio.ParseParticles(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse particles.json: %w", err)
+	}
+	return set, nil
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParticlesSteppedByTick(t *testing.T) {
+	e := New(60)
+	defer e.Close()
+
+	dir := t.TempDir()
+	assetsDir := filepath.Join(dir, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Particles", "entry": "main.lua"}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "particles.json"), []byte(`{"smoke": {"rate": 60, "life": 10}}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "main.lua"), []byte(`
+		smoke = rf.emitter_new("smoke", 10, 10)
+	`), 0644)
+
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder: %v", err)
+	}
+	e.RunFrames(30)
+	if n := e.luaState.Particles().Len(); n < 25 || n > 30 {
+		t.Fatalf("expected about 30 particles after 30 ticks at 60/s, got %d", n)
+	}
+
+	// Invalid definitions fail the load
+	os.WriteFile(filepath.Join(assetsDir, "particles.json"), []byte(`{"smoke": {"rate": 60}}`), 0644)
+	if err := e.LoadCartFolder(dir); err == nil {
+		t.Fatal("expected an error for an emitter without a life")
+	}
+}
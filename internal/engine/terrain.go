@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/voxel"
+)
+
+// loadTerrains decodes the voxel terrains listed in terrains.json (optional).
+// Images are read from assets/; colormaps that aren't paletted are matched to
+// palette.
+func loadTerrains(read func(file string) ([]byte, error), palette []color.RGBA) (voxel.Terrains, error) {
+	terrains := make(voxel.Terrains)
+	data, err := read(cartio.TerrainsFile)
+	if err != nil {
+		return terrains, nil
+	}
+	set, err := cartio.ParseTerrains(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse terrains.json: %w", err)
+	}
+	readAsset := func(file string) ([]byte, error) {
+		file, err := assetPath(file)
+		if err != nil {
+			return nil, err
+		}
+		return read("assets/" + file)
+	}
+	for name, def := range set {
+		heightmap, err := readAsset(def.Heightmap)
+		if err != nil {
+			return nil, fmt.Errorf("terrain %s: %w", name, err)
+		}
+		colormap, err := readAsset(def.Colormap)
+		if err != nil {
+			return nil, fmt.Errorf("terrain %s: %w", name, err)
+		}
+		if terrains[name], err = voxel.DecodeTerrain(heightmap, colormap, palette); err != nil {
+			return nil, fmt.Errorf("terrain %s: %w", name, err)
+		}
+	}
+	return terrains, nil
+}
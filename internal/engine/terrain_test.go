@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTerrainsLoadedFromCart(t *testing.T) {
+	e := New(60)
+	defer e.Close()
+
+	dir := t.TempDir()
+	assetsDir := filepath.Join(dir, "assets")
+	os.MkdirAll(assetsDir, 0755)
+
+	// A gray heightmap with one peak and a flat white colormap
+	height := image.NewGray(image.Rect(0, 0, 8, 8))
+	height.SetGray(2, 3, color.Gray{Y: 80})
+	colors := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range colors.Pix {
+		colors.Pix[i] = 255
+	}
+	writePNG(t, filepath.Join(assetsDir, "height.png"), height)
+	writePNG(t, filepath.Join(assetsDir, "color.png"), colors)
+
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Terrain", "entry": "main.lua"}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "terrains.json"), []byte(`{"hills": {"heightmap": "height.png", "colormap": "color.png"}}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "main.lua"), []byte(`
+		drawn = rf.voxel_render("hills", 0, 0, 100, 45, 0)
+		peak = rf.voxel_height("hills", 2, 3)
+	`), 0644)
+
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder: %v", err)
+	}
+	if e.VM.L.GetGlobal("drawn").String() != "true" || e.VM.L.GetGlobal("peak").String() != "80" {
+		t.Fatalf("drawn = %v, peak = %v", e.VM.L.GetGlobal("drawn"), e.VM.L.GetGlobal("peak"))
+	}
+
+	// Images outside assets/ fail the load, even when they exist
+	writePNG(t, filepath.Join(dir, "outside.png"), height)
+	os.WriteFile(filepath.Join(assetsDir, "terrains.json"), []byte(`{"hills": {"heightmap": "../outside.png", "colormap": "color.png"}}`), 0644)
+	if err := e.LoadCartFolder(dir); err == nil {
+		t.Fatal("expected an error for a heightmap outside assets/")
+	}
+
+	// Missing images fail the load
+	os.WriteFile(filepath.Join(assetsDir, "terrains.json"), []byte(`{"hills": {"heightmap": "height.png", "colormap": "color.png"}}`), 0644)
+	os.Remove(filepath.Join(assetsDir, "color.png"))
+	if err := e.LoadCartFolder(dir); err == nil {
+		t.Fatal("expected an error for a missing colormap")
+	}
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/tiled"
+)
+
+// loadTileset builds the cart tileset from tileset.json (nil data = empty tileset)
+func loadTileset(data []byte) (*graphics.Tileset, error) {
+	def, err := parseTileset(data)
+	if err != nil {
+		return nil, err
+	}
+	return buildTileset(def), nil
+}
+
+// parseTileset reads tileset.json (nil data = no tiles)
+func parseTileset(data []byte) (cartio.TilesetData, error) {
+	if data == nil {
+		return cartio.TilesetData{}, nil
+	}
+	def, err := cartio.ParseTileset(data)
+	if err != nil {
+		return def, fmt.Errorf("failed to parse tileset.json: %w", err)
+	}
+	return def, nil
+}
+
+// buildTileset creates the runtime tileset from tile definitions
+func buildTileset(def cartio.TilesetData) *graphics.Tileset {
+	ts := graphics.NewTileset()
+	ts.Sheet = def.Sheet
+	if def.TileW > 0 && def.TileH > 0 {
+		ts.CellW, ts.CellH = def.TileW, def.TileH
+	}
+	for index, tile := range def.Tiles {
+		switch {
+		case tile.Sprite != "":
+			ts.SetTile(index, graphics.Tile{Sprite: tile.Sprite})
+		case tile.Cell != nil:
+			ts.SetTile(index, graphics.Tile{Cell: *tile.Cell})
+		}
+		ts.SetFlags(index, tile.Flags)
+		if len(tile.Anim) > 0 {
+			frames := make([]graphics.TileFrame, len(tile.Anim))
+			for i, f := range tile.Anim {
+				frames[i] = graphics.TileFrame{Tile: f.Tile, Duration: f.Duration}
+			}
+			ts.SetAnim(index, frames)
+		}
+	}
+	return ts
+}
+
+// importTiled converts the Tiled maps (.tmx/.tmj) in a cart folder's assets
+// into the engine's maps, sprites and tile definitions (dev mode; packed carts
+// are converted by -pack)
+func (e *Engine) importTiled(cartPath string, def *cartio.TilesetData) error {
+	assetsDir := filepath.Join(cartPath, "assets")
+	files, err := tiled.FindMaps(assetsDir)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	imp := tiled.NewImporter(tiled.ReadFolder(assetsDir), e.Pal.Colors())
+	imp.Maps, imp.Tileset, imp.Sprites = e.maps, *def, e.spritesMap
+	e.tiledMaps = make(map[string]bool)
+	for _, file := range files {
+		if err := imp.Import(file); err != nil {
+			return fmt.Errorf("failed to import Tiled map: %w", err)
+		}
+		e.tiledMaps[tiled.MapName(file)] = true
+	}
+	e.maps, *def, e.spritesMap = imp.Maps, imp.Tileset, imp.Sprites
+	return nil
+}
+
+// loadMaps reads maps.json, or maps.bin if the cart has no maps.json. read
+// returns the contents of a cart file, or an error if it doesn't exist.
+// compressed reports which file was used, so saving writes the same one.
+func loadMaps(read func(file string) ([]byte, error)) (maps cartio.MapSet, compressed bool, err error) {
+	if data, err := read(cartio.MapsFile); err == nil {
+		maps, err = cartio.ParseMaps(data, false)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse maps.json: %w", err)
+		}
+		return maps, false, nil
+	}
+	if data, err := read(cartio.MapsBinFile); err == nil {
+		maps, err = cartio.ParseMaps(data, true)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse maps.bin: %w", err)
+		}
+		return maps, true, nil
+	}
+	return make(cartio.MapSet), false, nil
+}
+
+// saveMaps writes maps back to the cart folder (dev mode rf.map_save).
+// Maps imported from Tiled are left out; they are edited in Tiled.
+func (e *Engine) saveMaps(maps cartio.MapSet) error {
+	if e.devMode == nil || e.devMode.cartPath == "" {
+		return fmt.Errorf("map saving is only available in dev mode")
+	}
+	if len(e.tiledMaps) > 0 {
+		native := make(cartio.MapSet, len(maps))
+		for name, m := range maps {
+			if !e.tiledMaps[name] {
+				native[name] = m
+			}
+		}
+		maps = native
+	}
+	file := cartio.MapsFile
+	if e.mapsBin {
+		file = cartio.MapsBinFile
+	}
+	data, err := cartio.EncodeMaps(maps, e.mapsBin)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(e.devMode.cartPath, filepath.FromSlash(file)), data, 0644)
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+func TestLoadTileset(t *testing.T) {
+	ts, err := loadTileset([]byte(`{
+		"sheet": "tiles", "tile_w": 16, "tile_h": 16,
+		"tiles": {"1": {"sprite": "grass", "flags": 1}, "2": {"cell": 0, "flags": 3}, "9": {"flags": 2}}
+	}`))
+	if err != nil {
+		t.Fatalf("loadTileset: %v", err)
+	}
+	if ts.Sheet != "tiles" || ts.CellW != 16 || ts.CellH != 16 {
+		t.Fatalf("unexpected sheet %q %dx%d", ts.Sheet, ts.CellW, ts.CellH)
+	}
+	if tile, ok := ts.Tile(1); !ok || tile.Sprite != "grass" || ts.Flags(1) != 1 {
+		t.Fatalf("unexpected tile 1 %+v flags %d", tile, ts.Flags(1))
+	}
+	if tile, ok := ts.Tile(2); !ok || tile.Sprite != "" || tile.Cell != 0 || ts.Flags(2) != 3 {
+		t.Fatalf("unexpected tile 2 %+v flags %d", tile, ts.Flags(2))
+	}
+	if tile, _ := ts.Tile(9); tile.Cell != 9 || ts.Flags(9) != 2 {
+		t.Fatalf("flags-only tile should use its sheet cell, got %+v", tile)
+	}
+
+	if ts, err := loadTileset(nil); err != nil || ts == nil {
+		t.Fatalf("missing tileset.json should give an empty tileset, got %v", err)
+	}
+	if _, err := loadTileset([]byte(`{`)); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestLoadAndSaveMaps(t *testing.T) {
+	files := map[string][]byte{
+		cartio.MapsFile: []byte(`{"a": {"width": 1, "height": 1, "layers": [{"name": "main", "tiles": [7]}]}}`),
+	}
+	bin, err := cartio.EncodeMaps(cartio.MapSet{"b": {Width: 1, Height: 1}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[cartio.MapsBinFile] = bin
+	read := func(file string) ([]byte, error) {
+		if data, ok := files[file]; ok {
+			return data, nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	maps, compressed, err := loadMaps(read)
+	if err != nil || compressed {
+		t.Fatalf("expected maps.json to be preferred, got compressed=%v err=%v", compressed, err)
+	}
+	if _, ok := maps["a"]; !ok {
+		t.Fatal("expected map a from maps.json")
+	}
+	delete(files, cartio.MapsFile)
+	if maps, compressed, err = loadMaps(read); err != nil || !compressed || maps["b"].Width != 1 {
+		t.Fatalf("expected map b from maps.bin, got %v %v", maps, err)
+	}
+	delete(files, cartio.MapsBinFile)
+	if maps, _, err = loadMaps(read); err != nil || len(maps) != 0 {
+		t.Fatalf("expected no maps, got %v %v", maps, err)
+	}
+
+	// Saving writes the file the maps came from, in the cart folder
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	e := &Engine{devMode: &DevMode{cartPath: dir}, mapsBin: true}
+	if err := e.saveMaps(cartio.MapSet{"c": {Width: 2, Height: 1}}); err != nil {
+		t.Fatalf("saveMaps: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "assets", "maps.bin"))
+	if err != nil {
+		t.Fatalf("expected maps.bin to be written: %v", err)
+	}
+	if saved, err := cartio.ParseMaps(data, true); err != nil || saved["c"].Width != 2 {
+		t.Fatalf("unexpected saved maps %v %v", saved, err)
+	}
+	if err := (&Engine{}).saveMaps(cartio.MapSet{}); err == nil {
+		t.Fatal("expected error outside dev mode")
+	}
+}
+
+func TestLoadCartFolderImportsTiled(t *testing.T) {
+	e := New(60)
+	defer e.Close()
+
+	dir := t.TempDir()
+	assetsDir := filepath.Join(dir, "assets")
+	os.MkdirAll(filepath.Join(assetsDir, "maps"), 0755)
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"title": "Tiled", "entry": "main.lua"}`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "main.lua"), []byte(`loaded = rf.map_load("maps/level") solid = rf.fget(rf.mget(0, 0), 0)`), 0644)
+	os.WriteFile(filepath.Join(assetsDir, "tileset.json"), []byte(`{"tiles": {"1": {"sprite": "hero"}}}`), 0644)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{255, 255, 255, 255})
+	img.Set(1, 0, color.NRGBA{0, 0, 0, 255})
+	f, err := os.Create(filepath.Join(assetsDir, "maps", "tiles.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	png.Encode(f, img)
+	f.Close()
+	os.WriteFile(filepath.Join(assetsDir, "maps", "level.tmj"), []byte(`{
+		"width": 2, "height": 1, "tilewidth": 1, "tileheight": 1,
+		"tilesets": [{"firstgid": 1, "name": "t", "tilewidth": 1, "tileheight": 1, "image": "tiles.png",
+			"tiles": [{"id": 0, "objectgroup": {"objects": [{"x": 0, "y": 0, "width": 1, "height": 1}]}}]}],
+		"layers": [{"type": "tilelayer", "name": "main", "data": [1, 2]}]
+	}`), 0644)
+
+	if err := e.LoadCartFolder(dir); err != nil {
+		t.Fatalf("LoadCartFolder: %v", err)
+	}
+	if _, ok := e.maps["maps/level"]; !ok {
+		t.Fatalf("expected the Tiled map to be imported, got %v", e.maps)
+	}
+	if tile, ok := e.tileset.Tile(2); !ok || tile.Sprite != "t:0" {
+		t.Fatalf("expected Tiled tile 0 after the cart's tile 1, got %+v", tile)
+	}
+	if sprite := e.spritesMap["t:0"]; sprite.Pixels[0][0] != 1 {
+		t.Fatalf("expected a white tile sprite, got %+v", sprite)
+	}
+	if e.VM.L.GetGlobal("loaded").String() != "true" || e.VM.L.GetGlobal("solid").String() != "true" {
+		t.Fatal("expected the imported map to load with a solid first tile")
+	}
+	if n := len(e.luaState.MapBodies()); n != 1 {
+		t.Fatalf("expected one static body for the solid tile, got %d", n)
+	}
+
+	// Imported maps are not written back by rf.map_save
+	if err := e.saveMaps(e.maps); err != nil {
+		t.Fatalf("saveMaps: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(assetsDir, "maps.json"))
+	if saved, err := cartio.ParseMaps(data, false); err != nil || len(saved) != 0 {
+		t.Fatalf("expected no saved maps, got %v %v", saved, err)
+	}
+}
@@ -0,0 +1,139 @@
+package font
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Char is a single bitmap glyph of a Font.
+type Char struct {
+	W, H       int
+	XOff, YOff int    // Offset of the bitmap from the pen position (top of the line)
+	Advance    int    // Horizontal distance to the next pen position
+	Pix        []bool // W*H, row-major, true = ink
+}
+
+// Font is a bitmap font with variable-width glyphs, kerning and line height.
+type Font struct {
+	Name       string
+	Height     int // Glyph height (ascent + descent)
+	LineHeight int // Distance between the tops of consecutive lines
+	chars      map[rune]*Char
+	kerning    map[[2]rune]int
+	missing    int // Advance for runes the font doesn't have
+}
+
+// NewFont creates an empty font; glyphs are added with SetChar.
+func NewFont(name string, height, lineHeight int) *Font {
+	return &Font{
+		Name:       name,
+		Height:     height,
+		LineHeight: lineHeight,
+		chars:      make(map[rune]*Char),
+		kerning:    make(map[[2]rune]int),
+		missing:    (height + 1) / 2,
+	}
+}
+
+// SetChar adds or replaces the glyph for r.
+func (f *Font) SetChar(r rune, c *Char) {
+	f.chars[r] = c
+	if r == ' ' {
+		f.missing = c.Advance
+	}
+}
+
+// SetKerning adjusts the advance between the pair a, b (negative = tighter).
+func (f *Font) SetKerning(a, b rune, adjust int) {
+	f.kerning[[2]rune{a, b}] = adjust
+}
+
+// Char returns the glyph for r, falling back to the other letter case.
+func (f *Font) Char(r rune) (*Char, bool) {
+	if c, ok := f.chars[r]; ok {
+		return c, true
+	}
+	if c, ok := f.chars[unicode.ToUpper(r)]; ok {
+		return c, true
+	}
+	c, ok := f.chars[unicode.ToLower(r)]
+	return c, ok
+}
+
+// advance returns the pen step for r followed by next (0 = end of line)
+func (f *Font) advance(r, next rune) int {
+	adv := f.missing
+	if c, ok := f.Char(r); ok {
+		adv = c.Advance
+	}
+	if next != 0 {
+		adv += f.kerning[[2]rune{r, next}]
+	}
+	return adv
+}
+
+// Draw renders text with its top-left corner at (x, y), calling plot for
+// every ink pixel. '\n' starts a new line.
+func (f *Font) Draw(text string, x, y int, plot func(x, y int)) {
+	for i, line := range strings.Split(text, "\n") {
+		ly := y + i*f.LineHeight
+		cx := x
+		runes := []rune(line)
+		for j, r := range runes {
+			f.DrawGlyph(r, cx, ly, plot)
+			var next rune
+			if j+1 < len(runes) {
+				next = runes[j+1]
+			}
+			cx += f.advance(r, next)
+		}
+	}
+}
+
+// LineWidth returns the advance width of a single line of text.
+func (f *Font) LineWidth(line string) int {
+	w := 0
+	runes := []rune(line)
+	for j, r := range runes {
+		var next rune
+		if j+1 < len(runes) {
+			next = runes[j+1]
+		}
+		w += f.advance(r, next)
+	}
+	return w
+}
+
+// TextWidth returns the width of the widest line of text.
+func (f *Font) TextWidth(text string) int {
+	w := 0
+	for _, line := range strings.Split(text, "\n") {
+		if lw := f.LineWidth(line); lw > w {
+			w = lw
+		}
+	}
+	return w
+}
+
+// TextHeight returns the height of text including all of its lines.
+func (f *Font) TextHeight(text string) int {
+	lines := strings.Count(text, "\n")
+	return lines*f.LineHeight + f.Height
+}
+
+// Default is the built-in 5x7 font. Lowercase letters use the uppercase glyphs.
+var Default = func() *Font {
+	f := NewFont("default", Height, Height+1)
+	for r, g := range glyphs {
+		c := &Char{W: g.W, H: g.H, Advance: Advance, Pix: make([]bool, g.W*g.H)}
+		for row := 0; row < g.H; row++ {
+			for col := 0; col < g.W; col++ {
+				// Column 0 -> bit 4, column 4 -> bit 0
+				c.Pix[row*g.W+col] = g.Rows[row]&(1<<uint(Width-1-col)) != 0
+			}
+		}
+		f.SetChar(r, c)
+	}
+	f.missing = Advance
+	return f
+}()
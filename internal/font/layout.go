@@ -0,0 +1,295 @@
+package font
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Align positions text within a box, horizontally or vertically.
+type Align int
+
+const (
+	AlignStart  Align = iota // Left / top
+	AlignCenter              // Center / middle
+	AlignEnd                 // Right / bottom
+)
+
+// ParseAlign converts "left", "center", "right", "top", "middle" or
+// "bottom" to an Align; anything else is AlignStart.
+func ParseAlign(s string) Align {
+	switch strings.ToLower(s) {
+	case "center", "centre", "middle":
+		return AlignCenter
+	case "right", "bottom":
+		return AlignEnd
+	}
+	return AlignStart
+}
+
+// BoxOptions control how LayoutBox places text.
+type BoxOptions struct {
+	Align       Align   // Horizontal alignment of each line
+	VAlign      Align   // Vertical alignment of the block within the box
+	LineSpacing int     // Extra pixels between lines
+	Wrap        bool    // Break lines at word boundaries to fit the box width
+	Time        float64 // Seconds, drives {wave} and {shake}
+	Colors      int     // Palette size: {c:n} past it is printed as-is (0 = no limit)
+
+	// Ink maps an inline {c:n} color to a drawing color (nil = palette index n)
+	Ink func(n int) color.Color
+}
+
+// Placed is one character positioned by LayoutBox, relative to the box origin.
+type Placed struct {
+	R     rune
+	X, Y  int
+	Color int // Inline {c:n} color, -1 = the text's color
+}
+
+// span is one character of marked-up text with its style
+type span struct {
+	r              rune
+	color          int
+	wave, shake    bool
+	space, newline bool
+}
+
+// parseMarkup splits text into characters carrying their inline style.
+// Supported tags: {c:n} ... {/c}, {wave} ... {/wave}, {shake} ... {/shake};
+// "{{" is a literal brace. Unknown tags, and colors below 0 or from colors
+// up (when colors > 0), are printed as-is.
+func parseMarkup(text string, colors int) []span {
+	var out []span
+	color, wave, shake := -1, false, false
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '{' {
+			if i+1 < len(runes) && runes[i+1] == '{' {
+				out = append(out, span{r: '{', color: color, wave: wave, shake: shake})
+				i++
+				continue
+			}
+			if end := indexRune(runes[i:], '}'); end > 0 {
+				tag := string(runes[i+1 : i+end])
+				handled := true
+				switch {
+				case strings.HasPrefix(tag, "c:"):
+					n, err := strconv.Atoi(strings.TrimSpace(tag[2:]))
+					if err != nil || n < 0 || (colors > 0 && n >= colors) {
+						handled = false
+					} else {
+						color = n
+					}
+				case tag == "/c":
+					color = -1
+				case tag == "wave":
+					wave = true
+				case tag == "/wave":
+					wave = false
+				case tag == "shake":
+					shake = true
+				case tag == "/shake":
+					shake = false
+				default:
+					handled = false
+				}
+				if handled {
+					i += end
+					continue
+				}
+			}
+		}
+		out = append(out, span{
+			r: r, color: color, wave: wave, shake: shake,
+			space: r != '\n' && unicode.IsSpace(r), newline: r == '\n',
+		})
+	}
+	return out
+}
+
+func indexRune(runes []rune, r rune) int {
+	for i, c := range runes {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// EscapeMarkup escapes braces so text is printed literally by LayoutBox.
+func EscapeMarkup(text string) string {
+	return strings.ReplaceAll(text, "{", "{{")
+}
+
+// StripMarkup returns text with its inline markup removed.
+func StripMarkup(text string) string {
+	var b strings.Builder
+	for _, s := range parseMarkup(text, 0) {
+		b.WriteRune(s.r)
+	}
+	return b.String()
+}
+
+// spanWidth measures a run of styled characters
+func (f *Font) spanWidth(line []span) int {
+	w := 0
+	for i, s := range line {
+		var next rune
+		if i+1 < len(line) {
+			next = line[i+1].r
+		}
+		w += f.advance(s.r, next)
+	}
+	return w
+}
+
+// wrapLines splits styled text into lines no wider than w (when wrapping).
+// Words longer than a line are broken between characters.
+func (f *Font) wrapLines(text []span, w int, wrap bool) [][]span {
+	var lines [][]span
+	var line []span
+	flush := func() {
+		// Trailing spaces don't count toward alignment
+		for len(line) > 0 && line[len(line)-1].space {
+			line = line[:len(line)-1]
+		}
+		lines = append(lines, line)
+		line = nil
+	}
+
+	for i := 0; i < len(text); {
+		s := text[i]
+		if s.newline {
+			flush()
+			i++
+			continue
+		}
+		if !wrap || w <= 0 {
+			line = append(line, s)
+			i++
+			continue
+		}
+
+		// Take the next word (or a single space)
+		j := i + 1
+		if !s.space {
+			for j < len(text) && !text[j].space && !text[j].newline {
+				j++
+			}
+		}
+		word := text[i:j]
+		if s.space && len(line) == 0 {
+			i = j // Wrapped lines don't start with a space
+			continue
+		}
+		if f.spanWidth(append(line[:len(line):len(line)], word...)) <= w {
+			line = append(line, word...)
+			i = j
+			continue
+		}
+		if s.space {
+			flush()
+			i = j
+			continue
+		}
+		if len(line) > 0 {
+			flush()
+			continue
+		}
+		// The word alone is too wide: take as many characters as fit (at least one)
+		n := 1
+		for n < len(word) && f.spanWidth(word[:n+1]) <= w {
+			n++
+		}
+		line = append(line, word[:n]...)
+		flush()
+		i += n
+	}
+	flush()
+	return lines
+}
+
+// LayoutBox places text in a box of w×h pixels, handling inline markup,
+// word wrapping and alignment. It returns the placed glyphs (which may fall
+// outside the box; callers clip) and the height of the laid-out text.
+// A w or h of 0 or less leaves that dimension unbounded.
+func (f *Font) LayoutBox(text string, w, h int, opts BoxOptions) ([]Placed, int) {
+	lines := f.wrapLines(parseMarkup(text, opts.Colors), w, opts.Wrap)
+	step := f.LineHeight + opts.LineSpacing
+	height := (len(lines)-1)*step + f.Height
+
+	y := 0
+	if h > 0 {
+		switch opts.VAlign {
+		case AlignCenter:
+			y = (h - height) / 2
+		case AlignEnd:
+			y = h - height
+		}
+	}
+
+	var glyphs []Placed
+	n := 0 // Character count, to phase the animations
+	for li, line := range lines {
+		x := 0
+		if w > 0 {
+			switch opts.Align {
+			case AlignCenter:
+				x = (w - f.spanWidth(line)) / 2
+			case AlignEnd:
+				x = w - f.spanWidth(line)
+			}
+		}
+		ly := y + li*step
+		for i, s := range line {
+			gx, gy := x, ly
+			if s.wave {
+				gy += int(math.Round(2 * math.Sin(opts.Time*8+float64(n)*0.7)))
+			}
+			if s.shake {
+				dx, dy := shake(opts.Time, n)
+				gx += dx
+				gy += dy
+			}
+			if !s.space {
+				glyphs = append(glyphs, Placed{R: s.r, X: gx, Y: gy, Color: s.color})
+			}
+			var next rune
+			if i+1 < len(line) {
+				next = line[i+1].r
+			}
+			x += f.advance(s.r, next)
+			n++
+		}
+	}
+	return glyphs, height
+}
+
+// shake returns a jitter of -1..1 pixels that changes 20 times a second.
+// It is a hash of the time step and character, so layouts are reproducible.
+func shake(t float64, n int) (int, int) {
+	h := uint32(int(t*20))*2654435761 ^ uint32(n)*40503
+	h ^= h >> 13
+	h *= 0x5bd1e995
+	h ^= h >> 15
+	return int(h%3) - 1, int((h/3)%3) - 1
+}
+
+// DrawGlyph renders a single glyph with its top-left pen position at (x, y).
+func (f *Font) DrawGlyph(r rune, x, y int, plot func(x, y int)) {
+	c, ok := f.Char(r)
+	if !ok {
+		return
+	}
+	for row := 0; row < c.H; row++ {
+		for col := 0; col < c.W; col++ {
+			if c.Pix[row*c.W+col] {
+				plot(x+c.XOff+col, y+c.YOff+row)
+			}
+		}
+	}
+}
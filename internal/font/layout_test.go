@@ -0,0 +1,100 @@
+package font
+
+import "testing"
+
+func TestParseMarkup(t *testing.T) {
+	spans := parseMarkup("a{c:5}b{/c}{wave}c{/wave}{{d}{bogus}", 0)
+	got := ""
+	for _, s := range spans {
+		got += string(s.r)
+	}
+	if got != "abc{d}{bogus}" {
+		t.Fatalf("unexpected text %q", got)
+	}
+	if spans[0].color != -1 || spans[1].color != 5 || spans[2].color != -1 {
+		t.Fatalf("unexpected colors %d %d %d", spans[0].color, spans[1].color, spans[2].color)
+	}
+	if spans[1].wave || !spans[2].wave || spans[3].wave {
+		t.Fatal("wave should apply only inside {wave}")
+	}
+
+	// Colors outside the palette are not tags
+	if got := StripMarkup("{c:-5}a"); got != "{c:-5}a" {
+		t.Fatalf("negative color should print literally, got %q", got)
+	}
+	spans = parseMarkup("{c:3}a{c:4}b", 4)
+	if len(spans) != 7 || spans[0].r != 'a' || spans[0].color != 3 || spans[1].r != '{' {
+		t.Fatalf("{c:4} with 4 colors should print literally, got %+v", spans)
+	}
+	if StripMarkup("{shake}hi{/shake}") != "hi" {
+		t.Fatal("StripMarkup should remove tags")
+	}
+	if StripMarkup(EscapeMarkup("{c:1}")) != "{c:1}" {
+		t.Fatal("escaped text should print literally")
+	}
+}
+
+func TestLayoutBoxWrap(t *testing.T) {
+	// Default font: 6px advance, so a 30px box fits 5 characters
+	glyphs, height := Default.LayoutBox("AB CD EFGHIJK", 30, 0, BoxOptions{Wrap: true})
+	lines := map[int]string{}
+	for _, g := range glyphs {
+		lines[g.Y] += string(g.R)
+	}
+	want := map[int]string{0: "ABCD", 8: "EFGHI", 16: "JK"}
+	for y, s := range want {
+		if lines[y] != s {
+			t.Errorf("line at y=%d: expected %q, got %q", y, s, lines[y])
+		}
+	}
+	if height != 2*Default.LineHeight+Height {
+		t.Fatalf("expected height %d, got %d", 2*Default.LineHeight+Height, height)
+	}
+
+	_, height = Default.LayoutBox("AB CD EFGHIJK", 30, 0, BoxOptions{})
+	if height != Height {
+		t.Fatalf("unwrapped text should be one line, got height %d", height)
+	}
+}
+
+func TestLayoutBoxAlign(t *testing.T) {
+	opts := BoxOptions{Align: AlignEnd, VAlign: AlignCenter, LineSpacing: 2}
+	glyphs, height := Default.LayoutBox("A\nBB", 60, 40, opts)
+	if height != Default.LineHeight+2+Height {
+		t.Fatalf("line spacing should add to height, got %d", height)
+	}
+	top := (40 - height) / 2
+	if glyphs[0].X != 60-Advance || glyphs[0].Y != top {
+		t.Fatalf("expected right/middle aligned A at (%d, %d), got (%d, %d)", 60-Advance, top, glyphs[0].X, glyphs[0].Y)
+	}
+	if glyphs[1].X != 60-2*Advance {
+		t.Fatalf("expected second line right aligned at %d, got %d", 60-2*Advance, glyphs[1].X)
+	}
+
+	if ParseAlign("middle") != AlignCenter || ParseAlign("right") != AlignEnd || ParseAlign("x") != AlignStart {
+		t.Fatal("unexpected ParseAlign result")
+	}
+}
+
+func TestLayoutBoxAnimation(t *testing.T) {
+	a, _ := Default.LayoutBox("{shake}AAAA", 0, 0, BoxOptions{Time: 1.5})
+	b, _ := Default.LayoutBox("{shake}AAAA", 0, 0, BoxOptions{Time: 1.5})
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal("shake should be deterministic for the same time")
+		}
+		if dx := a[i].X - i*Advance; dx < -1 || dx > 1 {
+			t.Fatalf("shake offset out of range: %d", dx)
+		}
+	}
+	moved := false
+	for t0 := 0.0; t0 < 1; t0 += 0.1 {
+		g, _ := Default.LayoutBox("{wave}A", 0, 0, BoxOptions{Time: t0})
+		if g[0].Y != 0 {
+			moved = true
+		}
+	}
+	if !moved {
+		t.Fatal("wave should move glyphs over time")
+	}
+}
@@ -0,0 +1,260 @@
+package font
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Options describe how a cart font file is read (see cartio.FontSpec).
+type Options struct {
+	CellW, CellH int            // PNG: glyph cell size in pixels
+	Chars        string         // PNG: characters in cell order (default ASCII 32..126)
+	Spacing      int            // Extra pixels added to every advance
+	Monospace    bool           // PNG: advance by the cell width instead of the glyph width
+	LineHeight   int            // Override the line height (0 = height + 1)
+	Kerning      map[string]int // Two-character pairs -> advance adjustment
+}
+
+// maxGlyph is the largest glyph width or height a BDF file may declare.
+const maxGlyph = 256
+
+// Load reads a font file, choosing the format by extension (.png or .bdf).
+func Load(name, file string, data []byte, opts Options) (*Font, error) {
+	var f *Font
+	var err error
+	switch strings.ToLower(path.Ext(file)) {
+	case ".png":
+		f, err = LoadPNG(name, data, opts)
+	case ".bdf":
+		f, err = LoadBDF(name, data, opts)
+	default:
+		return nil, fmt.Errorf("font %s: unsupported format %q", name, path.Ext(file))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for pair, adjust := range opts.Kerning {
+		runes := []rune(pair)
+		if len(runes) != 2 {
+			return nil, fmt.Errorf("font %s: kerning pair %q must be two characters", name, pair)
+		}
+		f.SetKerning(runes[0], runes[1], adjust)
+	}
+	return f, nil
+}
+
+// asciiChars is the default PNG grid order: printable ASCII from space
+var asciiChars = func() string {
+	var b strings.Builder
+	for r := rune(32); r <= 126; r++ {
+		b.WriteRune(r)
+	}
+	return b.String()
+}()
+
+// LoadPNG reads a glyph grid: cells of CellW×CellH laid out left to right, top
+// to bottom, in the order of opts.Chars. Ink is any opaque pixel that differs
+// from the top-left pixel of the image, so both transparent and solid
+// backgrounds work. Glyphs are proportional unless opts.Monospace is set.
+func LoadPNG(name string, data []byte, opts Options) (*Font, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("font %s: %w", name, err)
+	}
+	if opts.CellW <= 0 || opts.CellH <= 0 {
+		return nil, fmt.Errorf("font %s: cell_w and cell_h are required for PNG fonts", name)
+	}
+	chars := opts.Chars
+	if chars == "" {
+		chars = asciiChars
+	}
+
+	b := img.Bounds()
+	bg := color.NRGBAModel.Convert(img.At(b.Min.X, b.Min.Y)).(color.NRGBA)
+	ink := func(x, y int) bool {
+		c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+		return c.A >= 128 && (bg.A < 128 || c != bg)
+	}
+
+	cols := b.Dx() / opts.CellW
+	rows := b.Dy() / opts.CellH
+	lineHeight := opts.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = opts.CellH + 1
+	}
+	f := NewFont(name, opts.CellH, lineHeight)
+	for i, r := range []rune(chars) {
+		if i >= cols*rows {
+			return nil, fmt.Errorf("font %s: image has %d cells, chars needs %d", name, cols*rows, len([]rune(chars)))
+		}
+		x0 := b.Min.X + (i%cols)*opts.CellW
+		y0 := b.Min.Y + (i/cols)*opts.CellH
+
+		c := &Char{W: opts.CellW, H: opts.CellH, Pix: make([]bool, opts.CellW*opts.CellH)}
+		width := 0
+		for y := 0; y < opts.CellH; y++ {
+			for x := 0; x < opts.CellW; x++ {
+				if ink(x0+x, y0+y) {
+					c.Pix[y*opts.CellW+x] = true
+					width = maxInt(width, x+1)
+				}
+			}
+		}
+		switch {
+		case opts.Monospace:
+			c.Advance = opts.CellW + opts.Spacing
+		case width == 0:
+			c.Advance = opts.CellW/2 + opts.Spacing // Blank cell (space)
+		default:
+			c.Advance = width + opts.Spacing
+		}
+		f.SetChar(r, c)
+	}
+	return f, nil
+}
+
+// LoadBDF reads a font in the Glyph Bitmap Distribution Format.
+func LoadBDF(name string, data []byte, opts Options) (*Font, error) {
+	var (
+		ascent, descent int
+		boxH, boxY      int
+		chars           = make(map[rune]*Char)
+		cur             *Char
+		encoding        = -1
+		bbxY            int
+		bitmapRow       = -1
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if bitmapRow >= 0 {
+			if fields[0] == "ENDCHAR" {
+				if encoding >= 0 {
+					// Remember the baseline offset until ascent is known
+					cur.YOff = bbxY
+					chars[rune(encoding)] = cur
+				}
+				cur, bitmapRow = nil, -1
+				continue
+			}
+			if bitmapRow < cur.H {
+				hex := fields[0]
+				for x := 0; x < cur.W && x/4 < len(hex); x++ {
+					nibble, err := strconv.ParseUint(hex[x/4:x/4+1], 16, 8)
+					if err != nil {
+						return nil, fmt.Errorf("font %s: bad bitmap row %q", name, hex)
+					}
+					cur.Pix[bitmapRow*cur.W+x] = nibble&(8>>uint(x%4)) != 0
+				}
+			}
+			bitmapRow++
+			continue
+		}
+
+		ints := func(n int) ([]int, error) {
+			if len(fields) < n+1 {
+				return nil, fmt.Errorf("font %s: %s needs %d values", name, fields[0], n)
+			}
+			out := make([]int, n)
+			for i := range out {
+				v, err := strconv.Atoi(fields[i+1])
+				if err != nil {
+					return nil, fmt.Errorf("font %s: bad %s value %q", name, fields[0], fields[i+1])
+				}
+				out[i] = v
+			}
+			return out, nil
+		}
+
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			v, err := ints(4)
+			if err != nil {
+				return nil, err
+			}
+			boxH, boxY = v[1], v[3]
+		case "FONT_ASCENT", "FONT_DESCENT":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			if fields[0] == "FONT_ASCENT" {
+				ascent = v[0]
+			} else {
+				descent = v[0]
+			}
+		case "STARTCHAR":
+			cur, encoding, bbxY = &Char{}, -1, 0
+		case "ENCODING":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			encoding = v[0]
+		case "DWIDTH":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			if cur != nil {
+				cur.Advance = v[0] + opts.Spacing
+			}
+		case "BBX":
+			v, err := ints(4)
+			if err != nil {
+				return nil, err
+			}
+			if v[0] <= 0 || v[1] <= 0 || v[0] > maxGlyph || v[1] > maxGlyph {
+				return nil, fmt.Errorf("font %s: bad BBX size %dx%d", name, v[0], v[1])
+			}
+			if cur != nil {
+				cur.W, cur.H, cur.XOff, bbxY = v[0], v[1], v[2], v[3]
+				cur.Pix = make([]bool, cur.W*cur.H)
+			}
+		case "BITMAP":
+			if cur == nil {
+				return nil, fmt.Errorf("font %s: BITMAP outside STARTCHAR", name)
+			}
+			bitmapRow = 0
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("font %s: %w", name, err)
+	}
+	if len(chars) == 0 {
+		return nil, fmt.Errorf("font %s: no glyphs", name)
+	}
+
+	// Fall back to the bounding box when the properties are missing
+	if ascent == 0 && descent == 0 {
+		ascent, descent = boxH+boxY, -boxY
+	}
+	lineHeight := opts.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = ascent + descent + 1
+	}
+	f := NewFont(name, ascent+descent, lineHeight)
+	for r, c := range chars {
+		// BBX y offset is from the baseline up to the glyph's bottom row
+		c.YOff = ascent - (c.YOff + c.H)
+		f.SetChar(r, c)
+	}
+	return f, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
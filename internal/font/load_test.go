@@ -0,0 +1,165 @@
+package font
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// gridPNG builds a 2-cell 4x5 glyph grid: a 3px wide bar and a blank cell
+func gridPNG(t *testing.T) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.NRGBA{0, 0, 0, 255})
+		}
+	}
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 3; x++ {
+			img.Set(x, y, color.NRGBA{255, 255, 255, 255})
+		}
+	}
+	// Background is taken from the top-left pixel, so keep it background
+	img.Set(0, 0, color.NRGBA{0, 0, 0, 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadPNG(t *testing.T) {
+	f, err := Load("grid", "grid.png", gridPNG(t), Options{CellW: 4, CellH: 5, Chars: "a ", Spacing: 1})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if f.Height != 5 || f.LineHeight != 6 {
+		t.Fatalf("expected height 5, line height 6, got %d, %d", f.Height, f.LineHeight)
+	}
+	a, ok := f.Char('a')
+	if !ok {
+		t.Fatal("expected glyph for 'a'")
+	}
+	if a.Advance != 4 {
+		t.Fatalf("expected proportional advance 3+1, got %d", a.Advance)
+	}
+	if a.Pix[0] || !a.Pix[1] {
+		t.Fatal("expected top-left pixel to be background and its neighbour ink")
+	}
+	if _, ok := f.Char('A'); !ok {
+		t.Fatal("expected 'A' to fall back to 'a'")
+	}
+	if got := f.LineWidth("a a"); got != 4+3+4 {
+		t.Fatalf("expected width 11, got %d", got)
+	}
+
+	mono, err := Load("grid", "grid.png", gridPNG(t), Options{CellW: 4, CellH: 5, Chars: "a ", Monospace: true})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := mono.TextWidth("aa"); got != 8 {
+		t.Fatalf("expected monospace width 8, got %d", got)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load("x", "x.ttf", nil, Options{}); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+	if _, err := Load("x", "x.png", gridPNG(t), Options{}); err == nil {
+		t.Fatal("expected error for missing cell size")
+	}
+	if _, err := Load("x", "x.png", gridPNG(t), Options{CellW: 4, CellH: 5, Chars: "abc"}); err == nil {
+		t.Fatal("expected error when chars exceed the grid")
+	}
+	if _, err := Load("x", "x.png", gridPNG(t), Options{CellW: 4, CellH: 5, Chars: "a", Kerning: map[string]int{"abc": 1}}); err == nil {
+		t.Fatal("expected error for bad kerning pair")
+	}
+	for _, bbx := range []string{"BBX -4 5 0 0", "BBX 4 0 0 0", "BBX 100000 100000 0 0"} {
+		bad := strings.Replace(testBDF, "BBX 4 5 0 0", bbx, 1)
+		if _, err := Load("x", "x.bdf", []byte(bad), Options{}); err == nil {
+			t.Errorf("expected error for %s", bbx)
+		}
+	}
+}
+
+const testBDF = `STARTFONT 2.1
+FONTBOUNDINGBOX 4 6 0 -1
+STARTPROPERTIES 2
+FONT_ASCENT 5
+FONT_DESCENT 1
+ENDPROPERTIES
+CHARS 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 5 0
+BBX 4 5 0 0
+BITMAP
+60
+90
+F0
+90
+90
+ENDCHAR
+STARTCHAR eacute
+ENCODING 233
+DWIDTH 4 0
+BBX 3 2 0 -1
+BITMAP
+E0
+20
+ENDCHAR
+ENDFONT
+`
+
+func TestLoadBDF(t *testing.T) {
+	f, err := Load("bdf", "test.bdf", []byte(testBDF), Options{Kerning: map[string]int{"AA": -1}})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if f.Height != 6 || f.LineHeight != 7 {
+		t.Fatalf("expected height 6, line height 7, got %d, %d", f.Height, f.LineHeight)
+	}
+	a, ok := f.Char('A')
+	if !ok {
+		t.Fatal("expected glyph for 'A'")
+	}
+	if a.YOff != 0 || a.Advance != 5 {
+		t.Fatalf("expected YOff 0, advance 5, got %d, %d", a.YOff, a.Advance)
+	}
+	if a.Pix[0] || !a.Pix[1] || !a.Pix[2] || a.Pix[3] {
+		t.Fatalf("unexpected first row %v", a.Pix[:4])
+	}
+	e, ok := f.Char('é')
+	if !ok {
+		t.Fatal("expected glyph for non-ASCII rune")
+	}
+	if e.YOff != 4 {
+		t.Fatalf("expected descender glyph at YOff 4, got %d", e.YOff)
+	}
+	if got := f.LineWidth("AA"); got != 9 {
+		t.Fatalf("expected kerned width 9, got %d", got)
+	}
+}
+
+func TestDrawAndMetrics(t *testing.T) {
+	if got := Default.TextWidth("ab\nabc"); got != 3*Advance {
+		t.Fatalf("expected widest line width %d, got %d", 3*Advance, got)
+	}
+	if got := Default.TextHeight("a\nb"); got != Default.LineHeight+Height {
+		t.Fatalf("expected two-line height %d, got %d", Default.LineHeight+Height, got)
+	}
+
+	maxY := 0
+	Default.Draw("i\ni", 0, 0, func(x, y int) {
+		if y > maxY {
+			maxY = y
+		}
+	})
+	if maxY != Default.LineHeight+Height-1 {
+		t.Fatalf("expected second line to end at %d, got %d", Default.LineHeight+Height-1, maxY)
+	}
+}
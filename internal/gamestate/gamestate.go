@@ -3,6 +3,7 @@ package gamestate
 import (
 	"fmt"
 	"image/color"
+	"strings"
 	"time"
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/app"
@@ -32,6 +33,7 @@ type GameStateMachine struct {
 	isDebug        bool
 	engineSplash   *EngineSplashState
 	credits        *CreditsState
+	remap          *RemapState
 	creditsEntries []CreditEntry
 
 	// Engine info for splash
@@ -45,6 +47,9 @@ type GameStateMachine struct {
 	// Renderer and palette for drawing built-in states
 	renderer graphics.Renderer
 	palette  *pal.Manager
+
+	// Saves the actions' bindings when the remap screen changes them
+	saveBindings func() error
 }
 
 // NewGameStateMachine creates a new game state machine with built-in states
@@ -63,10 +68,12 @@ func NewGameStateMachine(isDebug bool, engineName, engineVersion, engineDevelope
 	// Create built-in states
 	gsm.engineSplash = NewEngineSplashState(gsm)
 	gsm.credits = NewCreditsState(gsm)
+	gsm.remap = NewRemapState(gsm)
 
 	// Register built-in states
 	gsm.StateMachine.RegisterStateInstance(EngineSplashStateName, gsm.engineSplash)
 	gsm.StateMachine.RegisterStateInstance(CreditsStateName, gsm.credits)
+	gsm.StateMachine.RegisterStateInstance(RemapStateName, gsm.remap)
 
 	return gsm
 }
@@ -121,15 +128,15 @@ func (gsm *GameStateMachine) Start(initialState string) error {
 
 // Exit transitions to credits state before exiting
 func (gsm *GameStateMachine) Exit() error {
-	// Transition to credits
-	return gsm.StateMachine.ChangeState(CreditsStateName)
+	// Fade to credits
+	return gsm.StateMachine.ChangeStateWith(CreditsStateName, DefaultTransition)
 }
 
 // Override ChangeState to prevent direct changes to built-in states from outside
-// (except through Start() and Exit())
+// (except through Start(), Exit() and Remap())
 func (gsm *GameStateMachine) ChangeState(name string) error {
-	if name == EngineSplashStateName || name == CreditsStateName {
-		return fmt.Errorf("cannot directly change to built-in state '%s' (use Start() or Exit())", name)
+	if name == EngineSplashStateName || name == CreditsStateName || name == RemapStateName {
+		return fmt.Errorf("cannot directly change to built-in state '%s' (use Start(), Exit() or Remap())", name)
 	}
 	return gsm.StateMachine.ChangeState(name)
 }
@@ -163,37 +170,38 @@ func (ess *EngineSplashState) HandleInput(sm *statemachine.StateMachine) {
 	// Any input can skip the splash - transition immediately
 	// Check if any button is pressed
 	hasInput := false
-	for i := 0; i < 6; i++ {
+	for i := 0; i < input.NumButtons; i++ {
 		if input.Btnp(i) {
 			hasInput = true
 			break
 		}
 	}
 
-	if hasInput {
+	if hasInput && !ess.autoTransitioned {
 		// Skip splash on any input
-		if ess.gsm.initialState != "" {
-			ess.gsm.StateMachine.ChangeState(ess.gsm.initialState)
-		} else {
-			// Default to "menu" if no initial state
-			ess.gsm.StateMachine.ChangeState("menu")
-		}
+		ess.leave()
+		ess.autoTransitioned = true
 	}
 }
 
 func (ess *EngineSplashState) Update(dt float64) {
 	if !ess.autoTransitioned && time.Since(ess.startTime) >= ess.splashDuration {
 		// Auto-transition after duration
-		if ess.gsm.initialState != "" {
-			ess.gsm.StateMachine.ChangeState(ess.gsm.initialState)
-		} else {
-			// Default to "menu" if no initial state
-			ess.gsm.StateMachine.ChangeState("menu")
-		}
+		ess.leave()
 		ess.autoTransitioned = true
 	}
 }
 
+// leave fades from the splash to the initial state
+func (ess *EngineSplashState) leave() {
+	if ess.gsm.initialState != "" {
+		ess.gsm.StateMachine.ChangeStateWith(ess.gsm.initialState, DefaultTransition)
+	} else {
+		// Default to "menu" if no initial state
+		ess.gsm.StateMachine.ChangeStateWith("menu", DefaultTransition)
+	}
+}
+
 func (ess *EngineSplashState) Draw() {
 	// Draw engine splash screen
 	if ess.gsm.renderer == nil {
@@ -211,19 +219,19 @@ func (ess *EngineSplashState) Draw() {
 	// Draw engine name
 	nameCol := ess.gsm.palette.Color(15) // White
 	name := ess.gsm.engineName
-	ess.gsm.renderer.Print(name, (ess.gsm.renderer.Width()-len(name)*font.Advance)/2,
+	ess.gsm.renderer.Print(name, (ess.gsm.renderer.Width()-ess.gsm.renderer.Font().TextWidth(name))/2,
 		ess.gsm.renderer.Height()/2-20, color.RGBA{R: nameCol.R, G: nameCol.G, B: nameCol.B, A: nameCol.A})
 
 	// Draw version
 	version := "v" + ess.gsm.engineVersion
 	versionCol := ess.gsm.palette.Color(7) // Light gray
-	ess.gsm.renderer.Print(version, (ess.gsm.renderer.Width()-len(version)*font.Advance)/2,
+	ess.gsm.renderer.Print(version, (ess.gsm.renderer.Width()-ess.gsm.renderer.Font().TextWidth(version))/2,
 		ess.gsm.renderer.Height()/2, color.RGBA{R: versionCol.R, G: versionCol.G, B: versionCol.B, A: versionCol.A})
 
 	// Draw "Press any key" message at bottom
 	msg := "Press any key..."
 	msgCol := ess.gsm.palette.Color(6) // Gray
-	ess.gsm.renderer.Print(msg, (ess.gsm.renderer.Width()-len(msg)*font.Advance)/2,
+	ess.gsm.renderer.Print(msg, (ess.gsm.renderer.Width()-ess.gsm.renderer.Font().TextWidth(msg))/2,
 		ess.gsm.renderer.Height()-20, color.RGBA{R: msgCol.R, G: msgCol.G, B: msgCol.B, A: msgCol.A})
 }
 
@@ -281,7 +289,7 @@ func (cs *CreditsState) HandleInput(sm *statemachine.StateMachine) {
 	// Only exit if there's actual user input (any button pressed)
 	// Check all buttons - if any are currently pressed, exit
 	hasInput := false
-	for i := 0; i < 6; i++ {
+	for i := 0; i < input.NumButtons; i++ {
 		if input.Btnp(i) {
 			hasInput = true
 			break
@@ -320,70 +328,53 @@ func (cs *CreditsState) Draw() {
 	col := cs.gsm.palette.Color(1)
 	cs.gsm.renderer.Clear(color.RGBA{R: col.R, G: col.G, B: col.B, A: col.A})
 
+	w, h := cs.gsm.renderer.Width(), cs.gsm.renderer.Height()
+	ink := func(n int) color.Color {
+		c := cs.gsm.palette.Color(n)
+		return color.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+	}
+	centered := font.BoxOptions{Align: font.AlignCenter, Wrap: true, Ink: ink}
+
 	// Draw title at top
-	titleCol := cs.gsm.palette.Color(15) // White
-	title := "CREDITS"
-	cs.gsm.renderer.Print(title, (cs.gsm.renderer.Width()-len(title)*font.Advance)/2, 20,
-		color.RGBA{R: titleCol.R, G: titleCol.G, B: titleCol.B, A: titleCol.A})
+	cs.gsm.renderer.PrintBox("CREDITS", 0, 20, w, 0, ink(15), centered) // White
 
 	// Draw engine credits
 	engineName, engineVersion, engineDev := cs.gsm.GetEngineInfo()
-	engineCol := cs.gsm.palette.Color(11) // Light blue
+	engineText := font.EscapeMarkup(engineName+" "+engineVersion) + "\n" + font.EscapeMarkup("Developed by "+engineDev)
+	engineOpts := centered
+	engineOpts.LineSpacing = 7
 	y := 60
+	y += cs.gsm.renderer.PrintBox(engineText, 0, y, w, 0, ink(11), engineOpts) + 20 // Light blue
 
-	engineLine := engineName + " " + engineVersion
-	cs.gsm.renderer.Print(engineLine, (cs.gsm.renderer.Width()-len(engineLine)*font.Advance)/2, y,
-		color.RGBA{R: engineCol.R, G: engineCol.G, B: engineCol.B, A: engineCol.A})
-	y += 15
-
-	devLine := "Developed by " + engineDev
-	cs.gsm.renderer.Print(devLine, (cs.gsm.renderer.Width()-len(devLine)*font.Advance)/2, y,
-		color.RGBA{R: engineCol.R, G: engineCol.G, B: engineCol.B, A: engineCol.A})
-	y += 30
-
-	// Draw game credits
-	entryCol := cs.gsm.palette.Color(7)    // Light gray
-	categoryCol := cs.gsm.palette.Color(6) // Gray
-
-	// Group by category
+	// Draw game credits grouped by category, in the order categories were added
+	var order []string
 	categories := make(map[string][]CreditEntry)
 	for _, entry := range cs.gsm.creditsEntries {
+		if _, ok := categories[entry.Category]; !ok {
+			order = append(order, entry.Category)
+		}
 		categories[entry.Category] = append(categories[entry.Category], entry)
 	}
-
-	// Draw credits by category
-	for cat, entries := range categories {
-		if y > cs.gsm.renderer.Height()-40 {
-			break // Don't draw off screen
+	var b strings.Builder
+	for i, cat := range order {
+		if i > 0 {
+			b.WriteString("\n") // Space between categories
 		}
-
-		// Category header
-		catText := cat + ":"
-		cs.gsm.renderer.Print(catText, 20, y,
-			color.RGBA{R: categoryCol.R, G: categoryCol.G, B: categoryCol.B, A: categoryCol.A})
-		y += 15
-
-		// Entries in this category
-		for _, entry := range entries {
-			if y > cs.gsm.renderer.Height()-40 {
-				break
-			}
+		b.WriteString("{c:6}" + font.EscapeMarkup(cat) + ":{/c}\n") // Gray header
+		for _, entry := range categories[cat] {
 			entryText := "  " + entry.Name
 			if entry.Role != "" {
 				entryText = entryText + " - " + entry.Role
 			}
-			cs.gsm.renderer.Print(entryText, 20, y,
-				color.RGBA{R: entryCol.R, G: entryCol.G, B: entryCol.B, A: entryCol.A})
-			y += 12
+			b.WriteString(font.EscapeMarkup(entryText) + "\n")
 		}
-		y += 10 // Space between categories
 	}
+	// Entries are light gray; anything past the box is clipped
+	cs.gsm.renderer.PrintBox(b.String(), 20, y, w-40, h-40-y, ink(7),
+		font.BoxOptions{LineSpacing: 4, Wrap: true, Ink: ink})
 
 	// Draw "Press any key to exit" at bottom
-	msgCol := cs.gsm.palette.Color(6) // Gray
-	msg := "Press any key to exit"
-	cs.gsm.renderer.Print(msg, (cs.gsm.renderer.Width()-len(msg)*font.Advance)/2,
-		cs.gsm.renderer.Height()-15, color.RGBA{R: msgCol.R, G: msgCol.G, B: msgCol.B, A: msgCol.A})
+	cs.gsm.renderer.PrintBox("Press any key to exit", 0, h-15, w, 0, ink(6), centered) // Gray
 }
 
 func (cs *CreditsState) Exit(sm *statemachine.StateMachine) {
@@ -1,9 +1,16 @@
 package gamestate
 
 import (
+	"errors"
+	"image/color"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
 	"github.com/AndrewDonelson/retroforge-engine/internal/statemachine"
 )
 
@@ -130,6 +137,12 @@ func TestExit(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 
+	// Credits fade in; the state changes at the transition's midpoint
+	if active, _ := gsm.GetActiveState(); active != "test" {
+		t.Errorf("expected test state before the midpoint, got %s", active)
+	}
+	gsm.Update(0.3)
+
 	active, exists := gsm.GetActiveState()
 	if !exists {
 		t.Error("should have active state")
@@ -359,3 +372,148 @@ func TestCreditsStateMethods(t *testing.T) {
 	// Test HandleCreditsInput
 	credits.HandleCreditsInput() // This doesn't do much but covers the method
 }
+
+// Test ChangeStateWith draws the transition over the states
+func TestChangeStateWithTransition(t *testing.T) {
+	r := rendersoft.New(32, 16)
+	gsm := NewGameStateMachine(true, "TestEngine", "1.0.0", "TestDev", r, nil)
+	gsm.RegisterStateInstance("a", &TestState{name: "a"})
+	gsm.RegisterStateInstance("b", &TestState{name: "b"})
+	gsm.Start("a")
+
+	if err := gsm.ChangeStateWith("b", statemachine.Transition{Kind: "spin", Duration: 1}); err == nil {
+		t.Error("expected error for unknown transition")
+	}
+	if err := gsm.ChangeStateWith(CreditsStateName, DefaultTransition); err == nil {
+		t.Error("expected error when changing to credits directly")
+	}
+
+	for _, kind := range []string{TransitionFade, TransitionWipe, TransitionIris, TransitionDissolve, TransitionPixelate} {
+		if err := gsm.ChangeStateWith("b", statemachine.Transition{Kind: kind, Duration: 1}); err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+
+		// Fully covered at the midpoint
+		gsm.Update(0.5)
+		r.Clear(color.RGBA{255, 255, 255, 255})
+		gsm.Draw()
+		if kind != TransitionPixelate { // Pixelate covers with blocks, not black
+			for y := 0; y < 16; y++ {
+				for x := 0; x < 32; x++ {
+					if r.PGetIndex(x, y) != 0 {
+						t.Fatalf("%s: (%d, %d) not covered at the midpoint", kind, x, y)
+					}
+				}
+			}
+		}
+
+		// Nothing drawn once it ends
+		gsm.Update(0.5)
+		r.Clear(color.RGBA{255, 255, 255, 255})
+		gsm.Draw()
+		if got := r.PGet(0, 0); got != (color.RGBA{255, 255, 255, 255}) {
+			t.Errorf("%s: drew %v after the transition", kind, got)
+		}
+		gsm.ChangeState("a")
+	}
+}
+
+// Pixelate copies palette indices, so the screen palette applies once
+func TestPixelateKeepsIndices(t *testing.T) {
+	r := rendersoft.New(32, 16)
+	r.SetPalette(pal.Default50)
+	gsm := NewGameStateMachine(true, "TestEngine", "1.0.0", "TestDev", r, nil)
+	gsm.RegisterStateInstance("a", &TestState{name: "a"})
+	gsm.RegisterStateInstance("b", &TestState{name: "b"})
+	gsm.Start("a")
+	if err := gsm.ChangeStateWith("b", statemachine.Transition{Kind: TransitionPixelate, Duration: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	gsm.Update(0.5)
+	r.Clear(pal.Index(5))
+	r.SetScreenPal(5, 6)
+	gsm.Draw()
+	if got := r.PGetIndex(3, 3); got != 5 {
+		t.Errorf("block index = %d, want 5", got)
+	}
+	if got := r.PGet(3, 3); got != pal.Default50[6] {
+		t.Errorf("block color = %v, want %v", got, pal.Default50[6])
+	}
+}
+
+func TestRemapState(t *testing.T) {
+	input.Reset()
+	defer input.Reset()
+	defer input.SetActions(nil)
+	if err := input.SetActions(map[string][]string{"jump": {"btn:O", "key:space", "pad:a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	gsm := NewGameStateMachine(true, "TestEngine", "1.0.0", "TestDev", rendersoft.New(480, 270), pal.NewManager())
+	saved := 0
+	gsm.SetBindingSaver(func() error { saved++; return nil })
+	if err := gsm.ChangeState(RemapStateName); err == nil {
+		t.Error("remap screen should only open through Remap()")
+	}
+	if err := gsm.Remap(); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := gsm.GetActiveState(); name != RemapStateName {
+		t.Fatalf("active state = %q, want %q", name, RemapStateName)
+	}
+
+	// frame presses input for one frame of the remap screen
+	frame := func(press func()) {
+		input.Step()
+		press()
+		gsm.HandleInput()
+		gsm.Update(1.0 / 60)
+		gsm.Draw()
+	}
+	frame(func() { input.Set(input.BtnRight, true) }) // Pick key:space...
+	frame(func() {
+		input.Set(input.BtnRight, false)
+		input.Set(input.BtnO, true) // ...change it...
+	})
+	frame(func() {
+		input.Set(input.BtnO, false)
+		input.SetKey("w", true) // ...to W
+	})
+	if got := input.Rebound()["jump"]; !reflect.DeepEqual(got, []string{"btn:o", "key:w", "pad:a"}) {
+		t.Fatalf("jump rebound to %v", got)
+	}
+	frame(func() {
+		input.SetKey("w", false)
+		input.Set(input.BtnX, true) // Back
+	})
+	frame(func() {})
+	if saved != 1 || gsm.GetStackDepth() != 0 {
+		t.Errorf("saved %d times, %d states left; want 1, 0", saved, gsm.GetStackDepth())
+	}
+
+	// When saving fails the screen stays open showing why, until left again
+	gsm.SetBindingSaver(func() error { return errors.New("disk full") })
+	gsm.Remap()
+	frame(func() {
+		input.Set(input.BtnX, false)
+		input.Set(input.BtnO, true)
+	})
+	frame(func() {
+		input.Set(input.BtnO, false)
+		input.SetKey("q", true)
+	})
+	frame(func() {
+		input.SetKey("q", false)
+		input.Set(input.BtnX, true)
+	})
+	rs := gsm.remap
+	if gsm.GetStackDepth() != 1 || !strings.Contains(rs.err, "disk full") {
+		t.Fatalf("%d states left, error %q after a failed save", gsm.GetStackDepth(), rs.err)
+	}
+	frame(func() { input.Set(input.BtnX, false) })
+	frame(func() { input.Set(input.BtnX, true) })
+	if gsm.GetStackDepth() != 0 {
+		t.Error("leaving again should close the screen")
+	}
+}
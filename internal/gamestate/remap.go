@@ -0,0 +1,171 @@
+package gamestate
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/statemachine"
+)
+
+// RemapStateName is the built-in screen where players rebind the cart's actions
+const RemapStateName = "__remap"
+
+// SetBindingSaver sets what saves the actions' bindings when the remap screen
+// closes after changing them
+func (gsm *GameStateMachine) SetBindingSaver(save func() error) {
+	gsm.saveBindings = save
+}
+
+// Remap opens the remap screen over the current state; it pops itself when
+// the player is done
+func (gsm *GameStateMachine) Remap() error {
+	return gsm.StateMachine.PushState(RemapStateName)
+}
+
+// RemapState lists the cart's actions and their bindings. Left and right pick
+// one of an action's bindings (or a new one after them); O waits for the next
+// key, button or stick push and binds it there (see input.Rebind).
+type RemapState struct {
+	gsm       *GameStateMachine
+	selected  int    // Row: an action, then "Reset to defaults" and "Done"
+	slot      int    // Binding picked in the selected action's row
+	listening string // Action waiting for an input ("" = none)
+	changed   bool   // Bindings changed since the screen opened
+	err       string // Why the bindings weren't saved, shown until the screen closes
+}
+
+// NewRemapState creates a new remap screen
+func NewRemapState(gsm *GameStateMachine) *RemapState {
+	return &RemapState{gsm: gsm}
+}
+
+func (rs *RemapState) Initialize(sm *statemachine.StateMachine) error {
+	return nil
+}
+
+func (rs *RemapState) Enter(sm *statemachine.StateMachine) {
+	rs.selected, rs.slot, rs.listening, rs.changed, rs.err = 0, 0, "", false, ""
+}
+
+func (rs *RemapState) HandleInput(sm *statemachine.StateMachine) {
+	if rs.listening != "" {
+		if input.Keyp("escape") {
+			rs.listening = "" // Cancel
+		} else if b, ok := input.Capture(); ok {
+			input.Rebind(rs.listening, rs.slot, b)
+			rs.listening, rs.changed = "", true
+		}
+		return
+	}
+
+	actions := input.Actions()
+	rows := len(actions) + 2
+	switch {
+	case input.Btnp(input.BtnUp):
+		rs.selected, rs.slot = (rs.selected+rows-1)%rows, 0
+	case input.Btnp(input.BtnDown):
+		rs.selected, rs.slot = (rs.selected+1)%rows, 0
+	case input.Btnp(input.BtnLeft) && rs.selected < len(actions):
+		rs.slot = max(0, rs.slot-1)
+	case input.Btnp(input.BtnRight) && rs.selected < len(actions):
+		rs.slot = min(len(input.Bindings(actions[rs.selected])), rs.slot+1)
+	case input.Btnp(input.BtnO):
+		switch {
+		case rs.selected < len(actions):
+			rs.listening = actions[rs.selected]
+		case rs.selected == len(actions):
+			input.ResetBindings()
+			rs.changed = true
+		default:
+			rs.leave(sm)
+		}
+	case input.Btnp(input.BtnX), input.Btnp(input.BtnStart):
+		rs.leave(sm)
+	}
+}
+
+// leave saves the bindings if they changed and closes the screen. If they
+// can't be saved it stays open showing why; leaving again closes it.
+func (rs *RemapState) leave(sm *statemachine.StateMachine) {
+	changed := rs.changed
+	rs.changed = false
+	if changed && rs.gsm.saveBindings != nil {
+		if err := rs.gsm.saveBindings(); err != nil {
+			rs.err = "Bindings not saved: " + err.Error()
+			return
+		}
+	}
+	_ = sm.PopState()
+}
+
+func (rs *RemapState) Update(dt float64) {
+}
+
+func (rs *RemapState) Draw() {
+	r := rs.gsm.renderer
+	if r == nil {
+		return
+	}
+
+	// Reset camera and clipping to ensure clean drawing
+	r.SetCamera(0, 0)
+	r.SetClip(0, 0, 0, 0)
+
+	ink := func(n int) color.Color {
+		c := rs.gsm.palette.Color(n)
+		return color.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+	}
+	r.Clear(ink(1))
+	w, h := r.Width(), r.Height()
+	centered := font.BoxOptions{Align: font.AlignCenter, Ink: ink}
+	r.PrintBox("CONTROLS", 0, 20, w, 0, ink(15), centered)
+
+	actions := input.Actions()
+	rows := append(actions, "Reset to defaults", "Done")
+	const top, step = 50, 12
+	visible := max(1, (h-40-top)/step)
+	first := max(0, rs.selected-visible+1) // Scroll to keep the selection shown
+	for i := first; i < len(rows) && i < first+visible; i++ {
+		y := top + (i-first)*step
+		c := ink(7)
+		if i == rs.selected {
+			c = ink(15)
+			r.Print(">", 30, y, c)
+		}
+		r.Print(rows[i], 40, y, c)
+		if i >= len(actions) {
+			continue
+		}
+		specs := []string{}
+		for _, b := range input.Bindings(actions[i]) {
+			specs = append(specs, b.String())
+		}
+		if i == rs.selected {
+			specs = append(specs, "+") // A new binding
+			s := min(rs.slot, len(specs)-1)
+			specs[s] = "[" + specs[s] + "]"
+		}
+		text := strings.Join(specs, ", ")
+		if rows[i] == rs.listening {
+			text = "Press a key or button..."
+		}
+		r.PrintBox(font.EscapeMarkup(text), 160, y, w-180, step, c, font.BoxOptions{Ink: ink})
+	}
+
+	hint := "Left/Right: pick   O: change   X: back"
+	if rs.listening != "" {
+		hint = "Esc: cancel"
+	}
+	r.PrintBox(hint, 0, h-15, w, 0, ink(6), centered)
+	if rs.err != "" {
+		r.PrintBox(font.EscapeMarkup(rs.err), 20, h-30, w-40, 0, ink(3), centered) // Red
+	}
+}
+
+func (rs *RemapState) Exit(sm *statemachine.StateMachine) {
+}
+
+func (rs *RemapState) Shutdown() {
+}
@@ -0,0 +1,141 @@
+package gamestate
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	"github.com/AndrewDonelson/retroforge-engine/internal/statemachine"
+)
+
+// Transition kinds for ChangeStateWith
+const (
+	TransitionFade     = "fade"     // Ordered dither to black and back
+	TransitionWipe     = "wipe"     // Black sweeps in from the left, then uncovers to the right
+	TransitionIris     = "iris"     // Circle closes on the center, then opens
+	TransitionDissolve = "dissolve" // Random pixels turn black, then clear
+	TransitionPixelate = "pixelate" // Growing blocks, then sharpening
+)
+
+// DefaultTransition is used by the built-in splash and credits states
+var DefaultTransition = statemachine.Transition{Kind: TransitionFade, Duration: 0.5}
+
+// IsTransition reports whether kind names a built-in transition
+func IsTransition(kind string) bool {
+	switch kind {
+	case TransitionFade, TransitionWipe, TransitionIris, TransitionDissolve, TransitionPixelate:
+		return true
+	}
+	return false
+}
+
+// ChangeStateWith changes state through a built-in transition (see
+// statemachine.StateMachine.ChangeStateWith)
+func (gsm *GameStateMachine) ChangeStateWith(name string, t statemachine.Transition) error {
+	if name == EngineSplashStateName || name == CreditsStateName {
+		return fmt.Errorf("cannot directly change to built-in state '%s' (use Start() or Exit())", name)
+	}
+	if t.Duration > 0 && !IsTransition(t.Kind) {
+		return fmt.Errorf("unknown transition '%s'", t.Kind)
+	}
+	return gsm.StateMachine.ChangeStateWith(name, t)
+}
+
+// Draw draws the top state, then the transition in progress over it
+func (gsm *GameStateMachine) Draw() {
+	gsm.StateMachine.Draw()
+	if kind, progress, ok := gsm.Transition(); ok && gsm.renderer != nil {
+		gsm.drawTransition(kind, progress)
+	}
+}
+
+// bayer4 orders the pixels of a 4×4 cell for dithered fades
+var bayer4 = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// drawTransition draws transition kind at progress (0..1) over the frame. The
+// screen is fully covered at the midpoint, when the state changes.
+func (gsm *GameStateMachine) drawTransition(kind string, progress float64) {
+	r := gsm.renderer
+	camX, camY := r.GetCamera()
+	clipX, clipY, clipW, clipH := r.GetClip()
+	r.SetZoom(1) // Covers the whole screen, over zoomed drawing
+	r.SetCamera(0, 0)
+	r.SetClip(0, 0, 0, 0)
+	defer func() {
+		r.SetCamera(camX, camY)
+		r.SetClip(clipX, clipY, clipW, clipH)
+	}()
+
+	// Coverage rises to 1 at the midpoint and falls back to 0
+	cover := 1 - math.Abs(1-2*progress)
+	w, h := r.Width(), r.Height()
+	black := pal.Index(0)
+
+	switch kind {
+	case TransitionFade:
+		level := int(cover * 16)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if bayer4[y&3][x&3] < level {
+					r.PSet(x, y, black)
+				}
+			}
+		}
+	case TransitionDissolve:
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				// Fixed pseudo-random threshold per pixel
+				n := uint32(x)*73856093 ^ uint32(y)*19349663
+				n ^= n >> 13
+				n *= 0x5bd1e995
+				n ^= n >> 15
+				if float64(n&0xFFFF)/0x10000 < cover {
+					r.PSet(x, y, black)
+				}
+			}
+		}
+	case TransitionWipe:
+		edge := int(math.Round(cover * float64(w)))
+		if edge <= 0 {
+			return
+		}
+		if progress < 0.5 {
+			r.RectFill(0, 0, edge-1, h-1, black) // Covering from the left
+		} else {
+			r.RectFill(w-edge, 0, w-1, h-1, black) // Uncovering to the right
+		}
+	case TransitionIris:
+		cx, cy := float64(w)/2, float64(h)/2
+		radius := (1 - cover) * math.Hypot(cx, cy)
+		for y := 0; y < h; y++ {
+			dy := float64(y) + 0.5 - cy
+			if math.Abs(dy) >= radius {
+				r.RectFill(0, y, w-1, y, black)
+				continue
+			}
+			half := math.Sqrt(radius*radius - dy*dy)
+			left, right := int(math.Floor(cx-half)), int(math.Ceil(cx+half))
+			if left > 0 {
+				r.RectFill(0, y, left-1, y, black)
+			}
+			if right < w {
+				r.RectFill(right, y, w-1, y, black)
+			}
+		}
+	case TransitionPixelate:
+		block := 1 + int(cover*15)
+		if block < 2 {
+			return
+		}
+		for y := 0; y < h; y += block {
+			for x := 0; x < w; x += block {
+				r.RectFill(x, y, x+block-1, y+block-1, pal.Index(r.PGetIndex(x, y)))
+			}
+		}
+	}
+}
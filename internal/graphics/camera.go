@@ -0,0 +1,184 @@
+package graphics
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Camera2D follows a target through the world. Its position is the center of
+// the view; Apply sets the renderer's camera offset (including shake) and zoom
+// from it.
+type Camera2D struct {
+	X, Y           float64 // View center in world pixels
+	ScreenW        int     // Screen size in pixels
+	ScreenH        int
+	Zoom           int     // Integer zoom (1 = none)
+	Lerp           float64 // Fraction of the way to the target moved per 1/60 s (1 = snap)
+	DeadW, DeadH   float64 // Area around the center the target moves in without moving the camera
+	Trauma         float64 // Shake amount (0..1); the offset grows with its square
+	TraumaDecay    float64 // Trauma lost per second
+	MaxShake       float64 // Shake offset in pixels at full trauma
+	targetX        float64
+	targetY        float64
+	following      bool
+	bounded        bool
+	bx0, by0       float64 // World bounds the view stays inside
+	bx1, by1       float64
+	shakeX, shakeY float64
+	rng            *rand.Rand
+}
+
+// NewCamera2D creates a camera for a w×h screen centered on the screen, so
+// the world starts at its top-left corner as with no camera
+func NewCamera2D(w, h int) *Camera2D {
+	return &Camera2D{
+		X: float64(w) / 2, Y: float64(h) / 2,
+		ScreenW: w, ScreenH: h,
+		Zoom:        1,
+		Lerp:        1,
+		TraumaDecay: 1,
+		MaxShake:    8,
+		rng:         rand.New(rand.NewSource(1)),
+	}
+}
+
+// MoveTo centers the camera on (x, y) at once, inside its bounds
+func (c *Camera2D) MoveTo(x, y float64) {
+	c.X, c.Y = x, y
+	c.clamp()
+}
+
+// Follow makes the camera move toward (x, y)
+func (c *Camera2D) Follow(x, y float64) {
+	c.targetX, c.targetY, c.following = x, y, true
+}
+
+// Unfollow stops following the target
+func (c *Camera2D) Unfollow() {
+	c.following = false
+}
+
+// SetBounds keeps the view inside the world rectangle (x0, y0)-(x1, y1)
+func (c *Camera2D) SetBounds(x0, y0, x1, y1 float64) {
+	c.bx0, c.by0, c.bx1, c.by1, c.bounded = x0, y0, x1, y1, true
+	c.clamp()
+}
+
+// ClearBounds lets the view move anywhere
+func (c *Camera2D) ClearBounds() {
+	c.bounded = false
+}
+
+// Shake adds trauma (clamped to 1)
+func (c *Camera2D) Shake(amount float64) {
+	c.Trauma = math.Min(1, math.Max(0, c.Trauma+amount))
+}
+
+// ViewSize returns the size of the view in world pixels
+func (c *Camera2D) ViewSize() (w, h float64) {
+	z := float64(c.zoom())
+	return float64(c.ScreenW) / z, float64(c.ScreenH) / z
+}
+
+// Step moves the camera toward its target and decays the shake by dt seconds
+func (c *Camera2D) Step(dt float64) {
+	if c.following {
+		// Move only as far as needed to bring the target back inside the dead zone
+		gx := deadZone(c.X, c.targetX, c.DeadW/2)
+		gy := deadZone(c.Y, c.targetY, c.DeadH/2)
+		k := 1.0
+		if c.Lerp < 1 {
+			k = 1 - math.Pow(1-math.Max(0, c.Lerp), dt*60)
+		}
+		c.X += (gx - c.X) * k
+		c.Y += (gy - c.Y) * k
+	}
+	c.clamp()
+
+	c.Trauma = math.Max(0, c.Trauma-c.TraumaDecay*dt)
+	shake := c.Trauma * c.Trauma * c.MaxShake
+	c.shakeX = shake * (c.rng.Float64()*2 - 1)
+	c.shakeY = shake * (c.rng.Float64()*2 - 1)
+}
+
+// deadZone returns where the center must be for target to lie within half
+// of it
+func deadZone(center, target, half float64) float64 {
+	switch {
+	case target < center-half:
+		return target + half
+	case target > center+half:
+		return target - half
+	}
+	return center
+}
+
+// clamp keeps the view inside the bounds, centering it on bounds smaller
+// than the view
+func (c *Camera2D) clamp() {
+	if !c.bounded {
+		return
+	}
+	w, h := c.ViewSize()
+	c.X = clampAxis(c.X, w/2, c.bx0, c.bx1)
+	c.Y = clampAxis(c.Y, h/2, c.by0, c.by1)
+}
+
+func clampAxis(v, half, lo, hi float64) float64 {
+	if hi-lo <= half*2 {
+		return (lo + hi) / 2
+	}
+	return math.Min(math.Max(v, lo+half), hi-half)
+}
+
+func (c *Camera2D) zoom() int {
+	if c.Zoom < 1 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// Offset returns the renderer camera offset: the view's top-left corner in
+// world pixels, shaken
+func (c *Camera2D) Offset() (x, y int) {
+	w, h := c.ViewSize()
+	return int(math.Floor(c.X - w/2 + c.shakeX)), int(math.Floor(c.Y - h/2 + c.shakeY))
+}
+
+// Apply sets r's camera offset and zoom from the camera
+func (c *Camera2D) Apply(r Renderer) {
+	r.SetZoom(c.zoom())
+	r.SetCamera(c.Offset())
+}
+
+// ScreenToWorld converts a screen position to world pixels
+func (c *Camera2D) ScreenToWorld(sx, sy float64) (wx, wy float64) {
+	ox, oy := c.Offset()
+	z := float64(c.zoom())
+	return float64(ox) + sx/z, float64(oy) + sy/z
+}
+
+// WorldToScreen converts a world position to screen pixels
+func (c *Camera2D) WorldToScreen(wx, wy float64) (sx, sy float64) {
+	ox, oy := c.Offset()
+	z := float64(c.zoom())
+	return (wx - float64(ox)) * z, (wy - float64(oy)) * z
+}
+
+// View returns the world rectangle r currently draws to: the screen (or clip
+// rectangle) at the current zoom, moved by the camera offset
+func View(r Renderer) (x, y, w, h int) {
+	z := r.GetZoom()
+	if z < 1 {
+		z = 1
+	}
+	camX, camY := r.GetCamera()
+	x, y, w, h = r.GetClip()
+	if w <= 0 || h <= 0 {
+		x, y, w, h = 0, 0, r.Width(), r.Height()
+	}
+	// The zoomed area covers the top-left 1/z of the screen, rounded up
+	maxW, maxH := (r.Width()+z-1)/z, (r.Height()+z-1)/z
+	w, h = minInt(w, maxW-x), minInt(h, maxH-y)
+	return x + camX, y + camY, w, h
+}
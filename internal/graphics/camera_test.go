@@ -0,0 +1,114 @@
+package graphics
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+)
+
+func TestCameraFollowDeadZoneAndLerp(t *testing.T) {
+	c := NewCamera2D(100, 60)
+	if x, y := c.Offset(); x != 0 || y != 0 {
+		t.Fatalf("new camera offset = (%d, %d), want (0, 0)", x, y)
+	}
+
+	// Inside the dead zone the camera stays put
+	c.DeadW, c.DeadH = 20, 10
+	c.Follow(55, 32)
+	c.Step(1.0 / 60)
+	if c.X != 50 || c.Y != 30 {
+		t.Errorf("moved inside the dead zone: (%v, %v)", c.X, c.Y)
+	}
+
+	// Outside, it snaps so the target sits on the dead zone's edge
+	c.Follow(80, 30)
+	c.Step(1.0 / 60)
+	if c.X != 70 {
+		t.Errorf("X = %v, want 70", c.X)
+	}
+
+	// Lerp covers its fraction of the distance per 1/60 s
+	c.DeadW, c.Lerp = 0, 0.5
+	c.Follow(90, 30)
+	c.Step(1.0 / 60)
+	if c.X != 80 {
+		t.Errorf("lerped X = %v, want 80", c.X)
+	}
+	c.Step(2.0 / 60)
+	if c.X != 87.5 {
+		t.Errorf("lerped X after two frames = %v, want 87.5", c.X)
+	}
+}
+
+func TestCameraBoundsZoomAndShake(t *testing.T) {
+	c := NewCamera2D(100, 60)
+	c.SetBounds(0, 0, 300, 40)
+	c.MoveTo(-50, 500)
+	if c.X != 50 || c.Y != 20 {
+		t.Errorf("bounded center = (%v, %v), want (50, 20)", c.X, c.Y) // Bounds shorter than the view center it
+	}
+	c.MoveTo(400, 0)
+	if c.X != 250 {
+		t.Errorf("bounded X = %v, want 250", c.X)
+	}
+
+	// Zoom halves the view
+	c.Zoom = 2
+	c.MoveTo(100, 20)
+	if w, h := c.ViewSize(); w != 50 || h != 30 {
+		t.Errorf("view size = %v×%v", w, h)
+	}
+	if x, y := c.Offset(); x != 75 || y != 5 {
+		t.Errorf("zoomed offset = (%d, %d), want (75, 5)", x, y)
+	}
+	if wx, wy := c.ScreenToWorld(10, 20); wx != 80 || wy != 15 {
+		t.Errorf("ScreenToWorld = (%v, %v)", wx, wy)
+	}
+	if sx, sy := c.WorldToScreen(80, 15); sx != 10 || sy != 20 {
+		t.Errorf("WorldToScreen = (%v, %v)", sx, sy)
+	}
+
+	// Trauma shakes within trauma² × MaxShake and decays
+	c.Shake(0.5)
+	c.Step(0.25)
+	x, y := c.Offset()
+	if x < 75-1 || x > 75+1 || y < 5-1 || y > 5+1 {
+		t.Errorf("shaken offset (%d, %d) out of range", x, y)
+	}
+	c.Shake(2)
+	if c.Trauma != 1 {
+		t.Errorf("trauma = %v, want clamped to 1", c.Trauma)
+	}
+	c.Step(1)
+	if x, y := c.Offset(); c.Trauma != 0 || x != 75 || y != 5 {
+		t.Errorf("after decay: trauma %v offset (%d, %d)", c.Trauma, x, y)
+	}
+}
+
+func TestCameraApplyAndView(t *testing.T) {
+	r := rendersoft.New(100, 60)
+	r.SetPalette([]color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}})
+	c := NewCamera2D(100, 60)
+	c.Zoom = 2
+	c.MoveTo(40, 30)
+	c.Apply(r)
+	if x, y, w, h := View(r); x != 15 || y != 15 || w != 50 || h != 30 {
+		t.Errorf("View = (%d, %d, %d, %d), want (15, 15, 50, 30)", x, y, w, h)
+	}
+
+	// The world pixel at the view's corner fills a 2×2 block on screen
+	r.PSet(15, 15, pal.Index(1))
+	r.SetZoom(1)
+	if r.PGetIndex(1, 1) != 1 || r.PGetIndex(2, 2) != 0 {
+		t.Errorf("zoomed pixel: %d %d", r.PGetIndex(1, 1), r.PGetIndex(2, 2))
+	}
+
+	// The clip rectangle narrows the view
+	r.SetCamera(0, 0)
+	r.SetClip(10, 5, 20, 20)
+	if x, y, w, h := View(r); x != 10 || y != 5 || w != 20 || h != 20 {
+		t.Errorf("clipped View = (%d, %d, %d, %d)", x, y, w, h)
+	}
+}
@@ -0,0 +1,77 @@
+package graphics
+
+import "math"
+
+// Texture is a region of a row-major block of palette indices (negative =
+// transparent), as drawn by Renderer.Blit
+type Texture struct {
+	Pix        []int
+	Stride     int
+	X, Y, W, H int
+}
+
+// At returns the texel at (u, v) in the region
+func (t Texture) At(u, v int) int {
+	return t.Pix[(t.Y+v)*t.Stride+t.X+u]
+}
+
+// DepthFrame is a frame of palette indices with a depth buffer, as rendered
+// by the software 3D renderers, which draw billboards into it depth-tested
+// per pixel
+type DepthFrame struct {
+	W, H   int
+	Pix    []int     // The frame, row-major palette indices
+	Depth  []float32 // Distance drawn at each pixel
+	sprite []int     // Scratch block for billboards (-1 = transparent)
+}
+
+// NewDepthFrame creates a w×h frame
+func NewDepthFrame(w, h int) DepthFrame {
+	return DepthFrame{W: w, H: h, Pix: make([]int, w*h), Depth: make([]float32, w*h)}
+}
+
+// Size returns the frame size
+func (f *DepthFrame) Size() (w, h int) { return f.W, f.H }
+
+// Billboard draws tex stretched over the screen rectangle at (left, top),
+// sw×sh pixels, at distance dist. Pixels behind nearer ones and transparent
+// texels are skipped; shade, if not nil, recolors each texel drawn. It
+// returns a block holding only the billboard (the rest transparent) and its
+// screen rectangle; ok is false when nothing is visible.
+func (f *DepthFrame) Billboard(tex Texture, left, top, sw, sh, dist float64, shade func(c int) int) (pix []int, sx, sy, w, h int, ok bool) {
+	x0, x1 := max(0, int(math.Ceil(left-0.5))), min(f.W, int(math.Ceil(left+sw-0.5)))
+	y0, y1 := max(0, int(math.Ceil(top-0.5))), min(f.H, int(math.Ceil(top+sh-0.5)))
+	if x0 >= x1 || y0 >= y1 {
+		return nil, 0, 0, 0, 0, false
+	}
+
+	w, h = x1-x0, y1-y0
+	if cap(f.sprite) < w*h {
+		f.sprite = make([]int, w*h)
+	}
+	pix = f.sprite[:w*h]
+	d := float32(dist)
+	drawn := false
+	for py := y0; py < y1; py++ {
+		v := min(int((float64(py)+0.5-top)*float64(tex.H)/sh), tex.H-1)
+		out := pix[(py-y0)*w : (py-y0+1)*w]
+		for px := x0; px < x1; px++ {
+			out[px-x0] = -1
+			i := py*f.W + px
+			if d >= f.Depth[i] {
+				continue
+			}
+			u := min(int((float64(px)+0.5-left)*float64(tex.W)/sw), tex.W-1)
+			c := tex.At(u, v)
+			if c < 0 {
+				continue
+			}
+			if shade != nil {
+				c = shade(c)
+			}
+			out[px-x0], f.Pix[i], f.Depth[i] = c, c, d
+			drawn = true
+		}
+	}
+	return pix, x0, y0, w, h, drawn
+}
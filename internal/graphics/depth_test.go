@@ -0,0 +1,39 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBillboard(t *testing.T) {
+	f := NewDepthFrame(8, 8)
+	far := float32(math.Inf(1))
+	for i := range f.Depth {
+		f.Depth[i] = far
+	}
+	f.Depth[2*8+3] = 1 // A nearer pixel
+
+	// A 2×2 texture with one transparent texel, stretched over 4×4 pixels at (2, 1)
+	tex := Texture{Pix: []int{9, 9, 5, 7, -1, 7}, Stride: 3, X: 1, W: 2, H: 2}
+	pix, sx, sy, w, h, ok := f.Billboard(tex, 2, 1, 4, 4, 2, func(c int) int { return c + 1 })
+	if !ok || sx != 2 || sy != 1 || w != 4 || h != 4 {
+		t.Fatalf("billboard at %d, %d, %d×%d, ok %v", sx, sy, w, h, ok)
+	}
+	for _, p := range []struct{ x, y, want int }{
+		{2, 1, 10}, {5, 1, 6}, // Shaded texels
+		{3, 2, 0},            // Behind the nearer pixel
+		{5, 4, 8}, {2, 4, 0}, // Opaque and transparent texels
+	} {
+		if got := f.Pix[p.y*8+p.x]; got != p.want {
+			t.Errorf("frame (%d, %d) = %d, want %d", p.x, p.y, got, p.want)
+		}
+	}
+	if pix[1*w+1] != -1 || pix[0] != 10 || f.Depth[1*8+2] != 2 {
+		t.Errorf("block %v, depth %v", pix, f.Depth[1*8+2])
+	}
+
+	// Off screen
+	if _, _, _, _, _, ok := f.Billboard(tex, 10, 0, 4, 4, 1, nil); ok {
+		t.Error("billboard off screen drawn")
+	}
+}
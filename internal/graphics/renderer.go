@@ -1,36 +1,69 @@
 package graphics
 
-import "image/color"
+import (
+	"image/color"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
+)
 
 // Renderer defines minimal 2D drawing for text.
 type Renderer interface {
 	Width() int
 	Height() int
-	Clear(c color.RGBA)
-	Print(text string, x, y int, c color.RGBA)
-	PrintAnchored(text string, anchor string, c color.RGBA)
+	Clear(c color.Color)
+	Print(text string, x, y int, c color.Color)
+	PrintAnchored(text string, anchor string, c color.Color)
+	// PrintBox wraps, aligns and clips marked-up text to a box; returns the text height
+	PrintBox(text string, x, y, w, h int, c color.Color, opts font.BoxOptions) int
+	SetFont(f *font.Font) // Font used by Print (nil = built-in 5x7)
+	Font() *font.Font     // Current font, for measuring text
 	// Pixels exposes the backbuffer for tests/snapshots.
 	Pixels() []uint8 // RGBA length = width*height*4
+	// Indexed exposes the frame as palette indices and each index's displayed color, for capture
+	Indexed() (idx []uint8, colors []color.RGBA)
+	// Indexed framebuffer: colors may be pal.Index values or RGBA
+	SetPalette(colors []color.RGBA)  // Base colors for palette indices
+	SetScreenPal(index, display int) // Display index as another color for the whole frame
+	ResetScreenPal()                 // Restore the identity screen palette
+	PGetIndex(x, y int) int          // Palette index drawn at (x, y)
 	// Primitives
-	PSet(x, y int, c color.RGBA)
+	PSet(x, y int, c color.Color)
 	PGet(x, y int) color.RGBA // Get pixel color
-	Line(x0, y0, x1, y1 int, c color.RGBA)
-	Rect(x0, y0, x1, y1 int, c color.RGBA)
-	RectFill(x0, y0, x1, y1 int, c color.RGBA)
-	Circ(x, y, r int, c color.RGBA)
-	CircFill(x, y, r int, c color.RGBA)
-	Ellipse(x, y, rx, ry int, c color.RGBA)
-	EllipseFill(x, y, rx, ry int, c color.RGBA)
+	Line(x0, y0, x1, y1 int, c color.Color)
+	Rect(x0, y0, x1, y1 int, c color.Color)
+	RectFill(x0, y0, x1, y1 int, c color.Color)
+	Circ(x, y, r int, c color.Color)
+	CircFill(x, y, r int, c color.Color)
+	Ellipse(x, y, rx, ry int, c color.Color)
+	EllipseFill(x, y, rx, ry int, c color.Color)
 	// Shape primitives
-	Triangle(x, y, radius int, filled bool, c color.RGBA)
-	Diamond(x, y, radius int, filled bool, c color.RGBA)
-	Square(x, y, radius int, filled bool, c color.RGBA)
-	Pentagon(x, y, radius int, filled bool, c color.RGBA)
-	Hexagon(x, y, radius int, filled bool, c color.RGBA)
-	Star(x, y, radius int, filled bool, c color.RGBA)
+	Triangle(x, y, radius int, filled bool, c color.Color)
+	Diamond(x, y, radius int, filled bool, c color.Color)
+	Square(x, y, radius int, filled bool, c color.Color)
+	Pentagon(x, y, radius int, filled bool, c color.Color)
+	Hexagon(x, y, radius int, filled bool, c color.Color)
+	Star(x, y, radius int, filled bool, c color.Color)
+	// Freeform shapes (angles in degrees, 0 = right, clockwise)
+	Poly(points [][]int, filled bool, c color.Color)
+	LineThick(x0, y0, x1, y1, thickness int, c color.Color)
+	Arc(x, y, r int, a0, a1 float64, c color.Color)
+	Pie(x, y, r int, a0, a1 float64, filled bool, c color.Color)
+	RoundRect(x0, y0, x1, y1, r int, c color.Color)
+	RoundRectFill(x0, y0, x1, y1, r int, c color.Color)
+	BezierQuad(x0, y0, cx, cy, x1, y1 int, c color.Color)
+	BezierCubic(x0, y0, c1x, c1y, c2x, c2y, x1, y1 int, c color.Color)
+	// Textured drawing: sample returns the color at texture coordinates (u, v), ok=false for transparent
+	TLine(x0, y0, x1, y1 int, u, v, du, dv float64, sample func(u, v float64) (color.Color, bool))
+	Mode7(camX, camY, angle float64, horizon int, scale float64, sample func(u, v float64) (color.Color, bool))
+	// Blit draws the w×h region at (sx, sy) of a row-major block of palette indices
+	// (stride wide, negative = transparent) at (x, y), clipped once for the whole region
+	Blit(pix []int, stride, sx, sy, w, h, x, y int, flipX, flipY bool)
 	// State management
 	SetClip(x, y, w, h int)    // Set clipping rectangle (0,0,0,0 to disable)
 	GetClip() (x, y, w, h int) // Get current clip rectangle
 	SetCamera(x, y int)        // Set camera offset
 	GetCamera() (x, y int)     // Get camera offset
+	// SetZoom draws at an integer zoom (1 = none) until it changes
+	SetZoom(z int)
+	GetZoom() int
 }
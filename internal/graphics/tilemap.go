@@ -1,16 +1,23 @@
 package graphics
 
+// DefaultTileSize is the width and height of a tile in pixels
+const DefaultTileSize = 8
+
 // TileMap represents a 2D tilemap grid
 type TileMap struct {
 	width, height int
+	tileW, tileH  int   // Tile size in pixels
 	tiles         []int // 1D array: tiles[y*width + x] = tile index
+	version       int   // Bumped by every change, for data derived from the map
 }
 
-// NewTileMap creates a new tilemap of given dimensions
+// NewTileMap creates a new tilemap of given dimensions with 8×8 tiles
 func NewTileMap(w, h int) *TileMap {
 	return &TileMap{
 		width:  w,
 		height: h,
+		tileW:  DefaultTileSize,
+		tileH:  DefaultTileSize,
 		tiles:  make([]int, w*h),
 	}
 }
@@ -28,7 +35,10 @@ func (tm *TileMap) Set(x, y, v int) {
 	if x < 0 || y < 0 || x >= tm.width || y >= tm.height {
 		return
 	}
-	tm.tiles[y*tm.width+x] = v
+	if tm.tiles[y*tm.width+x] != v {
+		tm.tiles[y*tm.width+x] = v
+		tm.version++
+	}
 }
 
 // Width returns tilemap width
@@ -37,22 +47,78 @@ func (tm *TileMap) Width() int { return tm.width }
 // Height returns tilemap height
 func (tm *TileMap) Height() int { return tm.height }
 
+// SetTileSize sets the size of a tile in pixels (values below 1 are ignored)
+func (tm *TileMap) SetTileSize(w, h int) {
+	if w > 0 && h > 0 && (w != tm.tileW || h != tm.tileH) {
+		tm.tileW, tm.tileH = w, h
+		tm.version++
+	}
+}
+
+// Version changes whenever a tile or the tile size does
+func (tm *TileMap) Version() int { return tm.version }
+
+// TileSize returns the size of a tile in pixels
+func (tm *TileMap) TileSize() (w, h int) { return tm.tileW, tm.tileH }
+
+// TileAt returns the tile containing map pixel (px, py)
+func (tm *TileMap) TileAt(px, py int) (tx, ty int) {
+	return floorDiv(px, tm.tileW), floorDiv(py, tm.tileH)
+}
+
 // Draw draws a region of the tilemap using a sprite renderer
 // celX, celY: tile coordinates of top-left corner to draw
 // sx, sy: screen position to draw at
 // celW, celH: number of tiles to draw (width and height)
 // spriteRenderer: function that draws a sprite at (x, y) with given tile index
 func (tm *TileMap) Draw(celX, celY, sx, sy, celW, celH int, spriteRenderer func(x, y, tileIndex int)) {
-	for ty := 0; ty < celH; ty++ {
-		for tx := 0; tx < celW; tx++ {
+	tm.DrawCulled(celX, celY, sx, sy, celW, celH, 0, 0, 0, 0, spriteRenderer)
+}
+
+// DrawCulled is Draw limited to tiles overlapping the view rectangle
+// (viewX, viewY, viewW, viewH), given in the same coordinates as sx, sy.
+// A view with zero width or height draws the whole region.
+func (tm *TileMap) DrawCulled(celX, celY, sx, sy, celW, celH, viewX, viewY, viewW, viewH int, spriteRenderer func(x, y, tileIndex int)) {
+	tx0, ty0, tx1, ty1 := 0, 0, celW, celH
+	if viewW > 0 && viewH > 0 {
+		tx0 = maxInt(tx0, floorDiv(viewX-sx, tm.tileW))
+		ty0 = maxInt(ty0, floorDiv(viewY-sy, tm.tileH))
+		tx1 = minInt(tx1, floorDiv(viewX+viewW-1-sx, tm.tileW)+1)
+		ty1 = minInt(ty1, floorDiv(viewY+viewH-1-sy, tm.tileH)+1)
+	}
+	for ty := ty0; ty < ty1; ty++ {
+		for tx := tx0; tx < tx1; tx++ {
 			mapX := celX + tx
 			mapY := celY + ty
 			tileIndex := tm.Get(mapX, mapY)
 			if tileIndex != 0 { // 0 = empty/transparent
-				screenX := sx + tx*8 // Assuming 8x8 tiles
-				screenY := sy + ty*8
+				screenX := sx + tx*tm.tileW
+				screenY := sy + ty*tm.tileH
 				spriteRenderer(screenX, screenY, tileIndex)
 			}
 		}
 	}
 }
+
+// floorDiv divides rounding toward negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
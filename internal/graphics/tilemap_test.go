@@ -59,6 +59,18 @@ func TestTileMapGetSet(t *testing.T) {
 	if got := tm.Get(9, 9); got != 99 {
 		t.Errorf("Get(9, 9) = %d, expected 99", got)
 	}
+
+	// Only real changes bump the version
+	v := tm.Version()
+	tm.Set(9, 9, 99)
+	tm.Set(10, 10, 5)
+	if tm.Version() != v {
+		t.Errorf("version changed without a change")
+	}
+	tm.Set(9, 9, 3)
+	if tm.Version() == v {
+		t.Errorf("version unchanged after Set")
+	}
 }
 
 func TestTileMapDraw(t *testing.T) {
@@ -164,3 +176,40 @@ func TestTileMapEdgeCases(t *testing.T) {
 		t.Errorf("Draw with zero size should draw nothing, drew %d", drawn)
 	}
 }
+
+func TestTileMapTileSizeAndCulling(t *testing.T) {
+	tm := NewTileMap(10, 10)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			tm.Set(x, y, 1)
+		}
+	}
+	tm.SetTileSize(16, 12)
+	tm.SetTileSize(0, 5) // Ignored
+	if w, h := tm.TileSize(); w != 16 || h != 12 {
+		t.Fatalf("TileSize() = %d, %d, expected 16, 12", w, h)
+	}
+	if tx, ty := tm.TileAt(-1, 25); tx != -1 || ty != 2 {
+		t.Fatalf("TileAt(-1, 25) = %d, %d, expected -1, 2", tx, ty)
+	}
+
+	var drawn []struct{ x, y int }
+	tm.Draw(0, 0, 0, 0, 2, 1, func(x, y, tileIndex int) {
+		drawn = append(drawn, struct{ x, y int }{x, y})
+	})
+	if len(drawn) != 2 || drawn[1].x != 16 {
+		t.Fatalf("expected tiles 16px apart, got %v", drawn)
+	}
+
+	// A 20×20 view at (20, 10) overlaps tile columns 1-2 and rows 0-2
+	count := 0
+	tm.DrawCulled(0, 0, 0, 0, 10, 10, 20, 10, 20, 20, func(x, y, tileIndex int) {
+		if x+16 <= 20 || x >= 40 || y+12 <= 10 || y >= 30 {
+			t.Errorf("tile at (%d, %d) is outside the view", x, y)
+		}
+		count++
+	})
+	if count != 6 {
+		t.Fatalf("expected 6 visible tiles, got %d", count)
+	}
+}
@@ -0,0 +1,125 @@
+package graphics
+
+// Tileset resolves tile indices to sprites and holds per-tile flags.
+// A tile is either a whole sprite or a cell of the sheet sprite, which is cut
+// into CellW×CellH cells numbered left to right, top to bottom from 0.
+// Indices without a tile fall back to the sheet cell with the same number.
+type Tileset struct {
+	Sheet        string // Sprite used for sheet cells ("" = none)
+	CellW, CellH int    // Sheet cell size in pixels
+	tiles        map[int]Tile
+	flags        map[int]uint8
+	anims        map[int][]TileFrame
+	version      int // Bumped by every flag change, for data derived from the flags
+}
+
+// TileFrame is one frame of an animated tile
+type TileFrame struct {
+	Tile     int // Tile index drawn for this frame
+	Duration int // Milliseconds
+}
+
+// Tile is what a tile index draws
+type Tile struct {
+	Sprite string // Sprite name, or "" to use the sheet
+	Cell   int    // Sheet cell when Sprite is ""
+}
+
+// NewTileset creates an empty tileset with 8×8 sheet cells
+func NewTileset() *Tileset {
+	return &Tileset{
+		CellW: DefaultTileSize,
+		CellH: DefaultTileSize,
+		tiles: make(map[int]Tile),
+		flags: make(map[int]uint8),
+		anims: make(map[int][]TileFrame),
+	}
+}
+
+// SetTile sets what index draws
+func (ts *Tileset) SetTile(index int, t Tile) {
+	ts.tiles[index] = t
+}
+
+// Tile returns what index draws; ok is false if it draws nothing
+func (ts *Tileset) Tile(index int) (Tile, bool) {
+	if t, ok := ts.tiles[index]; ok {
+		return t, true
+	}
+	if ts.Sheet != "" && index > 0 {
+		return Tile{Cell: index}, true
+	}
+	return Tile{}, false
+}
+
+// Flags returns the flag bits of index
+func (ts *Tileset) Flags(index int) uint8 {
+	return ts.flags[index]
+}
+
+// SetFlags sets all flag bits of index
+func (ts *Tileset) SetFlags(index int, flags uint8) {
+	if ts.flags[index] != flags {
+		ts.version++
+	}
+	if flags == 0 {
+		delete(ts.flags, index)
+		return
+	}
+	ts.flags[index] = flags
+}
+
+// Version changes whenever a tile's flags do
+func (ts *Tileset) Version() int { return ts.version }
+
+// SetFlag sets or clears flag bit f (0-7) of index
+func (ts *Tileset) SetFlag(index, f int, on bool) {
+	if f < 0 || f > 7 {
+		return
+	}
+	flags := ts.flags[index]
+	if on {
+		flags |= 1 << uint(f)
+	} else {
+		flags &^= 1 << uint(f)
+	}
+	ts.SetFlags(index, flags)
+}
+
+// Matches reports whether index has every bit of mask set (mask 0 matches all)
+func (ts *Tileset) Matches(index int, mask uint8) bool {
+	return ts.flags[index]&mask == mask
+}
+
+// SetAnim makes index an animated tile cycling through frames (nil = not animated)
+func (ts *Tileset) SetAnim(index int, frames []TileFrame) {
+	if len(frames) == 0 {
+		delete(ts.anims, index)
+		return
+	}
+	ts.anims[index] = frames
+}
+
+// Animate returns the tile index drawn for index t seconds into its animation.
+// Indices that aren't animated are returned unchanged.
+func (ts *Tileset) Animate(index int, t float64) int {
+	frames, ok := ts.anims[index]
+	if !ok {
+		return index
+	}
+	total := 0
+	for _, f := range frames {
+		total += f.Duration
+	}
+	if total <= 0 {
+		return frames[0].Tile
+	}
+	ms := int(t*1000) % total
+	for _, f := range frames {
+		if ms < f.Duration {
+			return f.Tile
+		}
+		ms -= f.Duration
+	}
+	return frames[len(frames)-1].Tile
+}
@@ -0,0 +1,68 @@
+package graphics
+
+import "testing"
+
+func TestTileset(t *testing.T) {
+	ts := NewTileset()
+	if _, ok := ts.Tile(3); ok {
+		t.Fatal("tile without a sprite or sheet should not resolve")
+	}
+	ts.SetTile(3, Tile{Sprite: "grass"})
+	if tile, ok := ts.Tile(3); !ok || tile.Sprite != "grass" {
+		t.Fatalf("expected grass sprite, got %+v", tile)
+	}
+
+	ts.Sheet = "tiles"
+	if tile, ok := ts.Tile(7); !ok || tile.Sprite != "" || tile.Cell != 7 {
+		t.Fatalf("expected sheet cell 7, got %+v", tile)
+	}
+	if _, ok := ts.Tile(0); ok {
+		t.Fatal("tile 0 is empty")
+	}
+}
+
+func TestTilesetFlags(t *testing.T) {
+	ts := NewTileset()
+	ts.SetFlag(5, 0, true)
+	ts.SetFlag(5, 2, true)
+	ts.SetFlag(5, 9, true) // Out of range, ignored
+	if got := ts.Flags(5); got != 5 {
+		t.Fatalf("Flags(5) = %d, expected 5", got)
+	}
+	if !ts.Matches(5, 4) || ts.Matches(5, 2) || !ts.Matches(5, 0) || ts.Matches(6, 1) {
+		t.Fatal("unexpected Matches result")
+	}
+	ts.SetFlag(5, 0, false)
+	v := ts.Version()
+	ts.SetFlags(6, 0x80)
+	if ts.Flags(5) != 4 || ts.Flags(6) != 0x80 {
+		t.Fatalf("unexpected flags %d, %d", ts.Flags(5), ts.Flags(6))
+	}
+	if ts.Version() == v {
+		t.Fatal("version unchanged after SetFlags")
+	}
+	v = ts.Version()
+	if ts.SetFlags(6, 0x80); ts.Version() != v {
+		t.Fatal("version changed without a change")
+	}
+}
+
+func TestTilesetAnim(t *testing.T) {
+	ts := NewTileset()
+	ts.SetAnim(4, []TileFrame{{Tile: 4, Duration: 100}, {Tile: 5, Duration: 300}})
+	for _, c := range []struct {
+		t    float64
+		want int
+	}{{0, 4}, {0.099, 4}, {0.1, 5}, {0.399, 5}, {0.4, 4}, {0.55, 5}} {
+		if got := ts.Animate(4, c.t); got != c.want {
+			t.Errorf("Animate(4, %v) = %d, expected %d", c.t, got, c.want)
+		}
+	}
+	if got := ts.Animate(9, 1); got != 9 {
+		t.Errorf("tiles without an animation should be unchanged, got %d", got)
+	}
+	ts.SetAnim(4, nil)
+	if got := ts.Animate(4, 0.2); got != 4 {
+		t.Errorf("cleared animation should be unchanged, got %d", got)
+	}
+}
@@ -0,0 +1,337 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Actions are named inputs a cart declares in its manifest, each bound to any
+// number of buttons, keys, controller buttons, axes and mouse buttons:
+//
+//	"jump":   ["btn:O", "key:space", "pad:a"]
+//	"move_x": ["-key:left", "+key:right", "axis:leftx"]
+//
+// Players can rebind them; the buttons (Btn etc.) are not affected.
+
+// Binding is one input bound to an action, written "source:name".
+type Binding struct {
+	Source string // "btn", "key", "pad", "axis" or "mouse"
+	Name   string // Lower-case name
+	Index  int    // Button, controller button, axis or mouse button (not keys)
+	// Sign is the "-" or "+" prefix: -1 makes a button read as -1; on an
+	// axis, either picks that half, read as 0..1
+	Sign int
+}
+
+// Button names for btn: bindings, by button.
+var btnNames = [...]string{"left", "right", "up", "down", "o", "x", "start", "select", "l", "r"}
+
+// Controller buttons in SDL GameController order, for pad: bindings.
+var padButtonNames = [...]string{
+	"a", "b", "x", "y", "back", "guide", "start", "leftstick", "rightstick",
+	"leftshoulder", "rightshoulder", "dpup", "dpdown", "dpleft", "dpright",
+}
+
+// Axis names for axis: bindings, by axis.
+var axisNames = [...]string{"leftx", "lefty", "rightx", "righty", "lefttrigger", "righttrigger"}
+
+// Mouse buttons for mouse: bindings.
+var mouseNames = map[string]int{"left": MouseLeft, "right": MouseRight, "middle": MouseMiddle}
+
+// bindingAliases are other names for controller buttons and axes
+var bindingAliases = map[string]map[string]string{
+	"pad": {
+		"select": "back", "lb": "leftshoulder", "rb": "rightshoulder", "l": "leftshoulder", "r": "rightshoulder",
+		"ls": "leftstick", "rs": "rightstick", "up": "dpup", "down": "dpdown", "left": "dpleft", "right": "dpright",
+	},
+	"axis": {"lx": "leftx", "ly": "lefty", "rx": "rightx", "ry": "righty", "lt": "lefttrigger", "rt": "righttrigger"},
+}
+
+// ParseBinding parses a binding such as "btn:O", "key:space", "pad:a",
+// "-axis:lefty" or "mouse:left".
+func ParseBinding(s string) (Binding, error) {
+	var b Binding
+	spec := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(spec, "-"):
+		b.Sign, spec = -1, spec[1:]
+	case strings.HasPrefix(spec, "+"):
+		b.Sign, spec = 1, spec[1:]
+	}
+	source, name, ok := strings.Cut(spec, ":")
+	if !ok || name == "" {
+		return Binding{}, fmt.Errorf("binding %q: want source:name", s)
+	}
+	if alias, ok := bindingAliases[source][name]; ok {
+		name = alias
+	}
+	b.Source, b.Name = source, name
+	index := -1
+	switch source {
+	case "btn":
+		if n, err := strconv.Atoi(name); err == nil && n >= 0 && n < NumButtons {
+			index, b.Name = n, btnNames[n]
+		} else {
+			index = slices.Index(btnNames[:], name)
+		}
+	case "key":
+		b.Name, index = keyName(name), 0
+	case "pad":
+		index = slices.Index(padButtonNames[:], name)
+	case "axis":
+		index = slices.Index(axisNames[:], name)
+	case "mouse":
+		if m, ok := mouseNames[name]; ok {
+			index = m
+		}
+	default:
+		return Binding{}, fmt.Errorf("binding %q: unknown source %q (want btn, key, pad, axis or mouse)", s, source)
+	}
+	if index < 0 {
+		return Binding{}, fmt.Errorf("binding %q: unknown %s %q", s, source, name)
+	}
+	b.Index = index
+	return b, nil
+}
+
+// ParseBindings parses a list of bindings.
+func ParseBindings(specs []string) ([]Binding, error) {
+	bindings := make([]Binding, 0, len(specs))
+	for _, s := range specs {
+		b, err := ParseBinding(s)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
+
+// String returns the binding as ParseBinding reads it.
+func (b Binding) String() string {
+	prefix := ""
+	switch b.Sign {
+	case -1:
+		prefix = "-"
+	case 1:
+		prefix = "+"
+	}
+	return prefix + b.Source + ":" + b.Name
+}
+
+// value reads the binding for player p. Keys and the mouse belong to player
+// 0; keys that type read as up while text input is on.
+func (b Binding) value(p int) float64 {
+	on := false
+	switch b.Source {
+	case "btn":
+		on = players[p].Btn(b.Index)
+	case "key":
+		on = p == 0 && keys.down[b.Name] && !(text.on && keyTypes(b.Name))
+	case "pad":
+		on = pads[p] != nil && pads[p].buttons&(1<<b.Index) != 0
+	case "mouse":
+		on = p == 0 && mouse.buttons&b.Index != 0
+	case "axis":
+		v := players[p].Axis(b.Index)
+		if b.Sign != 0 {
+			v = math.Max(0, v*float64(b.Sign))
+		}
+		return v
+	}
+	switch {
+	case !on:
+		return 0
+	case b.Sign < 0:
+		return -1
+	}
+	return 1
+}
+
+var actions = struct {
+	defaults, bindings map[string][]Binding
+	held               [MaxPlayers]map[string]bool // Actions held as of the last Step
+}{defaults: map[string][]Binding{}, bindings: map[string][]Binding{}}
+
+// SetActions declares a cart's actions (name -> bindings), replacing any
+// declared before and any rebinding.
+func SetActions(defs map[string][]string) error {
+	defaults := make(map[string][]Binding, len(defs))
+	for name, specs := range defs {
+		bindings, err := ParseBindings(specs)
+		if err != nil {
+			return fmt.Errorf("action %q: %w", name, err)
+		}
+		defaults[name] = bindings
+	}
+	actions.defaults = defaults
+	ResetBindings()
+	return nil
+}
+
+// Actions returns the declared action names in order.
+func Actions() []string {
+	names := make([]string, 0, len(actions.defaults))
+	for name := range actions.defaults {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// HasAction reports whether an action is declared.
+func HasAction(name string) bool {
+	_, ok := actions.defaults[name]
+	return ok
+}
+
+// Bindings returns an action's current bindings.
+func Bindings(name string) []Binding { return slices.Clone(actions.bindings[name]) }
+
+// Bind replaces a declared action's bindings.
+func Bind(name string, bindings []Binding) {
+	if HasAction(name) {
+		actions.bindings[name] = slices.Clone(bindings)
+	}
+}
+
+// Rebind binds slot i of an action's bindings to b, keeping the sign of the
+// binding it replaces, so "-key:left" stays the negative half of "move_x".
+// An axis takes the slot's sign even when it has none, so a stick captured
+// on a whole-axis slot stays whole. Slot len(Bindings(name)) adds b as a new
+// binding.
+func Rebind(name string, i int, b Binding) {
+	bindings := actions.bindings[name]
+	if !HasAction(name) || i < 0 || i > len(bindings) {
+		return
+	}
+	if i == len(bindings) {
+		actions.bindings[name] = append(bindings, b)
+		return
+	}
+	if bindings[i].Sign != 0 || b.Source == "axis" {
+		b.Sign = bindings[i].Sign
+	}
+	bindings[i] = b
+}
+
+// ResetBindings restores every action's declared bindings.
+func ResetBindings() {
+	actions.bindings = make(map[string][]Binding, len(actions.defaults))
+	for name, bindings := range actions.defaults {
+		actions.bindings[name] = slices.Clone(bindings)
+	}
+	actions.held = [MaxPlayers]map[string]bool{}
+}
+
+// Rebound returns the bindings of the actions rebound from their defaults,
+// for saving.
+func Rebound() map[string][]string {
+	rebound := map[string][]string{}
+	for name, bindings := range actions.bindings {
+		if slices.Equal(bindings, actions.defaults[name]) {
+			continue
+		}
+		specs := make([]string, len(bindings))
+		for i, b := range bindings {
+			specs[i] = b.String()
+		}
+		rebound[name] = specs
+	}
+	return rebound
+}
+
+// stepActions remembers which actions are held before a new frame's input.
+func stepActions() {
+	for p := range actions.held {
+		held := map[string]bool{}
+		for name := range actions.bindings {
+			if Action(name, p) {
+				held[name] = true
+			}
+		}
+		actions.held[p] = held
+	}
+}
+
+// ActionValue returns an action's value for player p: the binding read
+// furthest from 0, where held buttons and keys read 1 (-1 with a "-" prefix)
+// and axes their position.
+func ActionValue(name string, p int) float64 {
+	if p < 0 || p >= MaxPlayers {
+		return 0
+	}
+	best := 0.0
+	for _, b := range actions.bindings[name] {
+		if v := b.value(p); math.Abs(v) > math.Abs(best) {
+			best = v
+		}
+	}
+	return best
+}
+
+// Action reports whether any of an action's bindings is held by player p.
+func Action(name string, p int) bool {
+	if p < 0 || p >= MaxPlayers {
+		return false
+	}
+	for _, b := range actions.bindings[name] {
+		if b.value(p) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionPressed reports whether player p started holding an action this
+// frame.
+func ActionPressed(name string, p int) bool {
+	return Action(name, p) && !actions.held[p][name]
+}
+
+// Capture returns the first input pressed this frame as a binding, for a
+// remap screen: a key, a mouse button, any player's controller button, or a
+// stick or trigger pushed past halfway (a stick gives the half it was pushed
+// to).
+func Capture() (Binding, bool) {
+	pressed := make([]string, 0, len(keys.pressed))
+	for name := range keys.pressed {
+		pressed = append(pressed, name)
+	}
+	if len(pressed) > 0 {
+		slices.Sort(pressed)
+		return Binding{Source: "key", Name: pressed[0]}, true
+	}
+	for _, name := range []string{"left", "right", "middle"} {
+		if m := mouseNames[name]; mouse.buttons&^mouse.prev&m != 0 {
+			return Binding{Source: "mouse", Name: name, Index: m}, true
+		}
+	}
+	for p, c := range pads {
+		if c == nil {
+			continue
+		}
+		if down := c.buttons &^ c.prev; down != 0 {
+			for i, name := range padButtonNames {
+				if down&(1<<i) != 0 {
+					return Binding{Source: "pad", Name: name, Index: i}, true
+				}
+			}
+		}
+		s := &players[p]
+		for i, name := range axisNames {
+			now, before := s.axes[i], s.prevAxes[i]
+			if math.Abs(now) > 0.5 && math.Abs(before) <= 0.5 {
+				b := Binding{Source: "axis", Name: name, Index: i}
+				if i < AxisTriggerLeft {
+					b.Sign = int(math.Copysign(1, now))
+				}
+				return b, true
+			}
+		}
+	}
+	return Binding{}, false
+}
@@ -0,0 +1,184 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBinding(t *testing.T) {
+	for spec, want := range map[string]string{
+		"btn:O":      "btn:o",
+		"btn:5":      "btn:x",
+		"key:Space":  "key:space",
+		"key:enter":  "key:return",
+		"pad:a":      "pad:a",
+		"pad:LB":     "pad:leftshoulder",
+		"-axis:ly":   "-axis:lefty",
+		"+key:right": "+key:right",
+		"mouse:left": "mouse:left",
+	} {
+		b, err := ParseBinding(spec)
+		if err != nil || b.String() != want {
+			t.Errorf("ParseBinding(%q) = %v, %v, want %s", spec, b, err, want)
+		}
+	}
+	for _, spec := range []string{"space", "btn:jump", "pad:z", "axis:up", "mouse:back", "joy:1", "key:"} {
+		if _, err := ParseBinding(spec); err == nil {
+			t.Errorf("ParseBinding(%q) should fail", spec)
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+	Reset()
+	defer Reset()
+	defer SetActions(nil)
+
+	if err := SetActions(map[string][]string{
+		"jump":   {"btn:O", "key:space", "pad:a"},
+		"move_x": {"-key:left", "+key:right", "axis:leftx"},
+		"look":   {"-axis:lefty"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := Actions(); !reflect.DeepEqual(got, []string{"jump", "look", "move_x"}) {
+		t.Fatalf("Actions = %v", got)
+	}
+
+	Connect(7, "Pad")
+	Set(BtnO, true)
+	if !Action("jump", 0) || !ActionPressed("jump", 0) || ActionValue("jump", 0) != 1 {
+		t.Error("btn:O should hold and press jump")
+	}
+	Step()
+	if !Action("jump", 0) || ActionPressed("jump", 0) {
+		t.Error("jump held on the second frame should not be pressed again")
+	}
+	Set(BtnO, false)
+	PadRawButton(7, 0, true) // A
+	if !Action("jump", 0) || ActionPressed("jump", 0) {
+		t.Error("switching bindings while held is not a new press")
+	}
+
+	// Keys count for player 0 only; the value is the binding furthest from 0
+	SetKey("left", true)
+	PadAxis(7, AxisLeftX, 16384)
+	if v := ActionValue("move_x", 0); v != -1 {
+		t.Errorf("move_x = %v, want -1", v)
+	}
+	SetKey("left", false)
+	if v := ActionValue("move_x", 0); v <= 0 || v >= 1 {
+		t.Errorf("move_x from the stick = %v, want between 0 and 1", v)
+	}
+
+	// A signed axis is half the axis, read as 0..1
+	PadAxis(7, AxisLeftY, 32767)
+	if Action("look", 0) {
+		t.Error("-axis:lefty held with the stick down")
+	}
+	PadAxis(7, AxisLeftY, -32767)
+	if v := ActionValue("look", 0); v != 1 {
+		t.Errorf("look = %v, want 1", v)
+	}
+	if Action("nope", 0) || ActionValue("jump", MaxPlayers) != 0 {
+		t.Error("unknown actions and players should read as up")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	Reset()
+	defer Reset()
+	defer SetActions(nil)
+
+	SetActions(map[string][]string{
+		"jump":   {"btn:O", "key:space", "pad:a"},
+		"move_x": {"-key:left", "+key:right"},
+	})
+	w, _ := ParseBinding("key:w")
+	Rebind("jump", 0, w)
+	if got := Rebound()["jump"]; !reflect.DeepEqual(got, []string{"key:w", "key:space", "pad:a"}) {
+		t.Errorf("rebound jump = %v, want [key:w key:space pad:a]", got)
+	}
+	Set(BtnO, true)
+	if Action("jump", 0) {
+		t.Error("btn:O should no longer jump")
+	}
+
+	// Each slot keeps its sign, so both directions stay bound
+	a, _ := ParseBinding("key:a")
+	d, _ := ParseBinding("key:d")
+	stick, _ := ParseBinding("axis:leftx")
+	Rebind("move_x", 0, a)
+	Rebind("move_x", 1, d)
+	Rebind("move_x", 2, stick)
+	Rebind("move_x", 9, w)
+	if got := Rebound()["move_x"]; !reflect.DeepEqual(got, []string{"-key:a", "+key:d", "axis:leftx"}) {
+		t.Errorf("rebound move_x = %v, want [-key:a +key:d axis:leftx]", got)
+	}
+	SetKey("a", true)
+	if v := ActionValue("move_x", 0); v != -1 {
+		t.Errorf("move_x = %v with A held, want -1", v)
+	}
+
+	// A stick captured pushed left on the whole-axis slot reads both ways
+	left := Binding{Source: "axis", Name: "rightx", Index: AxisRightX, Sign: -1}
+	Rebind("move_x", 2, left)
+	if got := Bindings("move_x")[2].String(); got != "axis:rightx" {
+		t.Errorf("rebound axis slot = %s, want axis:rightx", got)
+	}
+
+	ResetBindings()
+	if len(Rebound()) != 0 || !Action("jump", 0) {
+		t.Error("ResetBindings should restore btn:O")
+	}
+}
+
+func TestActionKeysWhileTexting(t *testing.T) {
+	Reset()
+	defer Reset()
+	defer SetActions(nil)
+	defer StopText()
+
+	SetActions(map[string][]string{"jump": {"key:space"}, "confirm": {"key:return"}})
+	SetKey("space", true)
+	SetKey("return", true)
+	StartText("", 0)
+	if Action("jump", 0) {
+		t.Error("a key that types shouldn't hold an action while texting")
+	}
+	if !Action("confirm", 0) {
+		t.Error("Return should still hold its action while texting")
+	}
+	StopText()
+	if !Action("jump", 0) {
+		t.Error("space should hold jump again after texting")
+	}
+}
+
+func TestCapture(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if _, ok := Capture(); ok {
+		t.Fatal("nothing pressed")
+	}
+	SetKey("Return", true)
+	if b, ok := Capture(); !ok || b.String() != "key:return" {
+		t.Errorf("Capture = %v, %v, want key:return", b, ok)
+	}
+
+	Step()
+	Connect(3, "Pad")
+	PadAxis(3, AxisLeftY, -30000)
+	if b, ok := Capture(); !ok || b.String() != "-axis:lefty" {
+		t.Errorf("Capture = %v, %v, want -axis:lefty", b, ok)
+	}
+	Step()
+	if _, ok := Capture(); ok {
+		t.Error("a stick still held is not captured again")
+	}
+	PadRawButton(3, 9, true)
+	if b, ok := Capture(); !ok || b.String() != "pad:leftshoulder" {
+		t.Errorf("Capture = %v, %v, want pad:leftshoulder", b, ok)
+	}
+}
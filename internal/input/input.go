@@ -1,6 +1,8 @@
 package input
 
-// Simple frame-based input state with btn/btnp for 6 Pico-like buttons.
+// Simple frame-based input state with btn/btnp for 6 Pico-like buttons,
+// plus Start, Select and the shoulder buttons.
+// The package-level functions act on player 0; Player returns the others.
 
 const (
     BtnLeft = 0
@@ -9,15 +11,22 @@ const (
     BtnDown = 3
     BtnO = 4
     BtnX = 5
-    num = 6
+    BtnStart = 6
+    BtnSelect = 7
+    BtnL = 8
+    BtnR = 9
+    NumButtons = 10
+    num = NumButtons
 )
 
-var cur [num]bool
-var prev [num]bool
-
-func Step() { prev = cur }
-func Set(i int, down bool) { if i>=0 && i<num { cur[i] = down } }
-func Btn(i int) bool { if i<0 || i>=num { return false }; return cur[i] }
-func Btnp(i int) bool { if i<0 || i>=num { return false }; return cur[i] && !prev[i] }
+var players [MaxPlayers]State
 
+// Player returns local player p's input (0..MaxPlayers-1), or nil.
+func Player(p int) *State { if p<0 || p>=MaxPlayers { return nil }; return &players[p] }
 
+func Step() { stepActions(); for p := range players { players[p].Step() }; stepPads(); stepMouse(); stepKeys() }
+func Set(i int, down bool) { players[0].Set(i, down) }
+func Btn(i int) bool { return players[0].Btn(i) }
+func Btnp(i int) bool { return players[0].Btnp(i) }
+func Btnr(i int) bool { return players[0].Btnr(i) }
+func Btnd(i int) int { return players[0].Btnd(i) }
@@ -201,3 +201,68 @@ func TestBtnpEdgeCases(t *testing.T) {
 		t.Fatalf("btnp should be true on press after release")
 	}
 }
+
+func TestBtnrBtnd(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Set(BtnStart, true)
+	if Btnd(BtnStart) != 1 || Btnr(BtnStart) {
+		t.Fatalf("first frame: btnd = %d, btnr = %v", Btnd(BtnStart), Btnr(BtnStart))
+	}
+	for i := 2; i <= 4; i++ {
+		Step()
+		if Btnd(BtnStart) != i {
+			t.Fatalf("frame %d: btnd = %d", i, Btnd(BtnStart))
+		}
+	}
+
+	Step()
+	Set(BtnStart, false)
+	if !Btnr(BtnStart) || Btnd(BtnStart) != 0 {
+		t.Errorf("release frame: btnr = %v, btnd = %d", Btnr(BtnStart), Btnd(BtnStart))
+	}
+	Step()
+	if Btnr(BtnStart) {
+		t.Error("btnr should only fire on the release frame")
+	}
+	if Btnr(-1) || Btnd(NumButtons) != 0 {
+		t.Error("out-of-range buttons should read as up")
+	}
+}
+
+func TestBtnpRepeat(t *testing.T) {
+	Reset()
+	defer Reset()
+	defer SetRepeat(0, 0)
+
+	// No repeat by default
+	Set(BtnDown, true)
+	for i := 0; i < 30; i++ {
+		Step()
+		if Btnp(BtnDown) {
+			t.Fatalf("btnp repeated on frame %d without repeat", i+2)
+		}
+	}
+
+	// PICO-8 timing: pressed, then 15 frames later and every 4 after that
+	SetRepeat(15, 4)
+	Reset()
+	Set(BtnDown, true)
+	var fired []int
+	for frame := 0; frame < 30; frame++ {
+		if Btnp(BtnDown) {
+			fired = append(fired, frame)
+		}
+		Step()
+	}
+	want := []int{0, 15, 19, 23, 27}
+	if len(fired) != len(want) {
+		t.Fatalf("btnp fired on frames %v, want %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Fatalf("btnp fired on frames %v, want %v", fired, want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package input
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Raw keys by name: the platform's key names in lower case ("a", "space",
+// "return", "left shift", "f1"...).
+var keys = struct {
+	down, pressed map[string]bool
+}{map[string]bool{}, map[string]bool{}}
+
+// keyAliases are other names carts may use for keys
+var keyAliases = map[string]string{
+	"enter":  "return",
+	"esc":    "escape",
+	"del":    "delete",
+	"lshift": "left shift",
+	"rshift": "right shift",
+	"lctrl":  "left ctrl",
+	"rctrl":  "right ctrl",
+	"lalt":   "left alt",
+	"ralt":   "right alt",
+}
+
+// keyEither are names matching both the left and right key
+var keyEither = map[string][2]string{
+	"shift": {"left shift", "right shift"},
+	"ctrl":  {"left ctrl", "right ctrl"},
+	"alt":   {"left alt", "right alt"},
+}
+
+func keyName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := keyAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// stepKeys starts a new frame for the keyboard.
+func stepKeys() {
+	clear(keys.pressed)
+}
+
+// SetKey presses or releases a key by name. Pressing a key already down (key
+// repeat) is not a new press.
+func SetKey(name string, down bool) {
+	name = keyName(name)
+	if down && !keys.down[name] {
+		keys.pressed[name] = true
+	}
+	if down {
+		keys.down[name] = true
+	} else {
+		delete(keys.down, name)
+	}
+}
+
+// Key reports whether a key is held.
+func Key(name string) bool {
+	name = keyName(name)
+	if both, ok := keyEither[name]; ok {
+		return keys.down[both[0]] || keys.down[both[1]]
+	}
+	return keys.down[name]
+}
+
+// Keyp reports whether a key was pressed this frame.
+func Keyp(name string) bool {
+	name = keyName(name)
+	if both, ok := keyEither[name]; ok {
+		return keys.pressed[both[0]] || keys.pressed[both[1]]
+	}
+	return keys.pressed[name]
+}
+
+// Text input: while it is on, typed text collects in a buffer and the keys
+// that type it don't press keys or buttons (see TypesText).
+var text struct {
+	on          bool
+	buf         string
+	max         int    // Most characters kept (0 = no limit)
+	composition string // Text being composed with an input method, not yet typed
+}
+
+// StartText turns text input on with initial text in the buffer, keeping at
+// most max characters (0 = no limit).
+func StartText(initial string, max int) {
+	text.on, text.max, text.composition = true, max, ""
+	text.buf = ""
+	AddText(initial)
+}
+
+// StopText turns text input off and returns the text typed.
+func StopText() string {
+	text.on, text.composition = false, ""
+	return text.buf
+}
+
+// Texting reports whether text input is on.
+func Texting() bool { return text.on }
+
+// Text returns the text typed and the text being composed.
+func Text() (typed, composition string) { return text.buf, text.composition }
+
+// AddText adds typed text, up to the length limit, ending any composition.
+func AddText(s string) {
+	text.composition = ""
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			continue // Control characters
+		}
+		if text.max > 0 && utf8.RuneCountInString(text.buf) >= text.max {
+			return
+		}
+		text.buf += string(r)
+	}
+}
+
+// Compose sets the text an input method is composing.
+func Compose(s string) { text.composition = s }
+
+// Backspace deletes the last character typed.
+func Backspace() {
+	if _, size := utf8.DecodeLastRuneInString(text.buf); size > 0 {
+		text.buf = text.buf[:len(text.buf)-size]
+	}
+}
+
+// TypesText reports whether a key with this character code types text (or
+// edits it), so it shouldn't press a button while text input is on.
+func TypesText(code int) bool {
+	return code == '\b' || (code >= ' ' && code < 0x7f)
+}
+
+// keyTypes reports whether the key with this name types text (see TypesText).
+func keyTypes(name string) bool {
+	if name == "space" || name == "backspace" {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return size == len(name) && TypesText(int(r))
+}
@@ -0,0 +1,55 @@
+package input
+
+import "testing"
+
+func TestKeys(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	SetKey("Left Shift", true)
+	SetKey("Return", true)
+	if !Key("shift") || !Key("lshift") || !Keyp("enter") || Key("right shift") {
+		t.Fatal("keys not pressed by name or alias")
+	}
+
+	// OS key repeat is not a new press
+	Step()
+	SetKey("Return", true)
+	if !Key("return") || Keyp("return") {
+		t.Errorf("repeat: Key %v, Keyp %v, want true, false", Key("return"), Keyp("return"))
+	}
+
+	// Pressed and released between frames still counts as pressed
+	Step()
+	SetKey("A", true)
+	SetKey("A", false)
+	if Key("a") || !Keyp("a") {
+		t.Errorf("tap: Key %v, Keyp %v, want false, true", Key("a"), Keyp("a"))
+	}
+}
+
+func TestText(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	StartText("Bo", 4)
+	if !Texting() {
+		t.Fatal("text input not on")
+	}
+	AddText("b\t")
+	Compose("é")
+	if typed, composition := Text(); typed != "Bob" || composition != "é" {
+		t.Errorf("Text = %q, %q, want \"Bob\", \"é\"", typed, composition)
+	}
+	AddText("éy") // Committed composition; the limit drops the y
+	Backspace()
+	Backspace()
+	AddText("!")
+	if got := StopText(); got != "Bo!" || Texting() {
+		t.Errorf("StopText = %q, texting %v", got, Texting())
+	}
+
+	if !TypesText('z') || !TypesText('\b') || TypesText('\r') || TypesText(27) {
+		t.Error("TypesText wrong for z, backspace, return or escape")
+	}
+}
@@ -0,0 +1,107 @@
+package input
+
+// Game controllers are assigned to players as they connect: each takes the
+// lowest player without one, so the first pad shares player 0 with the
+// keyboard. Controller ids are the platform's (SDL joystick instance ids).
+
+type pad struct {
+	id            int
+	name          string
+	buttons, prev uint32 // Controller buttons held, as bits in SDL order (see padButtonNames)
+}
+
+var pads [MaxPlayers]*pad
+
+// Connect assigns a newly connected controller to a player and returns the
+// player, or -1 when every player has one. A controller already connected
+// keeps its player.
+func Connect(id int, name string) int {
+	if p := PadPlayer(id); p >= 0 {
+		return p
+	}
+	for p := range pads {
+		if pads[p] == nil {
+			pads[p] = &pad{id: id, name: name}
+			return p
+		}
+	}
+	return -1
+}
+
+// Disconnect frees a controller's player, releasing what it held, and
+// returns the player (-1 if the controller wasn't assigned).
+func Disconnect(id int) int {
+	p := PadPlayer(id)
+	if p >= 0 {
+		pads[p] = nil
+		players[p].Release()
+	}
+	return p
+}
+
+// PadPlayer returns the player a controller is assigned to, or -1.
+func PadPlayer(id int) int {
+	for p, c := range pads {
+		if c != nil && c.id == id {
+			return p
+		}
+	}
+	return -1
+}
+
+// Pad returns the id and name of player p's controller.
+func Pad(p int) (id int, name string, ok bool) {
+	if p < 0 || p >= MaxPlayers || pads[p] == nil {
+		return 0, "", false
+	}
+	return pads[p].id, pads[p].name, true
+}
+
+// PadButton presses or releases a button on a controller.
+func PadButton(id, button int, down bool) {
+	if p := PadPlayer(id); p >= 0 {
+		players[p].SetPad(button, down)
+	}
+}
+
+// PadRawButton presses or releases a controller button by its index in SDL
+// GameController order, for pad: action bindings.
+func PadRawButton(id, button int, down bool) {
+	p := PadPlayer(id)
+	if p < 0 || button < 0 || button >= len(padButtonNames) {
+		return
+	}
+	if down {
+		pads[p].buttons |= 1 << button
+	} else {
+		pads[p].buttons &^= 1 << button
+	}
+}
+
+// stepPads starts a new frame for the controllers' buttons.
+func stepPads() {
+	for _, c := range pads {
+		if c != nil {
+			c.prev = c.buttons
+		}
+	}
+}
+
+// PadAxis moves a controller axis; value is the raw reading (-32768..32767).
+func PadAxis(id, axis int, value int16) {
+	if p := PadPlayer(id); p >= 0 {
+		players[p].SetAxis(axis, float64(value)/32767)
+	}
+}
+
+// Reset disconnects every controller and clears all players' input, the
+// mouse, the keys and text input.
+func Reset() {
+	pads = [MaxPlayers]*pad{}
+	players = [MaxPlayers]State{}
+	mouse.x, mouse.y, mouse.buttons, mouse.prev, mouse.wheel = 0, 0, 0, 0, 0
+	clear(keys.down)
+	clear(keys.pressed)
+	StopText()
+	text.buf = ""
+}
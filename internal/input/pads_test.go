@@ -0,0 +1,118 @@
+package input
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPlayers(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Player(2).Set(BtnO, true)
+	if !Player(2).Btn(BtnO) || !Player(2).Btnp(BtnO) || Btn(BtnO) || Player(1).Btn(BtnO) {
+		t.Fatal("player 2's button should be held by player 2 only")
+	}
+	Step()
+	if Player(2).Btnp(BtnO) {
+		t.Error("btnp should only fire on the first frame")
+	}
+	if Player(-1) != nil || Player(MaxPlayers) != nil {
+		t.Error("out-of-range players should be nil")
+	}
+}
+
+func TestPadHotPlug(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	// Pads take the lowest free player; reconnecting keeps the player
+	for i, id := range []int{10, 11, 12} {
+		if p := Connect(id, "Pad"); p != i {
+			t.Fatalf("pad %d got player %d, want %d", id, p, i)
+		}
+	}
+	if p := Connect(11, "Pad"); p != 1 {
+		t.Errorf("reconnect got player %d", p)
+	}
+
+	// The keyboard and the first pad share player 0 without releasing each other
+	Set(BtnX, true)
+	PadButton(10, BtnX, true)
+	PadButton(10, BtnX, false)
+	if !Btn(BtnX) {
+		t.Error("releasing the pad button released the key")
+	}
+
+	PadButton(11, BtnO, true)
+	if !Player(1).Btn(BtnO) {
+		t.Fatal("pad 11 should press player 1's O")
+	}
+	if p := Disconnect(11); p != 1 || Player(1).Btn(BtnO) {
+		t.Errorf("disconnect = %d, O still held = %v", p, Player(1).Btn(BtnO))
+	}
+	if p := Connect(13, "New"); p != 1 {
+		t.Errorf("new pad got player %d, want the freed player 1", p)
+	}
+	if _, name, ok := Pad(1); !ok || name != "New" {
+		t.Errorf("Pad(1) = %q, %v", name, ok)
+	}
+
+	// Only MaxPlayers pads are assigned
+	for id := 20; id < 20+MaxPlayers; id++ {
+		Connect(id, "Pad")
+	}
+	if PadPlayer(20+MaxPlayers-1) != -1 {
+		t.Error("a seventh pad should not get a player")
+	}
+}
+
+func TestAxes(t *testing.T) {
+	Reset()
+	defer Reset()
+	Connect(1, "Pad")
+
+	// Inside the dead zone the stick reads 0 and presses nothing
+	PadAxis(1, AxisLeftX, -6000)
+	if x := Player(0).Axis(AxisLeftX); x != 0 || Btn(BtnLeft) {
+		t.Errorf("axis = %v, left = %v inside the dead zone", x, Btn(BtnLeft))
+	}
+
+	// Past it the value is rescaled and the stick presses the d-pad
+	PadAxis(1, AxisLeftX, -32768)
+	PadAxis(1, AxisLeftY, 32767)
+	if x := Player(0).Axis(AxisLeftX); x != -1 {
+		t.Errorf("full left = %v", x)
+	}
+	if !Btn(BtnLeft) || !Btn(BtnDown) || Btn(BtnRight) || Btn(BtnUp) {
+		t.Error("stick should press left and down")
+	}
+	PadAxis(1, AxisLeftX, 0)
+	if Btn(BtnLeft) {
+		t.Error("centering the stick should release left")
+	}
+
+	half := int16(32767 * (DeadZone + (1-DeadZone)/2))
+	PadAxis(1, AxisTriggerRight, half)
+	if v := Player(0).Axis(AxisTriggerRight); math.Abs(v-0.5) > 0.001 {
+		t.Errorf("half trigger = %v", v)
+	}
+}
+
+func TestRumble(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	s := Player(3)
+	if _, _, _, ok := s.TakeRumble(); ok {
+		t.Fatal("no rumble requested yet")
+	}
+	s.Rumble(2, 0.5, 0.3)
+	low, high, seconds, ok := s.TakeRumble()
+	if !ok || low != 1 || high != 0.5 || seconds != 0.3 {
+		t.Errorf("TakeRumble = %v %v %v %v", low, high, seconds, ok)
+	}
+	if _, _, _, ok := s.TakeRumble(); ok {
+		t.Error("rumble should be taken once")
+	}
+}
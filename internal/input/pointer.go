@@ -0,0 +1,58 @@
+package input
+
+import "math"
+
+// Mouse buttons, as bits of the button masks.
+const (
+	MouseLeft = 1 << iota
+	MouseRight
+	MouseMiddle
+)
+
+// The mouse (or a touch, which acts as the left button) in logical screen
+// pixels.
+var mouse struct {
+	x, y          int
+	buttons, prev int
+	wheel         int // Wheel steps this frame, positive away from the user
+}
+
+// stepMouse starts a new frame for the mouse.
+func stepMouse() {
+	mouse.prev = mouse.buttons
+	mouse.wheel = 0
+}
+
+// SetMouse moves the pointer to logical screen pixel (x, y).
+func SetMouse(x, y int) { mouse.x, mouse.y = x, y }
+
+// SetMouseButton presses or releases mouse buttons (MouseLeft etc.).
+func SetMouseButton(button int, down bool) {
+	if down {
+		mouse.buttons |= button
+	} else {
+		mouse.buttons &^= button
+	}
+}
+
+// AddWheel adds wheel steps for this frame.
+func AddWheel(steps int) { mouse.wheel += steps }
+
+// Mouse returns the pointer position, the buttons held, the wheel steps this
+// frame and the buttons pressed and released this frame.
+func Mouse() (x, y, buttons, wheel, pressed, released int) {
+	return mouse.x, mouse.y, mouse.buttons, mouse.wheel, mouse.buttons &^ mouse.prev, mouse.prev &^ mouse.buttons
+}
+
+// WindowToLogical maps a position in a winW×winH window to the w×h screen
+// drawn in it: scaled up as far as it fits, keeping its aspect, and centered
+// with bars on two sides. Positions on the bars are clamped to the screen.
+func WindowToLogical(x, y, winW, winH, w, h float64) (int, int) {
+	scale := math.Min(winW/w, winH/h)
+	if scale <= 0 {
+		return 0, 0
+	}
+	lx := math.Floor((x - (winW-w*scale)/2) / scale)
+	ly := math.Floor((y - (winH-h*scale)/2) / scale)
+	return int(math.Max(0, math.Min(w-1, lx))), int(math.Max(0, math.Min(h-1, ly)))
+}
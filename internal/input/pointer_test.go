@@ -0,0 +1,45 @@
+package input
+
+import "testing"
+
+func TestWindowToLogical(t *testing.T) {
+	for _, tc := range []struct {
+		x, y, winW, winH float64
+		wantX, wantY     int
+	}{
+		{0, 0, 960, 540, 0, 0},         // 2× scale, no bars
+		{959, 539, 960, 540, 479, 269}, // Bottom-right pixel
+		{481, 271, 960, 540, 240, 135},
+		{10, 300, 1000, 540, 0, 150}, // Bars left and right: 20 px each at 2×
+		{520, 300, 1000, 540, 250, 150},
+		{5, 5, 960, 600, 2, 0}, // Bars top and bottom are clamped
+		{700, 595, 960, 600, 350, 269},
+		{10, 10, 0, 0, 0, 0}, // Minimized window
+	} {
+		x, y := WindowToLogical(tc.x, tc.y, tc.winW, tc.winH, 480, 270)
+		if x != tc.wantX || y != tc.wantY {
+			t.Errorf("(%v, %v) in %v×%v = (%d, %d), want (%d, %d)", tc.x, tc.y, tc.winW, tc.winH, x, y, tc.wantX, tc.wantY)
+		}
+	}
+}
+
+func TestMouse(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	SetMouse(40, 30)
+	SetMouseButton(MouseLeft|MouseRight, true)
+	AddWheel(1)
+	AddWheel(2)
+	x, y, buttons, wheel, pressed, released := Mouse()
+	if x != 40 || y != 30 || buttons != 3 || wheel != 3 || pressed != 3 || released != 0 {
+		t.Fatalf("Mouse = %d %d %d %d %d %d", x, y, buttons, wheel, pressed, released)
+	}
+
+	Step()
+	SetMouseButton(MouseRight, false)
+	_, _, buttons, wheel, pressed, released = Mouse()
+	if buttons != MouseLeft || wheel != 0 || pressed != 0 || released != MouseRight {
+		t.Errorf("next frame: buttons %d, wheel %d, pressed %d, released %d", buttons, wheel, pressed, released)
+	}
+}
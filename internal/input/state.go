@@ -0,0 +1,169 @@
+package input
+
+import "math"
+
+// MaxPlayers is the number of local players.
+const MaxPlayers = 6
+
+// Analog axes, in SDL GameController order. Sticks run -1..1 (left/up
+// negative), triggers 0..1.
+const (
+	AxisLeftX = iota
+	AxisLeftY
+	AxisRightX
+	AxisRightY
+	AxisTriggerLeft
+	AxisTriggerRight
+	NumAxes
+)
+
+// DeadZone is the fraction of an axis's range around rest that reads as 0.
+// Past it the left stick also presses the d-pad buttons.
+var DeadZone = 0.25
+
+// Input sources holding a button, so releasing a key doesn't release the same
+// button held on a pad.
+const (
+	srcKeys uint8 = 1 << iota
+	srcPad
+	srcStick
+)
+
+// Key repeat for Btnp, in frames: after a button has been held RepeatDelay
+// frames, Btnp fires again every RepeatInterval frames (PICO-8 uses 15 and
+// 4). A delay of 0 turns repeat off, the default.
+var RepeatDelay, RepeatInterval int
+
+// SetRepeat sets RepeatDelay and RepeatInterval.
+func SetRepeat(delay, interval int) {
+	RepeatDelay, RepeatInterval = max(delay, 0), max(interval, 0)
+}
+
+// State is one player's buttons and axes.
+type State struct {
+	down           [num]uint8 // Sources holding each button
+	prev           [num]bool
+	held           [num]int         // Frames each button was held before this one
+	axes, prevAxes [NumAxes]float64 // Raw values
+
+	rumbleLow, rumbleHigh, rumbleSeconds float64
+	rumble                               bool
+}
+
+// Step starts a new frame: Btnp compares against the buttons held now.
+func (s *State) Step() {
+	s.prevAxes = s.axes
+	for i, d := range s.down {
+		s.prev[i] = d != 0
+		if d != 0 {
+			s.held[i]++
+		} else {
+			s.held[i] = 0
+		}
+	}
+}
+
+// Set presses or releases button i from the keyboard (or injected input).
+func (s *State) Set(i int, down bool) { s.set(i, srcKeys, down) }
+
+// SetPad presses or releases button i from a game controller.
+func (s *State) SetPad(i int, down bool) { s.set(i, srcPad, down) }
+
+func (s *State) set(i int, src uint8, down bool) {
+	if i < 0 || i >= num {
+		return
+	}
+	if down {
+		s.down[i] |= src
+	} else {
+		s.down[i] &^= src
+	}
+}
+
+// Btn reports whether button i is held.
+func (s *State) Btn(i int) bool { return i >= 0 && i < num && s.down[i] != 0 }
+
+// Btnp reports whether button i was pressed this frame, or repeats this
+// frame while held (see RepeatDelay).
+func (s *State) Btnp(i int) bool {
+	if !s.Btn(i) {
+		return false
+	}
+	if !s.prev[i] {
+		return true
+	}
+	after := s.held[i] - RepeatDelay // Frames since the delay ran out
+	return RepeatDelay > 0 && RepeatInterval > 0 && after >= 0 && after%RepeatInterval == 0
+}
+
+// Btnr reports whether button i was released this frame.
+func (s *State) Btnr(i int) bool { return i >= 0 && i < num && s.prev[i] && s.down[i] == 0 }
+
+// Btnd returns the number of frames button i has been held, counting this
+// one, or 0 when it is up.
+func (s *State) Btnd(i int) int {
+	if !s.Btn(i) {
+		return 0
+	}
+	if !s.prev[i] {
+		return 1 // Pressed again within the frame it was released
+	}
+	return s.held[i] + 1
+}
+
+// SetAxis sets an axis's raw value, clamped to its range. The left stick
+// presses the d-pad buttons past the dead zone.
+func (s *State) SetAxis(axis int, v float64) {
+	if axis < 0 || axis >= NumAxes {
+		return
+	}
+	lo := -1.0
+	if axis >= AxisTriggerLeft {
+		lo = 0
+	}
+	s.axes[axis] = math.Max(lo, math.Min(1, v))
+
+	x, y := s.Axis(AxisLeftX), s.Axis(AxisLeftY)
+	s.set(BtnLeft, srcStick, x < 0)
+	s.set(BtnRight, srcStick, x > 0)
+	s.set(BtnUp, srcStick, y < 0)
+	s.set(BtnDown, srcStick, y > 0)
+}
+
+// Axis returns an axis's value with the dead zone removed: 0 inside it, then
+// rising to ±1 at the end of the range.
+func (s *State) Axis(axis int) float64 {
+	if axis < 0 || axis >= NumAxes {
+		return 0
+	}
+	v := s.axes[axis]
+	if math.Abs(v) <= DeadZone {
+		return 0
+	}
+	return math.Copysign((math.Abs(v)-DeadZone)/(1-DeadZone), v)
+}
+
+// Release lets go of everything held on a controller, for when it
+// disconnects. Keyboard buttons stay held.
+func (s *State) Release() {
+	for i := range s.down {
+		s.down[i] &^= srcPad | srcStick
+	}
+	s.axes = [NumAxes]float64{}
+	s.rumble = false
+}
+
+// Rumble asks the player's controller to vibrate: low and high are the
+// strengths (0..1) of its low- and high-frequency motors.
+func (s *State) Rumble(low, high, seconds float64) {
+	s.rumbleLow = math.Max(0, math.Min(1, low))
+	s.rumbleHigh = math.Max(0, math.Min(1, high))
+	s.rumbleSeconds = math.Max(0, seconds)
+	s.rumble = true
+}
+
+// TakeRumble returns and clears the last rumble request.
+func (s *State) TakeRumble() (low, high, seconds float64, ok bool) {
+	ok, s.rumble = s.rumble, false
+	return s.rumbleLow, s.rumbleHigh, s.rumbleSeconds, ok
+}
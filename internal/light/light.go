@@ -0,0 +1,280 @@
+// Package light computes 2D lighting for the 16 hue × 3 shade palette: each
+// screen pixel gets a light level from an ambient level plus point and cone
+// lights, with hard shadows cast by occluding tiles. Levels become shade
+// steps that move a pixel's index along its hue (black <- shadow <- base <-
+// highlight -> white).
+package light
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// Shade steps: Darkest turns every hue black, 0 leaves colors as drawn and
+// Brightest lightens them one shade
+const (
+	Darkest   = -3
+	Brightest = 1
+)
+
+// Light is a point light, or a cone light when Spread > 0. Positions are in
+// world pixels and angles in radians (0 = +X, clockwise).
+type Light struct {
+	X, Y      float64
+	Radius    float64 // Distance at which the light reaches 0
+	Falloff   float64 // Exponent of the fall toward the edge (1 = linear)
+	Intensity float64 // Level added at the center (1 = a full step from dark to unlit)
+	Angle     float64 // Cone direction
+	Spread    float64 // Cone half-angle (0 = point light)
+}
+
+// level returns the light's level at offset (dx, dy) from it
+func (l *Light) level(dx, dy float64) float64 {
+	d2 := dx*dx + dy*dy
+	if d2 >= l.Radius*l.Radius {
+		return 0
+	}
+	d := math.Sqrt(d2)
+	if l.Spread > 0 && d > 0 {
+		diff := math.Remainder(math.Atan2(dy, dx)-l.Angle, 2*math.Pi)
+		if math.Abs(diff) > l.Spread {
+			return 0
+		}
+	}
+	v := 1 - d/l.Radius
+	if l.Falloff > 0 && l.Falloff != 1 {
+		v = math.Pow(v, l.Falloff)
+	}
+	return l.Intensity * v
+}
+
+// System holds the lights by id and the ambient level
+type System struct {
+	Enabled bool
+	Ambient float64 // Level without lights (0 = dark, 1 = colors as drawn)
+	lights  map[int]*Light
+	nextID  int
+	sorted  []*Light // Lights in id order, rebuilt when they change
+	dirty   bool
+}
+
+// NewSystem creates a disabled system with full ambient light
+func NewSystem() *System {
+	return &System{Ambient: 1, lights: make(map[int]*Light), nextID: 1}
+}
+
+// Add adds a light and returns its id
+func (s *System) Add(l Light) int {
+	id := s.nextID
+	s.nextID++
+	s.lights[id] = &l
+	s.dirty = true
+	return id
+}
+
+// Get returns the light with id, which may be changed in place
+func (s *System) Get(id int) (*Light, bool) {
+	l, ok := s.lights[id]
+	return l, ok
+}
+
+// Remove removes the light with id
+func (s *System) Remove(id int) bool {
+	if _, ok := s.lights[id]; !ok {
+		return false
+	}
+	delete(s.lights, id)
+	s.dirty = true
+	return true
+}
+
+// Clear removes every light
+func (s *System) Clear() {
+	s.lights = make(map[int]*Light)
+	s.dirty = true
+}
+
+// Len returns the number of lights
+func (s *System) Len() int { return len(s.lights) }
+
+// Lights returns the lights in the order they were added
+func (s *System) Lights() []*Light {
+	if s.dirty {
+		ids := make([]int, 0, len(s.lights))
+		for id := range s.lights {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		s.sorted = s.sorted[:0]
+		for _, id := range ids {
+			s.sorted = append(s.sorted, s.lights[id])
+		}
+		s.dirty = false
+	}
+	return s.sorted
+}
+
+// Occluders is a grid of tiles that block light
+type Occluders struct {
+	w, h         int
+	tileW, tileH float64
+	solid        []bool
+}
+
+// NewOccluders marks the tiles of tm whose flags include every bit of mask
+func NewOccluders(tm *graphics.TileMap, ts *graphics.Tileset, mask uint8) *Occluders {
+	tw, th := tm.TileSize()
+	o := &Occluders{w: tm.Width(), h: tm.Height(), tileW: float64(tw), tileH: float64(th)}
+	o.solid = make([]bool, o.w*o.h)
+	for y := 0; y < o.h; y++ {
+		for x := 0; x < o.w; x++ {
+			if tile := tm.Get(x, y); tile != 0 && ts.Matches(tile, mask) {
+				o.solid[y*o.w+x] = true
+			}
+		}
+	}
+	return o
+}
+
+func (o *Occluders) blocked(tx, ty int) bool {
+	return tx >= 0 && ty >= 0 && tx < o.w && ty < o.h && o.solid[ty*o.w+tx]
+}
+
+// visible reports whether no occluding tile lies between (x0, y0) and the
+// tile holding (x1, y1). The tiles at both ends don't block, so the faces of
+// walls toward a light are lit.
+func (o *Occluders) visible(x0, y0, x1, y1 float64) bool {
+	x0, y0, x1, y1 = x0/o.tileW, y0/o.tileH, x1/o.tileW, y1/o.tileH
+	tx, ty := int(math.Floor(x0)), int(math.Floor(y0))
+	ex, ey := int(math.Floor(x1)), int(math.Floor(y1))
+	n := abs(ex-tx) + abs(ey-ty)
+	if n <= 1 {
+		return true
+	}
+
+	// Walk the tiles the segment crosses
+	dx, dy := x1-x0, y1-y0
+	stepX, stepY := 1, 1
+	deltaX, deltaY := math.Inf(1), math.Inf(1)
+	if dx != 0 {
+		deltaX = math.Abs(1 / dx)
+	}
+	if dy != 0 {
+		deltaY = math.Abs(1 / dy)
+	}
+	nextX, nextY := (float64(tx)+1-x0)*deltaX, (float64(ty)+1-y0)*deltaY
+	if dx < 0 {
+		stepX, nextX = -1, (x0-float64(tx))*deltaX
+	}
+	if dy < 0 {
+		stepY, nextY = -1, (y0-float64(ty))*deltaY
+	}
+	for i := 1; i < n; i++ {
+		if nextX < nextY {
+			tx += stepX
+			nextX += deltaX
+		} else {
+			ty += stepY
+			nextY += deltaY
+		}
+		if tx == ex && ty == ey {
+			return true
+		}
+		if o.blocked(tx, ty) {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Map holds the light level of every screen pixel
+type Map struct {
+	w, h  int
+	level []float32
+}
+
+// NewMap creates a light map for a w×h screen
+func NewMap(w, h int) *Map {
+	return &Map{w: w, h: h, level: make([]float32, w*h)}
+}
+
+// Render lights the screen from s's ambient level and lights. Screen pixel
+// (x, y) shows world pixel (originX + x*scale, originY + y*scale); occ (may be
+// nil) casts shadows.
+func (m *Map) Render(s *System, originX, originY, scale float64, occ *Occluders) {
+	ambient := float32(s.Ambient)
+	for i := range m.level {
+		m.level[i] = ambient
+	}
+	for _, l := range s.Lights() {
+		if l.Radius <= 0 || l.Intensity == 0 {
+			continue
+		}
+		// Only the pixels within the light's radius
+		x0 := max(0, int(math.Floor((l.X-l.Radius-originX)/scale)))
+		y0 := max(0, int(math.Floor((l.Y-l.Radius-originY)/scale)))
+		x1 := min(m.w, int(math.Ceil((l.X+l.Radius-originX)/scale))+1)
+		y1 := min(m.h, int(math.Ceil((l.Y+l.Radius-originY)/scale))+1)
+		for y := y0; y < y1; y++ {
+			wy := originY + (float64(y)+0.5)*scale
+			row := m.level[y*m.w : (y+1)*m.w]
+			for x := x0; x < x1; x++ {
+				wx := originX + (float64(x)+0.5)*scale
+				v := l.level(wx-l.X, wy-l.Y)
+				if v == 0 || (occ != nil && !occ.visible(l.X, l.Y, wx, wy)) {
+					continue
+				}
+				row[x] += float32(v)
+			}
+		}
+	}
+}
+
+// Step returns the shade step of pixel (x, y): Darkest at level 0, 0 at
+// level 1 and Brightest from level 4/3
+func (m *Map) Step(x, y int) int {
+	return StepOf(float64(m.level[y*m.w+x]))
+}
+
+// StepOf converts a light level to a shade step
+func StepOf(level float64) int {
+	step := int(math.Floor(level*3+1e-6)) - 3
+	return min(Brightest, max(Darkest, step))
+}
+
+// Shade moves index step shades along its hue: darker toward black, lighter
+// toward white. ok is false for indices outside the hues (black, white and
+// RGBA colors), which ScaleRGBA lights instead.
+func Shade(index, step int) (shaded int, ok bool) {
+	if _, _, ok := palette.HueShade(index); !ok {
+		return index, false
+	}
+	for ; step < 0; step++ {
+		index = palette.Darker(index)
+	}
+	for ; step > 0; step-- {
+		index = palette.Lighter(index)
+	}
+	return index, true
+}
+
+// ScaleRGBA lights a color outside the hues by step, a third of full
+// brightness per step
+func ScaleRGBA(c color.RGBA, step int) color.RGBA {
+	f := float64(3+step) / 3
+	scale := func(v uint8) uint8 {
+		return uint8(math.Min(255, math.Round(float64(v)*f)))
+	}
+	return color.RGBA{scale(c.R), scale(c.G), scale(c.B), 0xFF}
+}
@@ -0,0 +1,119 @@
+package light
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+func TestStepOf(t *testing.T) {
+	for _, tc := range []struct {
+		level float64
+		want  int
+	}{
+		{0, Darkest}, {0.34, -2}, {0.67, -1}, {1, 0}, {1.2, 0}, {4.0 / 3, Brightest}, {5, Brightest}, {-1, Darkest},
+	} {
+		if got := StepOf(tc.level); got != tc.want {
+			t.Errorf("StepOf(%v) = %d, want %d", tc.level, got, tc.want)
+		}
+	}
+}
+
+func TestShade(t *testing.T) {
+	base := palette.IndexOf(3, palette.Base)
+	if c, ok := Shade(base, -1); !ok || c != palette.IndexOf(3, palette.Shadow) {
+		t.Errorf("one step darker: %d, %v", c, ok)
+	}
+	if c, _ := Shade(base, Darkest); c != palette.Black {
+		t.Errorf("darkest: %d", c)
+	}
+	if c, _ := Shade(base, Brightest); c != palette.IndexOf(3, palette.Highlight) {
+		t.Errorf("brightest: %d", c)
+	}
+	if _, ok := Shade(palette.White, -1); ok {
+		t.Error("white is not a hue")
+	}
+	if c := ScaleRGBA(color.RGBA{255, 150, 30, 255}, -1); c != (color.RGBA{170, 100, 20, 255}) {
+		t.Errorf("scaled %v", c)
+	}
+	if c := ScaleRGBA(color.RGBA{255, 150, 30, 255}, Brightest); c != (color.RGBA{255, 200, 40, 255}) {
+		t.Errorf("brightened %v", c)
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := NewSystem()
+	s.Ambient = 0
+	s.Add(Light{X: 20, Y: 20, Radius: 10, Intensity: 1.2})
+	cone := s.Add(Light{X: 60, Y: 20, Radius: 10, Intensity: 1, Angle: 0, Spread: math.Pi / 4})
+
+	m := NewMap(80, 40)
+	m.Render(s, 0, 0, 1, nil)
+	if got := m.Step(20, 20); got != 0 {
+		t.Errorf("center step %d, want 0", got)
+	}
+	if got := m.Step(20, 35); got != Darkest {
+		t.Errorf("outside the radius step %d, want %d", got, Darkest)
+	}
+	if m.Step(63, 20) == Darkest || m.Step(57, 20) != Darkest {
+		t.Errorf("cone: ahead %d, behind %d", m.Step(63, 20), m.Step(57, 20))
+	}
+
+	// Moving the view moves the lights on screen; zoom scales them
+	m.Render(s, 10, 10, 0.5, nil)
+	if got := m.Step(20, 20); got != 0 {
+		t.Errorf("zoomed center step %d, want 0", got)
+	}
+
+	s.Remove(cone)
+	if s.Len() != 1 || len(s.Lights()) != 1 {
+		t.Fatalf("expected one light, got %d", s.Len())
+	}
+}
+
+func TestShadows(t *testing.T) {
+	tm := graphics.NewTileMap(10, 5)
+	tm.SetTileSize(8, 8)
+	ts := graphics.NewTileset()
+	ts.SetFlag(1, 0, true)
+	tm.Set(4, 2, 1) // A wall between the light and (48, 20)
+
+	s := NewSystem()
+	s.Ambient = 0
+	s.Add(Light{X: 20, Y: 20, Radius: 40, Intensity: 2})
+	m := NewMap(80, 40)
+	m.Render(s, 0, 0, 1, NewOccluders(tm, ts, 1))
+
+	if got := m.Step(48, 20); got != Darkest {
+		t.Errorf("pixel behind the wall step %d, want %d", got, Darkest)
+	}
+	if got := m.Step(33, 20); got == Darkest {
+		t.Error("the wall's face toward the light is dark")
+	}
+	if got := m.Step(48, 4); got == Darkest {
+		t.Error("pixel beside the shadow is dark")
+	}
+}
+
+func BenchmarkRender480x270(b *testing.B) {
+	tm := graphics.NewTileMap(60, 34)
+	ts := graphics.NewTileset()
+	ts.SetFlag(1, 0, true)
+	for x := 0; x < 60; x += 3 {
+		tm.Set(x, 10, 1)
+		tm.Set(x, 20, 1)
+	}
+	s := NewSystem()
+	s.Ambient = 0.3
+	for i := 0; i < 8; i++ {
+		s.Add(Light{X: float64(30 + i*55), Y: float64(40 + i%3*80), Radius: 64, Intensity: 1})
+	}
+	m := NewMap(480, 270)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Render(s, 0, 0, 1, NewOccluders(tm, ts, 1))
+	}
+}
@@ -0,0 +1,67 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestCamera(t *testing.T) {
+	r := rendersoft.New(32, 16)
+	state := NewState()
+	L, run := newTestLua(t, r, nil, state)
+
+	// Ticks move a following camera, inside its bounds
+	run(`
+		cam = rf.cam_new()
+		rf.cam_bounds(cam, 0, 0, 100, 16)
+		rf.cam_follow(cam, 60, 8)
+	`)
+	state.Tick(1.0/60, r)
+	run(`x, y = rf.cam_pos(cam)`)
+	if L.GetGlobal("x") != lua.LNumber(60) || L.GetGlobal("y") != lua.LNumber(8) {
+		t.Errorf("cam_pos = %v, %v, want 60, 8", L.GetGlobal("x"), L.GetGlobal("y"))
+	}
+
+	// Attached at 2× zoom, world (52, 4)..(67, 11) fills the screen; rf.camera() draws a HUD on top
+	run(`
+		rf.cam_zoom(cam, 2)
+		rf.clear_i(0)
+		rf.cam_attach(cam)
+		rf.pset(52, 4, 7)
+		wx, wy = rf.screen_to_world(2, 2)
+		sx, sy = rf.world_to_screen(wx, wy, cam)
+		rf.camera()
+		rf.pset(31, 15, 9)
+	`)
+	if L.GetGlobal("wx") != lua.LNumber(53) || L.GetGlobal("wy") != lua.LNumber(5) {
+		t.Errorf("screen_to_world = %v, %v, want 53, 5", L.GetGlobal("wx"), L.GetGlobal("wy"))
+	}
+	if L.GetGlobal("sx") != lua.LNumber(2) || L.GetGlobal("sy") != lua.LNumber(2) {
+		t.Errorf("world_to_screen = %v, %v, want 2, 2", L.GetGlobal("sx"), L.GetGlobal("sy"))
+	}
+	if r.PGetIndex(0, 0) != 7 || r.PGetIndex(1, 1) != 7 || r.PGetIndex(2, 2) != 0 || r.PGetIndex(31, 15) != 9 {
+		t.Errorf("zoomed draw: %d %d %d, HUD %d", r.PGetIndex(0, 0), r.PGetIndex(1, 1), r.PGetIndex(2, 2), r.PGetIndex(31, 15))
+	}
+
+	// The next frame starts unzoomed
+	run(`rf.cam_attach(cam)`)
+	state.EndFrame(r)
+	if r.GetZoom() != 1 {
+		t.Errorf("zoom after the frame = %d, want 1", r.GetZoom())
+	}
+
+	// Shake moves the offset, then decays
+	run(`rf.cam_shake(cam, 1, 4, 2)`)
+	state.Tick(0.25, r)
+	run(`rf.cam_attach(cam) ox, oy = rf.screen_to_world(0, 0) rf.camera()`)
+	if ox := float64(L.GetGlobal("ox").(lua.LNumber)); ox < 52-3 || ox > 52+3 {
+		t.Errorf("shaken offset %v out of range", ox)
+	}
+	state.Tick(1, r)
+	run(`rf.cam_attach(cam) ox, oy = rf.screen_to_world(0, 0) rf.camera() rf.cam_free(cam)`)
+	if L.GetGlobal("ox") != lua.LNumber(52) {
+		t.Errorf("offset after the shake = %v, want 52", L.GetGlobal("ox"))
+	}
+}
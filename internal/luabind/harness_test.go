@@ -0,0 +1,32 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// newTestLua registers rf.* drawing to r, with palette index i as red i, and
+// returns the Lua state and a run func that fails the test on Lua errors.
+// sprites may be nil.
+func newTestLua(t *testing.T, r graphics.Renderer, sprites cartio.SpriteMap, state *State) (*lua.LState, func(code string)) {
+	t.Helper()
+	if sprites == nil {
+		sprites = make(cartio.SpriteMap)
+	}
+	L := lua.NewState()
+	t.Cleanup(L.Close)
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), sprites, nil, state, nil)
+
+	run := func(code string) {
+		t.Helper()
+		if err := L.DoString(code); err != nil {
+			t.Fatalf("Lua error: %v", err)
+		}
+	}
+	return L, run
+}
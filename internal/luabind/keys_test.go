@@ -0,0 +1,35 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+)
+
+func TestKeysAndText(t *testing.T) {
+	input.Reset()
+	defer input.Reset()
+
+	L, run := newTestLua(t, rendersoft.New(32, 32), nil, NewState())
+
+	input.SetKey("F1", true)
+	run(`
+		held, pressed, other = rf.key("f1"), rf.keyp("F1"), rf.key("f2")
+		rf.text_start("ab", 8)
+	`)
+	input.AddText("c")
+	input.Compose("d")
+	run(`
+		typed, composing = rf.text_get()
+		final = rf.text_stop()
+	`)
+	for name, want := range map[string]string{"held": "true", "pressed": "true", "other": "false", "typed": "abc", "composing": "d", "final": "abc"} {
+		if got := L.GetGlobal(name).String(); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	if input.Texting() {
+		t.Error("rf.text_stop() left text input on")
+	}
+}
@@ -0,0 +1,97 @@
+package luabind
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestLighting(t *testing.T) {
+	r := rendersoft.New(64, 40)
+	state := NewState()
+	L, run := newTestLua(t, r, nil, state)
+
+	base := palette.IndexOf(4, palette.Base)
+
+	// Dim ambient light darkens by a shade; a light restores its surroundings
+	run(fmt.Sprintf(`
+		rf.lighting(true, 0.7, -1)
+		lamp = rf.light_add(10, 20, 12)
+		rf.clear_i(%d)
+	`, base))
+	r.PSet(60, 0, color.RGBA{90, 60, 30, 255}) // An RGBA color outside the palette
+	state.EndFrame(r)
+	if got := r.PGetIndex(50, 20); got != palette.IndexOf(4, palette.Shadow) {
+		t.Errorf("ambient shade %d, want %d", got, palette.IndexOf(4, palette.Shadow))
+	}
+	if got := r.PGetIndex(10, 20); got != palette.IndexOf(4, palette.Highlight) {
+		t.Errorf("lit shade %d, want %d", got, palette.IndexOf(4, palette.Highlight))
+	}
+	if got := r.PGetIndex(60, 0); got != 49 { // Palette index i is red i: 49 is nearest {60, 40, 20}
+		t.Errorf("RGBA pixel lit to index %d, want the nearest palette color", got)
+	}
+
+	// Lights move with the camera; rf.light_apply leaves a HUD drawn after it unlit
+	run(fmt.Sprintf(`
+		moved = rf.light_move(lamp, 40, 20)
+		rf.camera(20, 0)
+		rf.clear_i(%d)
+		rf.light_apply()
+		rf.camera()
+		rf.rectfill(0, 0, 3, 3, %d)
+	`, base, base))
+	state.EndFrame(r)
+	if L.GetGlobal("moved") != lua.LTrue {
+		t.Error("light_move failed")
+	}
+	if got := r.PGetIndex(20, 20); got != palette.IndexOf(4, palette.Highlight) {
+		t.Errorf("moved light shade %d", got)
+	}
+	if got := r.PGetIndex(1, 1); got != base {
+		t.Errorf("HUD lit to %d", got)
+	}
+
+	// Turning lighting off or removing lights stops it
+	run(fmt.Sprintf(`
+		removed = rf.light_remove(lamp)
+		again = rf.light_remove(lamp)
+		rf.lighting(false)
+		rf.clear_i(%d)
+	`, base))
+	state.EndFrame(r)
+	if L.GetGlobal("removed") != lua.LTrue || L.GetGlobal("again") != lua.LFalse {
+		t.Error("light_remove results wrong")
+	}
+	if got := r.PGetIndex(50, 20); got != base {
+		t.Errorf("unlit frame changed to %d", got)
+	}
+
+	// Shadows are opt-in: a wall of tiles with flag 0 blocks the light only
+	// once flag 0 is given, and opening it lets the light through again
+	lit := func() int {
+		run(fmt.Sprintf(`rf.clear_i(%d)`, base))
+		state.EndFrame(r)
+		return r.PGetIndex(40, 20)
+	}
+	run(`
+		rf.fset(1, 0, true)
+		for y = 0, 4 do rf.mset(3, y, 1) end
+		rf.lighting(true, 0)
+		rf.light_add(8, 20, 100)
+	`)
+	if got := lit(); got != palette.IndexOf(4, palette.Shadow) {
+		t.Errorf("without an occluder flag %d, want lit to %d", got, palette.IndexOf(4, palette.Shadow))
+	}
+	run(`rf.lighting(true, 0, 0)`)
+	if got := lit(); got != palette.Black {
+		t.Errorf("behind the wall %d, want black", got)
+	}
+	run(`rf.mset(3, 2, 0)`)
+	if got := lit(); got != palette.IndexOf(4, palette.Shadow) {
+		t.Errorf("through the opened wall %d, want lit", got)
+	}
+}
@@ -1,19 +1,29 @@
 package luabind
 
 import (
+	"encoding/json"
 	"fmt"
 	"image/color"
+	"math"
+	"strings"
 	"time"
 
+	"github.com/AndrewDonelson/retroforge-engine/internal/anim"
 	"github.com/AndrewDonelson/retroforge-engine/internal/app"
 	"github.com/AndrewDonelson/retroforge-engine/internal/audio"
 	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
 	"github.com/AndrewDonelson/retroforge-engine/internal/font"
 	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
 	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/light"
 	"github.com/AndrewDonelson/retroforge-engine/internal/network"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+	"github.com/AndrewDonelson/retroforge-engine/internal/particles"
 	"github.com/AndrewDonelson/retroforge-engine/internal/physics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/raycast"
 	"github.com/AndrewDonelson/retroforge-engine/internal/spritepool"
+	"github.com/AndrewDonelson/retroforge-engine/internal/voxel"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -38,24 +48,24 @@ type DevStats struct {
 }
 
 // Register attaches rf.* drawing functions to the Lua state.
-func Register(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string), sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, netMgr *network.NetworkManager) {
+func Register(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string) error, sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, netMgr *network.NetworkManager) {
 	state := NewState()
 	RegisterWithState(L, r, colorByIndex, setPalette, sfxMap, musicMap, spritesMap, physWorld, state, netMgr)
 }
 
 // RegisterWithDev attaches rf.* drawing functions with dev mode support
-func RegisterWithDev(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string), sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, devMode DevModeHandler, netMgr *network.NetworkManager) {
+func RegisterWithDev(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string) error, sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, devMode DevModeHandler, netMgr *network.NetworkManager) {
 	state := NewState()
 	RegisterWithDevMode(L, r, colorByIndex, setPalette, sfxMap, musicMap, spritesMap, physWorld, state, devMode, netMgr)
 }
 
 // RegisterWithState attaches rf.* drawing functions with state management
-func RegisterWithState(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string), sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, state *State, netMgr *network.NetworkManager) {
+func RegisterWithState(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string) error, sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, state *State, netMgr *network.NetworkManager) {
 	RegisterWithDevMode(L, r, colorByIndex, setPalette, sfxMap, musicMap, spritesMap, physWorld, state, nil, netMgr)
 }
 
 // RegisterWithDevMode attaches rf.* drawing functions with dev mode support
-func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string), sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, state *State, devMode DevModeHandler, netMgr *network.NetworkManager) {
+func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorByIndex, setPalette func(string) error, sfxMap cartio.SFXMap, musicMap cartio.MusicMap, spritesMap cartio.SpriteMap, physWorld *physics.World, state *State, devMode DevModeHandler, netMgr *network.NetworkManager) {
 	rf := L.NewTable()
 	L.SetGlobal("rf", rf)
 
@@ -77,10 +87,36 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		}
 	}
 
-	// Create wrapper for colorByIndex that applies remapping
-	colorByIndexRemapped := func(i int) (c [4]uint8) {
+	// The renderer draws palette indices; give it the cart palette's colors
+	syncPalette := func() {
+		colors := make([]color.RGBA, pal.Size)
+		for i := range colors {
+			c := colorByIndex(i)
+			colors[i] = color.RGBA{c[0], c[1], c[2], c[3]}
+		}
+		r.SetPalette(colors)
+		state.SetPaletteColors(colors)
+	}
+	syncPalette()
+
+	// Draw palette remapping (pal(c0, c1)) applied to every drawing index
+	indexRemapped := func(i int) pal.Index {
 		remapped := state.GetPalRemap(i)
-		return colorByIndex(remapped)
+		if remapped < 0 || remapped > 255 {
+			remapped = 0
+		}
+		return pal.Index(remapped)
+	}
+
+	// Text starts in the built-in font; rf.font switches to cart fonts
+	r.SetFont(nil)
+
+	// cursorAfter returns where printing txt at (x, y) leaves the cursor
+	cursorAfter := func(txt string, x, y int) (int, int) {
+		f := r.Font()
+		lines := strings.Split(txt, "\n")
+		last := lines[len(lines)-1]
+		return x + f.LineWidth(last), y + (len(lines)-1)*f.LineHeight
 	}
 
 	// rf.print_anchored(text, anchor, index)
@@ -89,16 +125,14 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		txt := L.CheckString(1)
 		anchor := L.CheckString(2)
 		idx := L.CheckInt(3)
-		c := colorByIndexRemapped(idx)
-		r.PrintAnchored(txt, anchor, color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]})
+		r.PrintAnchored(txt, anchor, indexRemapped(idx))
 		return 0
 	}))
 
 	// rf.clear_i(idx)
 	L.SetField(rf, "clear_i", L.NewFunction(func(L *lua.LState) int {
 		idx := L.CheckInt(1)
-		c := colorByIndexRemapped(idx)
-		r.Clear(color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]})
+		r.Clear(indexRemapped(idx))
 		return 0
 	}))
 
@@ -124,24 +158,52 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 			}
 		}
 
-		c := colorByIndexRemapped(idx)
-		r.Print(txt, x, y, color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]})
+		r.Print(txt, x, y, indexRemapped(idx))
 
 		// Update cursor position after printing (handle newlines)
 		// Note: This matches PICO-8 behavior
-		finalX := x
-		finalY := y
-		runes := []rune(txt)
-		for _, r := range runes {
-			if r == '\n' {
-				finalX = x                // Return to start of line
-				finalY += font.Height + 1 // Advance to next line
-			} else {
-				finalX += font.Advance
+		state.SetCursor(cursorAfter(txt, x, y))
+		return 0
+	}))
+
+	// rf.print_box(text, x, y, w, h, [opts]) - Word-wrapped, aligned text clipped to a box.
+	// opts: align ("left"/"center"/"right"), valign ("top"/"middle"/"bottom"), line_spacing,
+	// wrap (default true), color (default text color). Inline markup: {c:n}, {wave}, {shake}.
+	// Returns the height of the laid-out text.
+	L.SetField(rf, "print_box", L.NewFunction(func(L *lua.LState) int {
+		txt := L.CheckString(1)
+		x, y := L.CheckInt(2), L.CheckInt(3)
+		w, h := L.CheckInt(4), L.CheckInt(5)
+
+		idx := 15 // Default white
+		if colorIdx, hasColor := state.GetTextColor(); hasColor {
+			idx = colorIdx
+		}
+		opts := font.BoxOptions{
+			Wrap: true,
+			Time: state.Clock(),
+			Ink:  func(n int) color.Color { return indexRemapped(n) },
+		}
+		if t, ok := L.Get(6).(*lua.LTable); ok {
+			if v, ok := t.RawGetString("align").(lua.LString); ok {
+				opts.Align = font.ParseAlign(string(v))
+			}
+			if v, ok := t.RawGetString("valign").(lua.LString); ok {
+				opts.VAlign = font.ParseAlign(string(v))
+			}
+			if v, ok := t.RawGetString("line_spacing").(lua.LNumber); ok {
+				opts.LineSpacing = int(v)
+			}
+			if v := t.RawGetString("wrap"); v != lua.LNil {
+				opts.Wrap = lua.LVAsBool(v)
+			}
+			if v, ok := t.RawGetString("color").(lua.LNumber); ok {
+				idx = int(v)
 			}
 		}
-		state.SetCursor(finalX, finalY)
-		return 0
+
+		L.Push(lua.LNumber(r.PrintBox(txt, x, y, w, h, indexRemapped(idx), opts)))
+		return 1
 	}))
 
 	// rf.cursor([x, y]) - Set text cursor position. No args resets cursor.
@@ -207,43 +269,76 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 			}
 		}
 
-		c := colorByIndexRemapped(idx)
-		r.Print(txt, x, y, color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]})
+		r.Print(txt, x, y, indexRemapped(idx))
 
 		// Update cursor position after printing if using state (handle newlines)
 		if useState {
-			finalX := x
-			finalY := y
-			runes := []rune(txt)
-			for _, r := range runes {
-				if r == '\n' {
-					finalX = x                // Return to start of line
-					finalY += font.Height + 1 // Advance to next line
-				} else {
-					finalX += font.Advance
-				}
-			}
-			state.SetCursor(finalX, finalY)
+			state.SetCursor(cursorAfter(txt, x, y))
 		}
 		return 0
 	}))
 
-	// palette.set(name)
+	// rf.font([name]) - Select a cart font from the manifest; no args restores the built-in font.
+	// Returns false if the font doesn't exist.
+	L.SetField(rf, "font", L.NewFunction(func(L *lua.LState) int {
+		name := L.OptString(1, "")
+		if name == "" || name == "default" {
+			r.SetFont(nil)
+			L.Push(lua.LTrue)
+			return 1
+		}
+		f, ok := state.GetFont(name)
+		if ok {
+			r.SetFont(f)
+		}
+		L.Push(lua.LBool(ok))
+		return 1
+	}))
+
+	// rf.text_width(str) - Width in pixels of the widest line in the current font
+	L.SetField(rf, "text_width", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LNumber(r.Font().TextWidth(L.CheckString(1))))
+		return 1
+	}))
+
+	// rf.text_height(str) - Height in pixels of all lines in the current font
+	L.SetField(rf, "text_height", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LNumber(r.Font().TextHeight(L.CheckString(1))))
+		return 1
+	}))
+
+	// rf.palette_set(name) - Switch to a built-in or cart palette by name
 	L.SetField(rf, "palette_set", L.NewFunction(func(L *lua.LState) int {
 		name := L.CheckString(1)
 		if setPalette != nil {
-			setPalette(name)
+			if err := setPalette(name); err != nil {
+				L.ArgError(1, err.Error())
+			}
+			syncPalette()
 		}
 		return 0
 	}))
 
+	// rf.palette_get(i) - r, g, b (0-255) of a color in the current palette
+	L.SetField(rf, "palette_get", L.NewFunction(func(L *lua.LState) int {
+		i := L.CheckInt(1)
+		if i < 0 || i >= pal.Size {
+			L.Push(lua.LNil)
+			return 1
+		}
+		c := colorByIndex(i)
+		L.Push(lua.LNumber(c[0]))
+		L.Push(lua.LNumber(c[1]))
+		L.Push(lua.LNumber(c[2]))
+		return 3
+	}))
+
 	// Drawing primitives (index-colored)
 	L.SetField(rf, "pset", L.NewFunction(func(L *lua.LState) int {
 		x := L.CheckInt(1)
 		y := L.CheckInt(2)
 		idx := L.CheckInt(3)
-		c := colorByIndexRemapped(idx)
-		r.PSet(x, y, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.PSet(x, y, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "line", L.NewFunction(func(L *lua.LState) int {
@@ -252,8 +347,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		x1 := L.CheckInt(3)
 		y1 := L.CheckInt(4)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Line(x0, y0, x1, y1, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Line(x0, y0, x1, y1, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "rect", L.NewFunction(func(L *lua.LState) int {
@@ -262,8 +356,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		x1 := L.CheckInt(3)
 		y1 := L.CheckInt(4)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Rect(x0, y0, x1, y1, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Rect(x0, y0, x1, y1, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "rectfill", L.NewFunction(func(L *lua.LState) int {
@@ -272,8 +365,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		x1 := L.CheckInt(3)
 		y1 := L.CheckInt(4)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.RectFill(x0, y0, x1, y1, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.RectFill(x0, y0, x1, y1, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "circ", L.NewFunction(func(L *lua.LState) int {
@@ -281,8 +373,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		y := L.CheckInt(2)
 		rad := L.CheckInt(3)
 		idx := L.CheckInt(4)
-		c := colorByIndexRemapped(idx)
-		r.Circ(x, y, rad, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Circ(x, y, rad, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "circfill", L.NewFunction(func(L *lua.LState) int {
@@ -290,8 +381,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		y := L.CheckInt(2)
 		rad := L.CheckInt(3)
 		idx := L.CheckInt(4)
-		c := colorByIndexRemapped(idx)
-		r.CircFill(x, y, rad, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.CircFill(x, y, rad, indexRemapped(idx))
 		return 0
 	}))
 
@@ -302,8 +392,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		radius := L.CheckInt(3)
 		filled := L.OptBool(4, false)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Triangle(x, y, radius, filled, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Triangle(x, y, radius, filled, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "diamond", L.NewFunction(func(L *lua.LState) int {
@@ -312,8 +401,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		radius := L.CheckInt(3)
 		filled := L.OptBool(4, false)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Diamond(x, y, radius, filled, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Diamond(x, y, radius, filled, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "square", L.NewFunction(func(L *lua.LState) int {
@@ -322,8 +410,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		radius := L.CheckInt(3)
 		filled := L.OptBool(4, false)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Square(x, y, radius, filled, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Square(x, y, radius, filled, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "pentagon", L.NewFunction(func(L *lua.LState) int {
@@ -332,8 +419,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		radius := L.CheckInt(3)
 		filled := L.OptBool(4, false)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Pentagon(x, y, radius, filled, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Pentagon(x, y, radius, filled, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "hexagon", L.NewFunction(func(L *lua.LState) int {
@@ -342,8 +428,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		radius := L.CheckInt(3)
 		filled := L.OptBool(4, false)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Hexagon(x, y, radius, filled, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Hexagon(x, y, radius, filled, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "star", L.NewFunction(func(L *lua.LState) int {
@@ -352,24 +437,107 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		radius := L.CheckInt(3)
 		filled := L.OptBool(4, false)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Star(x, y, radius, filled, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Star(x, y, radius, filled, indexRemapped(idx))
+		return 0
+	}))
+
+	// Freeform shapes
+	// rf.poly(points, index, [filled]) - points is {x1, y1, x2, y2, ...} or {{x, y}, ...}
+	L.SetField(rf, "poly", L.NewFunction(func(L *lua.LState) int {
+		points := tablePoints(L.CheckTable(1))
+		idx := L.CheckInt(2)
+		filled := L.OptBool(3, false)
+		r.Poly(points, filled, indexRemapped(idx))
+		return 0
+	}))
+	// rf.line_thick(x0, y0, x1, y1, thickness, index)
+	L.SetField(rf, "line_thick", L.NewFunction(func(L *lua.LState) int {
+		x0 := L.CheckInt(1)
+		y0 := L.CheckInt(2)
+		x1 := L.CheckInt(3)
+		y1 := L.CheckInt(4)
+		thickness := L.CheckInt(5)
+		idx := L.CheckInt(6)
+		r.LineThick(x0, y0, x1, y1, thickness, indexRemapped(idx))
+		return 0
+	}))
+	// rf.arc(x, y, radius, a0, a1, index) - angles in degrees, 0 = right, clockwise
+	L.SetField(rf, "arc", L.NewFunction(func(L *lua.LState) int {
+		x := L.CheckInt(1)
+		y := L.CheckInt(2)
+		radius := L.CheckInt(3)
+		a0 := float64(L.CheckNumber(4))
+		a1 := float64(L.CheckNumber(5))
+		idx := L.CheckInt(6)
+		r.Arc(x, y, radius, a0, a1, indexRemapped(idx))
+		return 0
+	}))
+	// rf.pie(x, y, radius, a0, a1, [filled], index) - angles in degrees, 0 = right, clockwise
+	L.SetField(rf, "pie", L.NewFunction(func(L *lua.LState) int {
+		x := L.CheckInt(1)
+		y := L.CheckInt(2)
+		radius := L.CheckInt(3)
+		a0 := float64(L.CheckNumber(4))
+		a1 := float64(L.CheckNumber(5))
+		filled := L.OptBool(6, false)
+		idx := L.CheckInt(7)
+		r.Pie(x, y, radius, a0, a1, filled, indexRemapped(idx))
+		return 0
+	}))
+	// rf.rrect(x0, y0, x1, y1, radius, index)
+	L.SetField(rf, "rrect", L.NewFunction(func(L *lua.LState) int {
+		x0 := L.CheckInt(1)
+		y0 := L.CheckInt(2)
+		x1 := L.CheckInt(3)
+		y1 := L.CheckInt(4)
+		radius := L.CheckInt(5)
+		idx := L.CheckInt(6)
+		r.RoundRect(x0, y0, x1, y1, radius, indexRemapped(idx))
+		return 0
+	}))
+	// rf.rrectfill(x0, y0, x1, y1, radius, index)
+	L.SetField(rf, "rrectfill", L.NewFunction(func(L *lua.LState) int {
+		x0 := L.CheckInt(1)
+		y0 := L.CheckInt(2)
+		x1 := L.CheckInt(3)
+		y1 := L.CheckInt(4)
+		radius := L.CheckInt(5)
+		idx := L.CheckInt(6)
+		r.RoundRectFill(x0, y0, x1, y1, radius, indexRemapped(idx))
+		return 0
+	}))
+	// rf.bezier_quad(x0, y0, cx, cy, x1, y1, index)
+	L.SetField(rf, "bezier_quad", L.NewFunction(func(L *lua.LState) int {
+		x0 := L.CheckInt(1)
+		y0 := L.CheckInt(2)
+		cx := L.CheckInt(3)
+		cy := L.CheckInt(4)
+		x1 := L.CheckInt(5)
+		y1 := L.CheckInt(6)
+		idx := L.CheckInt(7)
+		r.BezierQuad(x0, y0, cx, cy, x1, y1, indexRemapped(idx))
+		return 0
+	}))
+	// rf.bezier_cubic(x0, y0, c1x, c1y, c2x, c2y, x1, y1, index)
+	L.SetField(rf, "bezier_cubic", L.NewFunction(func(L *lua.LState) int {
+		x0 := L.CheckInt(1)
+		y0 := L.CheckInt(2)
+		c1x := L.CheckInt(3)
+		c1y := L.CheckInt(4)
+		c2x := L.CheckInt(5)
+		c2y := L.CheckInt(6)
+		x1 := L.CheckInt(7)
+		y1 := L.CheckInt(8)
+		idx := L.CheckInt(9)
+		r.BezierCubic(x0, y0, c1x, c1y, c2x, c2y, x1, y1, indexRemapped(idx))
 		return 0
 	}))
 
-	// Pixel reading
+	// Pixel reading: rf.pget(x, y) -> palette index
 	L.SetField(rf, "pget", L.NewFunction(func(L *lua.LState) int {
 		x := L.CheckInt(1)
 		y := L.CheckInt(2)
-		col := r.PGet(x, y)
-		// Find closest palette index (simplified - just return RGB components)
-		// For now, return table with r, g, b, a
-		tbl := L.NewTable()
-		tbl.RawSetString("r", lua.LNumber(col.R))
-		tbl.RawSetString("g", lua.LNumber(col.G))
-		tbl.RawSetString("b", lua.LNumber(col.B))
-		tbl.RawSetString("a", lua.LNumber(col.A))
-		L.Push(tbl)
+		L.Push(lua.LNumber(r.PGetIndex(x, y)))
 		return 1
 	}))
 
@@ -391,8 +559,9 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 	// Camera
 	L.SetField(rf, "camera", L.NewFunction(func(L *lua.LState) int {
 		if L.GetTop() == 0 {
-			// No args = reset camera
+			// No args = reset camera and zoom (rf.cam_attach)
 			r.SetCamera(0, 0)
+			r.SetZoom(1)
 		} else {
 			x := L.CheckInt(1)
 			y := L.CheckInt(2)
@@ -408,8 +577,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		rx := L.CheckInt(3)
 		ry := L.CheckInt(4)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.Ellipse(x, y, rx, ry, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.Ellipse(x, y, rx, ry, indexRemapped(idx))
 		return 0
 	}))
 	L.SetField(rf, "ellifill", L.NewFunction(func(L *lua.LState) int {
@@ -418,43 +586,105 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		rx := L.CheckInt(3)
 		ry := L.CheckInt(4)
 		idx := L.CheckInt(5)
-		c := colorByIndexRemapped(idx)
-		r.EllipseFill(x, y, rx, ry, color.RGBA{c[0], c[1], c[2], c[3]})
+		r.EllipseFill(x, y, rx, ry, indexRemapped(idx))
 		return 0
 	}))
 
 	// Input
+	// rf.btn(i, [player]) - Button i held by local player 0-5 (default 0)
 	L.SetField(rf, "btn", L.NewFunction(func(L *lua.LState) int {
 		i := L.CheckInt(1)
-
-		// Check if this is a multiplayer call: rf.btn(player_id, button)
-		if L.GetTop() >= 2 {
-			playerID := i
-			buttonID := L.CheckInt(2)
-			if netMgr != nil && netMgr.IsHost() {
-				// Host can check other players' inputs
-				L.Push(lua.LBool(netMgr.GetPlayerInput(playerID, buttonID)))
-				return 1
-			}
-			// Non-host trying to check other player - not allowed
-			L.Push(lua.LBool(false))
-			return 1
-		}
-
-		// Normal single-player or local player input
-		L.Push(lua.LBool(input.Btn(i)))
+		s := input.Player(L.OptInt(2, 0))
+		L.Push(lua.LBool(s != nil && s.Btn(i)))
 		return 1
 	}))
+	// rf.btnp(i, [player]) - Button i pressed this frame by local player 0-5
 	L.SetField(rf, "btnp", L.NewFunction(func(L *lua.LState) int {
 		i := L.CheckInt(1)
-		L.Push(lua.LBool(input.Btnp(i)))
+		s := input.Player(L.OptInt(2, 0))
+		L.Push(lua.LBool(s != nil && s.Btnp(i)))
+		return 1
+	}))
+	// rf.axis(player, axis) - Analog axis of a local player's controller, dead
+	// zone removed: 0-3 sticks (-1..1), 4-5 triggers (0..1)
+	L.SetField(rf, "axis", L.NewFunction(func(L *lua.LState) int {
+		s := input.Player(L.CheckInt(1))
+		axis := L.CheckInt(2)
+		if s == nil {
+			L.Push(lua.LNumber(0))
+			return 1
+		}
+		L.Push(lua.LNumber(s.Axis(axis)))
+		return 1
+	}))
+	// rf.rumble(player, strength, [seconds, high]) - Vibrate a player's
+	// controller; strength (0-1) drives the low-frequency motor and high
+	// (default strength) the high-frequency one
+	L.SetField(rf, "rumble", L.NewFunction(func(L *lua.LState) int {
+		s := input.Player(L.CheckInt(1))
+		low := float64(L.CheckNumber(2))
+		seconds := float64(L.OptNumber(3, 0.25))
+		high := float64(L.OptNumber(4, lua.LNumber(low)))
+		if s != nil {
+			s.Rumble(low, high, seconds)
+		}
+		return 0
+	}))
+	// rf.pad(player) - Name of the controller a local player uses, or nil
+	L.SetField(rf, "pad", L.NewFunction(func(L *lua.LState) int {
+		if _, name, ok := input.Pad(L.CheckInt(1)); ok {
+			L.Push(lua.LString(name))
+			return 1
+		}
+		L.Push(lua.LNil)
 		return 1
 	}))
+	// rf.btnr(i, [player]) - Button i released this frame by local player 0-5
 	L.SetField(rf, "btnr", L.NewFunction(func(L *lua.LState) int {
-		_ = L.CheckInt(1) // Button ID - release detection not yet implemented
-		// Button release detection (not yet implemented in input package)
-		// For now, return false
-		L.Push(lua.LBool(false))
+		i := L.CheckInt(1)
+		s := input.Player(L.OptInt(2, 0))
+		L.Push(lua.LBool(s != nil && s.Btnr(i)))
+		return 1
+	}))
+	// rf.btnd(i, [player]) - Frames button i has been held (0 when up)
+	L.SetField(rf, "btnd", L.NewFunction(func(L *lua.LState) int {
+		i := L.CheckInt(1)
+		s := input.Player(L.OptInt(2, 0))
+		if s == nil {
+			L.Push(lua.LNumber(0))
+			return 1
+		}
+		L.Push(lua.LNumber(s.Btnd(i)))
+		return 1
+	}))
+	// rf.btnp_repeat(delay, interval) - Make rf.btnp repeat after a button is
+	// held delay frames, every interval frames (0 = no repeat)
+	L.SetField(rf, "btnp_repeat", L.NewFunction(func(L *lua.LState) int {
+		input.SetRepeat(L.CheckInt(1), L.OptInt(2, 4))
+		return 0
+	}))
+
+	// checkAction returns the action named by argument 1, which the manifest must declare
+	checkAction := func(L *lua.LState) string {
+		name := L.CheckString(1)
+		if !input.HasAction(name) {
+			L.ArgError(1, "unknown action "+name)
+		}
+		return name
+	}
+	// rf.action(name, [player]) - Is an action from the manifest held by local player 0-5?
+	L.SetField(rf, "action", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(input.Action(checkAction(L), L.OptInt(2, 0))))
+		return 1
+	}))
+	// rf.action_pressed(name, [player]) - Did the player start holding an action this frame?
+	L.SetField(rf, "action_pressed", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(input.ActionPressed(checkAction(L), L.OptInt(2, 0))))
+		return 1
+	}))
+	// rf.action_value(name, [player]) - An action's value: -1..1 for axes, 1 (or -1) for held buttons
+	L.SetField(rf, "action_value", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LNumber(input.ActionValue(checkAction(L), L.OptInt(2, 0))))
 		return 1
 	}))
 
@@ -484,6 +714,13 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 			return 1
 		}))
 
+		// rf.network_btn(player_id, button) → boolean - Host only: another player's button
+		L.SetField(rf, "network_btn", L.NewFunction(func(L *lua.LState) int {
+			playerID, buttonID := L.CheckInt(1), L.CheckInt(2)
+			L.Push(lua.LBool(netMgr.IsHost() && netMgr.GetPlayerInput(playerID, buttonID)))
+			return 1
+		}))
+
 		// rf.network_sync(table, tier)
 		L.SetField(rf, "network_sync", L.NewFunction(func(L *lua.LState) int {
 			tbl := L.CheckTable(1)
@@ -658,6 +895,9 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 	// Maps are reference types in Go, so we can store the map directly
 	spriteMapPtr := &spritesMap
 
+	// Sprite pixels resolved through the draw palette, blitted by rf.spr and rf.map
+	spriteImages := newSpriteCache(state)
+
 	// Store pool manager for automatic pool registration
 	// This allows pools to be created/updated when sprite properties change
 
@@ -704,45 +944,426 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		}
 		tbl.RawSetString("mountPoints", mountPointsTbl)
 
-		L.Push(tbl)
+		L.Push(tbl)
+		return 1
+	}))
+
+	// drawSprite draws a sprite by name at (x, y) with optional flipping
+	drawSprite := func(name string, x, y int, flipX, flipY bool) {
+		sprite, ok := (*spriteMapPtr)[name]
+		if !ok {
+			return // Sprite not found, do nothing
+		}
+		img := spriteImages.get(name, sprite)
+		r.Blit(img.pix, img.w, 0, 0, img.w, img.h, x, y, flipX, flipY)
+	}
+
+	// Sprite drawing: rf.spr(name, x, y, [flip_x, flip_y])
+	// Draws a sprite by name at position (x, y) with optional flipping
+	L.SetField(rf, "spr", L.NewFunction(func(L *lua.LState) int {
+		drawSprite(L.CheckString(1), L.CheckInt(2), L.CheckInt(3), L.OptBool(4, false), L.OptBool(5, false))
+		return 0
+	}))
+
+	// rf.mouse() - Pointer x, y in screen pixels, buttons held (1 left, 2 right,
+	// 4 middle), wheel steps this frame, and buttons pressed and released this
+	// frame. A touch is the left button.
+	L.SetField(rf, "mouse", L.NewFunction(func(L *lua.LState) int {
+		x, y, buttons, wheel, pressed, released := input.Mouse()
+		for _, v := range []int{x, y, buttons, wheel, pressed, released} {
+			L.Push(lua.LNumber(v))
+		}
+		return 6
+	}))
+
+	// rf.mouse_cursor([sprite, hot_x, hot_y]) - Draw a sprite as the mouse cursor,
+	// over everything, with its hot spot at the pointer. No sprite = system cursor.
+	L.SetField(rf, "mouse_cursor", L.NewFunction(func(L *lua.LState) int {
+		if L.GetTop() == 0 || L.Get(1) == lua.LNil {
+			state.SetPointer(nil)
+			return 0
+		}
+		name := L.CheckString(1)
+		hotX, hotY := L.OptInt(2, 0), L.OptInt(3, 0)
+		state.SetPointer(func(x, y int) {
+			drawSprite(name, x-hotX, y-hotY, false, false)
+		})
+		return 0
+	}))
+
+	// rf.key(name) - Is a keyboard key held? Names as SDL gives them, any case:
+	// "a", "1", "space", "return", "left shift", "f1"... plus "shift", "ctrl", "alt"
+	L.SetField(rf, "key", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(input.Key(L.CheckString(1))))
+		return 1
+	}))
+
+	// rf.keyp(name) - Was a keyboard key pressed this frame?
+	L.SetField(rf, "keyp", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(input.Keyp(L.CheckString(1))))
+		return 1
+	}))
+
+	// rf.text_start([text], [max]) - Start text input with initial text, keeping at
+	// most max characters. Keys that type don't press buttons until rf.text_stop()
+	L.SetField(rf, "text_start", L.NewFunction(func(L *lua.LState) int {
+		input.StartText(L.OptString(1, ""), L.OptInt(2, 0))
+		return 0
+	}))
+
+	// rf.text_get() - Text typed so far, and text still being composed by an
+	// input method (shown after it, not yet part of the text)
+	L.SetField(rf, "text_get", L.NewFunction(func(L *lua.LState) int {
+		typed, composition := input.Text()
+		L.Push(lua.LString(typed))
+		L.Push(lua.LString(composition))
+		return 2
+	}))
+
+	// rf.text_stop() - Stop text input, returning the text typed
+	L.SetField(rf, "text_stop", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(input.StopText()))
+		return 1
+	}))
+
+	// checkAnimator returns the animator passed as argument n
+	checkAnimator := func(L *lua.LState, n int) *anim.Animator {
+		ud := L.CheckUserData(n)
+		a, ok := ud.Value.(*anim.Animator)
+		if !ok {
+			L.ArgError(n, "animator expected")
+		}
+		return a
+	}
+
+	// rf.anim_new(clip) - Create an animator playing a clip from sprites.json "animations".
+	// The engine advances it every tick. Returns nil if the clip doesn't exist.
+	L.SetField(rf, "anim_new", L.NewFunction(func(L *lua.LState) int {
+		a, ok := state.NewAnimator(L.CheckString(1))
+		if !ok {
+			L.Push(lua.LNil)
+			return 1
+		}
+		ud := L.NewUserData()
+		ud.Value = a
+		L.Push(ud)
+		return 1
+	}))
+
+	// rf.anim_play(a, [clip, restart]) - Resume playback, switching clips if given.
+	// A different clip (or restart=true) starts from its first frame. Returns false if the clip doesn't exist.
+	L.SetField(rf, "anim_play", L.NewFunction(func(L *lua.LState) int {
+		a := checkAnimator(L, 1)
+		clip := L.OptString(2, a.Clip())
+		L.Push(lua.LBool(a.Play(clip, L.OptBool(3, false))))
+		return 1
+	}))
+
+	// rf.anim_stop(a) - Pause on the current frame
+	L.SetField(rf, "anim_stop", L.NewFunction(func(L *lua.LState) int {
+		checkAnimator(L, 1).Stop()
+		return 0
+	}))
+
+	// rf.anim_speed(a, speed) - Set the playback rate (1 = normal)
+	L.SetField(rf, "anim_speed", L.NewFunction(func(L *lua.LState) int {
+		checkAnimator(L, 1).Speed = float64(L.CheckNumber(2))
+		return 0
+	}))
+
+	// rf.anim_frame(a) - Returns clip name, frame number (1-based), sprite name and whether it is playing
+	L.SetField(rf, "anim_frame", L.NewFunction(func(L *lua.LState) int {
+		a := checkAnimator(L, 1)
+		L.Push(lua.LString(a.Clip()))
+		L.Push(lua.LNumber(a.Frame() + 1))
+		L.Push(lua.LString(a.Sprite()))
+		L.Push(lua.LBool(a.Playing()))
+		return 4
+	}))
+
+	// rf.anim_draw(a, x, y, [flip_x, flip_y]) - Draw the animator's current frame
+	L.SetField(rf, "anim_draw", L.NewFunction(func(L *lua.LState) int {
+		a := checkAnimator(L, 1)
+		drawSprite(a.Sprite(), L.CheckInt(2), L.CheckInt(3), L.OptBool(4, false), L.OptBool(5, false))
+		return 0
+	}))
+
+	// rf.anim_on_event(a, fn) - Call fn(event, a) for frame event markers and "end"
+	// when a "once" clip finishes. nil removes the handler.
+	L.SetField(rf, "anim_on_event", L.NewFunction(func(L *lua.LState) int {
+		ud := L.CheckUserData(1)
+		a := checkAnimator(L, 1)
+		fn := L.OptFunction(2, nil)
+		if fn == nil {
+			a.OnEvent = nil
+			return 0
+		}
+		a.OnEvent = func(event string) {
+			L.Push(fn)
+			L.Push(lua.LString(event))
+			L.Push(ud)
+			if err := L.PCall(2, 0, nil); err != nil && devModePtr != nil {
+				devModePtr.AddDebugLog(fmt.Sprintf("anim event %q: %v", event, err))
+			}
+		}
+		return 0
+	}))
+
+	// rf.anim_free(a) - Stop the engine advancing an animator that is no longer used
+	L.SetField(rf, "anim_free", L.NewFunction(func(L *lua.LState) int {
+		state.RemoveAnimator(checkAnimator(L, 1))
+		return 0
+	}))
+
+	// checkEmitter returns the particle emitter passed as argument n
+	checkEmitter := func(L *lua.LState, n int) *particles.Emitter {
+		ud := L.CheckUserData(n)
+		e, ok := ud.Value.(*particles.Emitter)
+		if !ok {
+			L.ArgError(n, "emitter expected")
+		}
+		return e
+	}
+
+	// rf.emitter_new(def, x, y) - Create a particle emitter from a particles.json name, or from a
+	// table with the same fields. The engine steps its particles every tick.
+	// Returns nil if the name doesn't exist.
+	L.SetField(rf, "emitter_new", L.NewFunction(func(L *lua.LState) int {
+		var def cartio.EmitterDef
+		switch v := L.Get(1).(type) {
+		case lua.LString:
+			d, ok := state.Emitter(string(v))
+			if !ok {
+				L.Push(lua.LNil)
+				return 1
+			}
+			def = d
+		case *lua.LTable:
+			data, err := json.Marshal(tableJSON(v))
+			if err == nil {
+				def, err = cartio.ParseEmitter(data)
+			}
+			if err != nil {
+				L.ArgError(1, err.Error())
+			}
+		default:
+			L.ArgError(1, "emitter name or table expected")
+		}
+		ud := L.NewUserData()
+		ud.Value = state.Particles().New(def, float64(L.CheckNumber(2)), float64(L.CheckNumber(3)))
+		L.Push(ud)
+		return 1
+	}))
+
+	// rf.emitter_move(e, x, y) - Move an emitter; particles already emitted keep their course
+	L.SetField(rf, "emitter_move", L.NewFunction(func(L *lua.LState) int {
+		e := checkEmitter(L, 1)
+		e.X, e.Y = float64(L.CheckNumber(2)), float64(L.CheckNumber(3))
+		return 0
+	}))
+
+	// rf.emitter_burst(e, [n]) - Emit n particles at once (default: the definition's burst)
+	L.SetField(rf, "emitter_burst", L.NewFunction(func(L *lua.LState) int {
+		e := checkEmitter(L, 1)
+		state.Particles().Burst(e, L.OptInt(2, e.Def.Burst))
+		return 0
+	}))
+
+	// rf.emitter_rate(e, rate) - Set particles emitted per second (0 = bursts only)
+	L.SetField(rf, "emitter_rate", L.NewFunction(func(L *lua.LState) int {
+		checkEmitter(L, 1).Def.Rate = math.Max(0, float64(L.CheckNumber(2)))
+		return 0
+	}))
+
+	// rf.emitter_count(e) - Returns the emitter's live particle count
+	L.SetField(rf, "emitter_count", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LNumber(checkEmitter(L, 1).Len()))
+		return 1
+	}))
+
+	// rf.emitter_free(e) - Stop emitting; the emitter is removed once its particles have died
+	L.SetField(rf, "emitter_free", L.NewFunction(func(L *lua.LState) int {
+		state.Particles().Free(checkEmitter(L, 1))
+		return 0
+	}))
+
+	// drawParticle draws one particle as a pixel, circle or centered sprite
+	drawParticle := func(e *particles.Emitter, p particles.Particle) {
+		x, y := int(math.Floor(p.X)), int(math.Floor(p.Y))
+		switch e.Def.Shape {
+		case cartio.ShapeSprite:
+			if sprite, ok := (*spriteMapPtr)[e.Def.Sprite]; ok {
+				img := spriteImages.get(e.Def.Sprite, sprite)
+				r.Blit(img.pix, img.w, 0, 0, img.w, img.h, x-img.w/2, y-img.h/2, false, false)
+			}
+		case cartio.ShapeCircle:
+			r.CircFill(x, y, int(math.Round(p.Size)), indexRemapped(p.Color))
+		default:
+			if size := int(math.Round(p.Size)); size > 1 {
+				r.RectFill(x-size/2, y-size/2, x-size/2+size-1, y-size/2+size-1, indexRemapped(p.Color))
+			} else if size == 1 {
+				r.PSet(x, y, indexRemapped(p.Color))
+			}
+		}
+	}
+
+	// rf.particles_draw([layer]) - Draw the particles of emitters on layer (every layer if omitted)
+	L.SetField(rf, "particles_draw", L.NewFunction(func(L *lua.LState) int {
+		if L.GetTop() >= 1 {
+			state.Particles().Draw(L.CheckInt(1), drawParticle)
+		} else {
+			state.Particles().DrawAll(drawParticle)
+		}
+		return 0
+	}))
+
+	// rf.particles_clear() - Remove every emitter and particle
+	L.SetField(rf, "particles_clear", L.NewFunction(func(L *lua.LState) int {
+		state.Particles().Clear()
+		return 0
+	}))
+
+	// checkCamera returns the camera passed as argument n
+	checkCamera := func(L *lua.LState, n int) *graphics.Camera2D {
+		ud := L.CheckUserData(n)
+		c, ok := ud.Value.(*graphics.Camera2D)
+		if !ok {
+			L.ArgError(n, "camera expected")
+		}
+		return c
+	}
+
+	// rf.cam_new([x, y]) - Create a camera centered on world position (x, y) (default: the
+	// screen center, matching no camera). The engine steps it every tick.
+	L.SetField(rf, "cam_new", L.NewFunction(func(L *lua.LState) int {
+		c := state.NewCamera(r.Width(), r.Height())
+		if L.GetTop() >= 2 {
+			c.X, c.Y = float64(L.CheckNumber(1)), float64(L.CheckNumber(2))
+		}
+		ud := L.NewUserData()
+		ud.Value = c
+		L.Push(ud)
+		return 1
+	}))
+
+	// rf.cam_follow(c, x, y) - Move toward a target each tick; call every frame with its position.
+	// rf.cam_follow(c) stops following.
+	L.SetField(rf, "cam_follow", L.NewFunction(func(L *lua.LState) int {
+		c := checkCamera(L, 1)
+		if L.GetTop() < 3 {
+			c.Unfollow()
+			return 0
+		}
+		c.Follow(float64(L.CheckNumber(2)), float64(L.CheckNumber(3)))
+		return 0
+	}))
+
+	// rf.cam_set(c, x, y) - Center the camera on (x, y) at once
+	L.SetField(rf, "cam_set", L.NewFunction(func(L *lua.LState) int {
+		checkCamera(L, 1).MoveTo(float64(L.CheckNumber(2)), float64(L.CheckNumber(3)))
+		return 0
+	}))
+
+	// rf.cam_pos(c) - Returns the camera's center in world pixels
+	L.SetField(rf, "cam_pos", L.NewFunction(func(L *lua.LState) int {
+		c := checkCamera(L, 1)
+		L.Push(lua.LNumber(c.X))
+		L.Push(lua.LNumber(c.Y))
+		return 2
+	}))
+
+	// rf.cam_deadzone(c, w, h) - Size of the area around the center the target moves in
+	// without moving the camera (default 0 × 0)
+	L.SetField(rf, "cam_deadzone", L.NewFunction(func(L *lua.LState) int {
+		c := checkCamera(L, 1)
+		c.DeadW = math.Max(0, float64(L.CheckNumber(2)))
+		c.DeadH = math.Max(0, float64(L.CheckNumber(3)))
+		return 0
+	}))
+
+	// rf.cam_lerp(c, t) - Fraction of the way to the target moved per 1/60 s (1 = snap, default)
+	L.SetField(rf, "cam_lerp", L.NewFunction(func(L *lua.LState) int {
+		checkCamera(L, 1).Lerp = math.Min(1, math.Max(0, float64(L.CheckNumber(2))))
+		return 0
+	}))
+
+	// rf.cam_bounds(c, x0, y0, x1, y1) - Keep the view inside a world rectangle;
+	// rf.cam_bounds(c) removes the bounds
+	L.SetField(rf, "cam_bounds", L.NewFunction(func(L *lua.LState) int {
+		c := checkCamera(L, 1)
+		if L.GetTop() < 5 {
+			c.ClearBounds()
+			return 0
+		}
+		c.SetBounds(float64(L.CheckNumber(2)), float64(L.CheckNumber(3)),
+			float64(L.CheckNumber(4)), float64(L.CheckNumber(5)))
+		return 0
+	}))
+
+	// rf.cam_shake(c, trauma, [max, decay]) - Add trauma (0-1). The shake offset is
+	// trauma² × max pixels (default 8); trauma decays by decay per second (default 1).
+	L.SetField(rf, "cam_shake", L.NewFunction(func(L *lua.LState) int {
+		c := checkCamera(L, 1)
+		c.Shake(float64(L.CheckNumber(2)))
+		c.MaxShake = float64(L.OptNumber(3, lua.LNumber(c.MaxShake)))
+		c.TraumaDecay = float64(L.OptNumber(4, lua.LNumber(c.TraumaDecay)))
+		return 0
+	}))
+
+	// rf.cam_zoom(c, [z]) - Set the integer zoom (1 or more); returns the zoom
+	L.SetField(rf, "cam_zoom", L.NewFunction(func(L *lua.LState) int {
+		c := checkCamera(L, 1)
+		if L.GetTop() >= 2 {
+			c.Zoom = max(1, L.CheckInt(2))
+			c.MoveTo(c.X, c.Y) // The view size changed
+		}
+		L.Push(lua.LNumber(c.Zoom))
 		return 1
 	}))
 
-	// Sprite drawing: rf.spr(name, x, y, [flip_x, flip_y])
-	// Draws a sprite by name at position (x, y) with optional flipping
-	L.SetField(rf, "spr", L.NewFunction(func(L *lua.LState) int {
-		name := L.CheckString(1)
-		x := L.CheckInt(2)
-		y := L.CheckInt(3)
-		flipX := L.OptBool(4, false)
-		flipY := L.OptBool(5, false)
+	// rf.cam_attach(c) - Draw through the camera: sets the camera offset (with shake) and
+	// zoom until rf.camera() or the end of the frame
+	L.SetField(rf, "cam_attach", L.NewFunction(func(L *lua.LState) int {
+		checkCamera(L, 1).Apply(r)
+		return 0
+	}))
 
-		sprite, ok := (*spriteMapPtr)[name]
-		if !ok {
-			return 0 // Sprite not found, do nothing
-		}
+	// rf.cam_free(c) - Stop the engine stepping a camera that is no longer used
+	L.SetField(rf, "cam_free", L.NewFunction(func(L *lua.LState) int {
+		state.RemoveCamera(checkCamera(L, 1))
+		return 0
+	}))
 
-		// Draw sprite pixels
-		for sy := 0; sy < sprite.Height; sy++ {
-			for sx := 0; sx < sprite.Width; sx++ {
-				// Calculate source coordinates with flipping
-				srcX := sx
-				srcY := sy
-				if flipX {
-					srcX = sprite.Width - 1 - sx
-				}
-				if flipY {
-					srcY = sprite.Height - 1 - sy
-				}
+	// rf.screen_to_world(x, y, [c]) - Convert a screen position to world pixels through a
+	// camera, or through the current camera offset and zoom
+	L.SetField(rf, "screen_to_world", L.NewFunction(func(L *lua.LState) int {
+		x, y := float64(L.CheckNumber(1)), float64(L.CheckNumber(2))
+		if L.GetTop() >= 3 {
+			x, y = checkCamera(L, 3).ScreenToWorld(x, y)
+		} else {
+			camX, camY := r.GetCamera()
+			z := float64(r.GetZoom())
+			x, y = float64(camX)+x/z, float64(camY)+y/z
+		}
+		L.Push(lua.LNumber(x))
+		L.Push(lua.LNumber(y))
+		return 2
+	}))
 
-				colorIdx := sprite.Pixels[srcY][srcX]
-				if colorIdx >= 0 { // -1 is transparent
-					c := colorByIndexRemapped(colorIdx)
-					r.PSet(x+sx, y+sy, color.RGBA{c[0], c[1], c[2], c[3]})
-				}
-			}
+	// rf.world_to_screen(x, y, [c]) - Convert a world position to screen pixels through a
+	// camera, or through the current camera offset and zoom
+	L.SetField(rf, "world_to_screen", L.NewFunction(func(L *lua.LState) int {
+		x, y := float64(L.CheckNumber(1)), float64(L.CheckNumber(2))
+		if L.GetTop() >= 3 {
+			x, y = checkCamera(L, 3).WorldToScreen(x, y)
+		} else {
+			camX, camY := r.GetCamera()
+			z := float64(r.GetZoom())
+			x, y = (x-float64(camX))*z, (y-float64(camY))*z
 		}
-		return 0
+		L.Push(lua.LNumber(x))
+		L.Push(lua.LNumber(y))
+		return 2
 	}))
 
 	// Sprite region: rf.sspr(sx, sy, sw, sh, dx, dy, [dw, dh, flip_x, flip_y])
@@ -771,6 +1392,20 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 			return 0
 		}
 
+		// Unscaled regions inside the sprite are blitted. Flipping mirrors the
+		// whole sprite, so a flipped region is read from the mirrored position.
+		if dw == sw && dh == sh && sx >= 0 && sy >= 0 && sx+sw <= sprite.Width && sy+sh <= sprite.Height {
+			if flipX {
+				sx = sprite.Width - sx - sw
+			}
+			if flipY {
+				sy = sprite.Height - sy - sh
+			}
+			img := spriteImages.get(name, sprite)
+			r.Blit(img.pix, img.w, sx, sy, sw, sh, dx, dy, flipX, flipY)
+			return 0
+		}
+
 		// Draw scaled/flipped region
 		xScale := float64(dw) / float64(sw)
 		yScale := float64(dh) / float64(sh)
@@ -797,8 +1432,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 
 				colorIdx := sprite.Pixels[drawY][drawX]
 				if colorIdx >= 0 {
-					c := colorByIndexRemapped(colorIdx)
-					r.PSet(dx+dxi, dy+dyi, color.RGBA{c[0], c[1], c[2], c[3]})
+					r.PSet(dx+dxi, dy+dyi, indexRemapped(colorIdx))
 				}
 			}
 		}
@@ -844,6 +1478,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 
 		// Add to sprite map
 		(*spriteMapPtr)[name] = newSprite
+		spriteImages.invalidate(name)
 
 		// Automatically register pool if sprite meets criteria (isUI=false, maxSpawn>10)
 		// Note: Default sprite has isUI=true and maxSpawn=0, so it won't be pooled by default
@@ -916,6 +1551,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 			}
 		}
 		(*spriteMapPtr)[name] = sprite
+		spriteImages.invalidate(name)
 	}
 
 	// Helper to set pixel in sprite with bounds checking
@@ -923,6 +1559,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		if x >= 0 && x < sprite.Width && y >= 0 && y < sprite.Height {
 			sprite.Pixels[y][x] = idx
 			(*spriteMapPtr)[name] = sprite
+			spriteImages.invalidate(name)
 		}
 	}
 
@@ -948,6 +1585,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 
 		sprite.Pixels[y][x] = idx
 		(*spriteMapPtr)[name] = sprite
+		spriteImages.invalidate(name)
 		return 0
 	}))
 
@@ -1001,6 +1639,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		}
 
 		(*spriteMapPtr)[name] = sprite
+		spriteImages.invalidate(name)
 		return 0
 	}))
 
@@ -1063,6 +1702,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		}
 
 		(*spriteMapPtr)[name] = sprite
+		spriteImages.invalidate(name)
 		return 0
 	}))
 
@@ -1137,6 +1777,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		}
 
 		(*spriteMapPtr)[name] = sprite
+		spriteImages.invalidate(name)
 		return 0
 	}))
 
@@ -1187,6 +1828,7 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		}
 
 		(*spriteMapPtr)[name] = sprite
+		spriteImages.invalidate(name)
 
 		// Automatically register/update pool if sprite now meets criteria
 		if spritepool.ShouldPool(sprite) {
@@ -1207,57 +1849,661 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		return 0
 	}))
 
-	// Tilemap functions: mget, mset, map
+	// resolveTile finds the sprite region a tile index draws: the tile's sprite,
+	// or its cell of the tileset sheet. Animated tiles draw their current frame.
+	resolveTile := func(index int) (name string, sprite cartio.SpriteData, ox, oy, w, h int, ok bool) {
+		ts := state.GetTileset()
+		tile, ok := ts.Tile(ts.Animate(index, state.Clock()))
+		if !ok {
+			return "", sprite, 0, 0, 0, 0, false
+		}
+		if tile.Sprite != "" {
+			sprite, ok = (*spriteMapPtr)[tile.Sprite]
+			return tile.Sprite, sprite, 0, 0, sprite.Width, sprite.Height, ok
+		}
+		sprite, ok = (*spriteMapPtr)[ts.Sheet]
+		if !ok || sprite.Width < ts.CellW || ts.CellW <= 0 || ts.CellH <= 0 || tile.Cell < 0 {
+			return "", sprite, 0, 0, 0, 0, false
+		}
+		cols := sprite.Width / ts.CellW
+		ox, oy = (tile.Cell%cols)*ts.CellW, (tile.Cell/cols)*ts.CellH
+		if oy+ts.CellH > sprite.Height {
+			return "", sprite, 0, 0, 0, 0, false
+		}
+		return ts.Sheet, sprite, ox, oy, ts.CellW, ts.CellH, true
+	}
+
+	// layerArg returns the tile layer named, or numbered from 1, by argument n of
+	// the loaded map (the first layer when absent); nil if there is no such layer
+	layerArg := func(L *lua.LState, n int) *graphics.TileMap {
+		switch v := L.Get(n).(type) {
+		case lua.LString:
+			tm, _ := state.TileLayer(string(v))
+			return tm
+		case lua.LNumber:
+			tm, _ := state.TileLayerAt(int(v) - 1)
+			return tm
+		}
+		return state.GetTileMap()
+	}
+
+	// Tilemap functions: mget, mset, map. The optional layer is a name or 1-based index.
+	// rf.mget(x, y, [layer])
 	L.SetField(rf, "mget", L.NewFunction(func(L *lua.LState) int {
 		x := L.CheckInt(1)
 		y := L.CheckInt(2)
-		val := state.GetTileMap().Get(x, y)
+		val := 0
+		if tm := layerArg(L, 3); tm != nil {
+			val = tm.Get(x, y)
+		}
 		L.Push(lua.LNumber(val))
 		return 1
 	}))
+	// rf.mset(x, y, v, [layer])
 	L.SetField(rf, "mset", L.NewFunction(func(L *lua.LState) int {
 		x := L.CheckInt(1)
 		y := L.CheckInt(2)
 		v := L.CheckInt(3)
-		state.GetTileMap().Set(x, y, v)
+		if tm := layerArg(L, 4); tm != nil {
+			tm.Set(x, y, v)
+		}
 		return 0
 	}))
+
+	// rf.map([celx, cely, sx, sy, celw, celh, flags, layer]) - Draw tiles as their tileset sprites.
+	// Defaults draw the whole layer at (0, 0). With flags, only tiles whose flags include
+	// every bit are drawn; a string in place of flags names the layer. Tiles outside the
+	// camera view (at its zoom) and clip rectangle are skipped.
 	L.SetField(rf, "map", L.NewFunction(func(L *lua.LState) int {
-		celX := L.CheckInt(1)
-		celY := L.CheckInt(2)
-		sx := L.CheckInt(3)
-		sy := L.CheckInt(4)
-		celW := L.CheckInt(5)
-		celH := L.CheckInt(6)
-
-		// Draw tilemap region using sprites
+		var layer uint8
+		layerPos := 8
+		switch v := L.Get(7).(type) {
+		case lua.LNumber:
+			layer = uint8(v)
+		case lua.LString:
+			layerPos = 7
+		}
+		tm := layerArg(L, layerPos)
+		if tm == nil {
+			return 0 // Layer not found, do nothing
+		}
+		celX := L.OptInt(1, 0)
+		celY := L.OptInt(2, 0)
+		sx := L.OptInt(3, 0)
+		sy := L.OptInt(4, 0)
+		celW := L.OptInt(5, tm.Width())
+		celH := L.OptInt(6, tm.Height())
+
+		// Visible area in world coordinates
+		vx, vy, vw, vh := graphics.View(r)
+		if vw <= 0 || vh <= 0 {
+			return 0 // Clipped away
+		}
+
+		ts := state.GetTileset()
+		tm.DrawCulled(celX, celY, sx, sy, celW, celH, vx, vy, vw, vh, func(x, y, tileIndex int) {
+			if !ts.Matches(tileIndex, layer) {
+				return
+			}
+			name, sprite, ox, oy, w, h, ok := resolveTile(tileIndex)
+			if !ok {
+				return
+			}
+			img := spriteImages.get(name, sprite)
+			r.Blit(img.pix, img.w, ox, oy, w, h, x, y, false, false)
+		})
+		return 0
+	}))
+
+	// Raycaster (rf.ray_*). The frame is rendered at screen size on first use.
+	var rays *raycast.Renderer
+	raysReady := func() *raycast.Renderer {
+		if rays == nil {
+			rays = raycast.New(r.Width(), r.Height())
+		}
+		return rays
+	}
+	var rayTileW, rayTileH float64 = graphics.DefaultTileSize, graphics.DefaultTileSize
+	rayShade := 0.0 // Map pixels per shade step
+
+	// rf.ray_render(cam_x, cam_y, angle, fov, [map]) - Draw the tilemap Wolfenstein-style over the
+	// whole screen. cam_x/cam_y are map pixels; angle (0 = +X, clockwise) and fov are degrees.
+	// map is the loaded map's name or one of its tile layers (default: the first layer);
+	// anything else is an error. Non-zero tiles are walls textured with their tileset sprites.
+	L.SetField(rf, "ray_render", L.NewFunction(func(L *lua.LState) int {
 		tm := state.GetTileMap()
-		tm.Draw(celX, celY, sx, sy, celW, celH, func(x, y, tileIndex int) {
-			// Convert tile index to sprite name (simplified: assume tile index is sprite name index)
-			// For now, draw an 8x8 rectangle representing the tile
-			// Full implementation would look up sprite by index
-			if tileIndex > 0 {
-				c := colorByIndexRemapped(tileIndex % 50) // Use tile index as color
-				r.RectFill(x, y, x+7, y+7, color.RGBA{c[0], c[1], c[2], c[3]})
+		if name, ok := L.Get(5).(lua.LString); !ok || string(name) != state.MapName() {
+			tm = layerArg(L, 5)
+		}
+		if tm == nil {
+			L.ArgError(5, fmt.Sprintf("no loaded map or tile layer %s", L.Get(5)))
+			return 0
+		}
+		tw, th := tm.TileSize()
+		rayTileW, rayTileH = float64(tw), float64(th)
+		cam := raycast.Camera{
+			X:     float64(L.CheckNumber(1)) / rayTileW,
+			Y:     float64(L.CheckNumber(2)) / rayTileH,
+			Angle: float64(L.CheckNumber(3)) * math.Pi / 180,
+			FOV:   math.Min(math.Max(float64(L.CheckNumber(4)), 1), 179) * math.Pi / 180,
+		}
+		rc := raysReady()
+		rc.ShadeDist = rayShade / rayTileW
+		rc.Render(cam, tm, func(tile int) (graphics.Texture, bool) {
+			name, sprite, ox, oy, w, h, ok := resolveTile(tile)
+			if !ok {
+				return graphics.Texture{}, false
 			}
+			img := spriteImages.get(name, sprite)
+			return graphics.Texture{Pix: img.pix, Stride: img.w, X: ox, Y: oy, W: w, H: h}, true
 		})
+		w, h := rc.Size()
+		camX, camY := r.GetCamera() // The frame covers the screen, whatever the camera
+		r.Blit(rc.Pix, w, 0, 0, w, h, camX, camY, false, false)
+		return 0
+	}))
+
+	// rf.ray_sprite(name, x, y) - Draw a sprite as a billboard standing at map pixel (x, y) in the
+	// last rf.ray_render frame, hidden behind nearer walls and billboards. A sprite one tile tall
+	// is as tall as a wall.
+	L.SetField(rf, "ray_sprite", L.NewFunction(func(L *lua.LState) int {
+		name := L.CheckString(1)
+		x, y := float64(L.CheckNumber(2)), float64(L.CheckNumber(3))
+		sprite, ok := (*spriteMapPtr)[name]
+		if !ok || rays == nil {
+			return 0
+		}
+		img := spriteImages.get(name, sprite)
+		tex := graphics.Texture{Pix: img.pix, Stride: img.w, W: img.w, H: img.h}
+		if pix, sx, sy, w, h, ok := rays.Sprite(tex, x/rayTileW, y/rayTileH); ok {
+			camX, camY := r.GetCamera()
+			r.Blit(pix, w, 0, 0, w, h, sx+camX, sy+camY, false, false)
+		}
+		return 0
+	}))
+
+	// rf.ray_colors(ceiling, floor, [wall]) - Colors of the ceiling, the floor and walls
+	// without a texture (default black, black, white)
+	L.SetField(rf, "ray_colors", L.NewFunction(func(L *lua.LState) int {
+		rc := raysReady()
+		rc.Ceiling = int(indexRemapped(L.CheckInt(1)))
+		rc.Floor = int(indexRemapped(L.CheckInt(2)))
+		if L.GetTop() >= 3 {
+			rc.Wall = int(indexRemapped(L.CheckInt(3)))
+		}
+		return 0
+	}))
+
+	// rf.ray_shade(distance) - Darken walls, floor, ceiling and billboards one palette shade
+	// (highlight, base, shadow, black) per distance map pixels (0 = off, the default)
+	L.SetField(rf, "ray_shade", L.NewFunction(func(L *lua.LState) int {
+		rayShade = math.Max(0, float64(L.CheckNumber(1)))
+		return 0
+	}))
+
+	// Voxel terrain (rf.voxel_*). The frame is rendered at screen size on first use.
+	var voxels *voxel.Renderer
+	voxelsReady := func() *voxel.Renderer {
+		if voxels == nil {
+			voxels = voxel.New(r.Width(), r.Height())
+		}
+		return voxels
+	}
+	var voxelTerrain *voxel.Terrain // Last rendered, for billboards
+
+	// rf.voxel_render(terrain, x, y, height, yaw, pitch) - Draw a terrain from terrains.json
+	// Comanche-style over the whole screen, seen from map pixel (x, y) at the given height
+	// (0-255 height units). yaw (0 = +X, clockwise) and pitch (positive looks up) are degrees;
+	// the field of view is 90°. Returns false if the terrain is unknown.
+	L.SetField(rf, "voxel_render", L.NewFunction(func(L *lua.LState) int {
+		t, ok := state.Terrain(L.CheckString(1))
+		if !ok {
+			L.Push(lua.LFalse)
+			return 1
+		}
+		cam := voxel.Camera{
+			X:      float64(L.CheckNumber(2)),
+			Y:      float64(L.CheckNumber(3)),
+			Height: float64(L.CheckNumber(4)),
+			Yaw:    float64(L.CheckNumber(5)) * math.Pi / 180,
+			Pitch:  math.Min(math.Max(float64(L.OptNumber(6, 0)), -80), 80) * math.Pi / 180,
+		}
+		vr := voxelsReady()
+		vr.Render(t, cam)
+		voxelTerrain = t
+		w, h := vr.Size()
+		camX, camY := r.GetCamera() // The frame covers the screen, whatever the camera
+		r.Blit(vr.Pix, w, 0, 0, w, h, camX, camY, false, false)
+		L.Push(lua.LTrue)
+		return 1
+	}))
+
+	// rf.voxel_sprite(name, x, y, [z]) - Draw a sprite as a billboard standing at map pixel
+	// (x, y) and height z (default: the ground there) in the last rf.voxel_render frame,
+	// hidden behind nearer terrain and billboards. One sprite pixel is one map pixel.
+	L.SetField(rf, "voxel_sprite", L.NewFunction(func(L *lua.LState) int {
+		name := L.CheckString(1)
+		x, y := float64(L.CheckNumber(2)), float64(L.CheckNumber(3))
+		sprite, ok := (*spriteMapPtr)[name]
+		if !ok || voxels == nil || voxelTerrain == nil {
+			return 0
+		}
+		z := float64(voxelTerrain.HeightAt(x, y))
+		if L.GetTop() >= 4 {
+			z = float64(L.CheckNumber(4))
+		}
+		img := spriteImages.get(name, sprite)
+		tex := graphics.Texture{Pix: img.pix, Stride: img.w, W: img.w, H: img.h}
+		if pix, sx, sy, w, h, ok := voxels.Sprite(tex, x, y, z); ok {
+			camX, camY := r.GetCamera()
+			r.Blit(pix, w, 0, 0, w, h, sx+camX, sy+camY, false, false)
+		}
+		return 0
+	}))
+
+	// rf.voxel_height(terrain, x, y) - Ground height (0-255) at map pixel (x, y); the terrain
+	// wraps around. Returns nil if the terrain is unknown.
+	L.SetField(rf, "voxel_height", L.NewFunction(func(L *lua.LState) int {
+		t, ok := state.Terrain(L.CheckString(1))
+		if !ok {
+			L.Push(lua.LNil)
+			return 1
+		}
+		L.Push(lua.LNumber(t.HeightAt(float64(L.CheckNumber(2)), float64(L.CheckNumber(3)))))
+		return 1
+	}))
+
+	// rf.voxel_sky(color) - Color behind the terrain (default black)
+	L.SetField(rf, "voxel_sky", L.NewFunction(func(L *lua.LState) int {
+		voxelsReady().Sky = int(indexRemapped(L.CheckInt(1)))
+		return 0
+	}))
+
+	// rf.voxel_detail(distance, [lod]) - Draw distance in map pixels (default 600) and how
+	// quickly rows thin out with distance (default 0.005; 0 = every map row)
+	L.SetField(rf, "voxel_detail", L.NewFunction(func(L *lua.LState) int {
+		vr := voxelsReady()
+		vr.Distance = math.Max(1, float64(L.CheckNumber(1)))
+		if L.GetTop() >= 2 {
+			vr.LOD = math.Max(0, float64(L.CheckNumber(2)))
+		}
+		return 0
+	}))
+
+	// rf.fget(n, [f]) - Tile flags: all flag bits of tile n, or whether flag f (0-7) is set
+	L.SetField(rf, "fget", L.NewFunction(func(L *lua.LState) int {
+		flags := state.GetTileset().Flags(L.CheckInt(1))
+		if L.GetTop() >= 2 {
+			f := L.CheckInt(2)
+			L.Push(lua.LBool(f >= 0 && f <= 7 && flags&(1<<uint(f)) != 0))
+			return 1
+		}
+		L.Push(lua.LNumber(flags))
+		return 1
+	}))
+
+	// rf.fset(n, [f], v) - Set all flag bits of tile n, or set flag f (0-7) to the boolean v
+	L.SetField(rf, "fset", L.NewFunction(func(L *lua.LState) int {
+		n := L.CheckInt(1)
+		if L.GetTop() >= 3 {
+			state.GetTileset().SetFlag(n, L.CheckInt(2), L.ToBool(3))
+			return 0
+		}
+		state.GetTileset().SetFlags(n, uint8(L.CheckInt(2)))
+		return 0
+	}))
+
+	// rf.tile_sprite(n, sprite) - Draw tile n as a sprite (name) or a tileset sheet cell (number)
+	L.SetField(rf, "tile_sprite", L.NewFunction(func(L *lua.LState) int {
+		n := L.CheckInt(1)
+		switch v := L.Get(2).(type) {
+		case lua.LString:
+			state.GetTileset().SetTile(n, graphics.Tile{Sprite: string(v)})
+		case lua.LNumber:
+			state.GetTileset().SetTile(n, graphics.Tile{Cell: int(v)})
+		default:
+			L.ArgError(2, "sprite name or sheet cell expected")
+		}
+		return 0
+	}))
+
+	// rf.tile_size([w, h]) - Set the map's tile size in pixels (h defaults to w). Returns the tile size.
+	L.SetField(rf, "tile_size", L.NewFunction(func(L *lua.LState) int {
+		tm := state.GetTileMap()
+		if L.GetTop() >= 1 {
+			w := L.CheckInt(1)
+			tm.SetTileSize(w, L.OptInt(2, w))
+		}
+		w, h := tm.TileSize()
+		L.Push(lua.LNumber(w))
+		L.Push(lua.LNumber(h))
+		return 2
+	}))
+
+	// rf.map_load(name) - Load a map from maps.json, replacing the current tiles and the static
+	// physics bodies of its collision objects. Returns false if it doesn't exist.
+	L.SetField(rf, "map_load", L.NewFunction(func(L *lua.LState) int {
+		ok := state.LoadMap(L.CheckString(1))
+		if ok && physWorld != nil {
+			state.BuildMapBodies(physWorld)
+		}
+		L.Push(lua.LBool(ok))
+		return 1
+	}))
+
+	// rf.map_info() - Returns {name, width, height, tile_w, tile_h, layers = {names}} for the loaded map
+	L.SetField(rf, "map_info", L.NewFunction(func(L *lua.LState) int {
+		tm := state.GetTileMap()
+		tw, th := tm.TileSize()
+		tbl := L.NewTable()
+		tbl.RawSetString("name", lua.LString(state.MapName()))
+		tbl.RawSetString("width", lua.LNumber(tm.Width()))
+		tbl.RawSetString("height", lua.LNumber(tm.Height()))
+		tbl.RawSetString("tile_w", lua.LNumber(tw))
+		tbl.RawSetString("tile_h", lua.LNumber(th))
+		layers := L.NewTable()
+		for i, name := range state.MapLayers() {
+			layers.RawSetInt(i+1, lua.LString(name))
+		}
+		tbl.RawSetString("layers", layers)
+		L.Push(tbl)
+		return 1
+	}))
+
+	// rf.map_objects([name, layer]) - Objects of a map's object layer (all object layers if layer
+	// is omitted; the loaded map if name is omitted). Each is {id, name, type, shape, x, y, w, h,
+	// tile, points, properties}; points (polygons and polylines) is a flat list of x, y pairs.
+	// Returns nil if the map or layer doesn't exist.
+	L.SetField(rf, "map_objects", L.NewFunction(func(L *lua.LState) int {
+		name := L.OptString(1, "")
+		if name == "" {
+			name = state.MapName()
+		}
+		objects, ok := state.MapObjects(name, L.OptString(2, ""))
+		if !ok {
+			L.Push(lua.LNil)
+			return 1
+		}
+		tbl := L.NewTable()
+		for i, obj := range objects {
+			o := L.NewTable()
+			shape := obj.Shape
+			if shape == "" {
+				shape = cartio.ShapeRect
+			}
+			o.RawSetString("id", lua.LNumber(obj.ID))
+			o.RawSetString("name", lua.LString(obj.Name))
+			o.RawSetString("type", lua.LString(obj.Type))
+			o.RawSetString("shape", lua.LString(shape))
+			o.RawSetString("x", lua.LNumber(obj.X))
+			o.RawSetString("y", lua.LNumber(obj.Y))
+			o.RawSetString("w", lua.LNumber(obj.W))
+			o.RawSetString("h", lua.LNumber(obj.H))
+			o.RawSetString("tile", lua.LNumber(obj.Tile))
+			if len(obj.Points) > 0 {
+				points := L.NewTable()
+				for j, p := range obj.Points {
+					points.RawSetInt(j+1, lua.LNumber(p))
+				}
+				o.RawSetString("points", points)
+			}
+			props := L.NewTable()
+			for k, v := range obj.Properties {
+				props.RawSetString(k, goValueToLua(L, v))
+			}
+			o.RawSetString("properties", props)
+			tbl.RawSetInt(i+1, o)
+		}
+		L.Push(tbl)
+		return 1
+	}))
+
+	// rf.map_save() - Write the loaded map's tiles back to the cart folder (dev mode only).
+	// Returns true, or false and an error message.
+	L.SetField(rf, "map_save", L.NewFunction(func(L *lua.LState) int {
+		if err := state.SaveMap(); err != nil {
+			L.Push(lua.LFalse)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		L.Push(lua.LTrue)
+		return 1
+	}))
+
+	// Texture samplers for rf.tline / rf.mode7 (coordinates in texture pixels).
+	// The tilemap samples tile sprites; sprites wrap so they tile as textures.
+	mapSampler := func(u, v float64) (color.Color, bool) {
+		tm := state.GetTileMap()
+		tw, th := tm.TileSize()
+		px, py := int(math.Floor(u)), int(math.Floor(v))
+		tx, ty := tm.TileAt(px, py)
+		tileIndex := tm.Get(tx, ty)
+		if tileIndex == 0 {
+			return nil, false // 0 = empty/transparent
+		}
+		_, sprite, ox, oy, w, h, ok := resolveTile(tileIndex)
+		px, py = px-tx*tw, py-ty*th
+		if !ok || px >= w || py >= h || oy+py >= len(sprite.Pixels) || ox+px >= len(sprite.Pixels[oy+py]) {
+			return nil, false
+		}
+		colorIdx := sprite.Pixels[oy+py][ox+px]
+		if colorIdx < 0 {
+			return nil, false // -1 is transparent
+		}
+		return indexRemapped(colorIdx), true
+	}
+	spriteSampler := func(name string) func(u, v float64) (color.Color, bool) {
+		sprite, ok := (*spriteMapPtr)[name]
+		if !ok || sprite.Width <= 0 || sprite.Height <= 0 {
+			return nil
+		}
+		return func(u, v float64) (color.Color, bool) {
+			sx := int(math.Floor(u)) % sprite.Width
+			sy := int(math.Floor(v)) % sprite.Height
+			if sx < 0 {
+				sx += sprite.Width
+			}
+			if sy < 0 {
+				sy += sprite.Height
+			}
+			colorIdx := sprite.Pixels[sy][sx]
+			if colorIdx < 0 {
+				return nil, false // -1 is transparent
+			}
+			return indexRemapped(colorIdx), true
+		}
+	}
+
+	// rf.tline(x0, y0, x1, y1, mx, my, [mdx, mdy], [sprite_name])
+	// Textured line sampling the tilemap (mx/my in tiles, default step one pixel of a tile)
+	// or a sprite (mx/my in sprite pixels, default step 1 pixel, wrapping)
+	L.SetField(rf, "tline", L.NewFunction(func(L *lua.LState) int {
+		x0 := L.CheckInt(1)
+		y0 := L.CheckInt(2)
+		x1 := L.CheckInt(3)
+		y1 := L.CheckInt(4)
+		mx := float64(L.CheckNumber(5))
+		my := float64(L.CheckNumber(6))
+		name := L.OptString(9, "")
+
+		if name == "" {
+			tw, th := state.GetTileMap().TileSize()
+			mdx := float64(L.OptNumber(7, lua.LNumber(1/float64(tw))))
+			mdy := float64(L.OptNumber(8, 0))
+			w, h := float64(tw), float64(th)
+			r.TLine(x0, y0, x1, y1, mx*w, my*h, mdx*w, mdy*h, mapSampler)
+			return 0
+		}
+
+		sample := spriteSampler(name)
+		if sample == nil {
+			return 0 // Sprite not found, do nothing
+		}
+		mdx := float64(L.OptNumber(7, 1))
+		mdy := float64(L.OptNumber(8, 0))
+		r.TLine(x0, y0, x1, y1, mx, my, mdx, mdy, sample)
+		return 0
+	}))
+
+	// rf.mode7(camera_x, camera_y, angle, horizon, scale, [source])
+	// Renders a perspective floor below the horizon row. source is "map" (default) or a sprite name.
+	// camera_x/camera_y are in texture pixels, angle in degrees (0 = +X, clockwise), scale = viewer height.
+	L.SetField(rf, "mode7", L.NewFunction(func(L *lua.LState) int {
+		camX := float64(L.CheckNumber(1))
+		camY := float64(L.CheckNumber(2))
+		angle := float64(L.CheckNumber(3))
+		horizon := L.CheckInt(4)
+		scale := float64(L.CheckNumber(5))
+		source := L.OptString(6, "map")
+
+		sample := mapSampler
+		if source != "map" {
+			sample = spriteSampler(source)
+			if sample == nil {
+				return 0 // Sprite not found, do nothing
+			}
+		}
+		r.Mode7(camX, camY, angle, horizon, scale, sample)
 		return 0
 	}))
 
 	// Color remapping: pal(c0, c1, [p])
+	// p = 0 remaps drawing (default), p = 1 remaps the screen palette for the whole frame.
+	// A boolean p keeps the draw palette semantics (false resets c0).
 	L.SetField(rf, "pal", L.NewFunction(func(L *lua.LState) int {
 		if L.GetTop() == 0 {
 			// No args = reset all remapping
 			state.ResetPalRemap()
+			state.ResetScreenPal()
+			state.ApplyScreenPal(r)
 		} else {
 			c0 := L.CheckInt(1)
 			c1 := L.OptInt(2, c0) // Default to same color if not provided
+			if n, ok := L.Get(3).(lua.LNumber); ok {
+				if int(n) == 1 {
+					state.SetScreenPal(c0, c1)
+					state.ApplyScreenPal(r)
+				} else {
+					state.SetPalRemap(c0, c1, true)
+				}
+				return 0
+			}
 			p := L.OptBool(3, true)
 			state.SetPalRemap(c0, c1, p)
 		}
 		return 0
 	}))
 
+	// Palette effects: the engine advances these every tick (see State.Tick)
+	// rf.pal_fade(target, t) - Fade the whole screen t of the way (0..1) toward target, stepping through shades
+	L.SetField(rf, "pal_fade", L.NewFunction(func(L *lua.LState) int {
+		target := L.CheckInt(1)
+		t := float64(L.CheckNumber(2))
+		state.PalEffects().Fade(target, t)
+		state.ApplyScreenPal(r)
+		return 0
+	}))
+
+	// rf.pal_cycle(start, end, speed) - Rotate colors start..end at speed steps per second (0 = stop)
+	L.SetField(rf, "pal_cycle", L.NewFunction(func(L *lua.LState) int {
+		start := L.CheckInt(1)
+		end := L.CheckInt(2)
+		speed := float64(L.CheckNumber(3))
+		state.PalEffects().Cycle(start, end, speed)
+		state.ApplyScreenPal(r)
+		return 0
+	}))
+
+	// rf.pal_flash(index, frames, [color]) - Show index as color (default white) for a number of frames
+	L.SetField(rf, "pal_flash", L.NewFunction(func(L *lua.LState) int {
+		index := L.CheckInt(1)
+		frames := L.CheckInt(2)
+		c := L.OptInt(3, palette.White)
+		state.PalEffects().Flash(index, frames, c)
+		state.ApplyScreenPal(r)
+		return 0
+	}))
+
+	// rf.lighting(enabled, [ambient, flag]) - Turn lighting on or off. ambient is the level without
+	// lights (0 = dark, 1 = colors as drawn, the default); tiles of the first layer with flag
+	// (0-7) cast shadows, none by default or with -1
+	L.SetField(rf, "lighting", L.NewFunction(func(L *lua.LState) int {
+		lights := state.Lights()
+		lights.Enabled = L.ToBool(1)
+		if L.GetTop() >= 2 {
+			lights.Ambient = math.Max(0, float64(L.CheckNumber(2)))
+		}
+		if L.GetTop() >= 3 {
+			state.SetOccluderFlag(L.CheckInt(3))
+		}
+		return 0
+	}))
+
+	// rf.light_add(x, y, radius, [{intensity=, falloff=, angle=, cone=}]) - Add a light at world
+	// pixel (x, y). intensity is the level added at the center (default 1), falloff the exponent
+	// of its fall to the edge (default 1, linear). A cone (width in degrees) pointing at angle
+	// makes it a cone light. Returns the light's id
+	L.SetField(rf, "light_add", L.NewFunction(func(L *lua.LState) int {
+		l := light.Light{
+			X:         float64(L.CheckNumber(1)),
+			Y:         float64(L.CheckNumber(2)),
+			Radius:    float64(L.CheckNumber(3)),
+			Falloff:   1,
+			Intensity: 1,
+		}
+		if t, ok := L.Get(4).(*lua.LTable); ok {
+			if v, ok := t.RawGetString("intensity").(lua.LNumber); ok {
+				l.Intensity = float64(v)
+			}
+			if v, ok := t.RawGetString("falloff").(lua.LNumber); ok {
+				l.Falloff = float64(v)
+			}
+			if v, ok := t.RawGetString("angle").(lua.LNumber); ok {
+				l.Angle = float64(v) * math.Pi / 180
+			}
+			if v, ok := t.RawGetString("cone").(lua.LNumber); ok {
+				l.Spread = float64(v) / 2 * math.Pi / 180
+			}
+		}
+		L.Push(lua.LNumber(state.Lights().Add(l)))
+		return 1
+	}))
+
+	// rf.light_move(id, x, y, [angle]) - Move a light, and turn a cone light to angle (degrees).
+	// Returns false if the light doesn't exist
+	L.SetField(rf, "light_move", L.NewFunction(func(L *lua.LState) int {
+		l, ok := state.Lights().Get(L.CheckInt(1))
+		if !ok {
+			L.Push(lua.LFalse)
+			return 1
+		}
+		l.X, l.Y = float64(L.CheckNumber(2)), float64(L.CheckNumber(3))
+		if L.GetTop() >= 4 {
+			l.Angle = float64(L.CheckNumber(4)) * math.Pi / 180
+		}
+		L.Push(lua.LTrue)
+		return 1
+	}))
+
+	// rf.light_remove([id]) - Remove a light, or every light without an id. Returns false if
+	// the light doesn't exist
+	L.SetField(rf, "light_remove", L.NewFunction(func(L *lua.LState) int {
+		if L.GetTop() < 1 {
+			state.Lights().Clear()
+			L.Push(lua.LTrue)
+			return 1
+		}
+		L.Push(lua.LBool(state.Lights().Remove(L.CheckInt(1))))
+		return 1
+	}))
+
+	// rf.light_apply() - Light what has been drawn so far, through the current camera, instead of
+	// at the end of the frame; draw the HUD after it to leave it unlit
+	L.SetField(rf, "light_apply", L.NewFunction(func(L *lua.LState) int {
+		state.ApplyLighting(r)
+		return 0
+	}))
+
 	// Memory functions: poke, peek
 	L.SetField(rf, "poke", L.NewFunction(func(L *lua.LState) int {
 		addr := L.CheckInt(1)
@@ -1813,3 +3059,71 @@ func RegisterWithDevMode(L *lua.LState, r graphics.Renderer, colorByIndex ColorB
 		return 1
 	}))
 }
+
+// tablePoints converts a Lua point list into [][]int. Both flat lists
+// {x1, y1, x2, y2, ...} and nested lists {{x, y}, {x = .., y = ..}} are accepted.
+func tablePoints(tbl *lua.LTable) [][]int {
+	var points [][]int
+	n := tbl.Len()
+	if n == 0 {
+		return points
+	}
+	if _, nested := tbl.RawGetInt(1).(*lua.LTable); nested {
+		for i := 1; i <= n; i++ {
+			pt, ok := tbl.RawGetInt(i).(*lua.LTable)
+			if !ok {
+				continue
+			}
+			x, y := pt.RawGetInt(1), pt.RawGetInt(2)
+			if x == lua.LNil {
+				x, y = pt.RawGetString("x"), pt.RawGetString("y")
+			}
+			xn, xok := x.(lua.LNumber)
+			yn, yok := y.(lua.LNumber)
+			if xok && yok {
+				points = append(points, []int{int(xn), int(yn)})
+			}
+		}
+		return points
+	}
+	for i := 1; i+1 <= n; i += 2 {
+		xn, xok := tbl.RawGetInt(i).(lua.LNumber)
+		yn, yok := tbl.RawGetInt(i + 1).(lua.LNumber)
+		if xok && yok {
+			points = append(points, []int{int(xn), int(yn)})
+		}
+	}
+	return points
+}
+
+// tableJSON converts a Lua table to values encoding/json can marshal: tables
+// with array items become slices, other tables maps.
+func tableJSON(tbl *lua.LTable) interface{} {
+	value := func(v lua.LValue) interface{} {
+		switch v := v.(type) {
+		case lua.LString:
+			return string(v)
+		case lua.LNumber:
+			return float64(v)
+		case lua.LBool:
+			return bool(v)
+		case *lua.LTable:
+			return tableJSON(v)
+		}
+		return nil
+	}
+	if n := tbl.Len(); n > 0 {
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i] = value(tbl.RawGetInt(i + 1))
+		}
+		return items
+	}
+	fields := make(map[string]interface{})
+	tbl.ForEach(func(k, v lua.LValue) {
+		if key, ok := k.(lua.LString); ok {
+			fields[string(key)] = value(v)
+		}
+	})
+	return fields
+}
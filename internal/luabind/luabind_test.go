@@ -4,6 +4,10 @@ import (
 	"testing"
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/network"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
 	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
 	lua "github.com/yuin/gopher-lua"
 )
@@ -22,8 +26,9 @@ func TestRegister(t *testing.T) {
 		}
 		return [4]uint8{128, 128, 128, 255}
 	}
-	setPalette := func(name string) {
-		_ = name // not implemented yet
+	setPalette := func(name string) error {
+		_ = name
+		return nil
 	}
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nil)
@@ -435,13 +440,178 @@ func TestLuaNilSetPalette(t *testing.T) {
 	}
 }
 
+func TestPaletteSetGet(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(16, 16)
+	m := pal.NewManager()
+	Register(L, r, func(i int) (rgba [4]uint8) {
+		c := m.Color(i)
+		return [4]uint8{c.R, c.G, c.B, c.A}
+	}, m.Set, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nil)
+
+	if err := L.DoString(`
+		rf.palette_set("Grayscale 50")
+		r, g, b = rf.palette_get(1)
+		missing = rf.palette_get(50)
+	`); err != nil {
+		t.Fatalf("Lua error: %v", err)
+	}
+	if m.Name() != "Grayscale 50" {
+		t.Fatalf("current palette = %q", m.Name())
+	}
+	if L.GetGlobal("r").String() != "255" || L.GetGlobal("b").String() != "255" || L.GetGlobal("missing") != lua.LNil {
+		t.Errorf("palette_get(1) = %v %v %v, palette_get(50) = %v", L.GetGlobal("r"), L.GetGlobal("g"), L.GetGlobal("b"), L.GetGlobal("missing"))
+	}
+
+	// The renderer follows the switch
+	if _, colors := r.Indexed(); colors[2] != m.Color(2) {
+		t.Errorf("renderer color 2 = %v, want %v", colors[2], m.Color(2))
+	}
+
+	// Unknown names raise an error and keep the palette
+	if err := L.DoString(`rf.palette_set("Nope")`); err == nil {
+		t.Error("expected an error for an unknown palette")
+	}
+	if m.Name() != "Grayscale 50" {
+		t.Errorf("palette changed to %q", m.Name())
+	}
+}
+
+func TestLocalPlayers(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	input.Reset()
+	defer input.Reset()
+
+	r := rendersoft.New(16, 16)
+	Register(L, r, func(i int) (rgba [4]uint8) { return }, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nil)
+
+	// A controller for player 0 and one for player 1, injected as events
+	input.Connect(7, "Pad A")
+	input.Connect(8, "Pad B")
+	input.PadButton(8, input.BtnO, true)
+	input.PadAxis(8, input.AxisLeftX, 32767)
+
+	if err := L.DoString(`
+		o0, o1, op1 = rf.btn(4), rf.btn(4, 1), rf.btnp(4, 1)
+		right1, x1 = rf.btn(1, 1), rf.axis(1, 0)
+		name1, name2 = rf.pad(1), rf.pad(2)
+		bad = rf.btn(4, 9)
+		rf.rumble(1, 0.5)
+	`); err != nil {
+		t.Fatalf("Lua error: %v", err)
+	}
+	for name, want := range map[string]string{
+		"o0": "false", "o1": "true", "op1": "true", "right1": "true", "x1": "1",
+		"name1": "Pad B", "name2": "nil", "bad": "false",
+	} {
+		if got := L.GetGlobal(name).String(); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	if low, high, seconds, ok := input.Player(1).TakeRumble(); !ok || low != 0.5 || high != 0.5 || seconds != 0.25 {
+		t.Errorf("rumble = %v %v %v %v", low, high, seconds, ok)
+	}
+}
+
+func TestNetworkBtn(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	input.Reset()
+	defer input.Reset()
+
+	nm := network.NewNetworkManager()
+	if err := nm.InitializeMultiplayer("game", true, 1, 3, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	nm.SetPlayerInput(2, 0, true)
+	r := rendersoft.New(16, 16)
+	Register(L, r, func(i int) (rgba [4]uint8) { return }, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nm)
+
+	// The host reads remote players with rf.network_btn; rf.btn stays local
+	if err := L.DoString(`remote, left2 = rf.network_btn(2, 0), rf.btn(0, 2)`); err != nil {
+		t.Fatalf("Lua error: %v", err)
+	}
+	if L.GetGlobal("remote") != lua.LTrue || L.GetGlobal("left2") != lua.LFalse {
+		t.Errorf("network_btn = %v, btn = %v; want true, false", L.GetGlobal("remote"), L.GetGlobal("left2"))
+	}
+}
+
+func TestActions(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	input.Reset()
+	defer input.Reset()
+	defer input.SetActions(nil)
+
+	r := rendersoft.New(16, 16)
+	Register(L, r, func(i int) (rgba [4]uint8) { return }, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nil)
+	input.SetActions(map[string][]string{
+		"jump":   {"btn:O", "key:space"},
+		"move_x": {"-btn:left", "+btn:right"},
+	})
+
+	input.SetKey("space", true)
+	input.Player(1).Set(input.BtnLeft, true)
+	if err := L.DoString(`
+		jump, jumped, jump1 = rf.action("jump"), rf.action_pressed("jump"), rf.action("jump", 1)
+		x0, x1 = rf.action_value("move_x"), rf.action_value("move_x", 1)
+		ok = pcall(rf.action, "fly")
+	`); err != nil {
+		t.Fatalf("Lua error: %v", err)
+	}
+	for name, want := range map[string]string{
+		"jump": "true", "jumped": "true", "jump1": "false", "x0": "0", "x1": "-1", "ok": "false",
+	} {
+		if got := L.GetGlobal(name).String(); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestButtonEdges(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	input.Reset()
+	defer input.Reset()
+	defer input.SetRepeat(0, 0)
+
+	r := rendersoft.New(16, 16)
+	Register(L, r, func(i int) (rgba [4]uint8) { return }, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nil)
+
+	input.Player(1).Set(input.BtnR, true)
+	input.Player(1).Step()
+	input.Player(1).Step()
+	input.Set(input.BtnSelect, true)
+	input.Step()
+	input.Set(input.BtnSelect, false)
+	if err := L.DoString(`
+		held = rf.btnd(9, 1)
+		released = rf.btnr(7)
+		up = rf.btnd(7)
+		rf.btnp_repeat(10, 2)
+	`); err != nil {
+		t.Fatalf("Lua error: %v", err)
+	}
+	for name, want := range map[string]string{"held": "4", "released": "true", "up": "0"} {
+		if got := L.GetGlobal(name).String(); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	if input.RepeatDelay != 10 || input.RepeatInterval != 2 {
+		t.Errorf("repeat = %d, %d", input.RepeatDelay, input.RepeatInterval)
+	}
+}
+
 func TestRegisterWithDev(t *testing.T) {
 	L := lua.NewState()
 	defer L.Close()
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{0, 0, 0, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 
 	// Create a mock dev mode handler
 	mockDevMode := &mockDevModeHandler{enabled: true}
@@ -509,3 +679,252 @@ func (m *mockDevModeHandler) GetStats() interface{} {
 	}
 	return m.stats
 }
+
+func TestFreeformShapeFunctions(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(100, 100)
+	colorByIndex := func(i int) (rgba [4]uint8) {
+		if i == 8 {
+			return [4]uint8{255, 0, 0, 255}
+		}
+		return [4]uint8{0, 0, 0, 255}
+	}
+	state := NewState()
+	RegisterWithState(L, r, colorByIndex, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, state, nil)
+
+	// Flat and nested point lists are both accepted; pal() remapping applies
+	script := `
+		rf.pal(3, 8)
+		rf.poly({10, 10, 30, 10, 30, 30, 10, 30}, 3, true)
+		rf.poly({{60, 10}, {x = 80, y = 10}, {80, 30}}, 8)
+		rf.line_thick(10, 50, 40, 50, 3, 8)
+		rf.arc(70, 70, 10, 0, 180, 8)
+		rf.pie(70, 70, 10, 180, 270, true, 8)
+		rf.rrect(5, 80, 30, 95, 4, 8)
+		rf.rrectfill(35, 80, 55, 95, 4, 8)
+		rf.bezier_quad(0, 99, 50, 60, 99, 99, 8)
+		rf.bezier_cubic(0, 0, 30, 90, 60, -20, 99, 60, 8)
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("freeform shape script failed: %v", err)
+	}
+	if r.PGet(20, 20).R != 255 {
+		t.Errorf("rf.poly should fill using the remapped color")
+	}
+	if r.PGet(25, 50).R != 255 {
+		t.Errorf("rf.line_thick should draw")
+	}
+}
+
+func TestTLineAndMode7Functions(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(64, 48)
+	colorByIndex := func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}
+	solid := make([][]int, 8)
+	for i := range solid {
+		solid[i] = []int{3, 3, 3, 3, 3, 3, 3, 3}
+	}
+	sprites := cartio.SpriteMap{
+		"stripe": {Width: 2, Height: 1, Pixels: [][]int{{7, -1}}},
+		"solid":  {Width: 8, Height: 8, Pixels: solid},
+	}
+	Register(L, r, colorByIndex, nil, make(cartio.SFXMap), make(cartio.MusicMap), sprites, nil, nil)
+
+	// Tilemap source: tile 3 at (1, 0) covers pixels 8..15 in texture space
+	script := `
+		rf.tile_sprite(3, "solid")
+		rf.mset(1, 0, 3)
+		rf.tline(0, 0, 15, 0, 0, 0)
+		rf.tline(0, 2, 5, 2, 0, 0, 1, 0, "stripe")
+		rf.mode7(0, 0, 0, 30, 8, "stripe")
+		rf.mode7(0, 0, 45, 30, 8)
+		rf.tline(0, 4, 5, 4, 0, 0, 1, 0, "missing")
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("tline/mode7 script failed: %v", err)
+	}
+	if r.PGet(4, 0).R != 0 || r.PGet(10, 0).R != 3 {
+		t.Errorf("rf.tline should sample the tilemap in tile units, got %v and %v", r.PGet(4, 0), r.PGet(10, 0))
+	}
+	if r.PGet(0, 2).R != 7 || r.PGet(1, 2).R != 0 || r.PGet(2, 2).R != 7 {
+		t.Errorf("rf.tline should sample a wrapping sprite with transparency")
+	}
+}
+
+func TestPGetAndScreenPalette(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(16, 16)
+	colorByIndex := func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i * 10), 0, 0, 255}
+	}
+	Register(L, r, colorByIndex, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, nil)
+
+	// pget returns the drawn index; pal(c0, c1, 1) only changes what is displayed
+	script := `
+		rf.clear_i(0)
+		rf.pset(1, 1, 5)
+		rf.pal(5, 9, 1)
+		drawn = rf.pget(1, 1)
+		rf.pal(3, 4)
+		rf.pset(2, 2, 3)
+		remapped = rf.pget(2, 2)
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("pget/pal script failed: %v", err)
+	}
+	if got := L.GetGlobal("drawn"); got != lua.LNumber(5) {
+		t.Errorf("rf.pget should return index 5, got %v", got)
+	}
+	if got := L.GetGlobal("remapped"); got != lua.LNumber(4) {
+		t.Errorf("rf.pget should return the draw-remapped index 4, got %v", got)
+	}
+	if got := r.PGet(1, 1).R; got != 90 {
+		t.Errorf("screen palette should display index 5 as color 9, got R=%d", got)
+	}
+
+	// pal() with no arguments resets the screen palette too
+	if err := L.DoString(`rf.pal()`); err != nil {
+		t.Fatalf("rf.pal() failed: %v", err)
+	}
+	if got := r.PGet(1, 1).R; got != 50 {
+		t.Errorf("rf.pal() should reset the screen palette, got R=%d", got)
+	}
+}
+
+func TestPaletteEffects(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(8, 8)
+	colorByIndex := func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}
+	state := NewState()
+	RegisterWithState(L, r, colorByIndex, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, state, nil)
+
+	script := `
+		rf.clear_i(0)
+		rf.pset(0, 0, 2)
+		rf.pset(1, 0, 10)
+		rf.pal_fade(0, 1)
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("pal_fade script failed: %v", err)
+	}
+	if got := r.PGet(0, 0).R; got != 0 {
+		t.Errorf("full fade to black should display index 0, got R=%d", got)
+	}
+
+	// Flashes last the given number of engine ticks
+	if err := L.DoString(`rf.pal_fade(0, 0) rf.pal_flash(10, 1, 5)`); err != nil {
+		t.Fatalf("pal_flash failed: %v", err)
+	}
+	if got := r.PGet(1, 0).R; got != 5 {
+		t.Errorf("flash should display index 5, got R=%d", got)
+	}
+	state.Tick(1.0/60, r)
+	if got := r.PGet(1, 0).R; got != 10 {
+		t.Errorf("flash should end after one tick, got R=%d", got)
+	}
+
+	// Cycles advance with engine ticks
+	if err := L.DoString(`rf.pal_cycle(10, 11, 60)`); err != nil {
+		t.Fatalf("pal_cycle failed: %v", err)
+	}
+	state.Tick(1.0/60, r)
+	if got := r.PGet(1, 0).R; got != 11 {
+		t.Errorf("cycle should rotate index 10 to 11, got R=%d", got)
+	}
+	if got := r.PGetIndex(1, 0); got != 10 {
+		t.Errorf("effects should not change drawn indices, got %d", got)
+	}
+}
+
+func TestFonts(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(64, 64)
+	state := NewState()
+	wide := font.NewFont("wide", 10, 12)
+	wide.SetChar('a', &font.Char{W: 8, H: 10, Advance: 9, Pix: make([]bool, 80)})
+	state.SetFonts(map[string]*font.Font{"wide": wide})
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{0, 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, state, nil)
+
+	script := `
+		w0 = rf.text_width("aa")
+		ok = rf.font("wide")
+		missing = rf.font("nope")
+		w1 = rf.text_width("aa")
+		h1 = rf.text_height("a\na")
+		rf.cursor(0, 0)
+		rf.print("aa\na")
+		rf.font()
+		w2 = rf.text_width("aa")
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("font script failed: %v", err)
+	}
+	checks := map[string]lua.LValue{
+		"w0": lua.LNumber(2 * font.Advance), "ok": lua.LTrue, "missing": lua.LFalse,
+		"w1": lua.LNumber(18), "h1": lua.LNumber(22),
+		"w2": lua.LNumber(2 * font.Advance),
+	}
+	for name, want := range checks {
+		if got := L.GetGlobal(name); got != want {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+	// The cursor ends after the last line, measured in the font that printed it
+	if x, y, _ := state.GetCursor(); x != 9 || y != 12 {
+		t.Errorf("expected cursor at (9, 12), got (%d, %d)", x, y)
+	}
+}
+
+func TestPrintBox(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(64, 64)
+	state := NewState()
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), make(cartio.SpriteMap), nil, state, nil)
+
+	script := `
+		rf.clear_i(0)
+		rf.pal(3, 4)
+		h1 = rf.print_box("HI THERE", 0, 0, 30, 40, {align="center", line_spacing=2, color=2})
+		h2 = rf.print_box("{c:3}HI THERE", 0, 40, 30, 20, {wrap=false})
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("print_box script failed: %v", err)
+	}
+	if got := L.GetGlobal("h1"); got != lua.LNumber(font.Default.LineHeight+2+font.Height) {
+		t.Errorf("expected wrapped height %d, got %v", font.Default.LineHeight+2+font.Height, got)
+	}
+	if got := L.GetGlobal("h2"); got != lua.LNumber(font.Height) {
+		t.Errorf("expected single line height %d, got %v", font.Height, got)
+	}
+
+	// Inline colors go through the draw palette remap
+	found := map[int]bool{}
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			found[r.PGetIndex(x, y)] = true
+		}
+	}
+	if !found[2] || !found[4] || found[3] {
+		t.Errorf("expected colors 2 and remapped 4, got %v", found)
+	}
+}
@@ -0,0 +1,47 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+)
+
+func TestMouse(t *testing.T) {
+	input.Reset()
+	defer input.Reset()
+
+	r := rendersoft.New(32, 32)
+	state := NewState()
+	L, run := newTestLua(t, r, nil, state)
+
+	input.SetMouse(10, 12)
+	input.SetMouseButton(input.MouseLeft, true)
+	input.AddWheel(-1)
+	run(`
+		x, y, b, wheel, pressed, released = rf.mouse()
+		rf.newSprite("arrow", 2, 2)
+		rf.sprite_pset("arrow", 1, 1, 9)
+		rf.mouse_cursor("arrow", 1, 1)
+		rf.camera(5, 5)
+	`)
+	for name, want := range map[string]string{"x": "10", "y": "12", "b": "1", "wheel": "-1", "pressed": "1", "released": "0"} {
+		if got := L.GetGlobal(name).String(); got != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+
+	// The cursor is drawn in screen pixels with its hot spot on the pointer
+	state.EndFrame(r)
+	if !state.HasPointer() || r.PGetIndex(10, 12) != 9 {
+		t.Errorf("cursor pixel = %d, want 9", r.PGetIndex(10, 12))
+	}
+	if x, y := r.GetCamera(); x != 5 || y != 5 {
+		t.Errorf("camera = %d, %d after the cursor, want 5, 5", x, y)
+	}
+
+	run(`rf.mouse_cursor()`)
+	if state.HasPointer() {
+		t.Error("rf.mouse_cursor() should restore the system cursor")
+	}
+}
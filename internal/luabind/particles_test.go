@@ -0,0 +1,64 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestParticles(t *testing.T) {
+	r := rendersoft.New(32, 32)
+	sprites := cartio.SpriteMap{"dot": {Width: 3, Height: 3, Pixels: [][]int{{-1, -1, -1}, {-1, 6, -1}, {-1, -1, -1}}}}
+	state := NewState()
+	state.SetParticles(cartio.ParticleSet{
+		"spark": {Burst: 1, Life: cartio.Range{Min: 1, Max: 1}, Colors: []int{4, 5}},
+	})
+	L, run := newTestLua(t, r, sprites, state)
+
+	run(`
+		spark = rf.emitter_new("spark", 4, 4)
+		missing = rf.emitter_new("nope", 0, 0)
+		smoke = rf.emitter_new({life = {1, 1}, speed = 0, shape = "sprite", sprite = "dot", layer = 1}, 20, 20)
+		rf.emitter_burst(smoke, 1)
+		trail = rf.emitter_new({rate = 10, life = 5, colors = {9}}, 10, 10)
+	`)
+	if L.GetGlobal("missing") != lua.LNil {
+		t.Error("emitter_new should return nil for unknown emitters")
+	}
+
+	// Layers are drawn separately
+	run(`rf.clear_i(0) rf.particles_draw(0)`)
+	if r.PGetIndex(4, 4) != 4 || r.PGetIndex(20, 20) != 0 {
+		t.Errorf("layer 0: got %d at the spark and %d at the smoke", r.PGetIndex(4, 4), r.PGetIndex(20, 20))
+	}
+	run(`rf.clear_i(0) rf.particles_draw(1)`)
+	if r.PGetIndex(20, 20) != 6 || r.PGetIndex(4, 4) != 0 {
+		t.Errorf("layer 1: got %d at the smoke and %d at the spark", r.PGetIndex(20, 20), r.PGetIndex(4, 4))
+	}
+
+	// Engine ticks step particles: the color ramps, and moved emitters spawn at their new position
+	run(`rf.emitter_move(trail, 2, 30)`)
+	state.Tick(0.6, r)
+	run(`rf.clear_i(0) rf.particles_draw() n = rf.emitter_count(trail)`)
+	if r.PGetIndex(4, 4) != 5 {
+		t.Errorf("expected the spark's ramp at color 5, got %d", r.PGetIndex(4, 4))
+	}
+	if L.GetGlobal("n") != lua.LNumber(6) || r.PGetIndex(2, 30) != 9 {
+		t.Errorf("expected 6 trail particles at (2, 30), got %v and color %d", L.GetGlobal("n"), r.PGetIndex(2, 30))
+	}
+
+	// Freed emitters stop emitting
+	run(`rf.emitter_free(trail)`)
+	state.Tick(0.6, r)
+	run(`n = rf.emitter_count(trail)`)
+	if L.GetGlobal("n") != lua.LNumber(6) {
+		t.Errorf("freed emitter should keep its 6 particles without emitting, got %v", L.GetGlobal("n"))
+	}
+
+	// Bad definitions are argument errors
+	if err := L.DoString(`rf.emitter_new({shape = "cube", life = 1}, 0, 0)`); err == nil {
+		t.Error("expected an error for an unknown shape")
+	}
+}
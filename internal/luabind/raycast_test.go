@@ -0,0 +1,51 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+)
+
+func TestRaycaster(t *testing.T) {
+	r := rendersoft.New(64, 40)
+	solid := func(c int) cartio.SpriteData {
+		pix := make([][]int, 8)
+		for y := range pix {
+			pix[y] = []int{c, c, c, c, c, c, c, c}
+		}
+		return cartio.SpriteData{Width: 8, Height: 8, Pixels: pix}
+	}
+	sprites := cartio.SpriteMap{"wall": solid(5), "guard": solid(9)}
+	L, run := newTestLua(t, r, sprites, NewState())
+
+	// An 8×8 room walled with tile 1; the camera looks east from its middle
+	run(`
+		rf.tile_sprite(1, "wall")
+		for i = 0, 7 do
+			rf.mset(i, 0, 1) rf.mset(i, 7, 1) rf.mset(0, i, 1) rf.mset(7, i, 1)
+		end
+		rf.ray_colors(2, 3)
+		rf.ray_render(32, 36, 0, 90)
+	`)
+	if r.PGetIndex(32, 20) != 5 || r.PGetIndex(32, 0) != 2 || r.PGetIndex(32, 39) != 3 {
+		t.Errorf("wall %d, ceiling %d, floor %d", r.PGetIndex(32, 20), r.PGetIndex(32, 0), r.PGetIndex(32, 39))
+	}
+
+	// A billboard in front of the wall, and one outside the room behind it
+	run(`rf.ray_sprite("guard", 48, 36) rf.ray_sprite("guard", 80, 20)`)
+	if r.PGetIndex(32, 20) != 9 {
+		t.Errorf("billboard not drawn over the wall: %d", r.PGetIndex(32, 20))
+	}
+	if r.PGetIndex(20, 20) != 5 {
+		t.Errorf("billboard behind the wall drawn: %d", r.PGetIndex(20, 20))
+	}
+
+	// Maps and layers that aren't loaded are errors
+	for _, arg := range []string{`"nope"`, `9`} {
+		if err := L.DoString(`rf.ray_render(32, 36, 0, 90, ` + arg + `)`); err == nil {
+			t.Errorf("ray_render with map %s should fail", arg)
+		}
+	}
+	run(`rf.ray_render(32, 36, 0, 90, 1)`)
+}
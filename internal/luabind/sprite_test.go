@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/physics"
 	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
 	lua "github.com/yuin/gopher-lua"
 )
@@ -14,7 +16,7 @@ func TestNewSprite(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -65,7 +67,7 @@ func TestSpritePset(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -91,7 +93,7 @@ func TestSpriteLine(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -121,7 +123,7 @@ func TestSpriteRect(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -157,7 +159,7 @@ func TestSpriteRectfill(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -189,7 +191,7 @@ func TestSpriteCirc(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -228,7 +230,7 @@ func TestSpriteCircfill(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -255,7 +257,7 @@ func TestSetSpriteProperty(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -293,7 +295,7 @@ func TestSpriteProperties(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -323,7 +325,7 @@ func TestSpriteErrors(t *testing.T) {
 
 	r := rendersoft.New(480, 270)
 	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{255, 255, 255, 255} }
-	setPalette := func(name string) {}
+	setPalette := func(name string) error { return nil }
 	spritesMap := make(cartio.SpriteMap)
 
 	Register(L, r, colorByIndex, setPalette, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
@@ -350,3 +352,334 @@ func TestSpriteErrors(t *testing.T) {
 		t.Error("should error on nonexistent sprite")
 	}
 }
+
+func TestAnimations(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(16, 16)
+	sprites := cartio.SpriteMap{
+		"a": {Width: 1, Height: 1, Pixels: [][]int{{2}}},
+		"b": {Width: 1, Height: 1, Pixels: [][]int{{3}}},
+	}
+	state := NewState()
+	state.SetAnimations(cartio.AnimationMap{
+		"blink": {Duration: 100, Frames: []cartio.AnimFrame{{Sprite: "a"}, {Sprite: "b", Event: "on"}}},
+	})
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), sprites, nil, state, nil)
+
+	script := `
+		events = {}
+		a = rf.anim_new("blink")
+		missing = rf.anim_new("nope")
+		rf.anim_on_event(a, function(ev, who) table.insert(events, ev) same = (who == a) end)
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("anim script failed: %v", err)
+	}
+	if L.GetGlobal("missing") != lua.LNil {
+		t.Error("anim_new should return nil for unknown clips")
+	}
+
+	state.Tick(0.1, r) // Engine tick advances to frame 2
+	if err := L.DoString(`rf.clear_i(0) rf.anim_draw(a, 4, 5) clip, frame, sprite = rf.anim_frame(a)`); err != nil {
+		t.Fatalf("anim_draw failed: %v", err)
+	}
+	if got := r.PGetIndex(4, 5); got != 3 {
+		t.Errorf("expected frame 2 (color 3) drawn, got %d", got)
+	}
+	if L.GetGlobal("frame") != lua.LNumber(2) || L.GetGlobal("sprite") != lua.LString("b") {
+		t.Errorf("unexpected anim_frame result %v %v", L.GetGlobal("frame"), L.GetGlobal("sprite"))
+	}
+	if n := L.GetGlobal("events").(*lua.LTable).Len(); n != 1 || L.GetGlobal("same") != lua.LTrue {
+		t.Errorf("expected one event with the animator, got %d", n)
+	}
+
+	// Freed animators no longer advance
+	if err := L.DoString(`rf.anim_free(a)`); err != nil {
+		t.Fatalf("anim_free failed: %v", err)
+	}
+	state.Tick(0.1, r)
+	if err := L.DoString(`_, frame = rf.anim_frame(a)`); err != nil {
+		t.Fatal(err)
+	}
+	if L.GetGlobal("frame") != lua.LNumber(2) {
+		t.Error("freed animator should not advance")
+	}
+}
+
+func TestTilemapSprites(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(32, 32)
+	// A 4×2 sheet of 2×2 cells: cell 0 is color 2, cell 1 is color 3
+	sprites := cartio.SpriteMap{
+		"sheet": {Width: 4, Height: 2, Pixels: [][]int{{2, 2, 3, 3}, {2, 2, 3, 3}}},
+		"dot":   {Width: 1, Height: 1, Pixels: [][]int{{4}}},
+	}
+	state := NewState()
+	ts := graphics.NewTileset()
+	ts.Sheet, ts.CellW, ts.CellH = "sheet", 2, 2
+	state.SetTileset(ts)
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), sprites, nil, state, nil)
+
+	script := `
+		rf.clear_i(0)
+		rf.mset(0, 0, 1)           -- sheet cell 1
+		rf.mset(1, 0, 5)           -- sprite "dot"
+		rf.tile_sprite(5, "dot")
+		rf.fset(5, 1, true)
+		rf.fset(1, 3)
+		flags, bit = rf.fget(1), rf.fget(5, 1)
+		tw, th = rf.tile_size()
+		rf.map(0, 0, 0, 0, 2, 1)
+		rf.map(0, 0, 0, 10, 2, 1, 2)   -- only tiles with flag bit 1 (both)
+		rf.map(0, 0, 0, 20, 2, 1, 4)   -- no tiles have flag bit 2
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("tilemap script failed: %v", err)
+	}
+	if L.GetGlobal("flags") != lua.LNumber(3) || L.GetGlobal("bit") != lua.LTrue {
+		t.Errorf("unexpected fget results %v %v", L.GetGlobal("flags"), L.GetGlobal("bit"))
+	}
+	if L.GetGlobal("tw") != lua.LNumber(2) || L.GetGlobal("th") != lua.LNumber(2) {
+		t.Errorf("tile size should follow the tileset, got %v %v", L.GetGlobal("tw"), L.GetGlobal("th"))
+	}
+	if r.PGetIndex(0, 0) != 3 || r.PGetIndex(1, 1) != 3 || r.PGetIndex(2, 0) != 4 || r.PGetIndex(3, 0) != 0 {
+		t.Errorf("tiles should draw their sheet cell and sprite")
+	}
+	if r.PGetIndex(0, 10) != 3 || r.PGetIndex(2, 10) != 4 {
+		t.Errorf("layer filter should draw tiles with matching flags")
+	}
+	if r.PGetIndex(0, 20) != 0 || r.PGetIndex(2, 20) != 0 {
+		t.Errorf("layer filter should skip tiles without matching flags")
+	}
+
+	// With 8 px tiles and the camera moved right by a tile, tile (0, 0) is
+	// culled and tile (1, 0) lands at screen x 0
+	if err := L.DoString(`rf.clear_i(0) rf.tile_size(8) rf.camera(8, 0) rf.map()`); err != nil {
+		t.Fatalf("camera map script failed: %v", err)
+	}
+	if r.PGetIndex(0, 0) != 4 {
+		t.Errorf("expected tile (1, 0) at the left edge, got %d", r.PGetIndex(0, 0))
+	}
+}
+
+func TestMaps(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(32, 32)
+	sprites := cartio.SpriteMap{
+		"dot": {Width: 1, Height: 1, Pixels: [][]int{{4}}},
+	}
+	maps, err := cartio.ParseMaps([]byte(`{
+		"level": {"width": 2, "height": 1, "tile_w": 4, "tile_h": 4, "layers": [
+			{"name": "ground", "tiles": [1, 0]},
+			{"name": "top", "tiles": [0, 1]},
+			{"name": "things", "type": "objects", "objects": [
+				{"id": 3, "type": "coin", "x": 4, "y": 2, "properties": {"value": 10, "hidden": true}}
+			]}
+		]}
+	}`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := NewState()
+	state.GetTileset().SetTile(1, graphics.Tile{Sprite: "dot"})
+	state.SetMaps(maps)
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), sprites, nil, state, nil)
+
+	script := `
+		missing = rf.map_load("nope")
+		loaded = rf.map_load("level")
+		info = rf.map_info()
+		a, b, c = rf.mget(0, 0), rf.mget(1, 0, "top"), rf.mget(1, 0, 2)
+		rf.mset(0, 0, 1, "top")
+		d = rf.mget(0, 0, 2)
+		rf.clear_i(0)
+		rf.map(0, 0, 0, 0, 2, 1, "top")
+		objs = rf.map_objects()
+		none = rf.map_objects("level", "nope")
+		ok, err = rf.map_save()
+	`
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("maps script failed: %v", err)
+	}
+	if L.GetGlobal("missing") != lua.LFalse || L.GetGlobal("loaded") != lua.LTrue {
+		t.Fatal("map_load should fail for unknown maps and succeed for known ones")
+	}
+	info := L.GetGlobal("info").(*lua.LTable)
+	if info.RawGetString("name") != lua.LString("level") || info.RawGetString("tile_w") != lua.LNumber(4) {
+		t.Errorf("unexpected map_info name %v tile_w %v", info.RawGetString("name"), info.RawGetString("tile_w"))
+	}
+	if layers := info.RawGetString("layers").(*lua.LTable); layers.Len() != 2 || layers.RawGetInt(2) != lua.LString("top") {
+		t.Errorf("map_info should list the two tile layers")
+	}
+	if L.GetGlobal("a") != lua.LNumber(1) || L.GetGlobal("b") != lua.LNumber(1) || L.GetGlobal("c") != lua.LNumber(1) {
+		t.Errorf("unexpected mget results %v %v %v", L.GetGlobal("a"), L.GetGlobal("b"), L.GetGlobal("c"))
+	}
+	if L.GetGlobal("d") != lua.LNumber(1) {
+		t.Errorf("mset should write the named layer")
+	}
+	if r.PGetIndex(0, 0) != 4 || r.PGetIndex(4, 0) != 4 {
+		t.Errorf("map should draw the top layer at 4 px tiles")
+	}
+	objs := L.GetGlobal("objs").(*lua.LTable)
+	coin, _ := objs.RawGetInt(1).(*lua.LTable)
+	if objs.Len() != 1 || coin == nil || coin.RawGetString("type") != lua.LString("coin") {
+		t.Fatalf("expected one coin object, got %v", objs.RawGetInt(1))
+	}
+	props := coin.RawGetString("properties").(*lua.LTable)
+	if props.RawGetString("value") != lua.LNumber(10) || props.RawGetString("hidden") != lua.LTrue {
+		t.Errorf("object properties should keep their types")
+	}
+	if L.GetGlobal("none") != lua.LNil {
+		t.Errorf("missing object layer should give nil")
+	}
+	if L.GetGlobal("ok") != lua.LFalse || L.GetGlobal("err") == lua.LNil {
+		t.Errorf("map_save should fail without a saver")
+	}
+
+	var saved cartio.MapSet
+	state.SetMapSaver(func(maps cartio.MapSet) error {
+		saved = maps
+		return nil
+	})
+	if err := L.DoString(`ok = rf.map_save()`); err != nil {
+		t.Fatalf("map_save failed: %v", err)
+	}
+	if L.GetGlobal("ok") != lua.LTrue || saved == nil {
+		t.Fatal("map_save should call the saver")
+	}
+	if tiles := saved["level"].Layers[1].Tiles; tiles[0] != 1 || tiles[1] != 1 {
+		t.Errorf("saved top layer should include the edit, got %v", tiles)
+	}
+	if len(saved["level"].Layers[2].Objects) != 1 {
+		t.Errorf("saving should keep object layers")
+	}
+}
+
+func TestMapCollisionAndAnimatedTiles(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(16, 16)
+	sprites := cartio.SpriteMap{
+		"a": {Width: 1, Height: 1, Pixels: [][]int{{2}}},
+		"b": {Width: 1, Height: 1, Pixels: [][]int{{3}}},
+	}
+	maps := cartio.MapSet{
+		"walls": {Width: 2, Height: 1, TileW: 1, TileH: 1, Layers: []cartio.MapLayer{
+			{Name: "main", Tiles: []int{1, 0}},
+			{Name: "collision", Type: cartio.LayerObjects, Objects: []cartio.MapObject{
+				{Type: cartio.CollisionType, X: 0, Y: 0, W: 8, H: 8},
+				{Type: cartio.CollisionType, Shape: cartio.ShapeEllipse, X: 8, Y: 0, W: 4, H: 4},
+				{Type: cartio.CollisionType, Shape: cartio.ShapePolygon, Points: []float64{0, 0, 4, 0, 4, 4}},
+				{Type: "spawn", X: 1, Y: 1},
+			}},
+		}},
+		"empty": {Width: 1, Height: 1},
+	}
+	state := NewState()
+	ts := state.GetTileset()
+	ts.SetTile(1, graphics.Tile{Sprite: "a"})
+	ts.SetTile(2, graphics.Tile{Sprite: "b"})
+	ts.SetAnim(1, []graphics.TileFrame{{Tile: 1, Duration: 100}, {Tile: 2, Duration: 100}})
+	state.SetMaps(maps)
+	world := physics.NewWorld(0, 0)
+	RegisterWithState(L, r, func(i int) (rgba [4]uint8) {
+		return [4]uint8{uint8(i), 0, 0, 255}
+	}, nil, make(cartio.SFXMap), make(cartio.MusicMap), sprites, world, state, nil)
+
+	if err := L.DoString(`rf.map_load("walls") objs = rf.map_objects() rf.map()`); err != nil {
+		t.Fatalf("map script failed: %v", err)
+	}
+	if n := len(state.MapBodies()); n != 3 {
+		t.Fatalf("expected 3 static bodies for the collision objects, got %d", n)
+	}
+	poly := L.GetGlobal("objs").(*lua.LTable).RawGetInt(3).(*lua.LTable)
+	if poly.RawGetString("shape") != lua.LString("polygon") || poly.RawGetString("points").(*lua.LTable).Len() != 6 {
+		t.Errorf("map_objects should return shapes and points")
+	}
+	if r.PGetIndex(0, 0) != 2 {
+		t.Errorf("animated tile should start on its first frame, got %d", r.PGetIndex(0, 0))
+	}
+
+	state.Tick(0.15, r)
+	if err := L.DoString(`rf.map()`); err != nil {
+		t.Fatalf("map failed: %v", err)
+	}
+	if r.PGetIndex(0, 0) != 3 {
+		t.Errorf("animated tile should advance with engine ticks, got %d", r.PGetIndex(0, 0))
+	}
+
+	if err := L.DoString(`rf.map_load("empty")`); err != nil {
+		t.Fatalf("map_load failed: %v", err)
+	}
+	if n := len(state.MapBodies()); n != 0 {
+		t.Errorf("loading another map should remove the old bodies, got %d", n)
+	}
+}
+
+func TestSprBlit(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	r := rendersoft.New(64, 64)
+	colorByIndex := func(i int) (rgba [4]uint8) { return [4]uint8{uint8(i), uint8(i), uint8(i), 255} }
+	spritesMap := make(cartio.SpriteMap)
+	Register(L, r, colorByIndex, func(string) error { return nil }, make(cartio.SFXMap), make(cartio.MusicMap), spritesMap, nil, nil)
+
+	at := func(x, y int) int { return r.PGetIndex(x, y) }
+	run := func(code string) {
+		t.Helper()
+		if err := L.DoString(code); err != nil {
+			t.Fatalf("Lua error: %v", err)
+		}
+	}
+
+	// 4×2 sprite: left column 7, right column 9, the rest transparent
+	run(`
+		rf.newSprite("s", 4, 2)
+		rf.sprite_pset("s", 0, 0, 7)
+		rf.sprite_pset("s", 0, 1, 7)
+		rf.sprite_pset("s", 3, 0, 9)
+		rf.sprite_pset("s", 3, 1, 9)
+		rf.spr("s", 10, 10)
+	`)
+	if at(10, 10) != 7 || at(13, 11) != 9 || at(11, 10) != 0 {
+		t.Fatalf("spr: got %d %d %d", at(10, 10), at(13, 11), at(11, 10))
+	}
+
+	// Flipping, camera and transparency
+	run(`rf.clear_i(0) rf.camera(5, 0) rf.spr("s", 15, 20, true) rf.camera()`)
+	if at(10, 20) != 9 || at(13, 20) != 7 || at(11, 20) != 0 {
+		t.Fatalf("flipped spr: got %d %d %d", at(10, 20), at(13, 20), at(11, 20))
+	}
+
+	// Remapping rebuilds the cached pixels, and resetting it restores them
+	run(`rf.clear_i(0) rf.pal(7, 8) rf.spr("s", 0, 0) rf.pal() rf.spr("s", 0, 4)`)
+	if at(0, 0) != 8 || at(0, 4) != 7 {
+		t.Fatalf("remapped spr: got %d, then %d", at(0, 0), at(0, 4))
+	}
+
+	// Editing the sprite is seen by the next draw
+	run(`rf.clear_i(0) rf.sprite_pset("s", 1, 0, 5) rf.spr("s", 0, 0)`)
+	if at(1, 0) != 5 {
+		t.Fatalf("edited spr: got %d", at(1, 0))
+	}
+
+	// Unscaled sspr regions mirror across the whole sprite when flipped, like the scaled path
+	run(`rf.clear_i(0) rf.sspr("s", 0, 0, 2, 2, 0, 0) rf.sspr("s", 0, 0, 2, 2, 0, 4, 2, 2, true)`)
+	if at(0, 0) != 7 || at(1, 0) != 5 || at(0, 4) != 9 || at(1, 4) != 0 {
+		t.Fatalf("sspr: got %d %d / %d %d", at(0, 0), at(1, 0), at(0, 4), at(1, 4))
+	}
+}
@@ -0,0 +1,59 @@
+package luabind
+
+import "github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+
+// spriteCache keeps sprites' pixels flattened and resolved through the draw
+// palette remapping, ready for Renderer.Blit. An entry is rebuilt when the
+// remapping changes and dropped when its sprite is edited. Palette color
+// changes need no rebuild: the renderer resolves indices to colors itself.
+type spriteCache struct {
+	state  *State
+	images map[string]*spriteImage
+}
+
+// spriteImage is a sprite's remapped palette indices, row-major (-1 = transparent)
+type spriteImage struct {
+	pix  []int
+	w, h int
+	gen  int // State.RemapGen the pixels were resolved with
+}
+
+func newSpriteCache(state *State) *spriteCache {
+	return &spriteCache{state: state, images: make(map[string]*spriteImage)}
+}
+
+// get returns the resolved pixels of the sprite called name
+func (c *spriteCache) get(name string, sprite cartio.SpriteData) *spriteImage {
+	gen := c.state.RemapGen()
+	img, ok := c.images[name]
+	if ok && img.gen == gen && img.w == sprite.Width && img.h == sprite.Height {
+		return img
+	}
+	if !ok || len(img.pix) != sprite.Width*sprite.Height {
+		img = &spriteImage{pix: make([]int, sprite.Width*sprite.Height)}
+		c.images[name] = img
+	}
+	img.w, img.h, img.gen = sprite.Width, sprite.Height, gen
+	for y := 0; y < img.h; y++ {
+		var row []int
+		if y < len(sprite.Pixels) {
+			row = sprite.Pixels[y]
+		}
+		for x := 0; x < img.w; x++ {
+			ci := -1
+			if x < len(row) && row[x] >= 0 { // -1 is transparent
+				ci = c.state.GetPalRemap(row[x])
+				if ci < 0 || ci > 255 {
+					ci = 0
+				}
+			}
+			img.pix[y*img.w+x] = ci
+		}
+	}
+	return img
+}
+
+// invalidate drops the cached pixels of a sprite after it is edited
+func (c *spriteCache) invalidate(name string) {
+	delete(c.images, name)
+}
@@ -1,28 +1,86 @@
 package luabind
 
 import (
+	"fmt"
+	"image/color"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/anim"
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
 	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/AndrewDonelson/retroforge-engine/internal/light"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+	"github.com/AndrewDonelson/retroforge-engine/internal/particles"
+	"github.com/AndrewDonelson/retroforge-engine/internal/physics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/voxel"
 )
 
 // State holds persistent state for Lua bindings (tilemap, memory, color remapping)
 type State struct {
 	tileMap   *graphics.TileMap
-	memory    []byte   // Memory for poke/peek (default 2MB like PICO-8)
-	palRemap  [256]int // Color remapping: palRemap[oldIndex] = newIndex
-	palActive bool     // Whether color remapping is active
-	cartStore []byte   // Cart storage for cstore/reload (default 64KB like PICO-8)
-	cursorX   int      // Text cursor X position
-	cursorY   int      // Text cursor Y position
-	textColor int      // Text color index (-1 = use default)
-	hasCursor bool     // Whether cursor has been set
-	hasColor  bool     // Whether color has been set
-	rngSeed   uint32   // Random number generator seed (for deterministic rnd())
+	tileset   *graphics.Tileset     // Tile index -> sprite, and tile flags
+	memory    []byte                // Memory for poke/peek (default 2MB like PICO-8)
+	palRemap  [256]int              // Color remapping: palRemap[oldIndex] = newIndex
+	palActive bool                  // Whether color remapping is active
+	remapGen  int                   // Bumped when palRemap changes, for cached sprite pixels
+	screenPal [256]int              // Screen palette set with pal(c0, c1, 1)
+	palFX     pal.Effects           // Fades, cycles and flashes layered over the screen palette
+	cartStore []byte                // Cart storage for cstore/reload (default 64KB like PICO-8)
+	cursorX   int                   // Text cursor X position
+	cursorY   int                   // Text cursor Y position
+	textColor int                   // Text color index (-1 = use default)
+	hasCursor bool                  // Whether cursor has been set
+	hasColor  bool                  // Whether color has been set
+	rngSeed   uint32                // Random number generator seed (for deterministic rnd())
+	fonts     map[string]*font.Font // Cart fonts by name (rf.font)
+	clock     float64               // Seconds of engine ticks, animates marked-up text and tiles
+	clips     cartio.AnimationMap   // Animation clips from sprites.json
+	animators []*anim.Animator      // Animators advanced every tick (rf.anim_new)
+	maps      cartio.MapSet         // Maps from maps.json (rf.map_load)
+	mapName   string                // Loaded map ("" = the default empty map)
+	layers    []mapLayer            // Tile layers of the loaded map; tileMap is the first
+	mapSaver  MapSaver              // Writes maps back to the cart folder (dev mode only)
+	mapBodies []*physics.Body       // Static bodies of the loaded map's collision objects
+	emitters  cartio.ParticleSet    // Emitter definitions from particles.json (rf.emitter_new)
+	particles *particles.System     // Particles stepped every tick
+	cameras   []*graphics.Camera2D  // Cameras stepped every tick (rf.cam_new)
+	terrains  voxel.Terrains        // Voxel terrains from terrains.json (rf.voxel_render)
+	lights    *light.System         // Lights and ambient level (rf.light_add, rf.lighting)
+	lightMap  *light.Map            // Light level per screen pixel, rendered each lit frame
+	lightFlag int                   // Tile flag of occluders (-1 = no shadows, the default)
+	lightDone bool                  // Lighting already applied this frame (rf.light_apply)
+	occluders *light.Occluders      // Occluders of the first layer, kept while occKey matches
+	occKey    occluderKey           // What occluders were built from
+	palColors []color.RGBA          // Cart palette colors, which lit colors outside the hues take
+	relit     map[color.RGBA]int    // Palette index nearest each lit color outside the hues
+	pointer   func(x, y int)        // Draws the mouse cursor sprite (rf.mouse_cursor)
+}
+
+// MapSaver writes the cart's maps (rf.map_save)
+type MapSaver func(maps cartio.MapSet) error
+
+// occluderKey identifies the map, tileset and flag occluders were built from
+type occluderKey struct {
+	tm          *graphics.TileMap
+	ts          *graphics.Tileset
+	mapVersion  int
+	tileVersion int
+	flag        int
+}
+
+// mapLayer is a tile layer of the loaded map
+type mapLayer struct {
+	name string
+	tm   *graphics.TileMap
 }
 
 // NewState creates a new state with default tilemap and memory
 func NewState() *State {
 	s := &State{
 		tileMap:   graphics.NewTileMap(256, 256), // Default 256×256 tilemap
+		tileset:   graphics.NewTileset(),         // Carts replace it with tileset.json
 		memory:    make([]byte, 2*1024*1024),     // 2MB like PICO-8
 		cartStore: make([]byte, 64*1024),         // 64KB cart storage (2x PICO-8's 32KB)
 		palRemap:  [256]int{},                    // Will be initialized to identity mapping
@@ -33,10 +91,15 @@ func NewState() *State {
 		hasCursor: false,
 		hasColor:  false,
 		rngSeed:   1, // Initial seed (PICO-8 compatible)
+		particles: particles.NewSystem(),
+		lights:    light.NewSystem(),
+		lightFlag: -1,
 	}
-	// Initialize palRemap to identity mapping
+	s.layers = []mapLayer{{name: "main", tm: s.tileMap}}
+	// Initialize palRemap and screenPal to identity mapping
 	for i := range s.palRemap {
 		s.palRemap[i] = i
+		s.screenPal[i] = i
 	}
 	return s
 }
@@ -46,6 +109,184 @@ func (s *State) GetTileMap() *graphics.TileMap {
 	return s.tileMap
 }
 
+// SetTileset sets the cart tileset; the tilemap takes its cell size as tile size
+func (s *State) SetTileset(ts *graphics.Tileset) {
+	s.tileset = ts
+	for _, l := range s.layers {
+		l.tm.SetTileSize(ts.CellW, ts.CellH)
+	}
+}
+
+// SetMaps sets the maps available to rf.map_load
+func (s *State) SetMaps(maps cartio.MapSet) {
+	s.maps = maps
+}
+
+// SetMapSaver sets the function rf.map_save uses to write maps (nil = saving disabled)
+func (s *State) SetMapSaver(save MapSaver) {
+	s.mapSaver = save
+}
+
+// LoadMap replaces the tilemap with a fresh copy of the tile layers of a named map
+func (s *State) LoadMap(name string) bool {
+	m, ok := s.maps[name]
+	if !ok {
+		return false
+	}
+	tw, th := m.TileW, m.TileH
+	if tw <= 0 || th <= 0 {
+		tw, th = s.tileset.CellW, s.tileset.CellH
+	}
+
+	var layers []mapLayer
+	for _, l := range m.Layers {
+		if l.IsObjects() {
+			continue
+		}
+		tm := graphics.NewTileMap(m.Width, m.Height)
+		tm.SetTileSize(tw, th)
+		for i, v := range l.Tiles {
+			tm.Set(i%m.Width, i/m.Width, v)
+		}
+		layers = append(layers, mapLayer{name: l.Name, tm: tm})
+	}
+	if len(layers) == 0 {
+		// Objects-only maps still get an empty tile layer to draw and edit
+		tm := graphics.NewTileMap(m.Width, m.Height)
+		tm.SetTileSize(tw, th)
+		layers = append(layers, mapLayer{name: "main", tm: tm})
+	}
+	s.mapName = name
+	s.layers = layers
+	s.tileMap = layers[0].tm
+	return true
+}
+
+// BuildMapBodies replaces the static bodies of the previous map with bodies
+// for the loaded map's collision objects (type cartio.CollisionType).
+// Rectangles become boxes, ellipses circles and polygons and polylines chains.
+func (s *State) BuildMapBodies(w *physics.World) {
+	for _, b := range s.mapBodies {
+		b.Destroy()
+	}
+	s.mapBodies = nil
+	objects, _ := s.MapObjects(s.mapName, "")
+	for _, o := range objects {
+		if o.Type != cartio.CollisionType {
+			continue
+		}
+		var b *physics.Body
+		switch o.Shape {
+		case "", cartio.ShapeRect:
+			if o.W <= 0 || o.H <= 0 {
+				continue
+			}
+			b = w.CreateStaticBody(o.X+o.W/2, o.Y+o.H/2)
+			b.CreateBoxFixture(o.W, o.H, 0)
+		case cartio.ShapeEllipse:
+			b = w.CreateStaticBody(o.X+o.W/2, o.Y+o.H/2)
+			b.CreateCircleFixture((o.W+o.H)/4, 0)
+		case cartio.ShapePolygon, cartio.ShapePolyline:
+			b = w.CreateStaticBody(o.X, o.Y)
+			b.CreateChainFixture(o.Points, o.Shape == cartio.ShapePolygon)
+		default:
+			continue
+		}
+		s.mapBodies = append(s.mapBodies, b)
+	}
+}
+
+// MapBodies returns the static bodies built for the loaded map
+func (s *State) MapBodies() []*physics.Body {
+	return s.mapBodies
+}
+
+// MapName returns the loaded map's name ("" for the default map)
+func (s *State) MapName() string {
+	return s.mapName
+}
+
+// MapLayers returns the names of the loaded map's tile layers
+func (s *State) MapLayers() []string {
+	names := make([]string, len(s.layers))
+	for i, l := range s.layers {
+		names[i] = l.name
+	}
+	return names
+}
+
+// TileLayer returns a tile layer of the loaded map by name
+func (s *State) TileLayer(name string) (*graphics.TileMap, bool) {
+	for _, l := range s.layers {
+		if l.name == name {
+			return l.tm, true
+		}
+	}
+	return nil, false
+}
+
+// TileLayerAt returns a tile layer of the loaded map by index (0-based)
+func (s *State) TileLayerAt(i int) (*graphics.TileMap, bool) {
+	if i < 0 || i >= len(s.layers) {
+		return nil, false
+	}
+	return s.layers[i].tm, true
+}
+
+// MapObjects returns the objects on a named map's object layer, or on all of
+// its object layers when layer is "". The map must exist in maps.json.
+func (s *State) MapObjects(mapName, layer string) ([]cartio.MapObject, bool) {
+	m, ok := s.maps[mapName]
+	if !ok {
+		return nil, false
+	}
+	var objects []cartio.MapObject
+	found := layer == ""
+	for _, l := range m.Layers {
+		if l.IsObjects() && (layer == "" || l.Name == layer) {
+			objects = append(objects, l.Objects...)
+			found = true
+		}
+	}
+	return objects, found
+}
+
+// SaveMap copies the loaded map's tile layers into the map set and writes
+// it with the map saver.
+func (s *State) SaveMap() error {
+	if s.mapSaver == nil {
+		return fmt.Errorf("map saving is only available in dev mode")
+	}
+	m, ok := s.maps[s.mapName]
+	if !ok {
+		return fmt.Errorf("no map loaded from maps.json")
+	}
+
+	layers := make([]cartio.MapLayer, len(m.Layers))
+	copy(layers, m.Layers)
+	for i, l := range layers {
+		tm, ok := s.TileLayer(l.Name)
+		if l.IsObjects() || !ok {
+			continue
+		}
+		tiles := make([]int, m.Width*m.Height)
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				tiles[y*m.Width+x] = tm.Get(x, y)
+			}
+		}
+		layers[i].Tiles = tiles
+	}
+	m.Layers = layers
+	s.maps[s.mapName] = m
+	return s.mapSaver(s.maps)
+}
+
+// GetTileset returns the tileset
+func (s *State) GetTileset() *graphics.Tileset {
+	return s.tileset
+}
+
 // GetMemory returns the memory array
 func (s *State) GetMemory() []byte {
 	return s.memory
@@ -54,11 +295,13 @@ func (s *State) GetMemory() []byte {
 // SetPalRemap sets color remapping: oldIndex -> newIndex
 func (s *State) SetPalRemap(oldIndex, newIndex int, p bool) {
 	if oldIndex >= 0 && oldIndex < 256 {
-		if p {
-			s.palRemap[oldIndex] = newIndex
-		} else {
-			s.palRemap[oldIndex] = oldIndex // Reset to identity
+		if !p {
+			newIndex = oldIndex // Reset to identity
+		}
+		if !s.palActive || s.palRemap[oldIndex] != newIndex {
+			s.remapGen++
 		}
+		s.palRemap[oldIndex] = newIndex
 		s.palActive = true
 	}
 }
@@ -82,6 +325,269 @@ func (s *State) ResetPalRemap() {
 	s.palActive = false
 }
 
+// RemapGen identifies the current color remapping: it changes whenever the
+// remapping does and is 0 while none is active.
+func (s *State) RemapGen() int {
+	if !s.palActive {
+		return 0
+	}
+	return s.remapGen
+}
+
+// SetScreenPal displays index as display for the whole frame
+func (s *State) SetScreenPal(index, display int) {
+	if index >= 0 && index < 256 {
+		s.screenPal[index] = display
+	}
+}
+
+// ResetScreenPal resets the screen palette to identity
+func (s *State) ResetScreenPal() {
+	for i := range s.screenPal {
+		s.screenPal[i] = i
+	}
+}
+
+// PalEffects returns the palette effects (fades, cycles, flashes)
+func (s *State) PalEffects() *pal.Effects {
+	return &s.palFX
+}
+
+// ApplyScreenPal pushes the screen palette, with effects applied, to the renderer
+func (s *State) ApplyScreenPal(r graphics.Renderer) {
+	for i, display := range s.screenPal {
+		r.SetScreenPal(i, s.palFX.Apply(display))
+	}
+}
+
+// Tick advances the clock, animators, particles, cameras and palette effects by one engine tick of dt seconds
+func (s *State) Tick(dt float64, r graphics.Renderer) {
+	s.clock += dt
+	for _, a := range s.animators {
+		a.Step(dt)
+	}
+	s.particles.Step(dt)
+	for _, c := range s.cameras {
+		c.Step(dt)
+	}
+	if !s.palFX.Active() {
+		return
+	}
+	s.palFX.Step(dt)
+	s.ApplyScreenPal(r)
+}
+
+// SetFonts sets the cart fonts available to rf.font
+func (s *State) SetFonts(fonts map[string]*font.Font) {
+	s.fonts = fonts
+}
+
+// GetFont returns a cart font by name
+func (s *State) GetFont(name string) (*font.Font, bool) {
+	f, ok := s.fonts[name]
+	return f, ok
+}
+
+// Clock returns the seconds of engine ticks, used to animate marked-up text and tiles
+func (s *State) Clock() float64 {
+	return s.clock
+}
+
+// SetAnimations sets the animation clips available to rf.anim_new
+func (s *State) SetAnimations(clips cartio.AnimationMap) {
+	s.clips = clips
+}
+
+// NewAnimator creates an animator for clip that Tick advances until RemoveAnimator
+func (s *State) NewAnimator(clip string) (*anim.Animator, bool) {
+	a, ok := anim.New(s.clips, clip)
+	if !ok {
+		return nil, false
+	}
+	s.animators = append(s.animators, a)
+	return a, true
+}
+
+// RemoveAnimator stops advancing a
+func (s *State) RemoveAnimator(a *anim.Animator) {
+	for i, other := range s.animators {
+		if other == a {
+			s.animators = append(s.animators[:i], s.animators[i+1:]...)
+			return
+		}
+	}
+}
+
+// NewCamera creates a camera for a w×h screen that Tick steps until RemoveCamera
+func (s *State) NewCamera(w, h int) *graphics.Camera2D {
+	c := graphics.NewCamera2D(w, h)
+	s.cameras = append(s.cameras, c)
+	return c
+}
+
+// RemoveCamera stops stepping c
+func (s *State) RemoveCamera(c *graphics.Camera2D) {
+	for i, other := range s.cameras {
+		if other == c {
+			s.cameras = append(s.cameras[:i], s.cameras[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetTerrains sets the voxel terrains from terrains.json
+func (s *State) SetTerrains(terrains voxel.Terrains) {
+	s.terrains = terrains
+}
+
+// Terrain returns the voxel terrain called name
+func (s *State) Terrain(name string) (*voxel.Terrain, bool) {
+	t, ok := s.terrains[name]
+	return t, ok
+}
+
+// Lights returns the lights and ambient level
+func (s *State) Lights() *light.System {
+	return s.lights
+}
+
+// SetOccluderFlag makes tiles of the first layer with flag f (0-7) cast
+// shadows; -1 (the default) turns shadows off
+func (s *State) SetOccluderFlag(f int) {
+	s.lightFlag = f
+}
+
+// SetPaletteColors sets the cart palette colors, which lit colors outside the
+// hues are matched to
+func (s *State) SetPaletteColors(colors []color.RGBA) {
+	s.palColors = colors
+	s.relit = nil
+}
+
+// occluderGrid returns the occluders of the first layer, rebuilt only when
+// the map, its tiles or their flags change
+func (s *State) occluderGrid() *light.Occluders {
+	if s.lightFlag < 0 || s.lightFlag > 7 {
+		return nil
+	}
+	key := occluderKey{s.tileMap, s.tileset, s.tileMap.Version(), s.tileset.Version(), s.lightFlag}
+	if s.occluders == nil || key != s.occKey {
+		s.occluders = light.NewOccluders(s.tileMap, s.tileset, 1<<uint(s.lightFlag))
+		s.occKey = key
+	}
+	return s.occluders
+}
+
+// relight returns the palette index nearest to c lit by step, for colors
+// outside the hues, so lighting never draws RGBA colors
+func (s *State) relight(c color.RGBA, step int) int {
+	c = light.ScaleRGBA(c, step)
+	if s.relit == nil {
+		s.relit = make(map[color.RGBA]int)
+	}
+	i, ok := s.relit[c]
+	if !ok {
+		i = palette.Nearest(s.palColors, c)
+		s.relit[c] = i
+	}
+	return i
+}
+
+// ApplyLighting shades the frame drawn so far by the lights, seen through r's
+// camera offset and zoom, unless it was already lit this frame. Hue indices
+// move along their shades; black, white and RGBA colors are scaled and take
+// the nearest palette color.
+func (s *State) ApplyLighting(r graphics.Renderer) {
+	if !s.lights.Enabled || s.lightDone {
+		return
+	}
+	s.lightDone = true
+
+	w, h := r.Width(), r.Height()
+	if s.lightMap == nil {
+		s.lightMap = light.NewMap(w, h)
+	}
+	camX, camY := r.GetCamera()
+	zoom := max(1, r.GetZoom())
+	s.lightMap.Render(s.lights, float64(camX), float64(camY), 1/float64(zoom), s.occluderGrid())
+
+	// Shade whole screen pixels
+	clipX, clipY, clipW, clipH := r.GetClip()
+	r.SetZoom(1) // Lit pixels are final, so zoomed drawing ends here for the frame
+	r.SetCamera(0, 0)
+	r.SetClip(0, 0, 0, 0)
+	defer func() {
+		r.SetCamera(camX, camY)
+		r.SetClip(clipX, clipY, clipW, clipH)
+	}()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			step := s.lightMap.Step(x, y)
+			if step == 0 {
+				continue
+			}
+			c, ok := light.Shade(r.PGetIndex(x, y), step)
+			if !ok {
+				c = s.relight(r.PGet(x, y), step)
+			}
+			r.PSet(x, y, pal.Index(c))
+		}
+	}
+}
+
+// EndFrame finishes the frame: lighting is applied if rf.light_apply didn't
+// already, and the zoom is reset, so each frame starts unzoomed until
+// rf.cam_attach
+func (s *State) EndFrame(r graphics.Renderer) {
+	s.ApplyLighting(r)
+	s.lightDone = false
+	s.drawPointer(r)
+	r.SetZoom(1)
+}
+
+// SetPointer sets the function drawing the mouse cursor at a screen pixel
+// (nil = the system cursor)
+func (s *State) SetPointer(draw func(x, y int)) {
+	s.pointer = draw
+}
+
+// HasPointer reports whether the cart draws its own mouse cursor
+func (s *State) HasPointer() bool {
+	return s.pointer != nil
+}
+
+// drawPointer draws the mouse cursor on top of the frame, in screen pixels
+func (s *State) drawPointer(r graphics.Renderer) {
+	if s.pointer == nil {
+		return
+	}
+	camX, camY := r.GetCamera()
+	clipX, clipY, clipW, clipH := r.GetClip()
+	r.SetZoom(1) // The last drawing of the frame, which EndFrame leaves unzoomed
+	r.SetCamera(0, 0)
+	r.SetClip(0, 0, 0, 0)
+	x, y, _, _, _, _ := input.Mouse()
+	s.pointer(x, y)
+	r.SetCamera(camX, camY)
+	r.SetClip(clipX, clipY, clipW, clipH)
+}
+
+// SetParticles sets the emitter definitions from particles.json
+func (s *State) SetParticles(emitters cartio.ParticleSet) {
+	s.emitters = emitters
+}
+
+// Emitter returns the emitter definition called name
+func (s *State) Emitter(name string) (cartio.EmitterDef, bool) {
+	def, ok := s.emitters[name]
+	return def, ok
+}
+
+// Particles returns the particle system Tick steps
+func (s *State) Particles() *particles.System {
+	return s.particles
+}
+
 // GetCartStore returns the cart storage array
 func (s *State) GetCartStore() []byte {
 	return s.cartStore
@@ -127,10 +127,23 @@ func RegisterStateMachine(L *lua.LState, gsm *gamestate.GameStateMachine) {
 		return 0
 	}))
 
-	// game.changeState(name)
+	// game.changeState(name, [{transition=kind, duration=seconds}])
 	L.SetField(game, "changeState", L.NewFunction(func(L *lua.LState) int {
 		name := L.CheckString(1)
-		err := gsm.ChangeState(name)
+		opts := L.OptTable(2, nil)
+		var err error
+		if opts == nil {
+			err = gsm.ChangeState(name)
+		} else {
+			t := gamestate.DefaultTransition
+			if kind, ok := opts.RawGetString("transition").(lua.LString); ok {
+				t.Kind = string(kind)
+			}
+			if d, ok := opts.RawGetString("duration").(lua.LNumber); ok {
+				t.Duration = float64(d)
+			}
+			err = gsm.ChangeStateWith(name, t)
+		}
 		if err != nil {
 			L.RaiseError("failed to change state: %v", err)
 			return 0
@@ -250,6 +263,14 @@ func RegisterStateMachine(L *lua.LState, gsm *gamestate.GameStateMachine) {
 		return 0
 	}))
 
+	// game.remap() - Open the built-in screen where players rebind the manifest's actions
+	L.SetField(game, "remap", L.NewFunction(func(L *lua.LState) int {
+		if err := gsm.Remap(); err != nil {
+			L.RaiseError("failed to open remap screen: %v", err)
+		}
+		return 0
+	}))
+
 	// Utility
 
 	// game.drawPreviousState() - Draw state underneath in stack
@@ -0,0 +1,53 @@
+package luabind
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/rendersoft"
+	"github.com/AndrewDonelson/retroforge-engine/internal/voxel"
+	lua "github.com/yuin/gopher-lua"
+)
+
+func TestVoxel(t *testing.T) {
+	r := rendersoft.New(64, 40)
+	pix := make([][]int, 8)
+	for y := range pix {
+		pix[y] = []int{9, 9, 9, 9, 9, 9, 9, 9}
+	}
+	sprites := cartio.SpriteMap{"tree": {Width: 8, Height: 8, Pixels: pix}}
+	state := NewState()
+	L, run := newTestLua(t, r, sprites, state)
+
+	// Flat ground of color 4 with one tall texel
+	flat := &voxel.Terrain{W: 16, H: 16, Height: make([]uint8, 256), Color: make([]uint8, 256)}
+	for i := range flat.Color {
+		flat.Color[i] = 4
+	}
+	flat.Height[3*16+5] = 50
+	state.SetTerrains(voxel.Terrains{"flat": flat})
+
+	// Looking east from height 10: sky above the horizon, ground below
+	run(`
+		rf.voxel_sky(2)
+		ok = rf.voxel_render("flat", 8, 8, 10, 0, 0)
+		missing = rf.voxel_render("nope", 8, 8, 10, 0, 0)
+		h = rf.voxel_height("flat", 5, 3)
+		wrapped = rf.voxel_height("flat", 21, 19)
+	`)
+	if L.GetGlobal("ok") != lua.LTrue || L.GetGlobal("missing") != lua.LFalse {
+		t.Errorf("voxel_render returned %v, %v", L.GetGlobal("ok"), L.GetGlobal("missing"))
+	}
+	if r.PGetIndex(32, 0) != 2 || r.PGetIndex(32, 39) != 4 {
+		t.Errorf("sky %d, ground %d", r.PGetIndex(32, 0), r.PGetIndex(32, 39))
+	}
+	if L.GetGlobal("h") != lua.LNumber(50) || L.GetGlobal("wrapped") != lua.LNumber(50) {
+		t.Errorf("voxel_height = %v, wrapped %v", L.GetGlobal("h"), L.GetGlobal("wrapped"))
+	}
+
+	// A billboard standing on the ground ahead
+	run(`rf.voxel_sprite("tree", 20, 8)`)
+	if r.PGetIndex(32, 30) != 9 {
+		t.Errorf("billboard not drawn: %d", r.PGetIndex(32, 30))
+	}
+}
@@ -1,44 +1,83 @@
 package pal
 
-import "image/color"
-
-// Default50: index 0=black, 1=white, rest simple gradient placeholders.
-var Default50 = func() []color.RGBA {
-    out := make([]color.RGBA, 50)
-    out[0] = color.RGBA{0,0,0,255}
-    out[1] = color.RGBA{255,255,255,255}
-    // fill remaining with a repeating pattern
-    idx := 2
-    for i := 0; i < 16 && idx < 50; i++ {
-        base := uint8((i*15)%256)
-        for s := 0; s < 3 && idx < 50; s++ {
-            out[idx] = color.RGBA{base, uint8((int(base)+40)%256), uint8((int(base)+80)%256), 255}
-            idx++
-        }
-    }
-    for idx < 50 {
-        v := uint8((idx*5)&255)
-        out[idx] = color.RGBA{v,v,v,255}
-        idx++
-    }
-    return out
-}()
+import (
+    "fmt"
+    "image/color"
+    "sort"
+    "strings"
+
+    "github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
 
+// Default50 is the default palette ("RetroForge 50"): index 0=black, 1=white,
+// then 16 hues × 3 shades.
+var Default50 = palette.Builtins()[0].RGBA()
+
+// Manager holds the named palettes (built-ins plus any registered by a cart)
+// and the active one.
 type Manager struct {
-    current []color.RGBA
+    current  []color.RGBA
+    name     string
+    palettes map[string]palette.Palette // Keyed by lower-case name
 }
 
-func NewManager() *Manager { return &Manager{current: append([]color.RGBA{}, Default50...)} }
+// NewManager returns a manager with the built-in palettes registered and
+// "RetroForge 50" active.
+func NewManager() *Manager {
+    m := &Manager{palettes: map[string]palette.Palette{}}
+    for _, p := range palette.Builtins() {
+        m.palettes[strings.ToLower(p.Name)] = p
+    }
+    m.current = append([]color.RGBA{}, Default50...)
+    m.name = palette.DefaultName
+    return m
+}
 
 func (m *Manager) Color(i int) color.RGBA {
     if i < 0 || i >= len(m.current) { return m.current[0] }
     return m.current[i]
 }
 
-func (m *Manager) Set(name string) {
-    // TODO: support multiple named palettes; for now only default
-    _ = name
-    m.current = append([]color.RGBA{}, Default50...)
+// Colors returns a copy of the current palette
+func (m *Manager) Colors() []color.RGBA { return append([]color.RGBA{}, m.current...) }
+
+// Name returns the name of the current palette
+func (m *Manager) Name() string { return m.name }
+
+// Names returns the registered palette names, sorted
+func (m *Manager) Names() []string {
+    names := make([]string, 0, len(m.palettes))
+    for _, p := range m.palettes {
+        names = append(names, p.Name)
+    }
+    sort.Strings(names)
+    return names
 }
 
+// Register adds a palette, replacing any with the same name (names are not
+// case-sensitive). The palette must be valid and named.
+func (m *Manager) Register(p palette.Palette) error {
+    if p.Name == "" {
+        return fmt.Errorf("palette has no name")
+    }
+    if err := p.IsValid(); err != nil {
+        return fmt.Errorf("palette %q: %w", p.Name, err)
+    }
+    m.palettes[strings.ToLower(p.Name)] = p
+    return nil
+}
 
+// Set makes the named palette current ("default" is "RetroForge 50"). An
+// unknown name returns an error and keeps the current palette.
+func (m *Manager) Set(name string) error {
+    if strings.EqualFold(name, "default") {
+        name = palette.DefaultName
+    }
+    p, ok := m.palettes[strings.ToLower(name)]
+    if !ok {
+        return fmt.Errorf("unknown palette %q", name)
+    }
+    m.current = p.RGBA()
+    m.name = p.Name
+    return nil
+}
@@ -1,6 +1,11 @@
 package pal
 
-import "testing"
+import (
+	"image/color"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
 
 func TestNewManager(t *testing.T) {
 	m := NewManager()
@@ -180,3 +185,58 @@ func TestColorAllIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexRGBA(t *testing.T) {
+	r, g, b, a := Index(1).RGBA()
+	if r != 0xffff || g != 0xffff || b != 0xffff || a != 0xffff {
+		t.Fatalf("Index(1) should report white, got %d %d %d %d", r, g, b, a)
+	}
+
+	// Out of range indices report index 0
+	r, g, b, _ = Index(200).RGBA()
+	if r != 0 || g != 0 || b != 0 {
+		t.Fatalf("Index(200) should report black, got %d %d %d", r, g, b)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	m := NewManager()
+	if m.Name() != palette.RetroForge50 {
+		t.Fatalf("default palette = %q", m.Name())
+	}
+	for _, name := range []string{"SNES 50", "snes 50", "RetroForge 50", "Default"} {
+		if err := m.Set(name); err != nil {
+			t.Errorf("Set(%q): %v", name, err)
+		}
+	}
+
+	custom := palette.Builtins()[0]
+	custom.Name = "Dusk"
+	custom.Colors[2] = "#123456"
+	if err := m.Register(custom); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := m.Set("dusk"); err != nil || m.Name() != "Dusk" {
+		t.Fatalf("Set(dusk) = %v, name %q", err, m.Name())
+	}
+	if c := m.Color(2); c != (color.RGBA{0x12, 0x34, 0x56, 255}) {
+		t.Errorf("Color(2) = %v", c)
+	}
+
+	// Unknown names fail and keep the current palette
+	if err := m.Set("Nope"); err == nil || m.Name() != "Dusk" {
+		t.Errorf("Set(Nope) = %v, name %q", err, m.Name())
+	}
+
+	// Invalid or unnamed palettes are rejected
+	custom.Colors[3] = "red"
+	if err := m.Register(custom); err == nil {
+		t.Error("expected an error for an invalid color")
+	}
+	if err := m.Register(palette.Palette{}); err == nil {
+		t.Error("expected an error for an unnamed palette")
+	}
+	if len(m.Names()) != len(palette.Builtins())+1 {
+		t.Errorf("Names = %v", m.Names())
+	}
+}
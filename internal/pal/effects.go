@@ -0,0 +1,158 @@
+package pal
+
+import (
+	"math"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// Effects animates the screen palette: whole-screen fades, color cycling and
+// short flashes. The engine calls Step once per tick; Apply maps a drawn index
+// to the index displayed this frame.
+type Effects struct {
+	fadeTarget int
+	fadeT      float64 // 0 = no fade, 1 = fully faded to fadeTarget
+	cycles     []cycle
+	flashes    []flash
+}
+
+type cycle struct {
+	start, end int
+	speed      float64 // Steps per second (negative runs backwards)
+	phase      float64
+}
+
+type flash struct {
+	index, color int
+	frames       int // Ticks remaining
+}
+
+// Fade moves every color t of the way (0..1) toward target, stepping through
+// each hue's shades. t = 0 turns the fade off.
+func (e *Effects) Fade(target int, t float64) {
+	e.fadeTarget = target
+	e.fadeT = math.Max(0, math.Min(1, t))
+}
+
+// Cycle rotates the colors of indices start..end at speed steps per second.
+// Calling it again for the same range changes the speed; speed 0 stops it.
+func (e *Effects) Cycle(start, end int, speed float64) {
+	if start > end {
+		start, end = end, start
+	}
+	for i := range e.cycles {
+		if e.cycles[i].start == start && e.cycles[i].end == end {
+			if speed == 0 {
+				e.cycles = append(e.cycles[:i], e.cycles[i+1:]...)
+			} else {
+				e.cycles[i].speed = speed
+			}
+			return
+		}
+	}
+	if speed != 0 && start < end {
+		e.cycles = append(e.cycles, cycle{start: start, end: end, speed: speed})
+	}
+}
+
+// Flash shows index as color for the given number of ticks.
+func (e *Effects) Flash(index, frames, color int) {
+	for i := range e.flashes {
+		if e.flashes[i].index == index {
+			e.flashes[i].color = color
+			e.flashes[i].frames = frames
+			return
+		}
+	}
+	if frames > 0 {
+		e.flashes = append(e.flashes, flash{index: index, color: color, frames: frames})
+	}
+}
+
+// Reset stops all effects.
+func (e *Effects) Reset() {
+	*e = Effects{}
+}
+
+// Active reports whether any effect changes the screen palette.
+func (e *Effects) Active() bool {
+	return e.fadeT > 0 || len(e.cycles) > 0 || len(e.flashes) > 0
+}
+
+// Step advances cycles by dt seconds and flashes by one tick.
+func (e *Effects) Step(dt float64) {
+	for i := range e.cycles {
+		e.cycles[i].phase += e.cycles[i].speed * dt
+	}
+	live := e.flashes[:0]
+	for _, f := range e.flashes {
+		f.frames--
+		if f.frames > 0 {
+			live = append(live, f)
+		}
+	}
+	e.flashes = live
+}
+
+// Apply returns the index displayed for drawn index i.
+func (e *Effects) Apply(i int) int {
+	out := i
+	for _, c := range e.cycles {
+		if i >= c.start && i <= c.end {
+			n := c.end - c.start + 1
+			off := int(math.Floor(c.phase)) % n
+			out = c.start + ((i-c.start+off)%n+n)%n
+			break
+		}
+	}
+	for _, f := range e.flashes {
+		if f.index == i {
+			out = f.color
+		}
+	}
+	if e.fadeT > 0 {
+		chain := FadeChain(out, e.fadeTarget)
+		out = chain[int(math.Round(e.fadeT*float64(len(chain)-1)))]
+	}
+	return out
+}
+
+// FadeChain lists the indices a color passes through while fading to target:
+// it walks its hue's shades toward the target's brightness (shadow for black,
+// highlight for white) before switching to the target itself.
+func FadeChain(from, target int) []int {
+	chain := []int{from}
+	if from == target {
+		return chain
+	}
+	if hue, shade, ok := palette.HueShade(from); ok {
+		want := brightness(target)
+		for (want < 0 && shade < palette.Shadow) || (want > 0 && shade > palette.Highlight) || (want == 0 && shade != palette.Base) {
+			if want < 0 || (want == 0 && shade < palette.Base) {
+				shade++
+			} else {
+				shade--
+			}
+			chain = append(chain, palette.IndexOf(hue, shade))
+		}
+	}
+	if chain[len(chain)-1] != target {
+		chain = append(chain, target)
+	}
+	return chain
+}
+
+// brightness ranks an index's shade: -1 darker than base, 0 base, 1 lighter
+func brightness(index int) int {
+	switch index {
+	case palette.Black:
+		return -1
+	case palette.White:
+		return 1
+	}
+	_, shade, ok := palette.HueShade(index)
+	if !ok {
+		return 0
+	}
+	return palette.Base - shade
+}
@@ -0,0 +1,79 @@
+package pal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFadeChain(t *testing.T) {
+	// Hue 0: 2 = highlight, 3 = base, 4 = shadow
+	cases := []struct {
+		from, target int
+		want         []int
+	}{
+		{2, 0, []int{2, 3, 4, 0}},
+		{4, 1, []int{4, 3, 2, 1}},
+		{2, 7, []int{2, 3, 4, 7}}, // 7 is hue 1's shadow: darken to shadow, then switch hue
+		{1, 0, []int{1, 0}},
+		{5, 5, []int{5}},
+	}
+	for _, c := range cases {
+		if got := FadeChain(c.from, c.target); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("FadeChain(%d, %d) = %v, want %v", c.from, c.target, got, c.want)
+		}
+	}
+}
+
+func TestEffectsFade(t *testing.T) {
+	var e Effects
+	e.Fade(0, 0.5)
+	if !e.Active() {
+		t.Fatalf("fade should be active")
+	}
+	if got := e.Apply(2); got != 4 {
+		t.Fatalf("half fade of highlight to black should be shadow, got %d", got)
+	}
+	e.Fade(0, 1)
+	if got := e.Apply(2); got != 0 {
+		t.Fatalf("full fade should reach black, got %d", got)
+	}
+	e.Fade(0, 0)
+	if e.Active() || e.Apply(2) != 2 {
+		t.Fatalf("t = 0 should turn the fade off")
+	}
+}
+
+func TestEffectsCycle(t *testing.T) {
+	var e Effects
+	e.Cycle(10, 12, 2) // Two steps per second
+	e.Step(0.5)
+	if got := e.Apply(10); got != 11 {
+		t.Fatalf("expected 10 -> 11 after one step, got %d", got)
+	}
+	if got := e.Apply(12); got != 10 {
+		t.Fatalf("expected 12 to wrap to 10, got %d", got)
+	}
+	if got := e.Apply(13); got != 13 {
+		t.Fatalf("indices outside the range are unchanged, got %d", got)
+	}
+	e.Cycle(10, 12, 0)
+	if e.Active() {
+		t.Fatalf("speed 0 should remove the cycle")
+	}
+}
+
+func TestEffectsFlash(t *testing.T) {
+	var e Effects
+	e.Flash(8, 2, 1)
+	if got := e.Apply(8); got != 1 {
+		t.Fatalf("flashing index should show white, got %d", got)
+	}
+	e.Step(1.0 / 60)
+	if got := e.Apply(8); got != 1 {
+		t.Fatalf("flash should last 2 frames")
+	}
+	e.Step(1.0 / 60)
+	if e.Active() || e.Apply(8) != 8 {
+		t.Fatalf("flash should end after 2 frames")
+	}
+}
@@ -0,0 +1,20 @@
+package pal
+
+import "image/color"
+
+// Size is the number of colors in a cart palette.
+const Size = 50
+
+// Index is a palette index that can be passed anywhere a color.Color is
+// accepted. Indexed renderers store it as-is and resolve it through their
+// own palette; RGBA reports the matching Default50 color.
+type Index uint8
+
+func (i Index) RGBA() (r, g, b, a uint32) {
+	if int(i) >= len(Default50) {
+		return Default50[0].RGBA()
+	}
+	return Default50[i].RGBA()
+}
+
+var _ color.Color = Index(0)
@@ -0,0 +1,126 @@
+package palette
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+)
+
+// Shade derivation: highlights blend toward a warm white and shadows toward a
+// cool near-black, so shades shift hue the way pixel artists shade by hand.
+var (
+	highlightTint = color.RGBA{255, 248, 224, 255}
+	shadowTint    = color.RGBA{24, 16, 48, 255}
+)
+
+const (
+	highlightMix = 0.4
+	shadowMix    = 0.5
+)
+
+// RGBA returns the palette's colors. Colors must be valid (see IsValid).
+func (p Palette) RGBA() []color.RGBA {
+	out := make([]color.RGBA, len(p.Colors))
+	for i, c := range p.Colors {
+		out[i], _ = ParseHex(c)
+	}
+	return out
+}
+
+// ParseHex parses a #RRGGBB color.
+func ParseHex(s string) (color.RGBA, error) {
+	if !isHex(s) {
+		return color.RGBA{}, fmt.Errorf("%w: %q", ErrHexFormat, s)
+	}
+	v, _ := strconv.ParseUint(s[1:], 16, 32)
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// Hex formats c as #rrggbb.
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	m := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5) }
+	return color.RGBA{m(a.R, b.R), m(a.G, b.G), m(a.B, b.B), 255}
+}
+
+// Shades derives a hue's highlight and shadow from its base color.
+func Shades(base color.RGBA) (highlight, shadow color.RGBA) {
+	return mix(base, highlightTint, highlightMix), mix(base, shadowTint, shadowMix)
+}
+
+// FromHues builds a palette from black, white and 16 base colors, deriving
+// each hue's highlight and shadow.
+func FromHues(name string, black, white color.RGBA, hues [NumHues]color.RGBA) Palette {
+	p := Palette{Name: name}
+	p.Colors[Black], p.Colors[White] = Hex(black), Hex(white)
+	for h, base := range hues {
+		highlight, shadow := Shades(base)
+		p.Colors[IndexOf(h, Highlight)] = Hex(highlight)
+		p.Colors[IndexOf(h, Base)] = Hex(base)
+		p.Colors[IndexOf(h, Shadow)] = Hex(shadow)
+	}
+	return p
+}
+
+// fromHex is FromHues for built-in palettes written as hex strings.
+func fromHex(name, black, white string, hues [NumHues]string) Palette {
+	var rgba [NumHues]color.RGBA
+	for i, h := range hues {
+		rgba[i], _ = ParseHex(h)
+	}
+	b, _ := ParseHex(black)
+	w, _ := ParseHex(white)
+	return FromHues(name, b, w, rgba)
+}
+
+// Names of the built-in palettes.
+const (
+	RetroForge50 = "RetroForge 50"
+	SNES50       = "SNES 50"
+	PICO8        = "PICO-8"
+	SuperMario50 = "Super Mario 50"
+	Grayscale50  = "Grayscale 50"
+	DefaultName  = RetroForge50
+)
+
+// Builtins returns the built-in palettes; the first is the default.
+func Builtins() []Palette {
+	return []Palette{
+		// Evenly spread, fairly saturated hues with a brown and a slate for ground and stone
+		fromHex(RetroForge50, "#000000", "#ffffff", [NumHues]string{
+			"#e03c3c", "#f07830", "#f4b03c", "#f0e050", "#a0d848", "#48b848", "#30a088", "#38c0d0",
+			"#4890e0", "#3858d0", "#5840b0", "#8848c0", "#c048a8", "#f07098", "#a0603c", "#607088",
+		}),
+		// 15-bit colors (multiples of 8) in the rich, slightly muted style of 16-bit console games
+		fromHex(SNES50, "#080808", "#f8f8f8", [NumHues]string{
+			"#d82828", "#e86818", "#f8a830", "#f8e060", "#98d030", "#40a840", "#208868", "#28b8b0",
+			"#3890f8", "#2850c8", "#5838a8", "#9050c8", "#c83898", "#f888a8", "#906038", "#687890",
+		}),
+		// PICO-8's 16 colors as the bases
+		fromHex(PICO8, "#000000", "#fff1e8", [NumHues]string{
+			"#1d2b53", "#7e2553", "#008751", "#ab5236", "#5f574f", "#c2c3c7", "#ff004d", "#ffa300",
+			"#ffec27", "#00e436", "#29adff", "#83769c", "#ff77a8", "#ffccaa", "#742f29", "#125359",
+		}),
+		// Sky, brick, pipe and coin colors of 8-bit platformers
+		fromHex(SuperMario50, "#000000", "#fcfcfc", [NumHues]string{
+			"#5c94fc", "#c84c0c", "#fc9838", "#e45c10", "#00a800", "#80d010", "#fcbcb0", "#f8d878",
+			"#d82800", "#0058f8", "#3cbcfc", "#a4e4fc", "#940084", "#f878f8", "#7c7c7c", "#503000",
+		}),
+		grayscale(),
+	}
+}
+
+// grayscale spreads 48 grays over the hues from light to dark, so each hue's
+// highlight, base and shadow still get darker in order.
+func grayscale() Palette {
+	p := Palette{Name: Grayscale50}
+	p.Colors[Black], p.Colors[White] = "#000000", "#ffffff"
+	for i := 0; i < NumHues*NumShades; i++ {
+		v := uint8(250 - i*5)
+		p.Colors[FirstHue+i] = Hex(color.RGBA{v, v, v, 255})
+	}
+	return p
+}
@@ -0,0 +1,64 @@
+package palette
+
+import (
+	"image/color"
+	"testing"
+)
+
+func brightness(c color.RGBA) int { return 299*int(c.R) + 587*int(c.G) + 114*int(c.B) }
+
+func TestBuiltins(t *testing.T) {
+	names := map[string]bool{}
+	for _, p := range Builtins() {
+		if err := p.IsValid(); err != nil {
+			t.Fatalf("%s: %v", p.Name, err)
+		}
+		names[p.Name] = true
+		rgba := p.RGBA()
+		for h := 0; h < NumHues; h++ {
+			hi, base, lo := rgba[IndexOf(h, Highlight)], rgba[IndexOf(h, Base)], rgba[IndexOf(h, Shadow)]
+			if !(brightness(hi) > brightness(base) && brightness(base) > brightness(lo)) {
+				t.Errorf("%s hue %d: shades not ordered light to dark: %v %v %v", p.Name, h, hi, base, lo)
+			}
+		}
+	}
+	for _, n := range []string{RetroForge50, SNES50, PICO8, SuperMario50, Grayscale50} {
+		if !names[n] {
+			t.Errorf("missing built-in %q", n)
+		}
+	}
+	if Builtins()[0].Name != DefaultName {
+		t.Errorf("first built-in is %q, want %q", Builtins()[0].Name, DefaultName)
+	}
+}
+
+func TestHex(t *testing.T) {
+	c, err := ParseHex("#FF8000")
+	if err != nil || c != (color.RGBA{255, 128, 0, 255}) {
+		t.Fatalf("ParseHex = %v, %v", c, err)
+	}
+	if Hex(c) != "#ff8000" {
+		t.Errorf("Hex = %q", Hex(c))
+	}
+	if _, err := ParseHex("ff8000"); err == nil {
+		t.Error("expected an error without #")
+	}
+}
+
+func TestFromHues(t *testing.T) {
+	var hues [NumHues]color.RGBA
+	for i := range hues {
+		hues[i] = color.RGBA{uint8(i * 16), 100, 200, 255}
+	}
+	p := FromHues("Test", color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, hues)
+	if err := p.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Colors[IndexOf(3, Base)] != Hex(hues[3]) {
+		t.Errorf("base of hue 3 = %s, want %s", p.Colors[IndexOf(3, Base)], Hex(hues[3]))
+	}
+	hi, lo := Shades(hues[3])
+	if p.Colors[IndexOf(3, Highlight)] != Hex(hi) || p.Colors[IndexOf(3, Shadow)] != Hex(lo) {
+		t.Errorf("hue 3 shades = %s %s", p.Colors[IndexOf(3, Highlight)], p.Colors[IndexOf(3, Shadow)])
+	}
+}
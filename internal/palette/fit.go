@@ -0,0 +1,205 @@
+package palette
+
+import (
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Fit turns any list of colors into a RetroForge palette. 50 colors are kept
+// as they are, assumed to be in RetroForge order already. Otherwise the
+// darkest and lightest colors become black and white (or pure black and
+// white when the source has none dark or light enough) and the rest are
+// reduced or blended to 16 base colors, sorted by hue with grays last, whose
+// highlight and shadow shades are derived.
+func Fit(name string, colors []color.RGBA) Palette {
+	if len(colors) == len(Palette{}.Colors) {
+		p := Palette{Name: name}
+		for i, c := range colors {
+			p.Colors[i] = Hex(c)
+		}
+		return p
+	}
+
+	pool := unique(colors)
+	black, white := color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}
+	if i := darkest(pool); i >= 0 && luma(pool[i]) < 64 {
+		black = pool[i]
+		pool = append(pool[:i], pool[i+1:]...)
+	}
+	if i := lightest(pool); i >= 0 && luma(pool[i]) > 192 {
+		white = pool[i]
+		pool = append(pool[:i], pool[i+1:]...)
+	}
+
+	if len(pool) > NumHues {
+		pool = spread(pool, NumHues)
+	}
+	for len(pool) < NumHues {
+		pool = fill(pool)
+	}
+	sortByHue(pool)
+
+	var hues [NumHues]color.RGBA
+	copy(hues[:], pool)
+	return FromHues(name, black, white, hues)
+}
+
+func unique(colors []color.RGBA) []color.RGBA {
+	seen := make(map[color.RGBA]bool)
+	var out []color.RGBA
+	for _, c := range colors {
+		c.A = 255
+		if !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// luma is perceived brightness, 0..255.
+func luma(c color.RGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+func darkest(colors []color.RGBA) int {
+	best := -1
+	for i, c := range colors {
+		if best < 0 || luma(c) < luma(colors[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func lightest(colors []color.RGBA) int {
+	best := -1
+	for i, c := range colors {
+		if best < 0 || luma(c) > luma(colors[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// distance is a perceptually weighted RGB distance ("redmean").
+func distance(a, b color.RGBA) float64 {
+	rm := (float64(a.R) + float64(b.R)) / 2
+	dr, dg, db := float64(a.R)-float64(b.R), float64(a.G)-float64(b.G), float64(a.B)-float64(b.B)
+	return math.Sqrt((2+rm/256)*dr*dr + 4*dg*dg + (2+(255-rm)/256)*db*db)
+}
+
+// Nearest returns the index of the color in colors closest to c
+func Nearest(colors []color.RGBA, c color.Color) int {
+	r, g, b, _ := c.RGBA()
+	best, bestDist := 0, -1
+	for i, p := range colors {
+		dr := int(r>>8) - int(p.R)
+		dg := int(g>>8) - int(p.G)
+		db := int(b>>8) - int(p.B)
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// hsv returns c's hue (degrees), saturation and value (0..1).
+func hsv(c color.RGBA) (h, s, v float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	v = hi
+	if hi == 0 {
+		return 0, 0, v
+	}
+	d := hi - lo
+	s = d / hi
+	if d == 0 {
+		return 0, s, v
+	}
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
+// spread picks n colors that are as different from each other as possible,
+// starting from the most saturated.
+func spread(colors []color.RGBA, n int) []color.RGBA {
+	first := 0
+	for i, c := range colors {
+		_, s, v := hsv(c)
+		if _, fs, fv := hsv(colors[first]); s*v > fs*fv {
+			first = i
+		}
+	}
+	picked := []color.RGBA{colors[first]}
+	nearest := make([]float64, len(colors)) // Distance to the closest picked color
+	for i, c := range colors {
+		nearest[i] = distance(c, colors[first])
+	}
+	for len(picked) < n {
+		far := 0
+		for i := range colors {
+			if nearest[i] > nearest[far] {
+				far = i
+			}
+		}
+		picked = append(picked, colors[far])
+		for i, c := range colors {
+			nearest[i] = math.Min(nearest[i], distance(c, colors[far]))
+		}
+	}
+	return picked
+}
+
+// fill adds one color: the blend of the two hue neighbours furthest apart.
+func fill(colors []color.RGBA) []color.RGBA {
+	switch len(colors) {
+	case 0:
+		return []color.RGBA{{128, 128, 128, 255}}
+	case 1:
+		return append(colors, mix(colors[0], color.RGBA{255, 255, 255, 255}, 0.5))
+	}
+	sortByHue(colors)
+	at, best := 0, -1.0
+	for i := range colors {
+		if d := distance(colors[i], colors[(i+1)%len(colors)]); d > best {
+			at, best = i, d
+		}
+	}
+	mid := mix(colors[at], colors[(at+1)%len(colors)], 0.5)
+	if best == 0 {
+		mid = mix(colors[at], color.RGBA{255, 255, 255, 255}, 0.5)
+	}
+	out := append([]color.RGBA{}, colors[:at+1]...)
+	out = append(out, mid)
+	return append(out, colors[at+1:]...)
+}
+
+// sortByHue orders colors around the color wheel from red, with grays
+// (low saturation) last from light to dark.
+func sortByHue(colors []color.RGBA) {
+	const gray = 0.15
+	sort.SliceStable(colors, func(i, j int) bool {
+		hi, si, vi := hsv(colors[i])
+		hj, sj, vj := hsv(colors[j])
+		if (si < gray) != (sj < gray) {
+			return sj < gray
+		}
+		if si < gray {
+			return vi > vj
+		}
+		return hi < hj
+	})
+}
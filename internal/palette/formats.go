@@ -0,0 +1,248 @@
+package palette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Palette file formats, by extension.
+const (
+	FormatGPL  = ".gpl"  // GIMP palette
+	FormatHex  = ".hex"  // Lospec: one RRGGBB per line
+	FormatPAL  = ".pal"  // JASC-PAL (Paint Shop Pro, Lospec); Paint.NET lists are read too
+	FormatTXT  = ".txt"  // Paint.NET: one AARRGGBB per line, ; comments
+	FormatPNG  = ".png"  // Swatches: every distinct color, in reading order
+	FormatJSON = ".json" // RetroForge palette.json
+)
+
+// ErrFormat is returned for an unknown file extension.
+var ErrFormat = errors.New("unknown palette format")
+
+// Format returns the format of a palette file name (its lower-case
+// extension), or ErrFormat.
+func Format(name string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch ext {
+	case FormatGPL, FormatHex, FormatPAL, FormatTXT, FormatPNG, FormatJSON:
+		return ext, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrFormat, ext)
+}
+
+// Decode reads the colors of a palette file in the given format. name is the
+// palette name stored in the file (GIMP and RetroForge palettes only).
+func Decode(format string, r io.Reader) (colors []color.RGBA, name string, err error) {
+	switch format {
+	case FormatGPL:
+		return decodeGPL(r)
+	case FormatHex:
+		colors, err = decodeHex(r, 6)
+	case FormatPAL:
+		data, rerr := io.ReadAll(r)
+		if rerr != nil {
+			return nil, "", rerr
+		}
+		if bytes.HasPrefix(data, []byte("JASC-PAL")) {
+			colors, err = decodeJASC(bytes.NewReader(data))
+		} else {
+			colors, err = decodeHex(bytes.NewReader(data), 8)
+		}
+	case FormatTXT:
+		colors, err = decodeHex(r, 8)
+	case FormatPNG:
+		colors, err = decodePNG(r)
+	case FormatJSON:
+		var p Palette
+		if err := json.NewDecoder(r).Decode(&p); err != nil {
+			return nil, "", err
+		}
+		if err := p.IsValid(); err != nil {
+			return nil, "", err
+		}
+		return p.RGBA(), p.Name, nil
+	default:
+		return nil, "", fmt.Errorf("%w: %q", ErrFormat, format)
+	}
+	return colors, "", err
+}
+
+// Encode writes p in the given format. PNGs have one pixel per color.
+func Encode(format string, w io.Writer, p Palette) error {
+	colors := p.RGBA()
+	switch format {
+	case FormatGPL:
+		var b strings.Builder
+		fmt.Fprintf(&b, "GIMP Palette\nName: %s\nColumns: %d\n#\n", p.Name, NumShades)
+		for i, c := range colors {
+			fmt.Fprintf(&b, "%3d %3d %3d\t%s\n", c.R, c.G, c.B, colorName(i))
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case FormatHex:
+		var b strings.Builder
+		for _, c := range colors {
+			b.WriteString(Hex(c)[1:] + "\n")
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case FormatPAL:
+		var b strings.Builder
+		fmt.Fprintf(&b, "JASC-PAL\r\n0100\r\n%d\r\n", len(colors))
+		for _, c := range colors {
+			fmt.Fprintf(&b, "%d %d %d\r\n", c.R, c.G, c.B)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case FormatTXT:
+		var b strings.Builder
+		fmt.Fprintf(&b, "; paint.net Palette File\n; Palette Name: %s\n; Colors: %d\n", p.Name, len(colors))
+		for _, c := range colors {
+			fmt.Fprintf(&b, "FF%02X%02X%02X\n", c.R, c.G, c.B)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case FormatPNG:
+		img := image.NewRGBA(image.Rect(0, 0, len(colors), 1))
+		for i, c := range colors {
+			img.SetRGBA(i, 0, c)
+		}
+		return png.Encode(w, img)
+	case FormatJSON:
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	return fmt.Errorf("%w: %q", ErrFormat, format)
+}
+
+// colorName labels index i for GIMP: black, white or hue n's shade.
+func colorName(i int) string {
+	hue, shade, ok := HueShade(i)
+	switch {
+	case i == Black:
+		return "Black"
+	case i == White:
+		return "White"
+	case !ok:
+		return ""
+	}
+	return fmt.Sprintf("Hue %d %s", hue+1, [NumShades]string{"highlight", "base", "shadow"}[shade])
+}
+
+// decodeGPL reads a GIMP palette: a "GIMP Palette" header, optional Name and
+// Columns lines, # comments and "R G B [name]" lines.
+func decodeGPL(r io.Reader) ([]color.RGBA, string, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() || strings.TrimSpace(sc.Text()) != "GIMP Palette" {
+		return nil, "", errors.New("not a GIMP palette")
+	}
+	var colors []color.RGBA
+	var name string
+	for line := 2; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "Columns:"):
+			continue
+		case strings.HasPrefix(text, "Name:"):
+			name = strings.TrimSpace(strings.TrimPrefix(text, "Name:"))
+			continue
+		}
+		c, err := parseRGB(strings.Fields(text))
+		if err != nil {
+			return nil, "", fmt.Errorf("line %d: %w", line, err)
+		}
+		colors = append(colors, c)
+	}
+	return colors, name, sc.Err()
+}
+
+// decodeJASC reads a JASC-PAL file: header, version, count, "R G B" lines.
+func decodeJASC(r io.Reader) ([]color.RGBA, error) {
+	sc := bufio.NewScanner(r)
+	var colors []color.RGBA
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if line <= 3 || text == "" {
+			continue
+		}
+		c, err := parseRGB(strings.Fields(text))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		colors = append(colors, c)
+	}
+	return colors, sc.Err()
+}
+
+// decodeHex reads one hex color per line, RRGGBB (digits 6) or AARRGGBB
+// (digits 8, alpha ignored), with an optional # and ; comments.
+func decodeHex(r io.Reader, digits int) ([]color.RGBA, error) {
+	sc := bufio.NewScanner(r)
+	var colors []color.RGBA
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimPrefix(strings.TrimSpace(sc.Text()), "#")
+		if text == "" || strings.HasPrefix(text, ";") {
+			continue
+		}
+		v, err := strconv.ParseUint(text, 16, 32)
+		if err != nil || len(text) != digits {
+			return nil, fmt.Errorf("line %d: %q is not a %d-digit hex color", line, text, digits)
+		}
+		colors = append(colors, color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255})
+	}
+	return colors, sc.Err()
+}
+
+// decodePNG reads every distinct opaque color of an image in reading order,
+// so swatch strips and grids of any cell size give their colors in order.
+func decodePNG(r io.Reader) ([]color.RGBA, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	seen := make(map[color.RGBA]bool)
+	var colors []color.RGBA
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			rgba := color.RGBA{c.R, c.G, c.B, 255}
+			if !seen[rgba] {
+				seen[rgba] = true
+				colors = append(colors, rgba)
+			}
+		}
+	}
+	return colors, nil
+}
+
+func parseRGB(fields []string) (color.RGBA, error) {
+	if len(fields) < 3 {
+		return color.RGBA{}, errors.New("expected R G B")
+	}
+	var v [3]uint8
+	for i := range v {
+		n, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("bad color component %q", fields[i])
+		}
+		v[i] = uint8(n)
+	}
+	return color.RGBA{v[0], v[1], v[2], 255}, nil
+}
@@ -0,0 +1,109 @@
+package palette
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	p := Builtins()[1]
+	for _, format := range []string{FormatGPL, FormatHex, FormatPAL, FormatTXT, FormatPNG, FormatJSON} {
+		var buf bytes.Buffer
+		if err := Encode(format, &buf, p); err != nil {
+			t.Fatalf("%s: Encode: %v", format, err)
+		}
+		colors, _, err := Decode(format, &buf)
+		if err != nil {
+			t.Fatalf("%s: Decode: %v", format, err)
+		}
+		if got := Fit(p.Name, colors); got != p {
+			t.Errorf("%s: round trip changed the palette:\n%v\n%v", format, got.Colors, p.Colors)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	want := []color.RGBA{{255, 0, 77, 255}, {0, 135, 81, 255}}
+	for name, tc := range map[string]struct{ format, data string }{
+		"gimp":     {FormatGPL, "GIMP Palette\nName: Two\nColumns: 2\n# comment\n255   0  77 red\n  0 135  81\n"},
+		"lospec":   {FormatHex, "ff004d\r\n#008751\r\n"},
+		"jasc":     {FormatPAL, "JASC-PAL\r\n0100\r\n2\r\n255 0 77\r\n0 135 81\r\n"},
+		"paintnet": {FormatTXT, "; paint.net Palette File\nFFFF004D\nFF008751\n"},
+		"pal list": {FormatPAL, "; Paint.NET list saved as .pal\nFFFF004D\nFF008751\n"},
+	} {
+		colors, stored, err := Decode(tc.format, strings.NewReader(tc.data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(colors) != 2 || colors[0] != want[0] || colors[1] != want[1] {
+			t.Errorf("%s: colors = %v", name, colors)
+		}
+		if name == "gimp" && stored != "Two" {
+			t.Errorf("gimp: name = %q", stored)
+		}
+	}
+
+	for name, tc := range map[string]struct{ format, data string }{
+		"no header": {FormatGPL, "255 0 0\n"},
+		"bad gimp":  {FormatGPL, "GIMP Palette\n255 0\n"},
+		"bad hex":   {FormatHex, "ff00\n"},
+		"bad png":   {FormatPNG, "nope"},
+	} {
+		if _, _, err := Decode(tc.format, strings.NewReader(tc.data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Format("colors.aco"); !errors.Is(err, ErrFormat) {
+		t.Errorf("Format(.aco) = %v", err)
+	}
+}
+
+func TestFit(t *testing.T) {
+	// PICO-8's 16 colors: black and white become indices 0 and 1, the other
+	// 14 are blended up to 16 hues
+	var pico []color.RGBA
+	for _, h := range []string{"#000000", "#1d2b53", "#7e2553", "#008751", "#ab5236", "#5f574f", "#c2c3c7", "#fff1e8",
+		"#ff004d", "#ffa300", "#ffec27", "#00e436", "#29adff", "#83769c", "#ff77a8", "#ffccaa"} {
+		c, _ := ParseHex(h)
+		pico = append(pico, c)
+	}
+	p := Fit("P", pico)
+	if err := p.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Colors[Black] != "#000000" || p.Colors[White] != "#fff1e8" {
+		t.Errorf("black, white = %s, %s", p.Colors[Black], p.Colors[White])
+	}
+	bases := map[string]bool{}
+	for h := 0; h < NumHues; h++ {
+		bases[p.Colors[IndexOf(h, Base)]] = true
+	}
+	for _, c := range pico[1:] {
+		if c != pico[7] && !bases[Hex(c)] {
+			t.Errorf("%s is not a base color", Hex(c))
+		}
+	}
+
+	// Large palettes keep 16 distinct bases; tiny ones still fill the layout
+	var many []color.RGBA
+	for i := 0; i < 64; i++ {
+		many = append(many, color.RGBA{uint8(i * 4), uint8(255 - i*4), uint8(i * 37), 255})
+	}
+	for _, colors := range [][]color.RGBA{many, {{200, 40, 40, 255}}, nil} {
+		p := Fit("X", colors)
+		if err := p.IsValid(); err != nil {
+			t.Errorf("%d colors: %v", len(colors), err)
+		}
+	}
+	bases = map[string]bool{}
+	p = Fit("X", many)
+	for h := 0; h < NumHues; h++ {
+		bases[p.Colors[IndexOf(h, Base)]] = true
+	}
+	if len(bases) != NumHues {
+		t.Errorf("64 colors gave %d distinct bases", len(bases))
+	}
+}
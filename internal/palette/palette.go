@@ -11,8 +11,8 @@ import (
 // - index 1: white
 // - indices 2..49: 16 hues × 3 shades (highlight, base, shadow)
 type Palette struct {
-    Name   string
-    Colors [50]string // hex colors like #RRGGBB (lower/upper case accepted)
+    Name   string     `json:"name"`
+    Colors [50]string `json:"colors"` // hex colors like #RRGGBB (lower/upper case accepted)
 }
 
 var (
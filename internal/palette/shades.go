@@ -0,0 +1,50 @@
+package palette
+
+// Shade positions within a hue, in palette order.
+const (
+	Highlight = iota
+	Base
+	Shadow
+)
+
+// Fixed indices and hue layout (see Palette).
+const (
+	Black     = 0
+	White     = 1
+	FirstHue  = 2
+	NumHues   = 16
+	NumShades = 3
+)
+
+// HueShade returns the hue (0..15) and shade (Highlight, Base or Shadow) of a
+// palette index. ok is false for black, white and out-of-range indices.
+func HueShade(index int) (hue, shade int, ok bool) {
+	if index < FirstHue || index >= FirstHue+NumHues*NumShades {
+		return 0, 0, false
+	}
+	i := index - FirstHue
+	return i / NumShades, i % NumShades, true
+}
+
+// IndexOf returns the palette index of a hue's shade.
+func IndexOf(hue, shade int) int {
+	return FirstHue + hue*NumShades + shade
+}
+
+// Darker returns the next darker step: highlight -> base -> shadow -> black.
+func Darker(index int) int {
+	hue, shade, ok := HueShade(index)
+	if !ok || shade == Shadow {
+		return Black
+	}
+	return IndexOf(hue, shade+1)
+}
+
+// Lighter returns the next lighter step: shadow -> base -> highlight -> white.
+func Lighter(index int) int {
+	hue, shade, ok := HueShade(index)
+	if !ok || shade == Highlight {
+		return White
+	}
+	return IndexOf(hue, shade-1)
+}
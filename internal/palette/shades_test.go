@@ -0,0 +1,34 @@
+package palette
+
+import "testing"
+
+func TestHueShade(t *testing.T) {
+	if _, _, ok := HueShade(Black); ok {
+		t.Fatalf("black has no hue")
+	}
+	if _, _, ok := HueShade(50); ok {
+		t.Fatalf("index 50 is out of range")
+	}
+	hue, shade, ok := HueShade(7)
+	if !ok || hue != 1 || shade != Shadow {
+		t.Fatalf("index 7 should be hue 1 shadow, got %d %d %v", hue, shade, ok)
+	}
+	if IndexOf(hue, shade) != 7 {
+		t.Fatalf("IndexOf should invert HueShade")
+	}
+}
+
+func TestDarkerLighter(t *testing.T) {
+	if got := Darker(2); got != 3 {
+		t.Fatalf("highlight should darken to base, got %d", got)
+	}
+	if got := Darker(4); got != Black {
+		t.Fatalf("shadow should darken to black, got %d", got)
+	}
+	if got := Lighter(4); got != 3 {
+		t.Fatalf("shadow should lighten to base, got %d", got)
+	}
+	if got := Lighter(2); got != White {
+		t.Fatalf("highlight should lighten to white, got %d", got)
+	}
+}
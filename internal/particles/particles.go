@@ -0,0 +1,200 @@
+package particles
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// DefaultMax limits live particles per emitter when its definition doesn't
+const DefaultMax = 512
+
+// DefaultColor is drawn by emitters without a color ramp (white)
+const DefaultColor = 1
+
+// Particle is a live particle as it is drawn
+type Particle struct {
+	X, Y  float64
+	Color int     // Palette index from the color ramp
+	Size  float64 // From the size curve
+}
+
+// particle is a particle's simulation state
+type particle struct {
+	x, y, vx, vy float64
+	age, life    float64
+}
+
+// Emitter spawns particles at its position. The system steps it until it is
+// freed and its last particle has died.
+type Emitter struct {
+	Def       cartio.EmitterDef
+	X, Y      float64
+	particles []particle
+	owed      float64 // Fraction of a particle owed by the rate
+	freed     bool
+}
+
+// System steps and draws every emitter
+type System struct {
+	emitters []*Emitter
+	rng      *rand.Rand
+}
+
+// NewSystem creates an empty system. Spawns are picked from a fixed seed so
+// runs are repeatable.
+func NewSystem() *System {
+	return &System{rng: rand.New(rand.NewSource(1))}
+}
+
+// New creates an emitter at (x, y), emitting def.Burst particles at once
+func (s *System) New(def cartio.EmitterDef, x, y float64) *Emitter {
+	e := &Emitter{Def: def, X: x, Y: y}
+	s.emitters = append(s.emitters, e)
+	s.Burst(e, def.Burst)
+	return e
+}
+
+// Free stops e emitting; it is removed once its particles have died
+func (s *System) Free(e *Emitter) {
+	e.freed = true
+}
+
+// Clear removes every emitter and particle
+func (s *System) Clear() {
+	s.emitters = nil
+}
+
+// Len returns the number of live particles
+func (s *System) Len() int {
+	n := 0
+	for _, e := range s.emitters {
+		n += len(e.particles)
+	}
+	return n
+}
+
+// Len returns the number of e's live particles
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// Burst emits n particles from e at once
+func (s *System) Burst(e *Emitter, n int) {
+	limit := e.Def.Max
+	if limit <= 0 {
+		limit = DefaultMax
+	}
+	for i := 0; i < n && len(e.particles) < limit; i++ {
+		e.particles = append(e.particles, s.spawn(e))
+	}
+}
+
+// pick returns a random value in r
+func (s *System) pick(r cartio.Range) float64 {
+	return r.Min + s.rng.Float64()*(r.Max-r.Min)
+}
+
+func (s *System) spawn(e *Emitter) particle {
+	d := e.Def
+	angle := s.pick(d.Angle) * math.Pi / 180
+	speed := s.pick(d.Speed)
+	spread := s.pick(d.Spread)
+	sin, cos := math.Sincos(angle)
+	return particle{
+		x:    e.X + cos*spread,
+		y:    e.Y + sin*spread,
+		vx:   cos * speed,
+		vy:   sin * speed,
+		life: s.pick(d.Life),
+	}
+}
+
+// Step advances every particle by dt seconds and emits new ones at each
+// emitter's rate
+func (s *System) Step(dt float64) {
+	live := s.emitters[:0]
+	for _, e := range s.emitters {
+		d := e.Def
+		drag := math.Max(0, 1-d.Drag*dt)
+		ps := e.particles[:0]
+		for _, p := range e.particles {
+			p.age += dt
+			if p.age >= p.life {
+				continue
+			}
+			p.vx = (p.vx + d.Gravity[0]*dt) * drag
+			p.vy = (p.vy + d.Gravity[1]*dt) * drag
+			p.x += p.vx * dt
+			p.y += p.vy * dt
+			ps = append(ps, p)
+		}
+		e.particles = ps
+
+		if !e.freed && d.Rate > 0 {
+			e.owed += d.Rate * dt
+			n := int(e.owed)
+			e.owed -= float64(n)
+			s.Burst(e, n)
+		}
+		if !e.freed || len(e.particles) > 0 {
+			live = append(live, e)
+		}
+	}
+	for i := len(live); i < len(s.emitters); i++ {
+		s.emitters[i] = nil
+	}
+	s.emitters = live
+}
+
+// Draw calls draw for every particle of the emitters on layer, oldest first
+func (s *System) Draw(layer int, draw func(e *Emitter, p Particle)) {
+	for _, e := range s.emitters {
+		if e.Def.Layer == layer {
+			e.each(draw)
+		}
+	}
+}
+
+// DrawAll calls draw for every particle on every layer
+func (s *System) DrawAll(draw func(e *Emitter, p Particle)) {
+	for _, e := range s.emitters {
+		e.each(draw)
+	}
+}
+
+func (e *Emitter) each(draw func(e *Emitter, p Particle)) {
+	for _, p := range e.particles {
+		t := p.age / p.life
+		draw(e, Particle{X: p.x, Y: p.y, Color: ramp(e.Def.Colors, t), Size: curve(e.Def.Sizes, t)})
+	}
+}
+
+// ramp returns the color for lifetime fraction t
+func ramp(colors []int, t float64) int {
+	if len(colors) == 0 {
+		return DefaultColor
+	}
+	i := int(t * float64(len(colors)))
+	if i >= len(colors) {
+		i = len(colors) - 1
+	}
+	return colors[i]
+}
+
+// curve interpolates sizes at lifetime fraction t (1 without sizes)
+func curve(sizes []float64, t float64) float64 {
+	switch len(sizes) {
+	case 0:
+		return 1
+	case 1:
+		return sizes[0]
+	}
+	f := t * float64(len(sizes)-1)
+	i := int(f)
+	if i >= len(sizes)-1 {
+		return sizes[len(sizes)-1]
+	}
+	return sizes[i] + (sizes[i+1]-sizes[i])*(f-float64(i))
+}
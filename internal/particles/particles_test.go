@@ -0,0 +1,115 @@
+package particles
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+func collect(s *System, layer int) []Particle {
+	var ps []Particle
+	s.Draw(layer, func(e *Emitter, p Particle) { ps = append(ps, p) })
+	return ps
+}
+
+func TestBurstAndMotion(t *testing.T) {
+	s := NewSystem()
+	e := s.New(cartio.EmitterDef{
+		Burst:   3,
+		Life:    cartio.Range{Min: 1, Max: 1},
+		Speed:   cartio.Range{Min: 10, Max: 10},
+		Angle:   cartio.Range{Min: 90, Max: 90}, // Straight down
+		Gravity: [2]float64{0, 20},
+	}, 50, 50)
+	if e.Len() != 3 {
+		t.Fatalf("expected the definition's burst of 3, got %d", e.Len())
+	}
+
+	s.Step(0.5)
+	ps := collect(s, 0)
+	if len(ps) != 3 {
+		t.Fatalf("expected 3 live particles, got %d", len(ps))
+	}
+	// vy = 10 + 20*0.5 = 20, y = 50 + 20*0.5 = 60
+	if p := ps[0]; math.Abs(p.X-50) > 1e-9 || math.Abs(p.Y-60) > 1e-9 {
+		t.Errorf("unexpected position %.3f, %.3f", p.X, p.Y)
+	}
+
+	// Particles die at the end of their life
+	s.Step(0.5)
+	if s.Len() != 0 {
+		t.Errorf("expected every particle dead, got %d", s.Len())
+	}
+}
+
+func TestRateDragAndLimit(t *testing.T) {
+	s := NewSystem()
+	e := s.New(cartio.EmitterDef{Rate: 10, Max: 4, Life: cartio.Range{Min: 10, Max: 10}, Speed: cartio.Range{Min: 100, Max: 100}, Drag: 1}, 0, 0)
+	s.Step(0.25) // 2.5 particles owed
+	if e.Len() != 2 {
+		t.Fatalf("expected 2 particles after 0.25s at 10/s, got %d", e.Len())
+	}
+	s.Step(0.25)
+	if e.Len() != 4 { // The half particle carried over; max caps the rest
+		t.Fatalf("expected 4 particles, got %d", e.Len())
+	}
+	s.Step(1)
+	if e.Len() != 4 {
+		t.Fatalf("max should cap live particles at 4, got %d", e.Len())
+	}
+
+	// Drag of 1 stops particles after a second-long step
+	before := collect(s, 0)
+	s.Step(0.1)
+	after := collect(s, 0)
+	if after[0].X != before[0].X {
+		t.Errorf("drag should have stopped the particle, moved from %.2f to %.2f", before[0].X, after[0].X)
+	}
+}
+
+func TestRampsAndCurves(t *testing.T) {
+	if got := ramp([]int{7, 8, 9}, 0.5); got != 8 {
+		t.Errorf("ramp(0.5) = %d, want 8", got)
+	}
+	if got := ramp([]int{7, 8, 9}, 1); got != 9 {
+		t.Errorf("ramp(1) = %d, want 9", got)
+	}
+	if got := ramp(nil, 0.5); got != DefaultColor {
+		t.Errorf("ramp without colors = %d, want %d", got, DefaultColor)
+	}
+	if got := curve([]float64{4, 0}, 0.25); got != 3 {
+		t.Errorf("curve(0.25) = %v, want 3", got)
+	}
+	if got := curve([]float64{1, 5, 1}, 0.5); got != 5 {
+		t.Errorf("curve(0.5) = %v, want 5", got)
+	}
+	if got := curve(nil, 0.5); got != 1 {
+		t.Errorf("curve without sizes = %v, want 1", got)
+	}
+}
+
+func TestFreeAndLayers(t *testing.T) {
+	s := NewSystem()
+	life := cartio.Range{Min: 1, Max: 1}
+	front := s.New(cartio.EmitterDef{Burst: 2, Rate: 100, Life: life, Layer: 1}, 0, 0)
+	s.New(cartio.EmitterDef{Burst: 1, Life: life}, 0, 0)
+
+	if n := len(collect(s, 1)); n != 2 {
+		t.Errorf("layer 1: expected 2 particles, got %d", n)
+	}
+	if n := len(collect(s, 0)); n != 1 {
+		t.Errorf("layer 0: expected 1 particle, got %d", n)
+	}
+
+	// A freed emitter stops emitting and goes away with its last particle
+	s.Free(front)
+	s.Step(0.5)
+	if front.Len() != 2 {
+		t.Errorf("freed emitter should not emit, has %d particles", front.Len())
+	}
+	s.Step(0.5)
+	if len(s.emitters) != 1 {
+		t.Errorf("expected the freed emitter removed, %d emitters left", len(s.emitters))
+	}
+}
@@ -126,6 +126,27 @@ func (b *Body) CreateCircleFixtureWithProps(radius float64, density, restitution
 	b.body.CreateFixtureFromDef(&fixtureDef)
 }
 
+// CreateChainFixture adds a chain of line segments through points (x, y pairs,
+// relative to the body). With loop the chain is closed. Chains suit static
+// level geometry: they may be concave and have any number of vertices.
+func (b *Body) CreateChainFixture(points []float64, loop bool) {
+	n := len(points) / 2
+	if n < 2 || (loop && n < 3) {
+		return
+	}
+	vertices := make([]box2d.B2Vec2, n)
+	for i := range vertices {
+		vertices[i] = box2d.MakeB2Vec2(points[i*2], points[i*2+1])
+	}
+	shape := box2d.MakeB2ChainShape()
+	if loop {
+		shape.CreateLoop(vertices, n)
+	} else {
+		shape.CreateChain(vertices, n)
+	}
+	b.body.CreateFixture(&shape, 0)
+}
+
 // SetPosition sets the body's position
 func (b *Body) SetPosition(x, y float64) {
 	b.body.SetTransform(box2d.MakeB2Vec2(x, y), b.body.GetAngle())
@@ -51,6 +51,26 @@ func TestBodyFixture(t *testing.T) {
 	body.CreateCircleFixture(5, 1.0)
 }
 
+func TestChainFixture(t *testing.T) {
+	world := NewWorld(0, 9.8)
+	ground := world.CreateStaticBody(0, 0)
+	ground.CreateChainFixture([]float64{-50, 10, 50, 10}, false)
+	ground.CreateChainFixture([]float64{0, 0}, false) // Too few points, ignored
+	if n := ground.body.GetFixtureList(); n == nil || n.GetNext() != nil {
+		t.Fatal("expected exactly one chain fixture")
+	}
+
+	// A box dropped on the chain comes to rest on it
+	box := world.CreateDynamicBody(0, 0)
+	box.CreateBoxFixture(2, 2, 1.0)
+	for i := 0; i < 300; i++ {
+		world.Step()
+	}
+	if _, y := box.GetPosition(); y > 10 {
+		t.Errorf("box fell through the chain, y = %f", y)
+	}
+}
+
 func TestBodyPosition(t *testing.T) {
 	world := NewWorld(0, 9.8)
 	body := world.CreateDynamicBody(0, 0)
@@ -0,0 +1,218 @@
+// Package raycast renders a tilemap Wolfenstein-style: a DDA ray per screen
+// column finds the nearest wall tile, drawn as a textured column over flat
+// floor and ceiling colors. Distance darkens colors through the palette's
+// shades, and billboard sprites are depth-tested per pixel.
+package raycast
+
+import (
+	"math"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// Shading steps: highlight -> base -> shadow -> black
+const shadeLevels = 4
+
+// near is the closest distance drawn, in tiles: walls nearer are drawn at it
+// and billboards nearer are culled
+const near = 0.01
+
+// Camera is the viewer: position in tiles, view angle in radians (0 = +X,
+// clockwise) and horizontal field of view in radians
+type Camera struct {
+	X, Y, Angle, FOV float64
+}
+
+// Renderer draws frames into a buffer of palette indices with a depth buffer
+// for billboards
+type Renderer struct {
+	graphics.DepthFrame // Distances in tiles
+
+	Ceiling   int     // Ceiling color
+	Floor     int     // Floor color
+	Wall      int     // Color of walls without a texture
+	ShadeDist float64 // Tiles per darker shade step (0 = no distance shading)
+	shades    [shadeLevels][256]int
+
+	// Projection of the last frame, for billboards
+	cam            Camera
+	dirX, dirY     float64
+	planeX, planeY float64
+	focal          float64 // Pixels per tile at distance 1
+	tileW, tileH   int
+}
+
+// New creates a renderer for w×h frames with a black ceiling and floor and
+// white untextured walls
+func New(w, h int) *Renderer {
+	r := &Renderer{
+		DepthFrame: graphics.NewDepthFrame(w, h),
+		Ceiling:    palette.Black,
+		Floor:      palette.Black,
+		Wall:       palette.White,
+	}
+	for i := range r.shades[0] {
+		c := i
+		for level := range r.shades {
+			r.shades[level][i] = c
+			c = palette.Darker(c)
+		}
+	}
+	return r
+}
+
+// shade darkens c for distance dist, plus extra steps
+func (r *Renderer) shade(c int, dist float64, extra int) int {
+	if c < 0 || c > 255 {
+		return c
+	}
+	level := extra
+	if r.ShadeDist > 0 {
+		level += int(dist / r.ShadeDist)
+	}
+	if level >= shadeLevels {
+		level = shadeLevels - 1
+	}
+	return r.shades[level][c]
+}
+
+// Render draws a frame of tm seen from cam. Non-zero tiles are walls, drawn
+// with their texture, or the Wall color without one. Walls facing
+// north or south are a shade darker.
+func (r *Renderer) Render(cam Camera, tm *graphics.TileMap, texture func(tile int) (graphics.Texture, bool)) {
+	r.cam = cam
+	r.dirY, r.dirX = math.Sincos(cam.Angle)
+	half := math.Tan(cam.FOV / 2)
+	r.planeX, r.planeY = -r.dirY*half, r.dirX*half
+	r.focal = float64(r.W) / 2 / half
+	r.tileW, r.tileH = tm.TileSize()
+	horizon := r.H / 2
+
+	// Floor and ceiling, darkened by the distance each row shows
+	inf := float32(math.Inf(1))
+	for y := 0; y < r.H; y++ {
+		var c int
+		var dist float64
+		if y < horizon {
+			c, dist = r.Ceiling, r.focal*0.5/(float64(horizon-y)-0.5)
+		} else {
+			c, dist = r.Floor, r.focal*0.5/(float64(y-horizon)+0.5)
+		}
+		c = r.shade(c, dist, 0)
+		row := r.Pix[y*r.W : (y+1)*r.W]
+		depth := r.Depth[y*r.W : (y+1)*r.W]
+		for x := range row {
+			row[x] = c
+			depth[x] = inf
+		}
+	}
+
+	mapW, mapH := tm.Width(), tm.Height()
+	maxSteps := mapW + mapH + 2
+	for x := 0; x < r.W; x++ {
+		camX := 2*(float64(x)+0.5)/float64(r.W) - 1
+		rayX, rayY := r.dirX+r.planeX*camX, r.dirY+r.planeY*camX
+
+		// DDA through the grid
+		mx, my := int(math.Floor(cam.X)), int(math.Floor(cam.Y))
+		deltaX, deltaY := math.Abs(1/rayX), math.Abs(1/rayY)
+		stepX, stepY := 1, 1
+		sideX, sideY := (float64(mx)+1-cam.X)*deltaX, (float64(my)+1-cam.Y)*deltaY
+		if rayX < 0 {
+			stepX, sideX = -1, (cam.X-float64(mx))*deltaX
+		}
+		if rayY < 0 {
+			stepY, sideY = -1, (cam.Y-float64(my))*deltaY
+		}
+		tile, ySide := 0, false
+		for i := 0; i < maxSteps; i++ {
+			if sideX < sideY {
+				sideX += deltaX
+				mx += stepX
+				ySide = false
+			} else {
+				sideY += deltaY
+				my += stepY
+				ySide = true
+			}
+			if mx < 0 || my < 0 || mx >= mapW || my >= mapH {
+				break // Left the map: nothing to draw
+			}
+			if tile = tm.Get(mx, my); tile != 0 {
+				break
+			}
+		}
+		if tile == 0 {
+			continue
+		}
+
+		// Perpendicular distance avoids fisheye; wallX is where the ray hit the wall (0..1)
+		var dist, wallX float64
+		if ySide {
+			dist = sideY - deltaY
+			wallX = cam.X + dist*rayX
+		} else {
+			dist = sideX - deltaX
+			wallX = cam.Y + dist*rayY
+		}
+		wallX -= math.Floor(wallX)
+		if (!ySide && rayX < 0) || (ySide && rayY > 0) {
+			wallX = 1 - wallX // Keep textures unmirrored on every face
+		}
+		extra := 0
+		if ySide {
+			extra = 1
+		}
+
+		dist = math.Max(dist, near) // A camera on a wall's face would make it infinitely tall
+		lineH := r.focal / dist
+		top := float64(horizon) - lineH/2
+		y0, y1 := max(0, int(math.Ceil(top-0.5))), min(r.H, int(math.Ceil(top+lineH-0.5)))
+		tex, ok := texture(tile)
+		d := float32(dist)
+		if !ok || tex.W <= 0 || tex.H <= 0 {
+			c := r.shade(r.Wall, dist, extra)
+			for y := y0; y < y1; y++ {
+				r.Pix[y*r.W+x], r.Depth[y*r.W+x] = c, d
+			}
+			continue
+		}
+		u := min(int(wallX*float64(tex.W)), tex.W-1)
+		step := float64(tex.H) / lineH
+		v := (float64(y0) + 0.5 - top) * step
+		for y := y0; y < y1; y, v = y+1, v+step {
+			c := tex.At(u, min(int(v), tex.H-1))
+			if c < 0 {
+				continue // Transparent texels show the floor and ceiling
+			}
+			r.Pix[y*r.W+x], r.Depth[y*r.W+x] = r.shade(c, dist, extra), d
+		}
+	}
+}
+
+// Sprite draws a billboard standing on the floor at (x, y) in tiles, scaled
+// so tex is as many tiles tall as it has tile heights of pixels. Pixels behind
+// walls or nearer billboards are skipped. It returns a block holding only the
+// billboard (the rest transparent) and its screen rectangle; ok is false when
+// nothing is visible.
+func (r *Renderer) Sprite(tex graphics.Texture, x, y float64) (pix []int, sx, sy, w, h int, ok bool) {
+	if r.focal == 0 || tex.W <= 0 || tex.H <= 0 {
+		return nil, 0, 0, 0, 0, false
+	}
+	relX, relY := x-r.cam.X, y-r.cam.Y
+	invDet := 1 / (r.planeX*r.dirY - r.dirX*r.planeY)
+	side := invDet * (r.dirY*relX - r.dirX*relY)
+	dist := invDet * (-r.planeY*relX + r.planeX*relY)
+	if dist <= near {
+		return nil, 0, 0, 0, 0, false // Behind the camera
+	}
+
+	screenX := float64(r.W) / 2 * (1 + side/dist)
+	spriteW := r.focal * float64(tex.W) / float64(r.tileW) / dist
+	spriteH := r.focal * float64(tex.H) / float64(r.tileH) / dist
+	bottom := float64(r.H/2) + r.focal*0.5/dist
+	return r.Billboard(tex, screenX-spriteW/2, bottom-spriteH, spriteW, spriteH, dist, func(c int) int {
+		return r.shade(c, dist, 0)
+	})
+}
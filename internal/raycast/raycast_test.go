@@ -0,0 +1,134 @@
+package raycast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// room returns a w×h map walled with tile 1
+func room(w, h int) *graphics.TileMap {
+	tm := graphics.NewTileMap(w, h)
+	for x := 0; x < w; x++ {
+		tm.Set(x, 0, 1)
+		tm.Set(x, h-1, 1)
+	}
+	for y := 0; y < h; y++ {
+		tm.Set(0, y, 1)
+		tm.Set(w-1, y, 1)
+	}
+	return tm
+}
+
+// solid returns an 8×8 texture of one color
+func solid(c int) graphics.Texture {
+	pix := make([]int, 64)
+	for i := range pix {
+		pix[i] = c
+	}
+	return graphics.Texture{Pix: pix, Stride: 8, W: 8, H: 8}
+}
+
+func TestRenderWalls(t *testing.T) {
+	red := palette.IndexOf(0, palette.Base)
+	r := New(64, 40)
+	r.Ceiling, r.Floor = palette.IndexOf(1, palette.Base), palette.IndexOf(2, palette.Base)
+	textures := func(tile int) (graphics.Texture, bool) { return solid(red), tile == 1 }
+
+	// Facing +X from the middle of an 8×8 room: the east wall is 3.5 tiles away
+	r.Render(Camera{X: 4, Y: 4.5, FOV: math.Pi / 2}, room(8, 8), textures)
+	if got := r.Pix[20*64+32]; got != red {
+		t.Errorf("center = %d, want the wall (%d)", got, red)
+	}
+	if got := r.Pix[0*64+32]; got != r.Ceiling {
+		t.Errorf("top = %d, want the ceiling", got)
+	}
+	if got := r.Pix[39*64+32]; got != r.Floor {
+		t.Errorf("bottom = %d, want the floor", got)
+	}
+
+	// Wall height is focal / distance: focal = 32 at 90°, 32 / 3 px at 3 tiles
+	height := 0
+	for y := 0; y < 40; y++ {
+		if r.Pix[y*64+32] == red {
+			height++
+		}
+	}
+	if height < 8 || height > 11 {
+		t.Errorf("wall column is %d px tall, want about 9", height)
+	}
+
+	// Facing +Y the wall is a north/south face, a shade darker
+	r.Render(Camera{X: 4.5, Y: 4, Angle: math.Pi / 2, FOV: math.Pi / 2}, room(8, 8), textures)
+	if got := r.Pix[20*64+32]; got != palette.Darker(red) {
+		t.Errorf("north/south face = %d, want %d", got, palette.Darker(red))
+	}
+
+	// A camera on a wall's face fills the column with the wall
+	r.Render(Camera{X: 1, Y: 4.5, Angle: math.Pi, FOV: math.Pi / 2}, room(8, 8), textures)
+	for y := 0; y < 40; y++ {
+		if got := r.Pix[y*64+32]; got != red {
+			t.Fatalf("row %d touching the wall = %d, want the wall", y, got)
+		}
+	}
+
+	// Distance shading: 3 tiles at one step per tile is three steps darker (black)
+	r.ShadeDist = 1
+	r.Render(Camera{X: 4, Y: 4.5, FOV: math.Pi / 2}, room(8, 8), textures)
+	if got := r.Pix[20*64+32]; got != palette.Black {
+		t.Errorf("shaded wall = %d, want black", got)
+	}
+}
+
+func TestSpriteDepth(t *testing.T) {
+	r := New(64, 40)
+	tm := room(8, 8)
+	tm.Set(4, 4, 1) // Pillar in front of the far sprite
+	r.Render(Camera{X: 2, Y: 4.5, FOV: math.Pi / 2}, tm, func(tile int) (graphics.Texture, bool) { return solid(2), true })
+
+	// In front of the pillar: drawn, centered, standing on the floor
+	pix, x, y, w, h, ok := r.Sprite(solid(5), 3, 4.5)
+	if !ok {
+		t.Fatal("near sprite should be visible")
+	}
+	if x+w/2 != 32 || y+h < 20 || y+h > 40 {
+		t.Errorf("near sprite at (%d, %d) %d×%d", x, y, w, h)
+	}
+	if pix[(h/2)*w+w/2] != 5 || r.Pix[(y+h/2)*64+32] != 5 {
+		t.Error("near sprite pixels not drawn")
+	}
+
+	// Behind the pillar: hidden
+	if _, _, _, _, _, ok := r.Sprite(solid(6), 6, 4.5); ok {
+		t.Error("sprite behind the pillar should be hidden")
+	}
+
+	// Behind the camera: skipped
+	if _, _, _, _, _, ok := r.Sprite(solid(6), 1, 4.5); ok {
+		t.Error("sprite behind the camera should be skipped")
+	}
+}
+
+// BenchmarkRender480x270 renders a 480×270 frame of a 64×64 maze of textured walls
+func BenchmarkRender480x270(b *testing.B) {
+	tm := room(64, 64)
+	for y := 2; y < 62; y += 4 {
+		for x := 2; x < 62; x += 3 {
+			tm.Set(x, y, 1)
+		}
+	}
+	tex := solid(2)
+	for i := range tex.Pix {
+		tex.Pix[i] = 2 + i%48
+	}
+	r := New(480, 270)
+	r.ShadeDist = 4
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		cam := Camera{X: 32.5, Y: 32.5, Angle: float64(n) * 0.01, FOV: math.Pi / 3}
+		r.Render(cam, tm, func(tile int) (graphics.Texture, bool) { return tex, true })
+		r.Sprite(tex, 34, 33)
+	}
+}
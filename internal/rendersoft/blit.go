@@ -0,0 +1,87 @@
+package rendersoft
+
+// Span and block writers. They clip against the screen and clip rectangle
+// once per call and then write pixels directly, instead of checking every
+// pixel like set.
+
+// drawable returns the screen area pixels may be written to, [x0, x1)×[y0, y1)
+func (s *Soft) drawable() (x0, y0, x1, y1 int) {
+	x0, y0, x1, y1 = 0, 0, s.viewW, s.viewH
+	if s.clipW > 0 && s.clipH > 0 {
+		x0, y0 = maxInt(x0, s.clipX), maxInt(y0, s.clipY)
+		x1, y1 = minInt(x1, s.clipX+s.clipW), minInt(y1, s.clipY+s.clipH)
+	}
+	return x0, y0, x1, y1
+}
+
+// put writes palette index ci at framebuffer offset i
+func (s *Soft) put(i int, ci uint8) {
+	s.idx[i] = ci
+}
+
+// hspan fills x0..x1 (inclusive) of row y in screen coordinates
+func (s *Soft) hspan(x0, x1, y int, ci uint8) {
+	cx0, cy0, cx1, cy1 := s.drawable()
+	if y < cy0 || y >= cy1 {
+		return
+	}
+	x0, x1 = maxInt(x0, cx0), minInt(x1, cx1-1)
+	if x0 > x1 {
+		return
+	}
+	row := y * s.w
+	idx := s.idx[row+x0 : row+x1+1]
+	for i := range idx {
+		idx[i] = ci
+	}
+}
+
+// Blit draws the w×h region at (sx, sy) of pix, a row-major block of palette
+// indices stride wide, with its top-left corner at (x, y). Negative indices
+// are transparent and indices outside the palette draw as 0, like pen. The
+// region is clipped once, then copied row by row.
+func (s *Soft) Blit(pix []int, stride, sx, sy, w, h, x, y int, flipX, flipY bool) {
+	if stride <= 0 || sx < 0 || sy < 0 {
+		return
+	}
+	// Keep the region inside the block
+	w = minInt(w, stride-sx)
+	h = minInt(h, len(pix)/stride-sy)
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	// Apply camera offset
+	x -= s.cameraX
+	y -= s.cameraY
+
+	cx0, cy0, cx1, cy1 := s.drawable()
+	x0, y0 := maxInt(x, cx0), maxInt(y, cy0)
+	x1, y1 := minInt(x+w, cx1), minInt(y+h, cy1)
+	if x0 >= x1 || y0 >= y1 {
+		return
+	}
+
+	idx, n := s.idx, s.numColors
+	for dy := y0; dy < y1; dy++ {
+		row := dy - y
+		if flipY {
+			row = h - 1 - row
+		}
+		src := pix[(sy+row)*stride+sx : (sy+row)*stride+sx+w]
+		col, step := x0-x, 1
+		if flipX {
+			col, step = w-1-col, -1
+		}
+		for i := dy*s.w + x0; i < dy*s.w+x1; i, col = i+1, col+step {
+			ci := src[col]
+			if ci < 0 {
+				continue // Transparent
+			}
+			if ci >= n {
+				ci = 0
+			}
+			idx[i] = uint8(ci)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package rendersoft
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+)
+
+// rampPalette returns n distinct colors
+func rampPalette(n int) []color.RGBA {
+	colors := make([]color.RGBA, n)
+	for i := range colors {
+		colors[i] = color.RGBA{uint8(i), uint8(i * 2), uint8(i * 3), 255}
+	}
+	return colors
+}
+
+func TestBlit(t *testing.T) {
+	r := New(16, 16)
+	r.SetPalette(rampPalette(50))
+
+	// 3×2 block: row 0 = 1 2 -1, row 1 = 3 -1 99 (99 is outside the palette)
+	pix := []int{1, 2, -1, 3, -1, 99}
+	r.Blit(pix, 3, 0, 0, 3, 2, 4, 4, false, false)
+	want := map[[2]int]int{{4, 4}: 1, {5, 4}: 2, {6, 4}: 0, {4, 5}: 3, {5, 5}: 0, {6, 5}: 0}
+	for p, ci := range want {
+		if got := r.PGetIndex(p[0], p[1]); got != ci {
+			t.Errorf("(%d, %d) = %d, want %d", p[0], p[1], got, ci)
+		}
+	}
+	if got := r.PGet(4, 5); got != (color.RGBA{3, 6, 9, 255}) {
+		t.Errorf("RGBA at (4, 5) = %v", got)
+	}
+
+	// Flipped both ways
+	r.Clear(pal.Index(0))
+	r.Blit(pix, 3, 0, 0, 3, 2, 0, 0, true, true)
+	if r.PGetIndex(2, 1) != 1 || r.PGetIndex(0, 0) != 0 || r.PGetIndex(2, 0) != 3 {
+		t.Errorf("flipped blit: %d %d %d", r.PGetIndex(2, 1), r.PGetIndex(0, 0), r.PGetIndex(2, 0))
+	}
+
+	// Region of the block
+	r.Clear(pal.Index(0))
+	r.Blit(pix, 3, 1, 0, 1, 1, 0, 0, false, false)
+	if r.PGetIndex(0, 0) != 2 || r.PGetIndex(1, 0) != 0 {
+		t.Errorf("region blit: %d %d", r.PGetIndex(0, 0), r.PGetIndex(1, 0))
+	}
+}
+
+func TestBlitClipAndCamera(t *testing.T) {
+	r := New(8, 8)
+	r.SetPalette(rampPalette(50))
+	pix := make([]int, 16)
+	for i := range pix {
+		pix[i] = 5
+	}
+
+	// Partly off the left/top edge
+	r.Blit(pix, 4, 0, 0, 4, 4, -2, -2, false, false)
+	if r.PGetIndex(0, 0) != 5 || r.PGetIndex(1, 1) != 5 || r.PGetIndex(2, 2) != 0 {
+		t.Errorf("edge clip: %d %d %d", r.PGetIndex(0, 0), r.PGetIndex(1, 1), r.PGetIndex(2, 2))
+	}
+
+	// Clip rectangle and camera
+	r.Clear(pal.Index(0))
+	r.SetClip(3, 3, 2, 2)
+	r.SetCamera(-2, -2)
+	r.Blit(pix, 4, 0, 0, 4, 4, 0, 0, false, false)
+	r.SetClip(0, 0, 0, 0)
+	r.SetCamera(0, 0)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			want := 0
+			if x >= 3 && x < 5 && y >= 3 && y < 5 {
+				want = 5
+			}
+			if got := r.PGetIndex(x, y); got != want {
+				t.Errorf("(%d, %d) = %d, want %d", x, y, got, want)
+			}
+		}
+	}
+
+	// Regions outside the block draw nothing
+	r.Clear(pal.Index(0))
+	r.Blit(pix, 4, 3, 3, 4, 4, 0, 0, false, false)
+	r.Blit(pix, 4, -1, 0, 2, 2, 4, 4, false, false)
+	if r.PGetIndex(0, 0) != 5 || r.PGetIndex(1, 0) != 0 || r.PGetIndex(4, 4) != 0 {
+		t.Errorf("out of block: %d %d %d", r.PGetIndex(0, 0), r.PGetIndex(1, 0), r.PGetIndex(4, 4))
+	}
+}
+
+func TestSpanFillsClip(t *testing.T) {
+	r := New(10, 10)
+	r.Clear(color.RGBA{0, 0, 0, 255})
+	r.SetClip(2, 2, 4, 4)
+	r.RectFill(0, 0, 9, 9, color.RGBA{255, 0, 0, 255})
+	r.CircFill(5, 5, 8, color.RGBA{255, 0, 0, 255})
+	r.SetClip(0, 0, 0, 0)
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			want := color.RGBA{0, 0, 0, 255}
+			if x >= 2 && x < 6 && y >= 2 && y < 6 {
+				want = color.RGBA{255, 0, 0, 255}
+			}
+			if got := r.PGet(x, y); got != want {
+				t.Errorf("(%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+// benchSprite is a 16×16 sprite with a transparent border, as sprites usually have
+func benchSprite() []int {
+	pix := make([]int, 16*16)
+	for i := range pix {
+		x, y := i%16, i/16
+		pix[i] = -1
+		if x > 0 && x < 15 && y > 0 && y < 15 {
+			pix[i] = 2 + (x+y)%48
+		}
+	}
+	return pix
+}
+
+// BenchmarkSprPSet draws 300 sprites a pixel at a time, the way rf.spr used to
+func BenchmarkSprPSet(b *testing.B) {
+	r := New(480, 270)
+	r.SetPalette(rampPalette(50))
+	pix := benchSprite()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < 300; i++ {
+			x, y := (i*37)%480-8, (i*53)%270-8
+			for sy := 0; sy < 16; sy++ {
+				for sx := 0; sx < 16; sx++ {
+					if ci := pix[sy*16+sx]; ci >= 0 {
+						r.PSet(x+sx, y+sy, pal.Index(ci))
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkSprBlit draws the same 300 sprites with Blit
+func BenchmarkSprBlit(b *testing.B) {
+	r := New(480, 270)
+	r.SetPalette(rampPalette(50))
+	pix := benchSprite()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < 300; i++ {
+			r.Blit(pix, 16, 0, 0, 16, 16, (i*37)%480-8, (i*53)%270-8, i%2 == 0, false)
+		}
+	}
+}
+
+func BenchmarkRectFill(b *testing.B) {
+	r := New(480, 270)
+	r.SetPalette(rampPalette(50))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.RectFill(-10, 10, 400, 250, pal.Index(n%50))
+	}
+}
+
+func BenchmarkCircFill(b *testing.B) {
+	r := New(480, 270)
+	r.SetPalette(rampPalette(50))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.CircFill(240, 135, 120, pal.Index(n%50))
+	}
+}
+
+func BenchmarkPolyFill(b *testing.B) {
+	r := New(480, 270)
+	r.SetPalette(rampPalette(50))
+	points := [][]int{{20, 10}, {460, 40}, {300, 260}, {240, 120}, {30, 250}}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.Poly(points, true, pal.Index(n%50))
+	}
+}
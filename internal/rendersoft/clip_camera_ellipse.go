@@ -23,7 +23,7 @@ func (s *Soft) GetCamera() (x, y int) {
 }
 
 // Ellipse drawing functions
-func (s *Soft) Ellipse(xc, yc, rx, ry int, c color.RGBA) {
+func (s *Soft) Ellipse(xc, yc, rx, ry int, c color.Color) {
 	if rx <= 0 || ry <= 0 {
 		return
 	}
@@ -31,6 +31,7 @@ func (s *Soft) Ellipse(xc, yc, rx, ry int, c color.RGBA) {
 	// Apply camera offset
 	xc -= s.cameraX
 	yc -= s.cameraY
+	ci := s.pen(c)
 
 	// Midpoint ellipse algorithm for outline
 	rx2 := rx * rx
@@ -48,7 +49,7 @@ func (s *Soft) Ellipse(xc, yc, rx, ry int, c color.RGBA) {
 	py = twoRx2 * y
 
 	// Draw first set of points
-	s.ellipsePlotPoints(xc, yc, x, y, c)
+	s.ellipsePlotPoints(xc, yc, x, y, ci)
 
 	p := ry2 - (rx2 * ry) + (rx2 / 4)
 	for px < py {
@@ -61,7 +62,7 @@ func (s *Soft) Ellipse(xc, yc, rx, ry int, c color.RGBA) {
 			py -= twoRx2
 			p += ry2 + px - py
 		}
-		s.ellipsePlotPoints(xc, yc, x, y, c)
+		s.ellipsePlotPoints(xc, yc, x, y, ci)
 	}
 
 	// Region 2
@@ -76,11 +77,11 @@ func (s *Soft) Ellipse(xc, yc, rx, ry int, c color.RGBA) {
 			px += twoRy2
 			p += rx2 - py + px
 		}
-		s.ellipsePlotPoints(xc, yc, x, y, c)
+		s.ellipsePlotPoints(xc, yc, x, y, ci)
 	}
 }
 
-func (s *Soft) EllipseFill(xc, yc, rx, ry int, c color.RGBA) {
+func (s *Soft) EllipseFill(xc, yc, rx, ry int, c color.Color) {
 	if rx <= 0 || ry <= 0 {
 		return
 	}
@@ -88,6 +89,7 @@ func (s *Soft) EllipseFill(xc, yc, rx, ry int, c color.RGBA) {
 	// Apply camera offset
 	xc -= s.cameraX
 	yc -= s.cameraY
+	ci := s.pen(c)
 
 	// Fill ellipse using horizontal scanlines
 	ry2 := ry * ry
@@ -101,14 +103,12 @@ func (s *Soft) EllipseFill(xc, yc, rx, ry int, c color.RGBA) {
 		width := int(float64(rx) * math.Sqrt(1.0-float64(y*y)/float64(ry2)))
 
 		// Draw horizontal line from -width to +width
-		for x := -width; x <= width; x++ {
-			s.set(xc+x, yc+y, c)
-			s.set(xc+x, yc-y, c)
-		}
+		s.hspan(xc-width, xc+width, yc+y, ci)
+		s.hspan(xc-width, xc+width, yc-y, ci)
 	}
 }
 
-func (s *Soft) ellipsePlotPoints(xc, yc, x, y int, c color.RGBA) {
+func (s *Soft) ellipsePlotPoints(xc, yc, x, y int, c uint8) {
 	s.set(xc+x, yc+y, c)
 	s.set(xc-x, yc+y, c)
 	s.set(xc+x, yc-y, c)
@@ -0,0 +1,315 @@
+package rendersoft
+
+import (
+	"image/color"
+	"math"
+)
+
+// Poly draws an arbitrary polygon through points given in world coordinates.
+// Concave and self-intersecting polygons are filled with the even-odd rule.
+func (s *Soft) Poly(points [][]int, filled bool, c color.Color) {
+	if len(points) == 0 {
+		return
+	}
+
+	ci := s.pen(c)
+
+	// Apply camera offset
+	screen := make([][]int, len(points))
+	for i, p := range points {
+		screen[i] = []int{p[0] - s.cameraX, p[1] - s.cameraY}
+	}
+
+	switch {
+	case len(screen) == 1:
+		s.set(screen[0][0], screen[0][1], ci)
+	case len(screen) == 2:
+		s.line(screen[0][0], screen[0][1], screen[1][0], screen[1][1], ci)
+	case filled:
+		s.fillPolygon(screen, ci)
+	default:
+		s.strokePolygon(screen, ci)
+	}
+}
+
+// LineThick draws a line of the given thickness with round caps, so
+// consecutive segments join without gaps.
+func (s *Soft) LineThick(x0, y0, x1, y1, thickness int, c color.Color) {
+	if thickness <= 1 {
+		s.Line(x0, y0, x1, y1, c)
+		return
+	}
+
+	ci := s.pen(c)
+
+	// Apply camera offset
+	x0 -= s.cameraX
+	y0 -= s.cameraY
+	x1 -= s.cameraX
+	y1 -= s.cameraY
+
+	radius := (thickness - 1) / 2
+	dx := float64(x1 - x0)
+	dy := float64(y1 - y0)
+	length := math.Hypot(dx, dy)
+	if length > 0 {
+		// Offset both endpoints along the segment normal to build the body quad
+		half := float64(thickness) / 2
+		nx := -dy / length * half
+		ny := dx / length * half
+		body := [][]int{
+			{x0 + int(math.Round(nx)), y0 + int(math.Round(ny))},
+			{x1 + int(math.Round(nx)), y1 + int(math.Round(ny))},
+			{x1 - int(math.Round(nx)), y1 - int(math.Round(ny))},
+			{x0 - int(math.Round(nx)), y0 - int(math.Round(ny))},
+		}
+		s.fillPolygon(body, ci)
+	}
+	s.circFill(x0, y0, radius, ci)
+	s.circFill(x1, y1, radius, ci)
+}
+
+// arcPoints returns points along a circular arc in screen coordinates.
+// Angles are in degrees, 0 = right, increasing clockwise (screen Y points down).
+func arcPoints(xc, yc, r int, a0, a1 float64) [][]int {
+	if a1 < a0 {
+		a1 += 360 * math.Ceil((a0-a1)/360)
+	}
+	sweep := (a1 - a0) * math.Pi / 180
+	start := a0 * math.Pi / 180
+
+	// Roughly one segment per two pixels of arc length
+	segments := int(math.Min(math.Ceil(sweep*float64(r)/2), maxSegments))
+	if segments < 4 {
+		segments = 4
+	}
+
+	points := make([][]int, 0, segments+1)
+	for i := 0; i <= segments; i++ {
+		a := start + sweep*float64(i)/float64(segments)
+		x := xc + int(math.Round(float64(r)*math.Cos(a)))
+		y := yc + int(math.Round(float64(r)*math.Sin(a)))
+		points = append(points, []int{x, y})
+	}
+	return points
+}
+
+// maxSegments caps the segments arcs and curves are flattened into
+const maxSegments = 256
+
+// screenReach returns the distance from (x, y) to the farthest screen corner:
+// a circle centered there with a larger radius lies wholly off screen
+func (s *Soft) screenReach(x, y int) int {
+	dx := max(abs(x), abs(s.w-1-x))
+	dy := max(abs(y), abs(s.h-1-y))
+	return int(math.Ceil(math.Hypot(float64(dx), float64(dy))))
+}
+
+// Arc draws the outline of a circular arc from angle a0 to a1 (degrees,
+// 0 = right, clockwise).
+func (s *Soft) Arc(xc, yc, r int, a0, a1 float64, c color.Color) {
+	if r <= 0 {
+		return
+	}
+
+	ci := s.pen(c)
+
+	// Apply camera offset
+	xc -= s.cameraX
+	yc -= s.cameraY
+	if r > s.screenReach(xc, yc) {
+		return
+	}
+
+	points := arcPoints(xc, yc, r, a0, a1)
+	for i := 0; i+1 < len(points); i++ {
+		s.line(points[i][0], points[i][1], points[i+1][0], points[i+1][1], ci)
+	}
+}
+
+// Pie draws a pie slice (circle sector) from angle a0 to a1 (degrees,
+// 0 = right, clockwise).
+func (s *Soft) Pie(xc, yc, r int, a0, a1 float64, filled bool, c color.Color) {
+	if r <= 0 {
+		return
+	}
+
+	// A full sweep is just a circle; avoid the seam through the center
+	if math.Abs(a1-a0) >= 360 {
+		if filled {
+			s.CircFill(xc, yc, r, c)
+		} else {
+			s.Circ(xc, yc, r, c)
+		}
+		return
+	}
+
+	ci := s.pen(c)
+
+	// Apply camera offset
+	xc -= s.cameraX
+	yc -= s.cameraY
+	r = min(r, s.screenReach(xc, yc)+1) // Beyond that the sector covers the same pixels
+
+	points := append([][]int{{xc, yc}}, arcPoints(xc, yc, r, a0, a1)...)
+	if filled {
+		s.fillPolygon(points, ci)
+	} else {
+		s.strokePolygon(points, ci)
+	}
+}
+
+// roundRectBounds normalizes corners and clamps the corner radius to fit
+func roundRectBounds(x0, y0, x1, y1, r int) (int, int, int, int, int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	r = minInt(r, minInt(x1-x0, y1-y0)/2)
+	return x0, y0, x1, y1, r
+}
+
+// RoundRect draws a rectangle outline with rounded corners of radius r
+func (s *Soft) RoundRect(x0, y0, x1, y1, r int, c color.Color) {
+	x0, y0, x1, y1, r = roundRectBounds(x0, y0, x1, y1, r)
+	if r <= 0 {
+		s.Rect(x0, y0, x1, y1, c)
+		return
+	}
+
+	ci := s.pen(c)
+
+	// Apply camera offset
+	x0 -= s.cameraX
+	y0 -= s.cameraY
+	x1 -= s.cameraX
+	y1 -= s.cameraY
+
+	// Straight edges between the corner arcs
+	s.line(x0+r, y0, x1-r, y0, ci)
+	s.line(x0+r, y1, x1-r, y1, ci)
+	s.line(x0, y0+r, x0, y1-r, ci)
+	s.line(x1, y0+r, x1, y1-r, ci)
+
+	// Corner arcs: midpoint circle split into quadrants
+	left, right := x0+r, x1-r
+	top, bottom := y0+r, y1-r
+	x, y, d := r, 0, 1-2*r
+	for y <= x {
+		s.set(right+x, top-y, ci)
+		s.set(right+y, top-x, ci)
+		s.set(left-x, top-y, ci)
+		s.set(left-y, top-x, ci)
+		s.set(right+x, bottom+y, ci)
+		s.set(right+y, bottom+x, ci)
+		s.set(left-x, bottom+y, ci)
+		s.set(left-y, bottom+x, ci)
+		if d < 0 {
+			d += 2*y + 1
+		} else {
+			d += 2*(y-x) + 1
+			x--
+		}
+		y++
+	}
+}
+
+// RoundRectFill draws a filled rectangle with rounded corners of radius r
+func (s *Soft) RoundRectFill(x0, y0, x1, y1, r int, c color.Color) {
+	x0, y0, x1, y1, r = roundRectBounds(x0, y0, x1, y1, r)
+	if r <= 0 {
+		s.RectFill(x0, y0, x1, y1, c)
+		return
+	}
+
+	ci := s.pen(c)
+
+	// Apply camera offset
+	x0 -= s.cameraX
+	y0 -= s.cameraY
+	x1 -= s.cameraX
+	y1 -= s.cameraY
+
+	left, right := x0+r, x1-r
+	top, bottom := y0+r, y1-r
+
+	// Middle band between the corner rows
+	for y := top; y <= bottom; y++ {
+		s.hspan(x0, x1, y, ci)
+	}
+
+	// Top and bottom bands widen along the corner circles
+	x, y, d := r, 0, 1-2*r
+	for y <= x {
+		s.hspan(left-x, right+x, top-y, ci)
+		s.hspan(left-x, right+x, bottom+y, ci)
+		s.hspan(left-y, right+y, top-x, ci)
+		s.hspan(left-y, right+y, bottom+x, ci)
+		if d < 0 {
+			d += 2*y + 1
+		} else {
+			d += 2*(y-x) + 1
+			x--
+		}
+		y++
+	}
+}
+
+// curveSegments picks a flattening step count from the control polygon length
+func curveSegments(pts ...float64) int {
+	length := 0.0
+	for i := 2; i+1 < len(pts); i += 2 {
+		length += math.Hypot(pts[i]-pts[i-2], pts[i+1]-pts[i-1])
+	}
+	n := int(length / 3)
+	if n < 4 {
+		n = 4
+	}
+	if n > maxSegments {
+		n = maxSegments
+	}
+	return n
+}
+
+// BezierQuad draws a quadratic Bézier curve from (x0, y0) to (x1, y1) with control point (cx, cy)
+func (s *Soft) BezierQuad(x0, y0, cx, cy, x1, y1 int, c color.Color) {
+	ci := s.pen(c)
+	px0, py0 := float64(x0-s.cameraX), float64(y0-s.cameraY)
+	pcx, pcy := float64(cx-s.cameraX), float64(cy-s.cameraY)
+	px1, py1 := float64(x1-s.cameraX), float64(y1-s.cameraY)
+
+	n := curveSegments(px0, py0, pcx, pcy, px1, py1)
+	lastX, lastY := int(px0), int(py0)
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		x := u*u*px0 + 2*u*t*pcx + t*t*px1
+		y := u*u*py0 + 2*u*t*pcy + t*t*py1
+		nx, ny := int(math.Round(x)), int(math.Round(y))
+		s.line(lastX, lastY, nx, ny, ci)
+		lastX, lastY = nx, ny
+	}
+}
+
+// BezierCubic draws a cubic Bézier curve from (x0, y0) to (x1, y1) with control points (c1x, c1y) and (c2x, c2y)
+func (s *Soft) BezierCubic(x0, y0, c1x, c1y, c2x, c2y, x1, y1 int, c color.Color) {
+	ci := s.pen(c)
+	px0, py0 := float64(x0-s.cameraX), float64(y0-s.cameraY)
+	pc1x, pc1y := float64(c1x-s.cameraX), float64(c1y-s.cameraY)
+	pc2x, pc2y := float64(c2x-s.cameraX), float64(c2y-s.cameraY)
+	px1, py1 := float64(x1-s.cameraX), float64(y1-s.cameraY)
+
+	n := curveSegments(px0, py0, pc1x, pc1y, pc2x, pc2y, px1, py1)
+	lastX, lastY := int(px0), int(py0)
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		x := u*u*u*px0 + 3*u*u*t*pc1x + 3*u*t*t*pc2x + t*t*t*px1
+		y := u*u*u*py0 + 3*u*u*t*pc1y + 3*u*t*t*pc2y + t*t*t*py1
+		nx, ny := int(math.Round(x)), int(math.Round(y))
+		s.line(lastX, lastY, nx, ny, ci)
+		lastX, lastY = nx, ny
+	}
+}
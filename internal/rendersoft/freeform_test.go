@@ -0,0 +1,176 @@
+package rendersoft
+
+import (
+	"image/color"
+	"testing"
+)
+
+func isSet(r *Soft, x, y int) bool {
+	return r.PGet(x, y).R == 255
+}
+
+func TestPolyConcave(t *testing.T) {
+	r := New(100, 100)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	// U shape: the notch between the arms must stay empty
+	u := [][]int{{10, 10}, {30, 10}, {30, 40}, {60, 40}, {60, 10}, {80, 10}, {80, 60}, {10, 60}}
+	r.Poly(u, true, c)
+
+	if !isSet(r, 20, 20) || !isSet(r, 70, 20) || !isSet(r, 45, 50) {
+		t.Errorf("Poly should fill the arms and base of a concave polygon")
+	}
+	if isSet(r, 45, 20) {
+		t.Errorf("Poly should not fill the notch of a concave polygon")
+	}
+}
+
+func TestPolyOutlineAndDegenerate(t *testing.T) {
+	r := New(50, 50)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	r.Poly([][]int{{10, 10}, {40, 10}, {40, 40}, {10, 40}}, false, c)
+	if !isSet(r, 25, 10) || !isSet(r, 40, 25) {
+		t.Errorf("Poly outline should draw edges")
+	}
+	if isSet(r, 25, 25) {
+		t.Errorf("Poly outline should not fill the interior")
+	}
+
+	// Degenerate inputs should not crash
+	r.Poly(nil, true, c)
+	r.Poly([][]int{{5, 5}}, true, c)
+	r.Poly([][]int{{0, 0}, {5, 5}}, true, c)
+	if !isSet(r, 5, 5) {
+		t.Errorf("Poly with one point should set that pixel")
+	}
+}
+
+func TestPolyCameraAndClip(t *testing.T) {
+	r := New(50, 50)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	r.SetCamera(10, 10)
+	r.Poly([][]int{{20, 20}, {30, 20}, {30, 30}, {20, 30}}, true, c)
+	if !isSet(r, 15, 15) {
+		t.Errorf("Poly should apply camera offset")
+	}
+	if isSet(r, 25, 25) {
+		t.Errorf("Poly should not draw at uncorrected world position")
+	}
+
+	r.Clear(color.RGBA{A: 255})
+	r.SetCamera(0, 0)
+	r.SetClip(0, 0, 20, 50)
+	r.Poly([][]int{{10, 10}, {40, 10}, {40, 40}, {10, 40}}, true, c)
+	if !isSet(r, 15, 15) || isSet(r, 30, 15) {
+		t.Errorf("Poly should respect the clip rectangle")
+	}
+}
+
+func TestLineThick(t *testing.T) {
+	r := New(50, 50)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	r.LineThick(10, 25, 40, 25, 5, c)
+	for _, y := range []int{23, 25, 27} {
+		if !isSet(r, 25, y) {
+			t.Errorf("LineThick should cover y=%d", y)
+		}
+	}
+	if isSet(r, 25, 31) {
+		t.Errorf("LineThick should not be wider than its thickness")
+	}
+
+	// Zero-length and thin lines should not crash
+	r.LineThick(5, 5, 5, 5, 4, c)
+	r.LineThick(0, 0, 10, 10, 1, c)
+}
+
+func TestArcAndPie(t *testing.T) {
+	r := New(60, 60)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	// Quarter arc from right (0°) to bottom (90°)
+	r.Arc(30, 30, 20, 0, 90, c)
+	if !isSet(r, 50, 30) || !isSet(r, 30, 50) {
+		t.Errorf("Arc should touch both end angles")
+	}
+	if isSet(r, 10, 30) {
+		t.Errorf("Arc should not draw outside its sweep")
+	}
+
+	r.Clear(color.RGBA{A: 255})
+	r.Pie(30, 30, 20, 0, 90, true, c)
+	if !isSet(r, 38, 38) {
+		t.Errorf("Pie fill should cover the sector interior")
+	}
+	if isSet(r, 22, 22) {
+		t.Errorf("Pie fill should not cover the opposite quadrant")
+	}
+
+	// Full sweep and reversed angles should not crash
+	r.Pie(30, 30, 10, 0, 360, false, c)
+	r.Arc(30, 30, 10, 270, 45, c)
+	r.Pie(30, 30, 0, 0, 90, true, c)
+}
+
+func TestArcHugeRadius(t *testing.T) {
+	r := New(60, 60)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	// A huge radius is drawn (or skipped) in a few segments
+	r.Arc(30, 30, 1<<40, 0, 360, c)
+	if isSet(r, 30, 30) {
+		t.Errorf("Arc far outside the screen should draw nothing")
+	}
+	r.Pie(0, 0, 1<<40, 0, 90, true, c)
+	if !isSet(r, 30, 30) || !isSet(r, 59, 59) {
+		t.Errorf("Pie with a huge radius should still fill its sector")
+	}
+	if n := len(arcPoints(0, 0, 1<<40, 0, 360)); n != maxSegments+1 {
+		t.Errorf("arcPoints = %d points, want %d", n, maxSegments+1)
+	}
+}
+
+func TestRoundRect(t *testing.T) {
+	r := New(50, 50)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	r.RoundRectFill(10, 10, 40, 40, 8, c)
+	if !isSet(r, 25, 25) || !isSet(r, 25, 10) || !isSet(r, 10, 25) {
+		t.Errorf("RoundRectFill should fill the interior and straight edges")
+	}
+	if isSet(r, 10, 10) {
+		t.Errorf("RoundRectFill should leave the corners rounded")
+	}
+
+	r.Clear(color.RGBA{A: 255})
+	r.RoundRect(10, 10, 40, 40, 8, c)
+	if !isSet(r, 25, 10) || isSet(r, 25, 25) || isSet(r, 10, 10) {
+		t.Errorf("RoundRect should draw only the rounded outline")
+	}
+
+	// Radius larger than the rect is clamped; zero radius is a plain rect
+	r.RoundRectFill(0, 0, 4, 4, 100, c)
+	r.RoundRect(0, 0, 4, 4, 0, c)
+}
+
+func TestBezier(t *testing.T) {
+	r := New(60, 60)
+	c := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	r.BezierQuad(0, 50, 30, 0, 59, 50, c)
+	if !isSet(r, 0, 50) || !isSet(r, 59, 50) {
+		t.Errorf("BezierQuad should reach both endpoints")
+	}
+	if !isSet(r, 30, 25) {
+		t.Errorf("BezierQuad should pass through its midpoint")
+	}
+
+	r.Clear(color.RGBA{A: 255})
+	r.BezierCubic(0, 30, 20, 0, 40, 59, 59, 30, c)
+	if !isSet(r, 0, 30) || !isSet(r, 59, 30) {
+		t.Errorf("BezierCubic should reach both endpoints")
+	}
+}
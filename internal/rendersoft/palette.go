@@ -0,0 +1,139 @@
+package rendersoft
+
+import (
+	"image/color"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+	palettes "github.com/AndrewDonelson/retroforge-engine/internal/palette" // Not the palette type below
+)
+
+// palette holds the indexed framebuffer's color state. Indices below
+// numColors are the cart palette; RGBA colors drawn through the color.RGBA
+// path are given spare indices from the top (255 downwards), which Clear frees
+// again.
+type palette struct {
+	colors    [256]color.RGBA      // Base color for each index
+	numColors int                  // Number of cart palette entries
+	screen    [256]uint8           // Screen palette: drawn index -> displayed index
+	lut       [256]color.RGBA      // colors[screen[i]], used when resolving pixels
+	lookup    map[color.RGBA]uint8 // Exact RGBA -> index
+	nextFree  int                  // Next spare index for RGBA colors (< numColors when full)
+}
+
+func (p *palette) init() {
+	for i := range p.colors {
+		p.colors[i] = color.RGBA{0, 0, 0, 255}
+		p.screen[i] = uint8(i)
+	}
+	p.lookup = make(map[color.RGBA]uint8)
+	p.nextFree = 255
+	p.updateLUT()
+}
+
+// updateLUT recomputes the displayed color of every index
+func (p *palette) updateLUT() {
+	for i := range p.lut {
+		p.lut[i] = p.colors[p.screen[i]]
+	}
+}
+
+// SetPalette sets the base colors for indices 0..len(colors)-1. Pixels
+// already drawn keep their indices and take on the new colors.
+func (s *Soft) SetPalette(colors []color.RGBA) {
+	n := minInt(len(colors), 256)
+
+	// Keep RGBA colors whose spare indices are still above the palette
+	lookup := make(map[color.RGBA]uint8)
+	nextFree := 255
+	for c, i := range s.lookup {
+		if int(i) >= n && int(i) >= s.numColors {
+			lookup[c] = i
+			nextFree = minInt(nextFree, int(i)-1)
+		}
+	}
+	for i := 0; i < n; i++ {
+		s.colors[i] = colors[i]
+		s.colors[i].A = 0xFF
+	}
+	// Palette entries win over spares; duplicates resolve to their first index
+	for i := n - 1; i >= 0; i-- {
+		lookup[s.colors[i]] = uint8(i)
+	}
+
+	s.numColors = n
+	s.lookup = lookup
+	s.nextFree = nextFree
+	s.updateLUT()
+}
+
+// SetScreenPal displays pixels drawn with index as the color of display,
+// for the whole frame (PICO-8's pal(c0, c1, 1)).
+func (s *Soft) SetScreenPal(index, display int) {
+	if index < 0 || index > 255 || display < 0 || display > 255 {
+		return
+	}
+	if s.screen[index] == uint8(display) {
+		return
+	}
+	s.screen[index] = uint8(display)
+	s.lut[index] = s.colors[display]
+}
+
+// ResetScreenPal restores the identity screen palette
+func (s *Soft) ResetScreenPal() {
+	for i := range s.screen {
+		s.screen[i] = uint8(i)
+	}
+	s.updateLUT()
+}
+
+// pen converts a drawing color to a palette index. pal.Index values are used
+// directly (out-of-palette indices draw as 0); other colors map to their
+// palette index, a spare index, or the nearest color once spares run out.
+func (s *Soft) pen(c color.Color) uint8 {
+	switch v := c.(type) {
+	case pal.Index:
+		if int(v) >= s.numColors {
+			return 0
+		}
+		return uint8(v)
+	case color.RGBA:
+		return s.indexOf(v)
+	case nil:
+		return 0
+	default:
+		return s.indexOf(color.RGBAModel.Convert(c).(color.RGBA))
+	}
+}
+
+// freeSpares forgets the RGBA colors given spare indices, once no pixel uses
+// them
+func (s *Soft) freeSpares() {
+	if s.nextFree == 255 {
+		return
+	}
+	for c, i := range s.lookup {
+		if int(i) >= s.numColors {
+			delete(s.lookup, c)
+		}
+	}
+	s.nextFree = 255
+}
+
+func (s *Soft) indexOf(c color.RGBA) uint8 {
+	c.A = 0xFF
+	if i, ok := s.lookup[c]; ok {
+		return i
+	}
+	if s.nextFree >= s.numColors {
+		i := uint8(s.nextFree)
+		s.nextFree--
+		s.colors[i] = c
+		s.lookup[c] = i
+		s.updateLUT()
+		return i
+	}
+
+	// Out of spare indices, so every index is in use: take the nearest color
+	return uint8(palettes.Nearest(s.colors[:], c))
+}
@@ -0,0 +1,148 @@
+package rendersoft
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+)
+
+var testPalette = []color.RGBA{
+	{0, 0, 0, 255},
+	{255, 255, 255, 255},
+	{255, 0, 0, 255},
+	{0, 255, 0, 255},
+}
+
+func TestIndexedDrawing(t *testing.T) {
+	r := New(10, 10)
+	r.SetPalette(testPalette)
+	r.Clear(pal.Index(0))
+	r.PSet(2, 3, pal.Index(2))
+
+	if got := r.PGetIndex(2, 3); got != 2 {
+		t.Fatalf("expected index 2, got %d", got)
+	}
+	if got := r.PGet(2, 3); got != testPalette[2] {
+		t.Fatalf("expected red, got %v", got)
+	}
+	if got := r.PGetIndex(-1, 0); got != 0 {
+		t.Fatalf("out of bounds should be index 0, got %d", got)
+	}
+
+	// Indices outside the palette draw as index 0
+	r.PSet(4, 4, pal.Index(40))
+	if got := r.PGetIndex(4, 4); got != 0 {
+		t.Fatalf("expected out-of-palette index to draw as 0, got %d", got)
+	}
+}
+
+func TestPixelsResolvedOnRead(t *testing.T) {
+	r := New(4, 4)
+	r.SetPalette(testPalette)
+	r.Clear(pal.Index(0))
+	pix := r.Pixels()
+
+	// Drawing writes indices only; RGBA is resolved when the frame is read
+	r.RectFill(0, 0, 3, 3, pal.Index(2))
+	r.Blit([]int{1}, 1, 0, 0, 1, 1, 1, 1, false, false)
+	if pix[0] != 0 {
+		t.Fatal("drawing should not write RGBA pixels")
+	}
+	pix = r.Pixels()
+	if pix[0] != 255 || pix[1] != 0 || pix[(1*4+1)*4+1] != 255 {
+		t.Errorf("Pixels = %v, want red with a white pixel at (1, 1)", pix[:24])
+	}
+}
+
+func TestRGBAColorsMapToIndices(t *testing.T) {
+	r := New(10, 10)
+	r.SetPalette(testPalette)
+
+	// Palette colors resolve to their index
+	r.PSet(0, 0, color.RGBA{0, 255, 0, 255})
+	if got := r.PGetIndex(0, 0); got != 3 {
+		t.Fatalf("expected green to map to index 3, got %d", got)
+	}
+
+	// Other colors get a spare index and keep their exact value
+	other := color.RGBA{12, 34, 56, 255}
+	r.PSet(1, 0, other)
+	if got := r.PGetIndex(1, 0); got < len(testPalette) {
+		t.Fatalf("expected a spare index, got %d", got)
+	}
+	if got := r.PGet(1, 0); got != other {
+		t.Fatalf("expected %v, got %v", other, got)
+	}
+}
+
+func TestSpareIndicesRunOut(t *testing.T) {
+	r := New(16, 16)
+	r.SetPalette(testPalette)
+
+	// More RGBA colors than spare indices: the rest take the nearest color
+	for i := 0; i < 256; i++ {
+		r.PSet(i%16, i/16, color.RGBA{uint8(i), 100, 0, 255})
+	}
+	if got := r.PGet(15, 15); got.R < 200 || got.G != 100 {
+		t.Fatalf("expected a color near {255 100 0}, got %v", got)
+	}
+	if got := r.PGetIndex(15, 15); got < len(testPalette) {
+		t.Fatalf("expected a spare index, got %d", got)
+	}
+
+	// Clear frees the spares for the next frame
+	r.Clear(pal.Index(0))
+	fresh := color.RGBA{1, 2, 3, 255}
+	r.PSet(0, 0, fresh)
+	if got := r.PGet(0, 0); got != fresh {
+		t.Fatalf("expected %v after Clear, got %v", fresh, got)
+	}
+	if got := r.PGetIndex(0, 0); got != 255 {
+		t.Fatalf("expected spare index 255 after Clear, got %d", got)
+	}
+}
+
+func TestScreenPalette(t *testing.T) {
+	r := New(4, 4)
+	r.SetPalette(testPalette)
+	r.Clear(pal.Index(0))
+	r.PSet(1, 1, pal.Index(2))
+
+	// Remapping the screen palette changes the displayed color, not the index
+	r.SetScreenPal(2, 3)
+	pix := r.Pixels()
+	o := (1*4 + 1) * 4
+	if pix[o+0] != 0 || pix[o+1] != 255 || pix[o+2] != 0 {
+		t.Fatalf("expected green after screen remap, got %v", pix[o:o+4])
+	}
+	if got := r.PGetIndex(1, 1); got != 2 {
+		t.Fatalf("pget should return the drawn index 2, got %d", got)
+	}
+
+	// Pixels drawn after the remap use it too
+	r.PSet(2, 2, pal.Index(2))
+	if got := r.PGet(2, 2); got != testPalette[3] {
+		t.Fatalf("expected green, got %v", got)
+	}
+
+	r.ResetScreenPal()
+	if got := r.PGet(1, 1); got != testPalette[2] {
+		t.Fatalf("expected red after reset, got %v", got)
+	}
+}
+
+func TestSetPaletteRecolorsFrame(t *testing.T) {
+	r := New(4, 4)
+	r.SetPalette(testPalette)
+	r.Clear(pal.Index(1))
+
+	swapped := append([]color.RGBA{}, testPalette...)
+	swapped[1] = color.RGBA{0, 0, 255, 255}
+	r.SetPalette(swapped)
+
+	pix := r.Pixels()
+	if pix[0] != 0 || pix[1] != 0 || pix[2] != 255 {
+		t.Fatalf("expected existing pixels to take the new color, got %v", pix[0:4])
+	}
+}
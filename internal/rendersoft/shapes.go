@@ -3,6 +3,7 @@ package rendersoft
 import (
 	"image/color"
 	"math"
+	"sort"
 )
 
 // Helper functions for shape drawing
@@ -20,8 +21,9 @@ func minInt(a, b int) int {
 	return b
 }
 
-// fillPolygon uses scanline algorithm to fill a polygon
-func (s *Soft) fillPolygon(points [][]int, c color.RGBA) {
+// fillPolygon fills a polygon given in screen coordinates using the even-odd
+// scanline rule, so concave and self-intersecting outlines fill correctly.
+func (s *Soft) fillPolygon(points [][]int, c uint8) {
 	if len(points) < 3 {
 		return
 	}
@@ -43,51 +45,48 @@ func (s *Soft) fillPolygon(points [][]int, c color.RGBA) {
 	maxY = minInt(s.h-1, maxY)
 
 	// Scanline fill
+	intersects := make([]int, 0, len(points))
 	for y := minY; y <= maxY; y++ {
-		var intersects []int
+		intersects = intersects[:0]
 
-		// Check each edge
+		// Check each edge (half-open in y so shared vertices are counted once)
 		for i := 0; i < len(points); i++ {
 			next := (i + 1) % len(points)
 			px0, py0 := points[i][0], points[i][1]
 			px1, py1 := points[next][0], points[next][1]
 
 			if py0 == py1 {
-				if py0 == y {
-					intersects = append(intersects, px0, px1)
-				}
-				continue
+				continue // Horizontal edges are covered by the outline pass below
 			}
-
-			if (py0 < y && py1 >= y) || (py1 < y && py0 >= y) {
-				denom := py1 - py0
-				if abs(denom) > 0 {
-					x := px0 + (y-py0)*(px1-px0)/denom
-					intersects = append(intersects, x)
-				}
+			if (py0 <= y && py1 > y) || (py1 <= y && py0 > y) {
+				t := float64(y-py0) / float64(py1-py0)
+				x := px0 + int(math.Round(t*float64(px1-px0)))
+				intersects = append(intersects, x)
 			}
 		}
 
-		if len(intersects) >= 2 {
-			// Sort intersects
-			for i := 0; i < len(intersects)-1; i++ {
-				for j := i + 1; j < len(intersects); j++ {
-					if intersects[i] > intersects[j] {
-						intersects[i], intersects[j] = intersects[j], intersects[i]
-					}
-				}
-			}
-
-			minX := maxInt(0, intersects[0])
-			maxX := minInt(s.w-1, intersects[len(intersects)-1])
-			for x := minX; x <= maxX; x++ {
-				s.set(x, y, c)
-			}
+		// Fill between pairs of intersections
+		sort.Ints(intersects)
+		for i := 0; i+1 < len(intersects); i += 2 {
+			s.hspan(intersects[i], intersects[i+1], y, c)
 		}
 	}
+
+	// Stroke the outline so the bottom row and horizontal edges are filled too
+	s.strokePolygon(points, c)
 }
 
-func (s *Soft) Triangle(cx, cy, radius int, filled bool, c color.RGBA) {
+// strokePolygon draws a closed outline through points given in screen coordinates
+func (s *Soft) strokePolygon(points [][]int, c uint8) {
+	for i := 0; i < len(points); i++ {
+		next := (i + 1) % len(points)
+		s.line(points[i][0], points[i][1], points[next][0], points[next][1], c)
+	}
+}
+
+func (s *Soft) Triangle(cx, cy, radius int, filled bool, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	cx -= s.cameraX
 	cy -= s.cameraY
@@ -101,17 +100,17 @@ func (s *Soft) Triangle(cx, cy, radius int, filled bool, c color.RGBA) {
 
 	if filled {
 		points := [][]int{{x0, y0}, {x1, y1}, {x2, y2}}
-		s.fillPolygon(points, c)
+		s.fillPolygon(points, ci)
 	} else {
-		// Line already applies camera, but we need to pass world coords
-		// So add camera back temporarily
-		s.Line(x0+s.cameraX, y0+s.cameraY, x1+s.cameraX, y1+s.cameraY, c)
-		s.Line(x1+s.cameraX, y1+s.cameraY, x2+s.cameraX, y2+s.cameraY, c)
-		s.Line(x2+s.cameraX, y2+s.cameraY, x0+s.cameraX, y0+s.cameraY, c)
+		s.line(x0, y0, x1, y1, ci)
+		s.line(x1, y1, x2, y2, ci)
+		s.line(x2, y2, x0, y0, ci)
 	}
 }
 
-func (s *Soft) Diamond(cx, cy, radius int, filled bool, c color.RGBA) {
+func (s *Soft) Diamond(cx, cy, radius int, filled bool, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	cx -= s.cameraX
 	cy -= s.cameraY
@@ -123,11 +122,7 @@ func (s *Soft) Diamond(cx, cy, radius int, filled bool, c color.RGBA) {
 			dy := abs(y - cy)
 			width := radius - dy
 			if width >= 0 {
-				minX := maxInt(0, cx-width)
-				maxX := minInt(s.w-1, cx+width)
-				for x := minX; x <= maxX; x++ {
-					s.set(x, y, c)
-				}
+				s.hspan(cx-width, cx+width, y, ci)
 			}
 		}
 	} else {
@@ -137,14 +132,11 @@ func (s *Soft) Diamond(cx, cy, radius int, filled bool, c color.RGBA) {
 			{cx, cy + radius},
 			{cx - radius, cy},
 		}
-		for i := 0; i < len(points); i++ {
-			next := (i + 1) % len(points)
-			s.Line(points[i][0]+s.cameraX, points[i][1]+s.cameraY, points[next][0]+s.cameraX, points[next][1]+s.cameraY, c)
-		}
+		s.strokePolygon(points, ci)
 	}
 }
 
-func (s *Soft) Square(cx, cy, radius int, filled bool, c color.RGBA) {
+func (s *Soft) Square(cx, cy, radius int, filled bool, c color.Color) {
 	// RectFill/Rect already apply camera
 	x0 := cx - radius
 	y0 := cy - radius
@@ -157,7 +149,9 @@ func (s *Soft) Square(cx, cy, radius int, filled bool, c color.RGBA) {
 	}
 }
 
-func (s *Soft) Pentagon(cx, cy, radius int, filled bool, c color.RGBA) {
+func (s *Soft) Pentagon(cx, cy, radius int, filled bool, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	cx -= s.cameraX
 	cy -= s.cameraY
@@ -171,16 +165,15 @@ func (s *Soft) Pentagon(cx, cy, radius int, filled bool, c color.RGBA) {
 	}
 
 	if filled {
-		s.fillPolygon(points, c)
+		s.fillPolygon(points, ci)
 	} else {
-		for i := 0; i < len(points); i++ {
-			next := (i + 1) % len(points)
-			s.Line(points[i][0]+s.cameraX, points[i][1]+s.cameraY, points[next][0]+s.cameraX, points[next][1]+s.cameraY, c)
-		}
+		s.strokePolygon(points, ci)
 	}
 }
 
-func (s *Soft) Hexagon(cx, cy, radius int, filled bool, c color.RGBA) {
+func (s *Soft) Hexagon(cx, cy, radius int, filled bool, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	cx -= s.cameraX
 	cy -= s.cameraY
@@ -194,16 +187,15 @@ func (s *Soft) Hexagon(cx, cy, radius int, filled bool, c color.RGBA) {
 	}
 
 	if filled {
-		s.fillPolygon(points, c)
+		s.fillPolygon(points, ci)
 	} else {
-		for i := 0; i < len(points); i++ {
-			next := (i + 1) % len(points)
-			s.Line(points[i][0]+s.cameraX, points[i][1]+s.cameraY, points[next][0]+s.cameraX, points[next][1]+s.cameraY, c)
-		}
+		s.strokePolygon(points, ci)
 	}
 }
 
-func (s *Soft) Star(cx, cy, radius int, filled bool, c color.RGBA) {
+func (s *Soft) Star(cx, cy, radius int, filled bool, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	cx -= s.cameraX
 	cy -= s.cameraY
@@ -223,11 +215,8 @@ func (s *Soft) Star(cx, cy, radius int, filled bool, c color.RGBA) {
 	}
 
 	if filled {
-		s.fillPolygon(points, c)
+		s.fillPolygon(points, ci)
 	} else {
-		for i := 0; i < len(points); i++ {
-			next := (i + 1) % len(points)
-			s.Line(points[i][0]+s.cameraX, points[i][1]+s.cameraY, points[next][0]+s.cameraX, points[next][1]+s.cameraY, c)
-		}
+		s.strokePolygon(points, ci)
 	}
 }
@@ -139,6 +139,7 @@ func TestSquare(t *testing.T) {
 	// Test filled square
 	r.Clear(color.RGBA{R: 0, G: 0, B: 0, A: 255})
 	r.Square(50, 50, 15, true, c)
+	pix = r.Pixels()
 
 	// Center should be filled
 	centerIdx := (50*100 + 50) * 4
@@ -4,34 +4,67 @@ import (
 	"image/color"
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/font"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
 )
 
 type Soft struct {
 	w, h                       int
-	pix                        []uint8 // RGBA
-	clipX, clipY, clipW, clipH int     // Clipping rectangle (0,0,0,0 = disabled)
-	cameraX, cameraY           int     // Camera offset
+	idx                        []uint8    // Palette indices (the framebuffer)
+	pix                        []uint8    // RGBA, resolved from idx through the screen palette by Pixels
+	clipX, clipY, clipW, clipH int        // Clipping rectangle (0,0,0,0 = disabled)
+	cameraX, cameraY           int        // Camera offset
+	zoom, viewW, viewH         int        // Integer zoom and the screen area drawn while zoomed
+	zoomed                     []uint8    // The frame read while zoomed, scaled up from idx
+	font                       *font.Font // Current font for Print
+	palette                               // Base colors, screen palette and RGBA index allocation
 }
 
-func New(w, h int) *Soft { return &Soft{w: w, h: h, pix: make([]uint8, w*h*4)} }
+func New(w, h int) *Soft {
+	s := &Soft{w: w, h: h, idx: make([]uint8, w*h), pix: make([]uint8, w*h*4), font: font.Default}
+	s.zoom, s.viewW, s.viewH = 1, w, h
+	s.palette.init()
+	return s
+}
+
+func (s *Soft) Width() int  { return s.w }
+func (s *Soft) Height() int { return s.h }
+
+// Pixels returns the RGBA backbuffer, resolved from the palette indices
+// through the screen palette here, once per frame. Zoomed drawing is scaled
+// up in the result, leaving the zoom as it is.
+func (s *Soft) Pixels() []uint8 {
+	s.resolve(s.frame())
+	return s.pix
+}
+
+// Indexed returns the frame as palette indices and the color each index is
+// displayed as (through the screen palette), without resolving RGBA pixels.
+// Zoomed drawing is scaled up first, as for Pixels.
+func (s *Soft) Indexed() (idx []uint8, colors []color.RGBA) {
+	return s.frame(), s.lut[:]
+}
 
-func (s *Soft) Width() int      { return s.w }
-func (s *Soft) Height() int     { return s.h }
-func (s *Soft) Pixels() []uint8 { return s.pix }
+// resolve writes every RGBA pixel from its palette index
+func (s *Soft) resolve(idx []uint8) {
+	for i, ci := range idx {
+		c := s.lut[ci]
+		p := s.pix[i*4 : i*4+4 : i*4+4]
+		p[0], p[1], p[2], p[3] = c.R, c.G, c.B, 0xFF
+	}
+}
 
-func (s *Soft) Clear(c color.RGBA) {
-	for i := 0; i < len(s.pix); i += 4 {
-		s.pix[i+0] = c.R
-		s.pix[i+1] = c.G
-		s.pix[i+2] = c.B
-		s.pix[i+3] = 0xFF
+func (s *Soft) Clear(c color.Color) {
+	s.freeSpares() // Every pixel is about to be overwritten
+	ci := s.pen(c)
+	for i := range s.idx {
+		s.idx[i] = ci
 	}
 }
 
-func (s *Soft) set(x, y int, c color.RGBA) {
+func (s *Soft) set(x, y int, ci uint8) {
 	// Camera offset is applied by callers (functions pass world coordinates)
 	// Check bounds
-	if x < 0 || y < 0 || x >= s.w || y >= s.h {
+	if x < 0 || y < 0 || x >= s.viewW || y >= s.viewH {
 		return
 	}
 
@@ -42,31 +75,32 @@ func (s *Soft) set(x, y int, c color.RGBA) {
 		}
 	}
 
-	idx := (y*s.w + x) * 4
-	s.pix[idx+0] = c.R
-	s.pix[idx+1] = c.G
-	s.pix[idx+2] = c.B
-	s.pix[idx+3] = 0xFF
+	s.put(y*s.w+x, ci)
 }
 
-func (s *Soft) PSet(x, y int, c color.RGBA) {
+func (s *Soft) PSet(x, y int, c color.Color) {
 	// Apply camera offset
 	x -= s.cameraX
 	y -= s.cameraY
-	s.set(x, y, c)
+	s.set(x, y, s.pen(c))
 }
 
 func (s *Soft) PGet(x, y int) color.RGBA {
 	if x < 0 || y < 0 || x >= s.w || y >= s.h {
 		return color.RGBA{0, 0, 0, 0} // Return transparent for out of bounds
 	}
-	idx := (y*s.w + x) * 4
-	return color.RGBA{
-		R: s.pix[idx+0],
-		G: s.pix[idx+1],
-		B: s.pix[idx+2],
-		A: s.pix[idx+3],
+	c := s.lut[s.idx[y*s.w+x]]
+	c.A = 0xFF
+	return c
+}
+
+// PGetIndex returns the palette index drawn at (x, y), before the screen
+// palette is applied (0 for out of bounds), like PICO-8's pget.
+func (s *Soft) PGetIndex(x, y int) int {
+	if x < 0 || y < 0 || x >= s.w || y >= s.h {
+		return 0
 	}
+	return int(s.idx[y*s.w+x])
 }
 
 func abs(a int) int {
@@ -76,13 +110,13 @@ func abs(a int) int {
 	return a
 }
 
-func (s *Soft) Line(x0, y0, x1, y1 int, c color.RGBA) {
+func (s *Soft) Line(x0, y0, x1, y1 int, c color.Color) {
 	// Apply camera offset
-	x0 -= s.cameraX
-	y0 -= s.cameraY
-	x1 -= s.cameraX
-	y1 -= s.cameraY
+	s.line(x0-s.cameraX, y0-s.cameraY, x1-s.cameraX, y1-s.cameraY, s.pen(c))
+}
 
+// line draws a Bresenham line in screen coordinates (camera already applied)
+func (s *Soft) line(x0, y0, x1, y1 int, c uint8) {
 	dx := abs(x1 - x0)
 	sx := -1
 	if x0 < x1 {
@@ -111,7 +145,7 @@ func (s *Soft) Line(x0, y0, x1, y1 int, c color.RGBA) {
 	}
 }
 
-func (s *Soft) Rect(x0, y0, x1, y1 int, c color.RGBA) {
+func (s *Soft) Rect(x0, y0, x1, y1 int, c color.Color) {
 	// Apply camera offset (Line already applies it, but we need consistent behavior)
 	s.Line(x0, y0, x1, y0, c)
 	s.Line(x1, y0, x1, y1, c)
@@ -119,7 +153,9 @@ func (s *Soft) Rect(x0, y0, x1, y1 int, c color.RGBA) {
 	s.Line(x0, y1, x0, y0, c)
 }
 
-func (s *Soft) RectFill(x0, y0, x1, y1 int, c color.RGBA) {
+func (s *Soft) RectFill(x0, y0, x1, y1 int, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	x0 -= s.cameraX
 	y0 -= s.cameraY
@@ -132,28 +168,28 @@ func (s *Soft) RectFill(x0, y0, x1, y1 int, c color.RGBA) {
 	if y0 > y1 {
 		y0, y1 = y1, y0
 	}
-	for y := y0; y <= y1; y++ {
-		for x := x0; x <= x1; x++ {
-			s.set(x, y, c)
-		}
+	for y := maxInt(y0, 0); y <= minInt(y1, s.h-1); y++ {
+		s.hspan(x0, x1, y, ci)
 	}
 }
 
-func (s *Soft) Circ(xc, yc, r int, c color.RGBA) {
+func (s *Soft) Circ(xc, yc, r int, c color.Color) {
+	ci := s.pen(c)
+
 	// Apply camera offset
 	xc -= s.cameraX
 	yc -= s.cameraY
 
 	x, y, d := r, 0, 1-2*r
 	for y <= x {
-		s.set(xc+x, yc+y, c)
-		s.set(xc+y, yc+x, c)
-		s.set(xc-x, yc+y, c)
-		s.set(xc-y, yc+x, c)
-		s.set(xc-x, yc-y, c)
-		s.set(xc-y, yc-x, c)
-		s.set(xc+x, yc-y, c)
-		s.set(xc+y, yc-x, c)
+		s.set(xc+x, yc+y, ci)
+		s.set(xc+y, yc+x, ci)
+		s.set(xc-x, yc+y, ci)
+		s.set(xc-y, yc+x, ci)
+		s.set(xc-x, yc-y, ci)
+		s.set(xc-y, yc-x, ci)
+		s.set(xc+x, yc-y, ci)
+		s.set(xc+y, yc-x, ci)
 		if d < 0 {
 			d += 2*y + 1
 		} else {
@@ -164,17 +200,17 @@ func (s *Soft) Circ(xc, yc, r int, c color.RGBA) {
 	}
 }
 
-func (s *Soft) CircFill(xc, yc, r int, c color.RGBA) {
+func (s *Soft) CircFill(xc, yc, r int, c color.Color) {
 	// Apply camera offset
-	xc -= s.cameraX
-	yc -= s.cameraY
+	s.circFill(xc-s.cameraX, yc-s.cameraY, r, s.pen(c))
+}
 
+// circFill fills a circle in screen coordinates (camera already applied)
+func (s *Soft) circFill(xc, yc, r int, c uint8) {
 	x, y, d := r, 0, 1-2*r
 	for y <= x {
-		for xi := xc - x; xi <= xc+x; xi++ {
-			s.set(xi, yc+y, c)
-			s.set(xi, yc-y, c)
-		}
+		s.hspan(xc-x, xc+x, yc+y, c)
+		s.hspan(xc-x, xc+x, yc-y, c)
 		if d < 0 {
 			d += 2*y + 1
 		} else {
@@ -185,39 +221,28 @@ func (s *Soft) CircFill(xc, yc, r int, c color.RGBA) {
 	}
 }
 
-func (s *Soft) Print(text string, x, y int, c color.RGBA) {
-	// Apply camera offset
-	x -= s.cameraX
-	y -= s.cameraY
-	cx := x
-	for _, r := range text {
-		if r == '\n' {
-			y += font.Height + 1
-			cx = x
-			continue
-		}
-		g, ok := font.Get(r)
-		if !ok {
-			cx += font.Advance
-			continue
-		}
-		for row := 0; row < g.H; row++ {
-			bits := g.Rows[row]
-			for col := 0; col < g.W; col++ {
-				// 5x7 glyphs use the lower 5 bits with LEFTMOST at bit 4
-				// so column 0 -> bit 4, column 4 -> bit 0
-				bit := uint((font.Width - 1) - col)
-				if (bits & (1 << bit)) != 0 {
-					s.set(cx+col, y+row, c)
-				}
-			}
-		}
-		cx += font.Advance
+// SetFont selects the font used by Print (nil = built-in 5x7 font)
+func (s *Soft) SetFont(f *font.Font) {
+	if f == nil {
+		f = font.Default
 	}
+	s.font = f
+}
+
+// Font returns the current font
+func (s *Soft) Font() *font.Font { return s.font }
+
+func (s *Soft) Print(text string, x, y int, c color.Color) {
+	ci := s.pen(c)
+
+	// Apply camera offset
+	s.font.Draw(text, x-s.cameraX, y-s.cameraY, func(px, py int) {
+		s.set(px, py, ci)
+	})
 }
 
-func (s *Soft) PrintCentered(text string, y int, c color.RGBA) {
-	w := len([]rune(text)) * font.Advance
+func (s *Soft) PrintCentered(text string, y int, c color.Color) {
+	w := s.font.TextWidth(text)
 	x := (s.w - w) / 2
 	if x < 0 {
 		x = 0
@@ -225,9 +250,9 @@ func (s *Soft) PrintCentered(text string, y int, c color.RGBA) {
 	s.Print(text, x, y, c)
 }
 
-func (s *Soft) PrintAnchored(text string, anchor string, c color.RGBA) {
-	textW := len([]rune(text)) * font.Advance
-	textH := font.Height
+func (s *Soft) PrintAnchored(text string, anchor string, c color.Color) {
+	textW := s.font.TextWidth(text)
+	textH := s.font.TextHeight(text)
 
 	var x, y int
 
@@ -274,3 +299,38 @@ func (s *Soft) PrintAnchored(text string, anchor string, c color.RGBA) {
 
 	s.Print(text, x, y, c)
 }
+
+// PrintBox draws text inside the box at (x, y) of size w×h, wrapping,
+// aligning and applying inline markup as described by opts. Pixels outside
+// the box are clipped (w or h <= 0 leaves that side open). It returns the
+// height of the laid-out text.
+func (s *Soft) PrintBox(text string, x, y, w, h int, c color.Color, opts font.BoxOptions) int {
+	if opts.Colors == 0 {
+		opts.Colors = s.numColors
+	}
+	glyphs, height := s.font.LayoutBox(text, w, h, opts)
+	ci := s.pen(c)
+	inks := make(map[int]uint8)
+	for _, g := range glyphs {
+		gi := ci
+		if g.Color >= 0 {
+			ink, ok := inks[g.Color]
+			if !ok {
+				if opts.Ink != nil {
+					ink = s.pen(opts.Ink(g.Color))
+				} else {
+					ink = s.pen(pal.Index(g.Color))
+				}
+				inks[g.Color] = ink
+			}
+			gi = ink
+		}
+		s.font.DrawGlyph(g.R, x+g.X, y+g.Y, func(px, py int) {
+			if (w > 0 && (px < x || px >= x+w)) || (h > 0 && (py < y || py >= y+h)) {
+				return
+			}
+			s.set(px-s.cameraX, py-s.cameraY, gi)
+		})
+	}
+	return height
+}
@@ -3,6 +3,9 @@ package rendersoft
 import (
 	"image/color"
 	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/font"
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
 )
 
 func TestNew(t *testing.T) {
@@ -189,6 +192,73 @@ func TestPrintCentered(t *testing.T) {
 	}
 }
 
+func TestSetFont(t *testing.T) {
+	// A font with a single 2x2 block glyph
+	f := font.NewFont("block", 2, 3)
+	f.SetChar('x', &font.Char{W: 2, H: 2, Advance: 3, Pix: []bool{true, true, true, true}})
+
+	r := New(20, 20)
+	r.SetFont(f)
+	if r.Font() != f {
+		t.Fatal("Font should return the font that was set")
+	}
+	c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	r.Print("xx\nx", 1, 1, c)
+	for _, p := range [][2]int{{1, 1}, {2, 2}, {4, 1}, {5, 2}, {1, 4}} {
+		if r.PGet(p[0], p[1]) != c {
+			t.Fatalf("expected glyph pixel at %v", p)
+		}
+	}
+	if r.PGet(3, 1) == c {
+		t.Fatal("expected gap between glyphs")
+	}
+
+	r.SetFont(nil)
+	if r.Font() != font.Default {
+		t.Fatal("SetFont(nil) should restore the default font")
+	}
+}
+
+func TestPrintBox(t *testing.T) {
+	r := New(60, 40)
+	r.SetPalette(testPalette)
+	r.Clear(pal.Index(0))
+
+	// Two wrapped lines; the box is only tall enough for the first
+	used := r.PrintBox("AAAA {c:2}AAAA", 2, 2, 30, 8, pal.Index(1), font.BoxOptions{Wrap: true})
+	if used != font.Default.LineHeight+font.Height {
+		t.Fatalf("expected two lines of height, got %d", used)
+	}
+	ink := map[int]bool{}
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			if i := r.PGetIndex(x, y); i != 0 {
+				ink[i] = true
+				if x < 2 || x >= 32 || y < 2 || y >= 10 {
+					t.Fatalf("pixel (%d, %d) drawn outside the box", x, y)
+				}
+			}
+		}
+	}
+	if !ink[1] || ink[2] {
+		t.Fatalf("expected only the first line's color, got %v", ink)
+	}
+
+	// A color past the palette is printed as text in the text's color
+	r.Clear(pal.Index(0))
+	r.PrintBox("{c:-5}A", 0, 20, 0, 0, pal.Index(1), font.BoxOptions{})
+	for y := 20; y < 28; y++ {
+		for x := 0; x < 60; x++ {
+			if i := r.PGetIndex(x, y); i > 1 {
+				t.Fatalf("pixel (%d, %d) = %d, want the text's color", x, y, i)
+			}
+		}
+	}
+	if r.PGetIndex(24, 20) != 1 { // The top of the "5"
+		t.Fatal("the tag should be printed")
+	}
+}
+
 func TestNewEdgeCases(t *testing.T) {
 	// Test zero dimensions
 	r := New(0, 0)
@@ -458,3 +528,19 @@ func TestColorValues(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexed(t *testing.T) {
+	s := New(4, 4)
+	s.SetPalette([]color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {255, 0, 0, 255}})
+	s.SetZoom(2)
+	s.PSet(1, 1, pal.Index(2))
+	s.SetScreenPal(2, 1)
+
+	idx, colors := s.Indexed()
+	if idx[3*4+3] != 2 || idx[0] != 0 {
+		t.Errorf("zoomed pixel not scaled up: %v", idx)
+	}
+	if colors[2] != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("index 2 displayed as %v, want white through the screen palette", colors[2])
+	}
+}
@@ -0,0 +1,96 @@
+package rendersoft
+
+import (
+	"image/color"
+	"math"
+)
+
+// TLine draws a textured line from (x0, y0) to (x1, y1) in world coordinates.
+// For each pixel along the line, sample is called with texture coordinates
+// starting at (u, v) and advancing by (du, dv) per pixel. Pixels for which
+// sample reports ok=false are left untouched (transparent).
+func (s *Soft) TLine(x0, y0, x1, y1 int, u, v, du, dv float64, sample func(u, v float64) (color.Color, bool)) {
+	if sample == nil {
+		return
+	}
+	// Apply camera offset
+	s.tline(x0-s.cameraX, y0-s.cameraY, x1-s.cameraX, y1-s.cameraY, u, v, du, dv, sample)
+}
+
+// tline walks the major axis of a line in screen coordinates (camera already
+// applied). Steps outside the clip rect and screen are skipped without sampling.
+func (s *Soft) tline(x0, y0, x1, y1 int, u, v, du, dv float64, sample func(u, v float64) (color.Color, bool)) {
+	dx := x1 - x0
+	dy := y1 - y0
+	steps := maxInt(abs(dx), abs(dy))
+	cx0, cy0, cx1, cy1 := s.drawable()
+	fx, lx, okx := clipSteps(x0, dx, steps, cx0, cx1)
+	fy, ly, oky := clipSteps(y0, dy, steps, cy0, cy1)
+	if !okx || !oky {
+		return
+	}
+	first, last := maxInt(fx, fy), minInt(lx, ly)
+	u += float64(first) * du
+	v += float64(first) * dv
+	for i := first; i <= last; i++ {
+		x, y := x0, y0
+		if steps > 0 {
+			x = x0 + int(math.Round(float64(i*dx)/float64(steps)))
+			y = y0 + int(math.Round(float64(i*dy)/float64(steps)))
+		}
+		if c, ok := sample(u, v); ok {
+			s.set(x, y, s.pen(c))
+		}
+		u += du
+		v += dv
+	}
+}
+
+// clipSteps returns the steps 0..steps at which c0 + i*d/steps (rounded) may
+// fall inside lo..hi-1, with a pixel of slack for rounding; set checks the rest.
+func clipSteps(c0, d, steps, lo, hi int) (first, last int, ok bool) {
+	if steps == 0 || d == 0 {
+		return 0, steps, c0 >= lo && c0 < hi
+	}
+	// c0 + t*d over t = i/steps in 0..1
+	t0 := (float64(lo-1) - float64(c0)) / float64(d)
+	t1 := (float64(hi) - float64(c0)) / float64(d)
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+	first = maxInt(0, int(math.Floor(t0*float64(steps))))
+	last = minInt(steps, int(math.Ceil(t1*float64(steps))))
+	return first, last, first <= last
+}
+
+// Mode7 renders a perspective floor below the horizon row, PICO-8/SNES style.
+// camX, camY is the viewer position in texture space, angle is the view
+// direction in degrees (0 = +X, clockwise), and scale is the viewer height
+// (larger values show more of the floor). The floor spans a 90° field of
+// view. Rows above the horizon are left untouched so callers can draw a sky.
+// Mode7 is a screen-space effect: the camera offset is ignored, clipping applies.
+func (s *Soft) Mode7(camX, camY, angle float64, horizon int, scale float64, sample func(u, v float64) (color.Color, bool)) {
+	if sample == nil || scale <= 0 {
+		return
+	}
+
+	rad := angle * math.Pi / 180
+	dirX, dirY := math.Cos(rad), math.Sin(rad)
+	perpX, perpY := -dirY, dirX // Points to the viewer's right (screen Y points down)
+
+	focal := float64(s.w) / 2
+	startY := maxInt(horizon+1, 0)
+	for y := startY; y < s.h; y++ {
+		// Distance to the floor seen through this row (sampled at pixel centers)
+		p := float64(y-horizon) + 0.5
+		dist := scale * focal / p
+
+		// World position of the left edge of the row, then step per column
+		stepU := perpX * dist / focal
+		stepV := perpY * dist / focal
+		u := camX + dirX*dist - perpX*dist + stepU*0.5
+		v := camY + dirY*dist - perpY*dist + stepV*0.5
+
+		s.tline(0, y, s.w-1, y, u, v, stepU, stepV, sample)
+	}
+}
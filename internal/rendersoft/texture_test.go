@@ -0,0 +1,113 @@
+package rendersoft
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// checker returns red on even texels and nothing (transparent) on odd texels
+func checker(u, v float64) (color.Color, bool) {
+	if (int(math.Floor(u))+int(math.Floor(v)))%2 == 0 {
+		return color.RGBA{R: 255, A: 255}, true
+	}
+	return nil, false
+}
+
+func TestTLine(t *testing.T) {
+	r := New(20, 20)
+
+	r.TLine(0, 5, 9, 5, 0, 0, 1, 0, checker)
+	for x := 0; x < 10; x++ {
+		want := x%2 == 0
+		if got := r.PGet(x, 5).R == 255; got != want {
+			t.Errorf("TLine pixel %d: got set=%v, want %v", x, got, want)
+		}
+	}
+
+	// Texture coordinates advance per pixel, not per unit of line length
+	r.Clear(color.RGBA{A: 255})
+	r.TLine(0, 0, 0, 9, 0, 0, 0.5, 0, checker)
+	if r.PGet(0, 0).R != 255 || r.PGet(0, 1).R != 255 || r.PGet(0, 2).R == 255 {
+		t.Errorf("TLine should sample at u += du for each pixel")
+	}
+
+	// Nil sampler should be ignored
+	r.TLine(0, 0, 5, 5, 0, 0, 1, 1, nil)
+}
+
+func TestTLineCamera(t *testing.T) {
+	r := New(20, 20)
+	solid := func(u, v float64) (color.Color, bool) { return color.RGBA{R: 255, A: 255}, true }
+
+	r.SetCamera(5, 5)
+	r.TLine(10, 10, 12, 10, 0, 0, 1, 0, solid)
+	if r.PGet(5, 5).R != 255 || r.PGet(7, 5).R != 255 {
+		t.Errorf("TLine should apply camera offset")
+	}
+}
+
+func TestTLineClip(t *testing.T) {
+	r := New(20, 20)
+	r.SetClip(5, 0, 10, 20)
+	calls := 0
+	firstU := -1.0
+	sample := func(u, v float64) (color.Color, bool) {
+		if calls == 0 {
+			firstU = u
+		}
+		calls++
+		return color.RGBA{R: 255, A: 255}, true
+	}
+
+	// A long line only samples the steps near the clip rect
+	r.TLine(-10000, 3, 10000, 3, 0, 0, 1, 0, sample)
+	if calls > 12 {
+		t.Errorf("sampled %d steps, want about 10", calls)
+	}
+	if firstU < 10003 || firstU > 10005 {
+		t.Errorf("first sample at u = %v, want the clip edge (10005)", firstU)
+	}
+	if r.PGet(4, 3).R == 255 || r.PGet(15, 3).R == 255 || r.PGet(5, 3).R != 255 || r.PGet(14, 3).R != 255 {
+		t.Error("TLine should draw exactly inside the clip rect")
+	}
+
+	// Entirely outside: nothing sampled
+	calls = 0
+	r.TLine(0, 30, 19, 40, 0, 0, 1, 0, sample)
+	if calls != 0 {
+		t.Errorf("sampled %d steps below the screen", calls)
+	}
+}
+
+func TestMode7(t *testing.T) {
+	r := New(64, 48)
+	var minV, maxV float64 = math.Inf(1), math.Inf(-1)
+	floor := func(u, v float64) (color.Color, bool) {
+		minV = math.Min(minV, v)
+		maxV = math.Max(maxV, v)
+		return color.RGBA{G: 255, A: 255}, true
+	}
+
+	horizon := 20
+	r.Mode7(0, 0, 0, horizon, 16, floor)
+
+	// Rows at and above the horizon stay untouched, rows below are filled
+	if r.PGet(10, horizon).G == 255 || r.PGet(10, 0).G == 255 {
+		t.Errorf("Mode7 should not draw at or above the horizon")
+	}
+	if r.PGet(10, horizon+1).G != 255 || r.PGet(63, 47).G != 255 {
+		t.Errorf("Mode7 should fill every row below the horizon")
+	}
+
+	// Facing +X, the lateral axis is V: it should span both sides of the viewer
+	if !(minV < 0 && maxV > 0) {
+		t.Errorf("Mode7 should sample both sides of the view direction, got v in [%f, %f]", minV, maxV)
+	}
+
+	// Invalid parameters should be ignored
+	r.Mode7(0, 0, 0, horizon, 0, floor)
+	r.Mode7(0, 0, 0, horizon, 16, nil)
+	r.Mode7(0, 0, 90, -100, 16, floor)
+	r.Mode7(0, 0, 0, 100, 16, floor)
+}
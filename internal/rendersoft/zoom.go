@@ -0,0 +1,77 @@
+package rendersoft
+
+// Integer zoom. While zoomed by z, drawing covers the top-left 1/z of the
+// screen in screen pixels; the area is scaled up to fill the screen when the
+// zoom changes, and into a copy when the frame is read. Drawing after a reset
+// to 1 (a HUD, say) is unzoomed. Zooming in shrinks what is already drawn into
+// the top-left area, so drawing scaled up by an earlier zoom survives zooming
+// in and out again.
+
+// SetZoom scales up any zoomed drawing, then sets the zoom (1 = none).
+// Setting the zoom already in use does nothing.
+func (s *Soft) SetZoom(z int) {
+	if z < 1 {
+		z = 1
+	}
+	if z == s.zoom {
+		return
+	}
+	if s.zoom > 1 {
+		s.unzoom()
+	}
+	s.zoom, s.viewW, s.viewH = z, (s.w+z-1)/z, (s.h+z-1)/z // Rounded up to cover the edges
+	if z > 1 {
+		s.shrink()
+	}
+}
+
+// GetZoom returns the current zoom
+func (s *Soft) GetZoom() int {
+	return s.zoom
+}
+
+// unzoom scales the zoomed area up to the whole screen, in place
+func (s *Soft) unzoom() {
+	s.scaleUp(s.idx)
+}
+
+// shrink keeps every zoom-th pixel of the screen in the zoomed area, the
+// inverse of scaleUp for drawing already scaled up. Each pixel's source is at
+// or after it, so filling from the start never reads a pixel already written.
+func (s *Soft) shrink() {
+	z := s.zoom
+	for y := 0; y < s.viewH; y++ {
+		src := y * z * s.w
+		row := y * s.w
+		for x := 0; x < s.viewW; x++ {
+			s.idx[row+x] = s.idx[src+x*z]
+		}
+	}
+}
+
+// frame returns the palette indices as displayed: the framebuffer, or while
+// zoomed a copy with the zoomed area scaled up
+func (s *Soft) frame() []uint8 {
+	if s.zoom == 1 {
+		return s.idx
+	}
+	if s.zoomed == nil {
+		s.zoomed = make([]uint8, len(s.idx))
+	}
+	s.scaleUp(s.zoomed)
+	return s.zoomed
+}
+
+// scaleUp writes the zoomed area of idx, scaled to the whole screen, to dst.
+// Each pixel's source is at or before it, so filling from the end never reads
+// a pixel already written when dst is idx.
+func (s *Soft) scaleUp(dst []uint8) {
+	z := s.zoom
+	for y := s.h - 1; y >= 0; y-- {
+		src := (y / z) * s.w
+		row := y * s.w
+		for x := s.w - 1; x >= 0; x-- {
+			dst[row+x] = s.idx[src+x/z]
+		}
+	}
+}
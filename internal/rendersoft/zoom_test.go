@@ -0,0 +1,80 @@
+package rendersoft
+
+import (
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/pal"
+)
+
+func TestZoom(t *testing.T) {
+	r := New(8, 6)
+	r.SetPalette(rampPalette(50))
+	r.Clear(pal.Index(0))
+
+	r.SetZoom(2)
+	if r.GetZoom() != 2 {
+		t.Fatalf("zoom = %d", r.GetZoom())
+	}
+	r.PSet(1, 2, pal.Index(5))
+	r.RectFill(3, 0, 10, 0, pal.Index(7)) // Clipped to the 4×3 zoomed area
+	r.PSet(4, 0, pal.Index(9))            // Outside the zoomed area
+
+	// HUD drawn after the reset is unzoomed
+	r.SetZoom(1)
+	r.PSet(0, 0, pal.Index(3))
+
+	want := map[[2]int]int{
+		{0, 0}: 3, {1, 0}: 0, {2, 4}: 5, {3, 5}: 5, {2, 3}: 0, {4, 4}: 0,
+		{6, 0}: 7, {7, 1}: 7, {5, 0}: 0,
+	}
+	for p, ci := range want {
+		if got := r.PGetIndex(p[0], p[1]); got != ci {
+			t.Errorf("(%d, %d) = %d, want %d", p[0], p[1], got, ci)
+		}
+	}
+
+	// Reading the frame scales up zoomed drawing but keeps the zoom (3× covers 3×2, rounded up)
+	r.Clear(pal.Index(0))
+	r.SetZoom(3)
+	r.PSet(1, 1, pal.Index(4))
+	pix := r.Pixels()
+	if r.GetZoom() != 3 {
+		t.Errorf("zoom after Pixels = %d, want 3", r.GetZoom())
+	}
+	if o := (5*8 + 3) * 4; pix[o] != 4 || pix[(2*8+2)*4] != 0 {
+		t.Errorf("zoom 3: %d %d", pix[o], pix[(2*8+2)*4])
+	}
+
+	// Drawing continues at the zoom after the read
+	r.PSet(0, 0, pal.Index(5))
+	r.SetZoom(1)
+	if r.PGetIndex(2, 2) != 5 || r.PGetIndex(5, 5) != 4 {
+		t.Errorf("after the read: %d %d", r.PGetIndex(2, 2), r.PGetIndex(5, 5))
+	}
+}
+
+func TestZoomReapplied(t *testing.T) {
+	r := New(8, 6)
+	r.SetPalette(rampPalette(50))
+	r.Clear(pal.Index(0))
+
+	// Applying the same zoom again keeps what was drawn
+	r.SetZoom(2)
+	r.PSet(1, 1, pal.Index(5))
+	r.SetZoom(2)
+	r.PSet(2, 1, pal.Index(6))
+	r.SetZoom(1)
+	if r.PGetIndex(3, 3) != 5 || r.PGetIndex(4, 3) != 6 || r.PGetIndex(1, 1) != 0 {
+		t.Errorf("reapplied zoom: %d %d %d", r.PGetIndex(3, 3), r.PGetIndex(4, 3), r.PGetIndex(1, 1))
+	}
+
+	// Zooming out and in again (cam_attach, camera, cam_attach) keeps it too
+	r.SetZoom(2)
+	r.PSet(0, 2, pal.Index(7))
+	r.SetZoom(1)
+	for p, ci := range map[[2]int]int{{3, 3}: 5, {2, 2}: 5, {5, 2}: 6, {1, 5}: 7, {1, 1}: 0} {
+		if got := r.PGetIndex(p[0], p[1]); got != ci {
+			t.Errorf("(%d, %d) = %d, want %d", p[0], p[1], got, ci)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+//go:build !js && !wasm
+
+package sdlrun
+
+import (
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// padButtons maps controller buttons to RetroForge buttons
+var padButtons = map[sdl.GameControllerButton]int{
+	sdl.CONTROLLER_BUTTON_DPAD_LEFT:     input.BtnLeft,
+	sdl.CONTROLLER_BUTTON_DPAD_RIGHT:    input.BtnRight,
+	sdl.CONTROLLER_BUTTON_DPAD_UP:       input.BtnUp,
+	sdl.CONTROLLER_BUTTON_DPAD_DOWN:     input.BtnDown,
+	sdl.CONTROLLER_BUTTON_A:             input.BtnO,
+	sdl.CONTROLLER_BUTTON_B:             input.BtnX,
+	sdl.CONTROLLER_BUTTON_START:         input.BtnStart,
+	sdl.CONTROLLER_BUTTON_BACK:          input.BtnSelect,
+	sdl.CONTROLLER_BUTTON_LEFTSHOULDER:  input.BtnL,
+	sdl.CONTROLLER_BUTTON_RIGHTSHOULDER: input.BtnR,
+}
+
+// controllers holds the open game controllers by joystick instance id.
+// SDL reports controllers connected at startup as added too.
+type controllers map[sdl.JoystickID]*sdl.GameController
+
+// handle applies a controller event, reporting whether it was one
+func (c controllers) handle(event sdl.Event) bool {
+	switch ev := event.(type) {
+	case *sdl.ControllerDeviceEvent:
+		if ev.Type == sdl.CONTROLLERDEVICEADDED {
+			// Which is the device index here; later events use the instance id
+			gc := sdl.GameControllerOpen(int(ev.Which))
+			if gc == nil {
+				return true
+			}
+			id := gc.Joystick().InstanceID()
+			if input.Connect(int(id), gc.Name()) < 0 {
+				gc.Close() // Every player has a controller
+				return true
+			}
+			c[id] = gc
+		} else if ev.Type == sdl.CONTROLLERDEVICEREMOVED {
+			input.Disconnect(int(ev.Which))
+			if gc, ok := c[ev.Which]; ok {
+				gc.Close()
+				delete(c, ev.Which)
+			}
+		}
+	case *sdl.ControllerButtonEvent:
+		input.PadRawButton(int(ev.Which), int(ev.Button), ev.State == sdl.PRESSED)
+		if b, ok := padButtons[sdl.GameControllerButton(ev.Button)]; ok {
+			input.PadButton(int(ev.Which), b, ev.State == sdl.PRESSED)
+		}
+	case *sdl.ControllerAxisEvent:
+		input.PadAxis(int(ev.Which), int(ev.Axis), ev.Value)
+	default:
+		return false
+	}
+	return true
+}
+
+// rumble starts the vibration players asked for this frame
+func (c controllers) rumble() {
+	for id, gc := range c {
+		s := input.Player(input.PadPlayer(int(id)))
+		if s == nil {
+			continue
+		}
+		if low, high, seconds, ok := s.TakeRumble(); ok {
+			_ = gc.Rumble(uint16(low*0xffff), uint16(high*0xffff), uint32(seconds*1000)) // Not every controller rumbles
+		}
+	}
+}
+
+// close closes every controller
+func (c controllers) close() {
+	for id, gc := range c {
+		input.Disconnect(int(id))
+		gc.Close()
+	}
+}
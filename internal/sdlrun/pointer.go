@@ -0,0 +1,68 @@
+//go:build !js && !wasm
+
+package sdlrun
+
+import (
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// mouseButtons maps SDL mouse buttons to input mouse buttons
+var mouseButtons = map[uint8]int{
+	sdl.BUTTON_LEFT:   input.MouseLeft,
+	sdl.BUTTON_RIGHT:  input.MouseRight,
+	sdl.BUTTON_MIDDLE: input.MouseMiddle,
+}
+
+// pointer feeds mouse and touch events to the input mouse in logical screen
+// pixels. Touches act as the left button; the mouse events SDL makes up for
+// them are ignored.
+type pointer struct {
+	win   *sdl.Window
+	w, h  int  // Logical screen size
+	moved bool // The mouse moved this frame
+}
+
+// handle applies a mouse or touch event, reporting whether it was one
+func (p *pointer) handle(event sdl.Event) bool {
+	switch ev := event.(type) {
+	case *sdl.MouseMotionEvent:
+		p.moved = p.moved || ev.Which != sdl.TOUCH_MOUSEID
+	case *sdl.MouseButtonEvent:
+		if b, ok := mouseButtons[ev.Button]; ok && ev.Which != sdl.TOUCH_MOUSEID {
+			p.moved = true
+			input.SetMouseButton(b, ev.State == sdl.PRESSED)
+		}
+	case *sdl.MouseWheelEvent:
+		steps := int(ev.Y)
+		if ev.Direction == sdl.MOUSEWHEEL_FLIPPED {
+			steps = -steps
+		}
+		input.AddWheel(steps)
+	case *sdl.TouchFingerEvent:
+		// Finger positions are 0..1 across the window
+		ww, wh := p.win.GetSize()
+		input.SetMouse(input.WindowToLogical(float64(ev.X)*float64(ww), float64(ev.Y)*float64(wh), float64(ww), float64(wh), float64(p.w), float64(p.h)))
+		switch ev.Type {
+		case sdl.FINGERDOWN:
+			input.SetMouseButton(input.MouseLeft, true)
+		case sdl.FINGERUP:
+			input.SetMouseButton(input.MouseLeft, false)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// update moves the input mouse to where the mouse is, after the frame's
+// events. The position is read in window pixels, before SDL's own scaling.
+func (p *pointer) update() {
+	if !p.moved {
+		return
+	}
+	p.moved = false
+	x, y, _ := sdl.GetMouseState()
+	ww, wh := p.win.GetSize()
+	input.SetMouse(input.WindowToLogical(float64(x), float64(y), float64(ww), float64(wh), float64(p.w), float64(p.h)))
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/app"
 	"github.com/AndrewDonelson/retroforge-engine/internal/audio"
+	"github.com/AndrewDonelson/retroforge-engine/internal/capture"
 	"github.com/AndrewDonelson/retroforge-engine/internal/engine"
 	"github.com/AndrewDonelson/retroforge-engine/internal/input"
 	"github.com/veandco/go-sdl2/sdl"
@@ -21,10 +22,12 @@ func RunWindow(e *engine.Engine, scale int) error {
 	if scale <= 0 {
 		scale = 2
 	}
-	if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+	if err := sdl.Init(sdl.INIT_VIDEO | sdl.INIT_GAMECONTROLLER); err != nil {
 		return err
 	}
 	defer sdl.Quit()
+	pads := make(controllers)
+	defer pads.close()
 
 	w := int32(e.Ren.Width() * scale)
 	h := int32(e.Ren.Height() * scale)
@@ -33,6 +36,9 @@ func RunWindow(e *engine.Engine, scale int) error {
 		return err
 	}
 	defer win.Destroy()
+	mouse := &pointer{win: win, w: e.Ren.Width(), h: e.Ren.Height()}
+	cursorShown := true
+	texting := false
 
 	ren, err := sdl.CreateRenderer(win, -1, sdl.RENDERER_ACCELERATED|sdl.RENDERER_PRESENTVSYNC)
 	if err != nil {
@@ -48,6 +54,7 @@ func RunWindow(e *engine.Engine, scale int) error {
 	defer tex.Destroy()
 
 	_ = audio.Init()
+	rec := capture.NewRecorder(e.Ren.Width(), e.Ren.Height(), capture.DefaultSeconds, capture.DefaultFPS)
 	running := true
 	for running {
 		// Step input state BEFORE polling events (prev = cur, then we update cur)
@@ -55,9 +62,16 @@ func RunWindow(e *engine.Engine, scale int) error {
 		input.Step()
 
 		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			if pads.handle(event) || mouse.handle(event) {
+				continue
+			}
 			switch ev := event.(type) {
 			case *sdl.QuitEvent:
 				running = false
+			case *sdl.TextInputEvent:
+				input.AddText(ev.GetText())
+			case *sdl.TextEditingEvent:
+				input.Compose(ev.GetText())
 			case *sdl.KeyboardEvent:
 				// ESC quitting disabled - games handle quitting through their own menus
 				// if ev.Type == sdl.KEYDOWN && ev.Keysym.Sym == sdl.K_ESCAPE {
@@ -68,25 +82,43 @@ func RunWindow(e *engine.Engine, scale int) error {
 					// Save screenshot
 					saveScreenshot(e)
 				}
-				switch ev.Keysym.Sym {
-				case sdl.K_LEFT:
-					input.Set(input.BtnLeft, down)
-				case sdl.K_RIGHT:
-					input.Set(input.BtnRight, down)
-				case sdl.K_UP:
-					input.Set(input.BtnUp, down)
-				case sdl.K_DOWN:
-					input.Set(input.BtnDown, down)
-				case sdl.K_z, sdl.K_RETURN: // O
-					input.Set(input.BtnO, down)
-				case sdl.K_x, sdl.K_SPACE: // X
-					input.Set(input.BtnX, down)
+				if ev.Type == sdl.KEYDOWN && ev.Repeat == 0 {
+					// Save the last seconds as a GIF at 2× (F9) or 3× (F10)
+					switch ev.Keysym.Sym {
+					case sdl.K_F9:
+						saveClip(rec, 2)
+					case sdl.K_F10:
+						saveClip(rec, 3)
+					}
 				}
+				pressKey(ev.Keysym.Sym, down)
+			}
+		}
+
+		mouse.update()
+		if input.Texting() != texting {
+			// The cart turned text input on or off last frame
+			texting = input.Texting()
+			if texting {
+				sdl.StartTextInput()
+			} else {
+				sdl.StopTextInput()
 			}
 		}
 
 		// Run one frame (now input state is correct: prev has old state, cur has new state)
 		e.RunFrames(1)
+		if e.DrawsCursor() == cursorShown {
+			// Hide the system cursor while the cart draws its own
+			cursorShown = !cursorShown
+			toggle := sdl.ENABLE
+			if !cursorShown {
+				toggle = sdl.DISABLE
+			}
+			sdl.ShowCursor(toggle)
+		}
+		pads.rumble()
+		rec.Add(e.Ren.Indexed())
 		if app.QuitRequested() {
 			running = false
 		}
@@ -110,6 +142,41 @@ func RunWindow(e *engine.Engine, scale int) error {
 	return nil
 }
 
+// pressKey passes a key press or release to the input package: the raw key
+// and the button it presses. While text input is on, presses of keys that type
+// go to the text instead; releases always pass, so nothing stays held.
+func pressKey(sym sdl.Keycode, down bool) {
+	if down && input.Texting() && input.TypesText(int(sym)) {
+		if sym == sdl.K_BACKSPACE {
+			input.Backspace()
+		}
+		return // Typing, not pressing keys or buttons
+	}
+	input.SetKey(sdl.GetKeyName(sym), down)
+	switch sym {
+	case sdl.K_LEFT:
+		input.Set(input.BtnLeft, down)
+	case sdl.K_RIGHT:
+		input.Set(input.BtnRight, down)
+	case sdl.K_UP:
+		input.Set(input.BtnUp, down)
+	case sdl.K_DOWN:
+		input.Set(input.BtnDown, down)
+	case sdl.K_z, sdl.K_RETURN: // O
+		input.Set(input.BtnO, down)
+	case sdl.K_x, sdl.K_SPACE: // X
+		input.Set(input.BtnX, down)
+	case sdl.K_ESCAPE, sdl.K_p: // Start
+		input.Set(input.BtnStart, down)
+	case sdl.K_TAB: // Select
+		input.Set(input.BtnSelect, down)
+	case sdl.K_q: // L
+		input.Set(input.BtnL, down)
+	case sdl.K_e: // R
+		input.Set(input.BtnR, down)
+	}
+}
+
 func saveScreenshot(e *engine.Engine) {
 	pix := e.Ren.Pixels()
 	w := e.Ren.Width()
@@ -126,3 +193,18 @@ func saveScreenshot(e *engine.Engine) {
 	defer f.Close()
 	_ = png.Encode(f, img)
 }
+
+// saveClip writes the recorded frames as a GIF in the background, so the game
+// keeps running while it encodes; recording starts again from empty
+func saveClip(rec *capture.Recorder, scale int) {
+	frames, fps := rec.Take(), rec.FPS()
+	filename := time.Now().Format("clip-20060102-150405.gif")
+	go func() {
+		f, err := os.Create(filename)
+		if err != nil {
+			return // silently fail
+		}
+		defer f.Close()
+		_ = capture.WriteGIF(f, frames, scale, fps)
+	}()
+}
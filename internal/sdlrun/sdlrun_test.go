@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/AndrewDonelson/retroforge-engine/internal/engine"
+	"github.com/AndrewDonelson/retroforge-engine/internal/input"
+	"github.com/veandco/go-sdl2/sdl"
 )
 
 func TestSaveScreenshot(t *testing.T) {
@@ -53,3 +55,29 @@ func TestSaveScreenshotEdgeCases(t *testing.T) {
 	e5.RunFrames(1)
 	saveScreenshot(e5)
 }
+
+func TestPressKeyWhileTexting(t *testing.T) {
+	input.Reset()
+	defer input.Reset()
+	defer input.StopText()
+	z := sdl.GetKeyName(sdl.K_z)
+
+	// Z held before text input starts is released while typing
+	pressKey(sdl.K_z, true)
+	input.StartText("", 0)
+	pressKey(sdl.K_z, false)
+	if input.Btn(input.BtnO) || input.Key(z) {
+		t.Error("Z stayed held after its release while typing")
+	}
+
+	// Typing Z presses neither the key nor O; Return still presses O
+	input.Step()
+	pressKey(sdl.K_z, true)
+	if input.Btn(input.BtnO) || input.Keyp(z) {
+		t.Error("typing Z pressed O or the Z key")
+	}
+	pressKey(sdl.K_RETURN, true)
+	if !input.Btn(input.BtnO) {
+		t.Error("Return didn't press O while typing")
+	}
+}
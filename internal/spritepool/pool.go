@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AndrewDonelson/retroforge-engine/internal/anim"
 	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
 )
 
@@ -18,6 +19,7 @@ type SpriteInstance struct {
 	IsPooled   bool                   // Whether this instance came from a pool
 	Data       cartio.SpriteData      // Reference to sprite data
 	CustomData map[string]interface{} // Custom data storage for Lua
+	Anim       *anim.Animator         // Optional animation (nil = draw Data)
 }
 
 // Pool manages a collection of sprite instances for reuse
@@ -70,9 +72,10 @@ func (p *Pool) Acquire() (*SpriteInstance, error) {
 		p.active[instance] = true
 		instance.IsActive = true
 		instance.Age = 0
-		// Reset position and custom data
+		// Reset position, animation and custom data
 		instance.X = 0
 		instance.Y = 0
+		instance.Anim = nil
 		for k := range instance.CustomData {
 			delete(instance.CustomData, k)
 		}
@@ -137,6 +140,7 @@ func (p *Pool) Release(instance *SpriteInstance) error {
 	instance.Age = 0
 	instance.X = 0
 	instance.Y = 0
+	instance.Anim = nil
 	for k := range instance.CustomData {
 		delete(instance.CustomData, k)
 	}
@@ -154,6 +158,13 @@ func (p *Pool) Update(deltaTime time.Duration) []*SpriteInstance {
 
 	expired := make([]*SpriteInstance, 0)
 
+	// Advance animations
+	for instance := range p.active {
+		if instance.IsActive && instance.Anim != nil {
+			instance.Anim.Step(deltaTime.Seconds())
+		}
+	}
+
 	// If sprite has lifetime, check for expiration
 	if p.spriteData.Lifetime > 0 {
 		lifetime := time.Duration(p.spriteData.Lifetime) * time.Millisecond
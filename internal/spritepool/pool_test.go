@@ -3,6 +3,9 @@ package spritepool
 import (
 	"testing"
 	"time"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/anim"
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
 )
 
 func TestNewPool(t *testing.T) {
@@ -207,6 +210,31 @@ func TestPoolUpdateNoLifetime(t *testing.T) {
 	}
 }
 
+func TestPoolUpdateAnimations(t *testing.T) {
+	spriteData := createTestSpriteData("coin", false, 100, 0)
+	pool := NewPool("coin", spriteData, 2, 100)
+
+	clips := cartio.AnimationMap{"spin": {Duration: 100, Frames: []cartio.AnimFrame{{Sprite: "coin1"}, {Sprite: "coin2"}}}}
+	instance, err := pool.Acquire()
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	instance.Anim, _ = anim.New(clips, "spin")
+
+	pool.Update(100 * time.Millisecond)
+	if got := instance.Anim.Sprite(); got != "coin2" {
+		t.Errorf("expected Update to advance the animation to coin2, got %s", got)
+	}
+
+	// Released instances drop their animator
+	if err := pool.Release(instance); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if instance.Anim != nil {
+		t.Error("expected Release to clear the animator")
+	}
+}
+
 func TestPoolGetStats(t *testing.T) {
 	spriteData := createTestSpriteData("bullet", false, 100, 2000)
 	pool := NewPool("bullet", spriteData, 10, 100)
@@ -2,6 +2,7 @@ package statemachine
 
 import (
 	"fmt"
+	"math"
 	"sync"
 )
 
@@ -104,6 +105,22 @@ func (ls *LuaState) Shutdown() {
 	}
 }
 
+// Transition animates a state change: the change happens at the midpoint of
+// Duration seconds, and input is blocked until it ends. Kind is interpreted by
+// whoever draws the transition.
+type Transition struct {
+	Kind     string
+	Duration float64
+}
+
+// activeTransition is a transition in progress
+type activeTransition struct {
+	Transition
+	target  string  // State entered at the midpoint
+	elapsed float64 // Seconds since the transition started
+	changed bool    // Whether target has been entered
+}
+
 // StateMachine manages game states with support for state stacking and shared context
 type StateMachine struct {
 	mu sync.RWMutex
@@ -122,6 +139,10 @@ type StateMachine struct {
 	pendingPushState   string // Queue a PushState operation
 	pendingPopState    bool   // Queue a PopState operation
 	inCallback         bool   // Flag to detect if we're in a callback (HandleInput/Update/Draw)
+
+	// Transitions (ChangeStateWith)
+	pendingTransition Transition        // Transition for the queued ChangeState (none if Duration is 0)
+	transition        *activeTransition // Transition in progress (nil = none)
 }
 
 // NewStateMachine creates a new generic state machine
@@ -211,6 +232,7 @@ func (sm *StateMachine) ChangeState(name string) error {
 	if inCallback {
 		sm.mu.Lock()
 		sm.pendingChangeState = name
+		sm.pendingTransition = Transition{}
 		sm.pendingPushState = ""
 		sm.pendingPopState = false
 		sm.mu.Unlock()
@@ -218,9 +240,88 @@ func (sm *StateMachine) ChangeState(name string) error {
 	}
 
 	// Otherwise execute immediately
+	sm.mu.Lock()
+	sm.transition = nil
+	sm.mu.Unlock()
 	return sm.doChangeState(name)
 }
 
+// ChangeStateWith changes to a new state through a transition. The current
+// state keeps running until the midpoint, when the stack is replaced as by
+// ChangeState. A transition without a duration changes immediately.
+func (sm *StateMachine) ChangeStateWith(name string, t Transition) error {
+	if t.Duration <= 0 {
+		return sm.ChangeState(name)
+	}
+	if !sm.IsStateRegistered(name) {
+		return fmt.Errorf("state '%s' is not registered", name)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	// If called from within HandleInput/Update/Draw, queue it
+	if sm.inCallback {
+		sm.pendingChangeState = name
+		sm.pendingTransition = t
+		sm.pendingPushState = ""
+		sm.pendingPopState = false
+		return nil
+	}
+
+	// Otherwise start it now
+	sm.transition = &activeTransition{Transition: t, target: name}
+	return nil
+}
+
+// Transition returns the kind and progress (0..1) of the transition in
+// progress; ok is false if there is none.
+func (sm *StateMachine) Transition() (kind string, progress float64, ok bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.transition == nil {
+		return "", 0, false
+	}
+	return sm.transition.Kind, math.Min(sm.transition.elapsed/sm.transition.Duration, 1), true
+}
+
+// InTransition reports whether a transition is running (input is blocked)
+func (sm *StateMachine) InTransition() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.transition != nil
+}
+
+// stepTransition advances the transition in progress, changing state at the midpoint
+func (sm *StateMachine) stepTransition(dt float64) error {
+	sm.mu.Lock()
+	t := sm.transition
+	if t == nil {
+		sm.mu.Unlock()
+		return nil
+	}
+	t.elapsed += dt
+	change := !t.changed && t.elapsed >= t.Duration/2
+	t.changed = t.changed || change
+	if t.elapsed >= t.Duration {
+		sm.transition = nil
+	}
+	sm.mu.Unlock()
+
+	if !change {
+		return nil
+	}
+	if err := sm.doChangeState(t.target); err != nil {
+		sm.mu.Lock()
+		if sm.transition == t {
+			sm.transition = nil
+		}
+		sm.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
 // doChangeState performs the actual state change (must be called without locks or with write lock)
 func (sm *StateMachine) doChangeState(name string) error {
 	sm.mu.Lock()
@@ -383,9 +484,13 @@ func (sm *StateMachine) PopAllStates() {
 	}
 }
 
-// HandleInput calls HandleInput on the top state
+// HandleInput calls HandleInput on the top state, unless a transition is running
 func (sm *StateMachine) HandleInput() {
 	sm.mu.Lock()
+	if sm.transition != nil {
+		sm.mu.Unlock()
+		return // Input is blocked during transitions
+	}
 	sm.inCallback = true
 	sm.mu.Unlock()
 
@@ -416,11 +521,14 @@ func (sm *StateMachine) Update(dt float64) {
 	pendingPop := sm.pendingPopState
 
 	var changeStateName string
+	var changeTransition Transition
 	var pushStateName string
 
 	if pendingChange {
 		changeStateName = sm.pendingChangeState
+		changeTransition = sm.pendingTransition
 		sm.pendingChangeState = ""
+		sm.pendingTransition = Transition{}
 		sm.pendingPushState = ""
 		sm.pendingPopState = false
 	} else if pendingPush {
@@ -436,7 +544,15 @@ func (sm *StateMachine) Update(dt float64) {
 	sm.mu.Unlock()
 
 	// Execute pending state changes outside lock to avoid deadlock
-	if pendingChange {
+	if pendingChange && changeTransition.Duration > 0 {
+		// Start the transition; the state changes at its midpoint
+		sm.mu.Lock()
+		sm.transition = &activeTransition{Transition: changeTransition, target: changeStateName}
+		sm.mu.Unlock()
+	} else if pendingChange {
+		sm.mu.Lock()
+		sm.transition = nil
+		sm.mu.Unlock()
 		if err := sm.doChangeState(changeStateName); err != nil {
 			// State change failed - don't call Update this frame to avoid issues
 			sm.mu.Lock()
@@ -465,6 +581,11 @@ func (sm *StateMachine) Update(dt float64) {
 		// After pop, the previous state is now on top, so it will get Update called below
 	}
 
+	// Advance a running transition (may change state at its midpoint)
+	if err := sm.stepTransition(dt); err != nil {
+		return
+	}
+
 	// Now call Update on the current top state (which may have just changed)
 	// Re-read the stack after state changes to ensure we have the latest state
 	sm.mu.Lock()
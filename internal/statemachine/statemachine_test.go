@@ -746,3 +746,98 @@ func TestGetActiveStateMultiple(t *testing.T) {
 		t.Errorf("expected state2, got %s (exists: %v)", name, exists)
 	}
 }
+
+// Test ChangeStateWith changes state at the transition's midpoint
+func TestChangeStateWith(t *testing.T) {
+	sm := NewStateMachine()
+	state1 := NewTestState("state1")
+	state2 := NewTestState("state2")
+	sm.RegisterStateInstance("state1", state1)
+	sm.RegisterStateInstance("state2", state2)
+	sm.ChangeState("state1")
+
+	if err := sm.ChangeStateWith("nonexistent", Transition{Kind: "fade", Duration: 1}); err == nil {
+		t.Error("expected error for non-existent state")
+	}
+	if err := sm.ChangeStateWith("state2", Transition{Kind: "fade", Duration: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sm.InTransition() {
+		t.Fatal("transition should be running")
+	}
+
+	// Before the midpoint the outgoing state keeps running, without input
+	sm.HandleInput()
+	sm.Update(0.25)
+	if name, _ := sm.GetActiveState(); name != "state1" {
+		t.Errorf("expected state1 before the midpoint, got %s", name)
+	}
+	if _, _, input, update, _, _, _ := state1.GetCounts(); input || !update {
+		t.Errorf("outgoing state: input %v, update %v", input, update)
+	}
+	if kind, progress, ok := sm.Transition(); !ok || kind != "fade" || progress != 0.25 {
+		t.Errorf("Transition() = %q, %v, %v", kind, progress, ok)
+	}
+
+	// Midpoint
+	sm.Update(0.25)
+	if name, _ := sm.GetActiveState(); name != "state2" {
+		t.Errorf("expected state2 at the midpoint, got %s", name)
+	}
+	if state1.GetExitCount() != 1 || state2.GetEnterCount() != 1 {
+		t.Errorf("exit %d, enter %d", state1.GetExitCount(), state2.GetEnterCount())
+	}
+
+	// End
+	sm.Update(0.5)
+	if sm.InTransition() {
+		t.Error("transition should have ended")
+	}
+	sm.HandleInput()
+	if _, _, input, _, _, _, _ := state2.GetCounts(); !input {
+		t.Error("input should reach the state after the transition")
+	}
+
+	// Without a duration the change is immediate
+	sm.ChangeStateWith("state1", Transition{Kind: "fade"})
+	if name, _ := sm.GetActiveState(); name != "state1" || sm.InTransition() {
+		t.Errorf("expected immediate change to state1, got %s", name)
+	}
+}
+
+// changerState changes state with a transition from HandleInput
+type changerState struct {
+	TestState
+	target string
+}
+
+func (cs *changerState) HandleInput(sm *StateMachine) {
+	sm.ChangeStateWith(cs.target, Transition{Kind: "wipe", Duration: 0.5})
+}
+
+// Test ChangeStateWith from a callback is queued until Update
+func TestChangeStateWithQueued(t *testing.T) {
+	sm := NewStateMachine()
+	sm.RegisterStateInstance("changer", &changerState{target: "next"})
+	sm.RegisterStateInstance("next", NewTestState("next"))
+	sm.ChangeState("changer")
+
+	sm.HandleInput()
+	if sm.InTransition() {
+		t.Error("transition should be queued until Update")
+	}
+	sm.Update(0.1)
+	if kind, _, ok := sm.Transition(); !ok || kind != "wipe" {
+		t.Fatalf("Transition() = %q, %v", kind, ok)
+	}
+	sm.Update(0.2)
+	if name, _ := sm.GetActiveState(); name != "next" {
+		t.Errorf("expected next, got %s", name)
+	}
+
+	// ChangeState cancels a running transition
+	sm.ChangeState("changer")
+	if sm.InTransition() {
+		t.Error("ChangeState should cancel the transition")
+	}
+}
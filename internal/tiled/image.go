@@ -0,0 +1,63 @@
+package tiled
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png" // Tileset images
+	"strconv"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// loadImage decodes a tileset image into palette indices (-1 = transparent).
+// Pixels that are mostly transparent or match trans ("rrggbb") are transparent;
+// the rest take the nearest palette color.
+func (imp *Importer) loadImage(name, trans string) ([][]int, int, int, error) {
+	data, err := imp.read(name)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("%s: %w", name, err)
+	}
+	key, hasKey := parseTrans(trans)
+
+	b := img.Bounds()
+	cache := make(map[color.NRGBA]int)
+	pix := make([][]int, b.Dy())
+	for y := range pix {
+		pix[y] = make([]int, b.Dx())
+		for x := range pix[y] {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			if c.A < 128 || (hasKey && c.R == key.R && c.G == key.G && c.B == key.B) {
+				pix[y][x] = -1
+				continue
+			}
+			c.A = 255
+			idx, ok := cache[c]
+			if !ok {
+				idx = palette.Nearest(imp.Palette, c)
+				cache[c] = idx
+			}
+			pix[y][x] = idx
+		}
+	}
+	return pix, b.Dx(), b.Dy(), nil
+}
+
+// parseTrans parses a Tiled transparent color ("rrggbb" or "#rrggbb")
+func parseTrans(s string) (color.NRGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 8 {
+		s = s[2:] // Tiled writes #aarrggbb when the alpha isn't opaque
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, true
+}
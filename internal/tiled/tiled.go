@@ -0,0 +1,471 @@
+// Package tiled imports maps made with the Tiled editor (.tmx and .tmj files)
+// into a cart's native data: maps.json maps, tileset.json tiles and sprites.
+package tiled
+
+import (
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// FlagSolid is set on tiles that have collision shapes in Tiled
+const FlagSolid uint8 = 1
+
+// CollisionLayer is the object layer that receives the collision shapes of
+// placed tiles, as objects of type cartio.CollisionType.
+const CollisionLayer = "collision"
+
+// Tile GID bits Tiled uses for flipped and rotated tiles
+const flipMask = 0xF0000000
+
+// IsMap reports whether name is a Tiled map file (.tmx or .tmj)
+func IsMap(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".tmx", ".tmj":
+		return true
+	}
+	return false
+}
+
+// MapName returns the native map name of a Tiled map file: its path under
+// assets/ without the extension (e.g. "levels/level1").
+func MapName(file string) string {
+	return strings.TrimSuffix(file, path.Ext(file))
+}
+
+// Importer converts Tiled maps into native cart data. Set Maps, Tileset and
+// Sprites to the cart's existing data before importing; imported tiles are
+// numbered after the tileset's last tile.
+type Importer struct {
+	Read    func(name string) ([]byte, error) // Reads a file by its slash-separated path under assets/
+	Palette []color.RGBA                      // Cart palette tile images are quantized to
+	Maps    cartio.MapSet
+	Tileset cartio.TilesetData
+	Sprites cartio.SpriteMap
+	Files   []string // Tiled files read (maps, tilesets, images), which carts don't need at runtime
+
+	tilesets map[string]int // Tileset key -> native index of its tile 0
+	names    map[string]bool
+	seen     map[string]bool
+	next     int // Next free native tile index
+}
+
+// NewImporter creates an importer reading files with read
+func NewImporter(read func(name string) ([]byte, error), palette []color.RGBA) *Importer {
+	return &Importer{Read: read, Palette: palette}
+}
+
+// mapDoc is a Tiled map decoded from either format
+type mapDoc struct {
+	orientation   string
+	width, height int
+	tileW, tileH  int
+	infinite      bool
+	tilesets      []tilesetRef
+	layers        []layerDoc
+}
+
+// tilesetRef is a map's reference to a tileset, external (source) or embedded
+type tilesetRef struct {
+	firstGID uint32
+	source   string // Resolved path of an external tileset
+	embedded *tilesetDoc
+}
+
+type tilesetDoc struct {
+	name           string
+	tileW, tileH   int
+	columns, count int
+	margin         int
+	spacing        int
+	image          string // Resolved path ("" for image collections)
+	trans          string // Transparent color, "rrggbb"
+	tiles          map[int]*tileDoc
+}
+
+type tileDoc struct {
+	image  string // Resolved path of the tile's own image (image collections)
+	props  map[string]interface{}
+	shapes []object
+	anim   []frameDoc
+}
+
+type frameDoc struct {
+	tileID   int
+	duration int
+}
+
+type layerDoc struct {
+	name    string
+	objects bool
+	gids    []uint32
+	objs    []object
+}
+
+// object is a map object whose tile GID is still to be resolved
+type object struct {
+	cartio.MapObject
+	gid uint32
+}
+
+// Import converts a Tiled map, given by its path under assets/, and adds it
+// to Maps as MapName(file) along with the tiles and sprites of its tilesets.
+func (imp *Importer) Import(file string) error {
+	data, err := imp.read(file)
+	if err != nil {
+		return err
+	}
+	var doc *mapDoc
+	if strings.EqualFold(path.Ext(file), ".tmx") {
+		doc, err = decodeTMX(data, path.Dir(file))
+	} else {
+		doc, err = decodeTMJ(data, path.Dir(file))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if doc.orientation != "" && doc.orientation != "orthogonal" {
+		return fmt.Errorf("%s: %s maps are not supported", file, doc.orientation)
+	}
+	if doc.infinite {
+		return fmt.Errorf("%s: infinite maps are not supported", file)
+	}
+	if doc.width <= 0 || doc.height <= 0 {
+		return fmt.Errorf("%s: width and height must be positive", file)
+	}
+
+	// Register tilesets, sorted by first GID for lookups
+	type gidRange struct {
+		first uint32
+		base  int
+		ts    *tilesetDoc
+	}
+	var ranges []gidRange
+	for _, ref := range doc.tilesets {
+		ts, key := ref.embedded, ref.source
+		fallback := strings.TrimSuffix(path.Base(ref.source), path.Ext(ref.source))
+		if ref.source != "" {
+			if ts, err = imp.loadTileset(ref.source); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		} else {
+			// Embedded tilesets need not be named; firstgid tells them apart
+			key = fmt.Sprintf("%s#%d", file, ref.firstGID)
+			fallback = fmt.Sprintf("tiles%d", ref.firstGID)
+		}
+		base, err := imp.addTileset(key, fallback, ts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		ranges = append(ranges, gidRange{ref.firstGID, base, ts})
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].first < ranges[j].first })
+	lookup := func(gid uint32) (int, *tileDoc) {
+		gid &^= flipMask // Flips and rotations are not supported
+		if gid == 0 {
+			return 0, nil
+		}
+		for i := len(ranges) - 1; i >= 0; i-- {
+			if r := ranges[i]; gid >= r.first {
+				id := int(gid - r.first)
+				return r.base + id, r.ts.tiles[id]
+			}
+		}
+		return 0, nil
+	}
+
+	m := cartio.MapData{Width: doc.width, Height: doc.height, TileW: doc.tileW, TileH: doc.tileH}
+	var collision []cartio.MapObject
+	for _, l := range doc.layers {
+		if l.objects {
+			layer := cartio.MapLayer{Name: l.name, Type: cartio.LayerObjects}
+			for _, o := range l.objs {
+				obj := o.MapObject
+				if o.gid != 0 {
+					// Tile objects are positioned by their bottom-left corner
+					obj.Tile, _ = lookup(o.gid)
+					obj.Y -= obj.H
+				}
+				layer.Objects = append(layer.Objects, obj)
+			}
+			m.Layers = append(m.Layers, layer)
+			continue
+		}
+		if len(l.gids) != doc.width*doc.height {
+			return fmt.Errorf("%s: layer %s has %d tiles, expected %d", file, l.name, len(l.gids), doc.width*doc.height)
+		}
+		tiles := make([]int, len(l.gids))
+		for i, gid := range l.gids {
+			tiles[i], _ = lookup(gid)
+		}
+		m.Layers = append(m.Layers, cartio.MapLayer{Name: l.name, Tiles: tiles})
+		collision = append(collision, collisionShapes(doc, l.gids, lookup)...)
+	}
+	if len(collision) > 0 {
+		m.Layers = addCollision(m.Layers, collision)
+	}
+
+	if imp.Maps == nil {
+		imp.Maps = make(cartio.MapSet)
+	}
+	imp.Maps[MapName(file)] = m
+	return nil
+}
+
+// collisionShapes places the collision shapes of a tile layer's tiles in map
+// pixels. Runs of tiles whose shape is a single rectangle filling the tile
+// are merged into one rectangle per row.
+func collisionShapes(doc *mapDoc, gids []uint32, lookup func(uint32) (int, *tileDoc)) []cartio.MapObject {
+	var out []cartio.MapObject
+	for y := 0; y < doc.height; y++ {
+		run := -1 // Index in out of the rectangle being extended
+		for x := 0; x < doc.width; x++ {
+			_, tile := lookup(gids[y*doc.width+x])
+			if tile == nil || len(tile.shapes) == 0 {
+				run = -1
+				continue
+			}
+			px, py := float64(x*doc.tileW), float64(y*doc.tileH)
+			if s := tile.shapes[0].MapObject; len(tile.shapes) == 1 && isRect(s) &&
+				s.X == 0 && s.Y == 0 && s.W == float64(doc.tileW) && s.H == float64(doc.tileH) {
+				if run >= 0 {
+					out[run].W += s.W
+					continue
+				}
+				out = append(out, cartio.MapObject{Type: cartio.CollisionType, X: px, Y: py, W: s.W, H: s.H})
+				run = len(out) - 1
+				continue
+			}
+			run = -1
+			for _, s := range tile.shapes {
+				obj := s.MapObject
+				obj.ID, obj.Type = 0, cartio.CollisionType
+				obj.X += px
+				obj.Y += py
+				out = append(out, obj)
+			}
+		}
+	}
+	return out
+}
+
+func isRect(o cartio.MapObject) bool {
+	return o.Shape == "" || o.Shape == cartio.ShapeRect
+}
+
+// addCollision appends collision objects to the map's collision object
+// layer, creating it if needed.
+func addCollision(layers []cartio.MapLayer, objs []cartio.MapObject) []cartio.MapLayer {
+	for i, l := range layers {
+		if l.IsObjects() && l.Name == CollisionLayer {
+			layers[i].Objects = append(layers[i].Objects, objs...)
+			return layers
+		}
+	}
+	return append(layers, cartio.MapLayer{Name: CollisionLayer, Type: cartio.LayerObjects, Objects: objs})
+}
+
+// read reads a file, remembering it in Files
+func (imp *Importer) read(name string) ([]byte, error) {
+	if imp.seen == nil {
+		imp.seen = make(map[string]bool)
+	}
+	if !imp.seen[name] {
+		imp.seen[name] = true
+		imp.Files = append(imp.Files, name)
+	}
+	return imp.Read(name)
+}
+
+// loadTileset reads an external .tsx or .tsj tileset
+func (imp *Importer) loadTileset(name string) (*tilesetDoc, error) {
+	data, err := imp.read(name)
+	if err != nil {
+		return nil, err
+	}
+	var ts *tilesetDoc
+	if strings.EqualFold(path.Ext(name), ".tsx") {
+		ts, err = decodeTSX(data, path.Dir(name))
+	} else {
+		ts, err = decodeTSJ(data, path.Dir(name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return ts, nil
+}
+
+// addTileset adds a tileset's tiles to the cart tileset and their images to
+// the sprites, once per key. Its sprites are named after the tileset, or
+// fallback if it has no name. It returns the native index of tile 0.
+func (imp *Importer) addTileset(key, fallback string, ts *tilesetDoc) (int, error) {
+	if base, ok := imp.tilesets[key]; ok {
+		return base, nil
+	}
+	if imp.tilesets == nil {
+		imp.tilesets = make(map[string]int)
+		imp.names = make(map[string]bool)
+		imp.next = imp.firstFree()
+	}
+	if imp.Sprites == nil {
+		imp.Sprites = make(cartio.SpriteMap)
+	}
+	if imp.Tileset.Tiles == nil {
+		imp.Tileset.Tiles = make(map[int]cartio.TileDef)
+	}
+
+	sprites, err := imp.tileSprites(ts)
+	if err != nil {
+		return 0, err
+	}
+	name := ts.name
+	if name == "" {
+		name = fallback
+	}
+	prefix := name
+	for i := 2; imp.names[prefix]; i++ {
+		prefix = fmt.Sprintf("%s%d", name, i)
+	}
+	imp.names[prefix] = true
+
+	base := imp.next
+	for id, sprite := range sprites {
+		if sprite == nil {
+			continue
+		}
+		name := fmt.Sprintf("%s:%d", prefix, id)
+		imp.Sprites[name] = *sprite
+		def := cartio.TileDef{Sprite: name}
+		if tile := ts.tiles[id]; tile != nil {
+			if f, ok := tile.props["flags"].(float64); ok {
+				def.Flags = uint8(f)
+			}
+			if len(tile.shapes) > 0 {
+				def.Flags |= FlagSolid
+			}
+			for _, f := range tile.anim {
+				def.Anim = append(def.Anim, cartio.TileFrame{Tile: base + f.tileID, Duration: f.duration})
+			}
+		}
+		imp.Tileset.Tiles[base+id] = def
+	}
+	imp.tilesets[key] = base
+	imp.next = base + len(sprites)
+	return base, nil
+}
+
+// firstFree returns the first tile index after the cart's own tiles,
+// including the cells of its tileset sheet
+func (imp *Importer) firstFree() int {
+	next := 1
+	for i := range imp.Tileset.Tiles {
+		if i >= next {
+			next = i + 1
+		}
+	}
+	if sheet, ok := imp.Sprites[imp.Tileset.Sheet]; ok && imp.Tileset.Sheet != "" {
+		tw, th := imp.Tileset.TileW, imp.Tileset.TileH
+		if tw <= 0 || th <= 0 {
+			tw, th = 8, 8
+		}
+		if cells := (sheet.Width / tw) * (sheet.Height / th); cells > next {
+			next = cells
+		}
+	}
+	return next
+}
+
+// tileSprites cuts a tileset into one sprite per tile ID (nil = no tile)
+func (imp *Importer) tileSprites(ts *tilesetDoc) ([]*cartio.SpriteData, error) {
+	if ts.image == "" {
+		// Image collection: every tile has its own image
+		n := ts.count
+		for id := range ts.tiles {
+			if id >= n {
+				n = id + 1
+			}
+		}
+		sprites := make([]*cartio.SpriteData, n)
+		for id, tile := range ts.tiles {
+			if tile.image == "" {
+				continue
+			}
+			pix, w, h, err := imp.loadImage(tile.image, ts.trans)
+			if err != nil {
+				return nil, err
+			}
+			sprites[id] = &cartio.SpriteData{Width: w, Height: h, Pixels: pix}
+		}
+		return sprites, nil
+	}
+
+	if ts.tileW <= 0 || ts.tileH <= 0 {
+		return nil, fmt.Errorf("tileset %s: tile size must be positive", ts.name)
+	}
+	pix, w, h, err := imp.loadImage(ts.image, ts.trans)
+	if err != nil {
+		return nil, err
+	}
+	// Trust the image over the declared column count
+	cols := (w - 2*ts.margin + ts.spacing) / (ts.tileW + ts.spacing)
+	if ts.columns > 0 && ts.columns < cols {
+		cols = ts.columns
+	}
+	rows := (h - 2*ts.margin + ts.spacing) / (ts.tileH + ts.spacing)
+	if cols <= 0 || rows <= 0 {
+		return nil, fmt.Errorf("tileset %s: image %s is smaller than one tile", ts.name, ts.image)
+	}
+	n := ts.count
+	if n <= 0 || n > cols*rows {
+		n = cols * rows
+	}
+	sprites := make([]*cartio.SpriteData, n)
+	for id := range sprites {
+		ox := ts.margin + (id%cols)*(ts.tileW+ts.spacing)
+		oy := ts.margin + (id/cols)*(ts.tileH+ts.spacing)
+		sprite := &cartio.SpriteData{Width: ts.tileW, Height: ts.tileH, Pixels: make([][]int, ts.tileH)}
+		for y := range sprite.Pixels {
+			sprite.Pixels[y] = append([]int(nil), pix[oy+y][ox:ox+ts.tileW]...)
+		}
+		sprites[id] = sprite
+	}
+	return sprites, nil
+}
+
+// FindMaps lists the Tiled maps under a cart's assets folder as sorted,
+// slash-separated paths relative to it
+func FindMaps(assetsDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(assetsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && IsMap(p) {
+			rel, err := filepath.Rel(assetsDir, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	sort.Strings(files)
+	return files, err
+}
+
+// ReadFolder returns a Read function for files under a cart's assets folder
+func ReadFolder(assetsDir string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(assetsDir, filepath.FromSlash(name)))
+	}
+}
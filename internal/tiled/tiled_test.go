@@ -0,0 +1,330 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+var testPalette = []color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {250, 10, 10, 255}}
+
+// tilesPNG is a 4×2 image of two 2×2 tiles: tile 0 is white, tile 1 is red
+// with a transparent top-left pixel
+func tilesPNG(t *testing.T) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			img.Set(x, y, color.NRGBA{240, 240, 240, 255})
+			img.Set(x+2, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	img.Set(2, 0, color.NRGBA{0, 0, 0, 0})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const testTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="terrain" tilewidth="2" tileheight="2" tilecount="2" columns="2">
+ <image source="img/tiles.png" width="4" height="2"/>
+ <tile id="0">
+  <animation><frame tileid="0" duration="100"/><frame tileid="1" duration="100"/></animation>
+ </tile>
+ <tile id="1">
+  <properties><property name="flags" type="int" value="4"/></properties>
+  <objectgroup><object id="1" x="0" y="0" width="2" height="2"/></objectgroup>
+ </tile>
+</tileset>`
+
+// zlibGIDs encodes GIDs as Tiled's zlib-compressed base64 layer data
+func zlibGIDs(t *testing.T, gids ...uint32) string {
+	var raw bytes.Buffer
+	for _, g := range gids {
+		binary.Write(&raw, binary.LittleEndian, g)
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(raw.Bytes())
+	zw.Close()
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func testTMX(t *testing.T) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="3" height="2" tilewidth="2" tileheight="2" infinite="0">
+ <tileset firstgid="1" source="terrain.tsx"/>
+ <layer id="1" name="ground" width="3" height="2">
+  <data encoding="csv">
+2,2,0,
+0,1,2147483650
+</data>
+ </layer>
+ <group id="2" name="top">
+  <layer id="3" name="deco" width="3" height="2">
+   <data encoding="base64" compression="zlib">` + zlibGIDs(t, 0, 1, 0, 0, 0, 0) + `</data>
+  </layer>
+ </group>
+ <objectgroup id="4" name="spawns">
+  <object id="5" name="p1" type="player" x="1" y="2">
+   <properties>
+    <property name="hp" type="int" value="3"/>
+    <property name="boss" type="bool" value="false"/>
+    <property name="tag" value="start"/>
+   </properties>
+   <point/>
+  </object>
+  <object id="6" class="zone" x="0" y="0"><polygon points="0,0 4,0 4,2"/></object>
+  <object id="7" gid="2" x="2" y="4" width="2" height="2"/>
+ </objectgroup>
+</map>`
+}
+
+func testFiles(t *testing.T) map[string][]byte {
+	return map[string][]byte{
+		"maps/level.tmx":      []byte(testTMX(t)),
+		"maps/terrain.tsx":    []byte(testTSX),
+		"maps/img/tiles.png":  tilesPNG(t),
+		"maps/other.tmj":      []byte(testTMJ),
+		"maps/img/other.png":  tilesPNG(t),
+		"maps/unused.txt":     []byte("x"),
+		"maps/missing.tmx":    []byte(`<map width="1" height="1" tilewidth="2" tileheight="2"><tileset firstgid="1" source="nope.tsx"/></map>`),
+		"maps/isometric.tmj":  []byte(`{"orientation": "isometric", "width": 1, "height": 1}`),
+		"maps/infinite.tmj":   []byte(`{"orientation": "orthogonal", "width": 1, "height": 1, "infinite": true}`),
+		"maps/wrongsize.tmj":  []byte(`{"width": 2, "height": 1, "layers": [{"type": "tilelayer", "name": "a", "data": [1]}]}`),
+		"maps/badencode.tmx":  []byte(`<map width="1" height="1"><layer name="a"><data encoding="base64" compression="zstd">AAAAAA==</data></layer></map>`),
+		"maps/notxml.tmx":     []byte(`{`),
+		"maps/emptylayer.tmj": []byte(`{"width": 1, "height": 1, "layers": [{"type": "tilelayer", "name": "a", "data": [0]}]}`),
+		"maps/tinyimage.tmj":  []byte(`{"width": 1, "height": 1, "tilesets": [{"firstgid": 1, "name": "t", "tilewidth": 8, "tileheight": 8, "image": "img/tiles.png"}]}`),
+		"maps/unnamed.tmj": []byte(`{"width": 2, "height": 1, "tilewidth": 2, "tileheight": 2, "tilesets": [
+			{"firstgid": 1, "tilewidth": 2, "tileheight": 2, "columns": 9, "tilecount": 18, "image": "img/tiles.png"},
+			{"firstgid": 3, "tilewidth": 2, "tileheight": 2, "image": "img/other.png"}
+		], "layers": [{"type": "tilelayer", "name": "a", "data": [2, 3]}]}`),
+	}
+}
+
+func newTestImporter(files map[string][]byte) *Importer {
+	return NewImporter(func(name string) ([]byte, error) {
+		if data, ok := files[name]; ok {
+			return data, nil
+		}
+		return nil, os.ErrNotExist
+	}, testPalette)
+}
+
+func TestImportTMX(t *testing.T) {
+	imp := newTestImporter(testFiles(t))
+	// Existing cart tiles: imported tiles are numbered after them
+	imp.Tileset.Tiles = map[int]cartio.TileDef{1: {Sprite: "grass"}, 4: {Sprite: "rock"}}
+	if err := imp.Import("maps/level.tmx"); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	m, ok := imp.Maps["maps/level"]
+	if !ok {
+		t.Fatalf("expected map maps/level, got %v", imp.Maps)
+	}
+	if m.Width != 3 || m.Height != 2 || m.TileW != 2 || m.TileH != 2 {
+		t.Fatalf("unexpected map size %+v", m)
+	}
+	// Tiled tile 0 (GID 1) is native 5 and tile 1 (GID 2) native 6; flip bits are dropped
+	if got := m.Layers[0].Tiles; len(got) != 6 || got[0] != 6 || got[2] != 0 || got[4] != 5 || got[5] != 6 {
+		t.Fatalf("unexpected ground tiles %v", got)
+	}
+	if m.Layers[1].Name != "deco" || m.Layers[1].Tiles[1] != 5 {
+		t.Fatalf("expected the grouped deco layer, got %+v", m.Layers[1])
+	}
+
+	spawns := m.Layers[2]
+	if !spawns.IsObjects() || len(spawns.Objects) != 3 {
+		t.Fatalf("expected 3 spawn objects, got %+v", spawns)
+	}
+	p1 := spawns.Objects[0]
+	if p1.Type != "player" || p1.Shape != cartio.ShapePoint || p1.Properties["hp"] != float64(3) ||
+		p1.Properties["boss"] != false || p1.Properties["tag"] != "start" {
+		t.Fatalf("unexpected player object %+v", p1)
+	}
+	if zone := spawns.Objects[1]; zone.Type != "zone" || zone.Shape != cartio.ShapePolygon || len(zone.Points) != 6 {
+		t.Fatalf("unexpected zone object %+v", zone)
+	}
+	if tile := spawns.Objects[2]; tile.Tile != 6 || tile.Y != 2 {
+		t.Fatalf("tile objects should resolve their tile and use a top-left origin, got %+v", tile)
+	}
+
+	// Solid tiles (Tiled tile 1) on the ground layer: (0,0) and (1,0) merge, (2,1) is alone
+	coll := m.Layers[3]
+	if coll.Name != CollisionLayer || len(coll.Objects) != 2 {
+		t.Fatalf("expected 2 merged collision rects, got %+v", coll)
+	}
+	if c := coll.Objects[0]; c.Type != cartio.CollisionType || c.X != 0 || c.Y != 0 || c.W != 4 || c.H != 2 {
+		t.Fatalf("unexpected first collision rect %+v", c)
+	}
+	if c := coll.Objects[1]; c.X != 4 || c.Y != 2 || c.W != 2 {
+		t.Fatalf("unexpected second collision rect %+v", c)
+	}
+
+	// Tiles, flags and animation
+	def := imp.Tileset.Tiles[6]
+	if def.Sprite != "terrain:1" || def.Flags != 4|FlagSolid {
+		t.Fatalf("unexpected tile 6 %+v", def)
+	}
+	if anim := imp.Tileset.Tiles[5].Anim; len(anim) != 2 || anim[1].Tile != 6 || anim[1].Duration != 100 {
+		t.Fatalf("unexpected tile 5 animation %+v", anim)
+	}
+	if imp.Tileset.Tiles[1].Sprite != "grass" {
+		t.Fatal("existing tiles should be kept")
+	}
+
+	// Quantized sprites
+	white, red := imp.Sprites["terrain:0"], imp.Sprites["terrain:1"]
+	if white.Width != 2 || white.Pixels[1][1] != 1 {
+		t.Fatalf("expected tile 0 quantized to white, got %+v", white)
+	}
+	if red.Pixels[0][0] != -1 || red.Pixels[0][1] != 2 {
+		t.Fatalf("expected tile 1 transparent then red, got %v", red.Pixels)
+	}
+
+	want := map[string]bool{"maps/level.tmx": true, "maps/terrain.tsx": true, "maps/img/tiles.png": true}
+	if len(imp.Files) != len(want) {
+		t.Fatalf("unexpected files read %v", imp.Files)
+	}
+	for _, f := range imp.Files {
+		if !want[f] {
+			t.Fatalf("unexpected file read %s", f)
+		}
+	}
+}
+
+const testTMJ = `{
+	"orientation": "orthogonal", "width": 2, "height": 1, "tilewidth": 2, "tileheight": 2,
+	"tilesets": [{
+		"firstgid": 1, "name": "other", "tilewidth": 2, "tileheight": 2, "tilecount": 2, "columns": 2,
+		"image": "img/other.png", "transparentcolor": "#f0f0f0",
+		"tiles": [{"id": 1, "objectgroup": {"objects": [{"x": 0, "y": 0, "ellipse": true, "width": 2, "height": 2}]}}]
+	}],
+	"layers": [
+		{"type": "tilelayer", "name": "main", "data": [1, 2]},
+		{"type": "objectgroup", "name": "collision", "objects": [
+			{"id": 1, "class": "collision", "x": 0, "y": 0, "polyline": [{"x": 0, "y": 0}, {"x": 4, "y": 0}]}
+		]},
+		{"type": "objectgroup", "name": "things", "objects": [
+			{"id": 2, "type": "chest", "x": 1, "y": 1, "properties": [
+				{"name": "loot", "type": "class", "value": {"gold": 5}},
+				{"name": "locked", "type": "bool", "value": true}
+			]}
+		]}
+	]
+}`
+
+func TestImportTMJ(t *testing.T) {
+	imp := newTestImporter(testFiles(t))
+	if err := imp.Import("maps/other.tmj"); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	m := imp.Maps["maps/other"]
+	if got := m.Layers[0].Tiles; got[0] != 1 || got[1] != 2 {
+		t.Fatalf("unexpected tiles %v", got)
+	}
+
+	// The ellipse of tile 1 joins the existing collision layer
+	coll := m.Layers[1]
+	if len(m.Layers) != 3 || len(coll.Objects) != 2 {
+		t.Fatalf("expected the tile shape in the existing collision layer, got %+v", m.Layers)
+	}
+	if c := coll.Objects[0]; c.Shape != cartio.ShapePolyline || c.Type != cartio.CollisionType {
+		t.Fatalf("unexpected polyline %+v", c)
+	}
+	if c := coll.Objects[1]; c.Shape != cartio.ShapeEllipse || c.X != 2 || c.W != 2 {
+		t.Fatalf("unexpected tile ellipse %+v", c)
+	}
+
+	chest := m.Layers[2].Objects[0]
+	if loot, ok := chest.Properties["loot"].(map[string]interface{}); !ok || loot["gold"] != float64(5) || chest.Properties["locked"] != true {
+		t.Fatalf("unexpected chest properties %v", chest.Properties)
+	}
+	if imp.Sprites["other:0"].Pixels[0][0] != -1 {
+		t.Fatal("transparentcolor pixels should be transparent")
+	}
+	if imp.Tileset.Tiles[2].Flags != FlagSolid {
+		t.Fatal("tile with a collision shape should be solid")
+	}
+
+	// A second map sharing no tilesets gets new indices; importing the same
+	// map again reuses its tileset
+	if err := imp.Import("maps/level.tmx"); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if got := imp.Maps["maps/level"].Layers[0].Tiles[4]; got != 3 {
+		t.Fatalf("expected level tiles after other's, got %d", got)
+	}
+	if err := imp.Import("maps/level.tmx"); err != nil || len(imp.Tileset.Tiles) != 4 {
+		t.Fatalf("reimport should reuse tilesets, got %d tiles, %v", len(imp.Tileset.Tiles), err)
+	}
+}
+
+func TestImportUnnamedTilesets(t *testing.T) {
+	imp := newTestImporter(testFiles(t))
+	if err := imp.Import("maps/unnamed.tmj"); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	// columns is more than the image holds: only its two tiles are cut
+	if _, ok := imp.Sprites["tiles1:1"]; !ok || len(imp.Sprites) != 4 {
+		t.Fatalf("expected two tiles from each tileset, got %v", imp.Sprites)
+	}
+	if _, ok := imp.Sprites["tiles3:0"]; !ok {
+		t.Fatal("unnamed tilesets should be named after their firstgid")
+	}
+	if got := imp.Maps["maps/unnamed"].Layers[0].Tiles; got[0] != 2 || got[1] != 3 {
+		t.Fatalf("unexpected tiles %v", got)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	for _, file := range []string{
+		"maps/nope.tmx", "maps/missing.tmx", "maps/isometric.tmj", "maps/infinite.tmj",
+		"maps/wrongsize.tmj", "maps/badencode.tmx", "maps/notxml.tmx", "maps/tinyimage.tmj",
+	} {
+		if err := newTestImporter(testFiles(t)).Import(file); err == nil {
+			t.Errorf("expected error importing %s", file)
+		}
+	}
+	if err := newTestImporter(testFiles(t)).Import("maps/emptylayer.tmj"); err != nil {
+		t.Errorf("map without tilesets should import: %v", err)
+	}
+}
+
+func TestFindMaps(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range testFiles(t) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := FindMaps(dir)
+	if err != nil {
+		t.Fatalf("FindMaps: %v", err)
+	}
+	if len(files) != 11 || files[0] != "maps/badencode.tmx" {
+		t.Fatalf("unexpected maps %v", files)
+	}
+	if data, err := ReadFolder(dir)("maps/unused.txt"); err != nil || string(data) != "x" {
+		t.Fatalf("ReadFolder: %q %v", data, err)
+	}
+	if files, err := FindMaps(filepath.Join(dir, "none")); err != nil || files != nil {
+		t.Fatalf("missing folder should have no maps, got %v %v", files, err)
+	}
+}
@@ -0,0 +1,244 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// TMJ (JSON) documents
+
+type jsonMap struct {
+	Orientation string        `json:"orientation"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Infinite    bool          `json:"infinite"`
+	Tilesets    []jsonTileset `json:"tilesets"`
+	Layers      []jsonLayer   `json:"layers"`
+}
+
+type jsonTileset struct {
+	FirstGID         uint32     `json:"firstgid"`
+	Source           string     `json:"source"`
+	Name             string     `json:"name"`
+	TileWidth        int        `json:"tilewidth"`
+	TileHeight       int        `json:"tileheight"`
+	TileCount        int        `json:"tilecount"`
+	Columns          int        `json:"columns"`
+	Spacing          int        `json:"spacing"`
+	Margin           int        `json:"margin"`
+	Image            string     `json:"image"`
+	TransparentColor string     `json:"transparentcolor"`
+	Tiles            []jsonTile `json:"tiles"`
+}
+
+type jsonTile struct {
+	ID          int            `json:"id"`
+	Image       string         `json:"image"`
+	Properties  []jsonProperty `json:"properties"`
+	ObjectGroup *jsonLayer     `json:"objectgroup"`
+	Animation   []struct {
+		TileID   int `json:"tileid"`
+		Duration int `json:"duration"`
+	} `json:"animation"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Data        json.RawMessage `json:"data"` // GID array, or a base64 string
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Chunks      json.RawMessage `json:"chunks"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	GID        uint32         `json:"gid"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"` // Class values are objects of their members
+}
+
+// decodeTMJ decodes a .tmj map; dir is its folder, for resolving paths
+func decodeTMJ(data []byte, dir string) (*mapDoc, error) {
+	var j jsonMap
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	doc := &mapDoc{
+		orientation: j.Orientation,
+		width:       j.Width, height: j.Height,
+		tileW: j.TileWidth, tileH: j.TileHeight,
+		infinite: j.Infinite,
+	}
+	for _, ts := range j.Tilesets {
+		ref := tilesetRef{firstGID: ts.FirstGID}
+		if ts.Source != "" {
+			ref.source = path.Join(dir, ts.Source)
+		} else {
+			ref.embedded = convertTSJ(ts, dir)
+		}
+		doc.tilesets = append(doc.tilesets, ref)
+	}
+	var err error
+	doc.layers, err = convertJSONLayers(j.Layers)
+	return doc, err
+}
+
+// decodeTSJ decodes an external .tsj tileset
+func decodeTSJ(data []byte, dir string) (*tilesetDoc, error) {
+	var ts jsonTileset
+	if err := json.Unmarshal(data, &ts); err != nil {
+		return nil, err
+	}
+	return convertTSJ(ts, dir), nil
+}
+
+func convertTSJ(j jsonTileset, dir string) *tilesetDoc {
+	ts := &tilesetDoc{
+		name:  j.Name,
+		tileW: j.TileWidth, tileH: j.TileHeight,
+		columns: j.Columns, count: j.TileCount,
+		margin: j.Margin, spacing: j.Spacing,
+		trans: j.TransparentColor,
+		tiles: make(map[int]*tileDoc),
+	}
+	if j.Image != "" {
+		ts.image = path.Join(dir, j.Image)
+	}
+	for _, t := range j.Tiles {
+		tile := &tileDoc{props: jsonProps(t.Properties)}
+		if t.Image != "" {
+			tile.image = path.Join(dir, t.Image)
+		}
+		if t.ObjectGroup != nil {
+			for _, o := range t.ObjectGroup.Objects {
+				tile.shapes = append(tile.shapes, convertJSONObject(o))
+			}
+		}
+		for _, f := range t.Animation {
+			tile.anim = append(tile.anim, frameDoc{tileID: f.TileID, duration: f.Duration})
+		}
+		ts.tiles[t.ID] = tile
+	}
+	return ts
+}
+
+// convertJSONLayers flattens groups into a list of tile and object layers
+func convertJSONLayers(js []jsonLayer) ([]layerDoc, error) {
+	var out []layerDoc
+	for _, j := range js {
+		switch j.Type {
+		case "tilelayer":
+			if len(j.Chunks) > 0 {
+				return nil, fmt.Errorf("infinite maps are not supported")
+			}
+			gids, err := jsonGIDs(j)
+			if err != nil {
+				return nil, fmt.Errorf("layer %s: %w", j.Name, err)
+			}
+			out = append(out, layerDoc{name: j.Name, gids: gids})
+		case "objectgroup":
+			l := layerDoc{name: j.Name, objects: true}
+			for _, o := range j.Objects {
+				l.objs = append(l.objs, convertJSONObject(o))
+			}
+			out = append(out, l)
+		case "group":
+			children, err := convertJSONLayers(j.Layers)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, children...)
+		}
+	}
+	return out, nil
+}
+
+// jsonGIDs decodes a tile layer's data: a GID array or base64
+func jsonGIDs(j jsonLayer) ([]uint32, error) {
+	if len(j.Data) == 0 {
+		return nil, nil
+	}
+	if j.Encoding == "base64" {
+		var s string
+		if err := json.Unmarshal(j.Data, &s); err != nil {
+			return nil, err
+		}
+		return decodeBase64GIDs(s, j.Compression)
+	}
+	var gids []uint32
+	if err := json.Unmarshal(j.Data, &gids); err != nil {
+		return nil, err
+	}
+	return gids, nil
+}
+
+func convertJSONObject(j jsonObject) object {
+	o := object{gid: j.GID, MapObject: cartio.MapObject{
+		ID: j.ID, Name: j.Name, Type: j.Type,
+		X: j.X, Y: j.Y, W: j.Width, H: j.Height,
+		Properties: jsonProps(j.Properties),
+	}}
+	if o.Type == "" {
+		o.Type = j.Class // Tiled 1.9 renamed type to class
+	}
+	points := func(ps []jsonPoint) []float64 {
+		out := make([]float64, 0, len(ps)*2)
+		for _, p := range ps {
+			out = append(out, p.X, p.Y)
+		}
+		return out
+	}
+	switch {
+	case j.Ellipse:
+		o.Shape = cartio.ShapeEllipse
+	case j.Point:
+		o.Shape = cartio.ShapePoint
+	case j.Polygon != nil:
+		o.Shape, o.Points = cartio.ShapePolygon, points(j.Polygon)
+	case j.Polyline != nil:
+		o.Shape, o.Points = cartio.ShapePolyline, points(j.Polyline)
+	}
+	return o
+}
+
+// jsonProps converts custom properties. JSON already gives numbers, booleans,
+// strings and (for classes) objects of their members.
+func jsonProps(ps []jsonProperty) map[string]interface{} {
+	if len(ps) == 0 {
+		return nil
+	}
+	out := make(map[string]interface{}, len(ps))
+	for _, p := range ps {
+		out[p.Name] = p.Value
+	}
+	return out
+}
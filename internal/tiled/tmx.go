@@ -0,0 +1,343 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/cartio"
+)
+
+// TMX (XML) documents
+
+type xmlMap struct {
+	Orientation string       `xml:"orientation,attr"`
+	Width       int          `xml:"width,attr"`
+	Height      int          `xml:"height,attr"`
+	TileWidth   int          `xml:"tilewidth,attr"`
+	TileHeight  int          `xml:"tileheight,attr"`
+	Infinite    int          `xml:"infinite,attr"`
+	Tilesets    []xmlTileset `xml:"tileset"`
+	Layers      []xmlLayer   `xml:",any"` // Layers, object groups and groups in drawing order
+}
+
+type xmlTileset struct {
+	FirstGID   uint32    `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Margin     int       `xml:"margin,attr"`
+	Image      *xmlImage `xml:"image"`
+	Tiles      []xmlTile `xml:"tile"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+	Trans  string `xml:"trans,attr"`
+}
+
+type xmlTile struct {
+	ID          int           `xml:"id,attr"`
+	Image       *xmlImage     `xml:"image"`
+	Properties  []xmlProperty `xml:"properties>property"`
+	ObjectGroup *xmlLayer     `xml:"objectgroup"`
+	Animation   []struct {
+		TileID   int `xml:"tileid,attr"`
+		Duration int `xml:"duration,attr"`
+	} `xml:"animation>frame"`
+}
+
+type xmlLayer struct {
+	XMLName xml.Name
+	Name    string      `xml:"name,attr"`
+	Data    *xmlData    `xml:"data"`
+	Objects []xmlObject `xml:"object"`
+	Layers  []xmlLayer  `xml:",any"` // Children of a group
+}
+
+type xmlData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	Chunks []struct{} `xml:"chunk"`
+}
+
+type xmlObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *xmlPoints    `xml:"polygon"`
+	Polyline   *xmlPoints    `xml:"polyline"`
+}
+
+type xmlPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type xmlProperty struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Value      *string       `xml:"value,attr"`
+	Text       string        `xml:",chardata"` // Multi-line strings
+	Properties []xmlProperty `xml:"properties>property"`
+}
+
+// decodeTMX decodes a .tmx map; dir is its folder, for resolving paths
+func decodeTMX(data []byte, dir string) (*mapDoc, error) {
+	var x xmlMap
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return nil, err
+	}
+	doc := &mapDoc{
+		orientation: x.Orientation,
+		width:       x.Width, height: x.Height,
+		tileW: x.TileWidth, tileH: x.TileHeight,
+		infinite: x.Infinite != 0,
+	}
+	for _, ts := range x.Tilesets {
+		ref := tilesetRef{firstGID: ts.FirstGID}
+		if ts.Source != "" {
+			ref.source = path.Join(dir, ts.Source)
+		} else {
+			ref.embedded = convertTSX(ts, dir)
+		}
+		doc.tilesets = append(doc.tilesets, ref)
+	}
+	var err error
+	doc.layers, err = convertXMLLayers(x.Layers)
+	return doc, err
+}
+
+// decodeTSX decodes an external .tsx tileset
+func decodeTSX(data []byte, dir string) (*tilesetDoc, error) {
+	var ts xmlTileset
+	if err := xml.Unmarshal(data, &ts); err != nil {
+		return nil, err
+	}
+	return convertTSX(ts, dir), nil
+}
+
+func convertTSX(x xmlTileset, dir string) *tilesetDoc {
+	ts := &tilesetDoc{
+		name:  x.Name,
+		tileW: x.TileWidth, tileH: x.TileHeight,
+		columns: x.Columns, count: x.TileCount,
+		margin: x.Margin, spacing: x.Spacing,
+		tiles: make(map[int]*tileDoc),
+	}
+	if x.Image != nil {
+		ts.image = path.Join(dir, x.Image.Source)
+		ts.trans = x.Image.Trans
+	}
+	for _, t := range x.Tiles {
+		tile := &tileDoc{props: xmlProps(t.Properties)}
+		if t.Image != nil {
+			tile.image = path.Join(dir, t.Image.Source)
+			if ts.trans == "" {
+				ts.trans = t.Image.Trans
+			}
+		}
+		if t.ObjectGroup != nil {
+			for _, o := range t.ObjectGroup.Objects {
+				tile.shapes = append(tile.shapes, convertXMLObject(o))
+			}
+		}
+		for _, f := range t.Animation {
+			tile.anim = append(tile.anim, frameDoc{tileID: f.TileID, duration: f.Duration})
+		}
+		ts.tiles[t.ID] = tile
+	}
+	return ts
+}
+
+// convertXMLLayers flattens groups into a list of tile and object layers
+func convertXMLLayers(xs []xmlLayer) ([]layerDoc, error) {
+	var out []layerDoc
+	for _, x := range xs {
+		switch x.XMLName.Local {
+		case "layer":
+			gids, err := xmlGIDs(x.Data)
+			if err != nil {
+				return nil, fmt.Errorf("layer %s: %w", x.Name, err)
+			}
+			out = append(out, layerDoc{name: x.Name, gids: gids})
+		case "objectgroup":
+			l := layerDoc{name: x.Name, objects: true}
+			for _, o := range x.Objects {
+				l.objs = append(l.objs, convertXMLObject(o))
+			}
+			out = append(out, l)
+		case "group":
+			children, err := convertXMLLayers(x.Layers)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, children...)
+		}
+	}
+	return out, nil
+}
+
+// xmlGIDs decodes a tile layer's data: CSV, base64 (optionally zlib or gzip
+// compressed) or <tile> elements
+func xmlGIDs(d *xmlData) ([]uint32, error) {
+	if d == nil {
+		return nil, nil
+	}
+	if len(d.Chunks) > 0 {
+		return nil, fmt.Errorf("infinite maps are not supported")
+	}
+	switch d.Encoding {
+	case "csv":
+		var gids []uint32
+		for _, f := range strings.Split(d.Text, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			v, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(v))
+		}
+		return gids, nil
+	case "base64":
+		return decodeBase64GIDs(strings.TrimSpace(d.Text), d.Compression)
+	case "":
+		gids := make([]uint32, len(d.Tiles))
+		for i, t := range d.Tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", d.Encoding)
+}
+
+// decodeBase64GIDs decodes base64 layer data of little-endian 32-bit GIDs
+func decodeBase64GIDs(s, compression string) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+	if raw, err = io.ReadAll(r); err != nil {
+		return nil, err
+	}
+	gids := make([]uint32, len(raw)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+	return gids, nil
+}
+
+func convertXMLObject(x xmlObject) object {
+	o := object{gid: x.GID, MapObject: cartio.MapObject{
+		ID: x.ID, Name: x.Name, Type: x.Type,
+		X: x.X, Y: x.Y, W: x.Width, H: x.Height,
+		Properties: xmlProps(x.Properties),
+	}}
+	if o.Type == "" {
+		o.Type = x.Class // Tiled 1.9 renamed type to class
+	}
+	switch {
+	case x.Ellipse != nil:
+		o.Shape = cartio.ShapeEllipse
+	case x.Point != nil:
+		o.Shape = cartio.ShapePoint
+	case x.Polygon != nil:
+		o.Shape, o.Points = cartio.ShapePolygon, parsePoints(x.Polygon.Points)
+	case x.Polyline != nil:
+		o.Shape, o.Points = cartio.ShapePolyline, parsePoints(x.Polyline.Points)
+	}
+	return o
+}
+
+// parsePoints parses "x1,y1 x2,y2 ..." into x, y pairs
+func parsePoints(s string) []float64 {
+	var out []float64
+	for _, p := range strings.Fields(s) {
+		xy := strings.SplitN(p, ",", 2)
+		if len(xy) != 2 {
+			continue
+		}
+		x, errX := strconv.ParseFloat(xy[0], 64)
+		y, errY := strconv.ParseFloat(xy[1], 64)
+		if errX == nil && errY == nil {
+			out = append(out, x, y)
+		}
+	}
+	return out
+}
+
+// xmlProps converts custom properties: int and float become numbers, bool
+// becomes a boolean, class becomes a table of its members and everything
+// else (string, color, file, object) is a string.
+func xmlProps(ps []xmlProperty) map[string]interface{} {
+	if len(ps) == 0 {
+		return nil
+	}
+	out := make(map[string]interface{}, len(ps))
+	for _, p := range ps {
+		v := p.Text
+		if p.Value != nil {
+			v = *p.Value
+		}
+		switch p.Type {
+		case "int", "float", "object":
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				out[p.Name] = n
+				continue
+			}
+			out[p.Name] = v
+		case "bool":
+			out[p.Name] = v == "true"
+		case "class":
+			members := xmlProps(p.Properties)
+			if members == nil {
+				members = make(map[string]interface{})
+			}
+			out[p.Name] = members
+		default:
+			out[p.Name] = v
+		}
+	}
+	return out
+}
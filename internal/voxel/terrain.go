@@ -0,0 +1,88 @@
+package voxel
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png" // Heightmaps and colormaps
+	"math"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// Terrain is a heightmap with a colormap of the same size. It wraps around at
+// its edges, so the world repeats forever.
+type Terrain struct {
+	W, H   int
+	Height []uint8 // Ground height per map pixel, row-major
+	Color  []uint8 // Palette index per map pixel, row-major
+}
+
+// Terrains maps terrain names to terrains
+type Terrains map[string]*Terrain
+
+// index returns the map offset of world position (x, y), wrapped
+func (t *Terrain) index(x, y float64) int {
+	ix, iy := int(math.Floor(x))%t.W, int(math.Floor(y))%t.H
+	if ix < 0 {
+		ix += t.W
+	}
+	if iy < 0 {
+		iy += t.H
+	}
+	return iy*t.W + ix
+}
+
+// HeightAt returns the ground height at world position (x, y)
+func (t *Terrain) HeightAt(x, y float64) float64 {
+	return float64(t.Height[t.index(x, y)])
+}
+
+// DecodeTerrain decodes a heightmap and a colormap image. Paletted images give
+// their pixels' indices as they are; otherwise heights are gray levels and
+// colors the nearest of colors.
+func DecodeTerrain(heightmap, colormap []byte, colors []color.RGBA) (*Terrain, error) {
+	himg, _, err := image.Decode(bytes.NewReader(heightmap))
+	if err != nil {
+		return nil, fmt.Errorf("heightmap: %w", err)
+	}
+	cimg, _, err := image.Decode(bytes.NewReader(colormap))
+	if err != nil {
+		return nil, fmt.Errorf("colormap: %w", err)
+	}
+	hb, cb := himg.Bounds(), cimg.Bounds()
+	if hb.Dx() != cb.Dx() || hb.Dy() != cb.Dy() {
+		return nil, fmt.Errorf("heightmap is %d×%d but colormap is %d×%d", hb.Dx(), hb.Dy(), cb.Dx(), cb.Dy())
+	}
+	if hb.Empty() {
+		return nil, fmt.Errorf("heightmap is empty")
+	}
+
+	t := &Terrain{W: hb.Dx(), H: hb.Dy()}
+	t.Height = make([]uint8, t.W*t.H)
+	t.Color = make([]uint8, t.W*t.H)
+	nearest := make(map[color.RGBA]uint8)
+	for y := 0; y < t.H; y++ {
+		for x := 0; x < t.W; x++ {
+			i := y*t.W + x
+			if p, ok := himg.(*image.Paletted); ok {
+				t.Height[i] = p.ColorIndexAt(hb.Min.X+x, hb.Min.Y+y)
+			} else {
+				t.Height[i] = color.GrayModel.Convert(himg.At(hb.Min.X+x, hb.Min.Y+y)).(color.Gray).Y
+			}
+			if p, ok := cimg.(*image.Paletted); ok {
+				t.Color[i] = p.ColorIndexAt(cb.Min.X+x, cb.Min.Y+y)
+				continue
+			}
+			c := color.RGBAModel.Convert(cimg.At(cb.Min.X+x, cb.Min.Y+y)).(color.RGBA)
+			idx, ok := nearest[c]
+			if !ok {
+				idx = uint8(palette.Nearest(colors, c))
+				nearest[c] = idx
+			}
+			t.Color[i] = idx
+		}
+	}
+	return t, nil
+}
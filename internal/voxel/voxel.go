@@ -0,0 +1,118 @@
+// Package voxel renders heightmap terrain Comanche-style ("voxel space"):
+// rows of the map at increasing distance are projected front to back, each
+// screen column drawing only what rises above everything nearer (a y-buffer).
+// Billboard sprites are depth-tested per pixel.
+package voxel
+
+import (
+	"math"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+	"github.com/AndrewDonelson/retroforge-engine/internal/palette"
+)
+
+// Defaults for New
+const (
+	DefaultDistance = 600   // Map pixels
+	DefaultLOD      = 0.005 // Row spacing growth per row
+)
+
+// Camera is the viewer: position in map pixels, eye height in height units,
+// yaw in radians (0 = +X, clockwise) and pitch in radians (positive looks up).
+// The field of view is 90°.
+type Camera struct {
+	X, Y, Height float64
+	Yaw, Pitch   float64
+}
+
+// Renderer draws frames into a buffer of palette indices with a depth buffer
+// for billboards
+type Renderer struct {
+	graphics.DepthFrame // Distances in map pixels
+
+	ybuf     []int   // Per column, the highest row drawn so far
+	Sky      int     // Color behind the terrain
+	Distance float64 // Draw distance in map pixels
+	LOD      float64 // How much farther apart each row is than the last (0 = every map pixel)
+
+	// Projection of the last frame, for billboards
+	terrain  *Terrain
+	cam      Camera
+	sin, cos float64
+	focal    float64 // Pixels per map pixel at distance 1
+	horizon  float64 // Screen row of the horizon
+}
+
+// New creates a renderer for w×h frames with a black sky
+func New(w, h int) *Renderer {
+	return &Renderer{
+		DepthFrame: graphics.NewDepthFrame(w, h),
+		ybuf:       make([]int, w),
+		Sky:        palette.Black,
+		Distance:   DefaultDistance,
+		LOD:        DefaultLOD,
+	}
+}
+
+// Render draws a frame of t seen from cam
+func (r *Renderer) Render(t *Terrain, cam Camera) {
+	r.terrain, r.cam = t, cam
+	r.sin, r.cos = math.Sincos(cam.Yaw)
+	r.focal = float64(r.W) / 2
+	r.horizon = float64(r.H)/2 + math.Tan(cam.Pitch)*r.focal
+
+	inf := float32(math.Inf(1))
+	for i := range r.Pix {
+		r.Pix[i], r.Depth[i] = r.Sky, inf
+	}
+	for x := range r.ybuf {
+		r.ybuf[x] = r.H
+	}
+
+	// Front to back: each row of the map across the view, from its left edge to its right
+	dz := 1.0
+	for z := 1.0; z < r.Distance; z, dz = z+dz, dz+r.LOD {
+		lx, ly := cam.X+z*(r.cos+r.sin), cam.Y+z*(r.sin-r.cos)
+		rx, ry := cam.X+z*(r.cos-r.sin), cam.Y+z*(r.sin+r.cos)
+		stepX, stepY := (rx-lx)/float64(r.W), (ry-ly)/float64(r.W)
+		px, py := lx+stepX/2, ly+stepY/2
+		scale := r.focal / z
+		d := float32(z)
+		for x := 0; x < r.W; x, px, py = x+1, px+stepX, py+stepY {
+			i := t.index(px, py)
+			top := max(0, int(math.Ceil((cam.Height-float64(t.Height[i]))*scale+r.horizon)))
+			bottom := r.ybuf[x]
+			if top >= bottom {
+				continue // Hidden behind nearer ground
+			}
+			c := int(t.Color[i])
+			for y := top; y < bottom; y++ {
+				r.Pix[y*r.W+x], r.Depth[y*r.W+x] = c, d
+			}
+			r.ybuf[x] = top
+		}
+	}
+}
+
+// Sprite draws a billboard whose bottom center is at (x, y) in map pixels and
+// height z, one map pixel per texel. Pixels behind nearer terrain or
+// billboards are skipped. It returns a block holding only the billboard (the
+// rest transparent) and its screen rectangle; ok is false when nothing is
+// visible.
+func (r *Renderer) Sprite(tex graphics.Texture, x, y, z float64) (pix []int, sx, sy, w, h int, ok bool) {
+	if r.terrain == nil || tex.W <= 0 || tex.H <= 0 {
+		return nil, 0, 0, 0, 0, false
+	}
+	relX, relY := x-r.cam.X, y-r.cam.Y
+	dist := relX*r.cos + relY*r.sin
+	side := relY*r.cos - relX*r.sin
+	if dist < 1 || dist >= r.Distance {
+		return nil, 0, 0, 0, 0, false // Behind the camera or beyond the draw distance
+	}
+
+	scale := r.focal / dist
+	spriteW, spriteH := float64(tex.W)*scale, float64(tex.H)*scale
+	left := float64(r.W)/2 + side*scale - spriteW/2
+	bottom := r.horizon + (r.cam.Height-z)*scale
+	return r.Billboard(tex, left, bottom-spriteH, spriteW, spriteH, dist, nil)
+}
@@ -0,0 +1,162 @@
+package voxel
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/AndrewDonelson/retroforge-engine/internal/graphics"
+)
+
+// flat returns a w×h terrain at height 0 in color c
+func flat(w, h int, c uint8) *Terrain {
+	t := &Terrain{W: w, H: h, Height: make([]uint8, w*h), Color: make([]uint8, w*h)}
+	for i := range t.Color {
+		t.Color[i] = c
+	}
+	return t
+}
+
+func solid(c, w, h int) graphics.Texture {
+	pix := make([]int, w*h)
+	for i := range pix {
+		pix[i] = c
+	}
+	return graphics.Texture{Pix: pix, Stride: w, W: w, H: h}
+}
+
+func TestRenderFlatAndHill(t *testing.T) {
+	r := New(64, 40)
+	r.Sky = 7
+	ter := flat(64, 64, 3)
+	r.Render(ter, Camera{X: 10, Y: 32, Height: 20})
+
+	// Level camera: sky above the horizon (row 20), ground below
+	if r.Pix[5*64+32] != 7 || r.Pix[35*64+32] != 3 {
+		t.Errorf("sky %d, ground %d", r.Pix[5*64+32], r.Pix[35*64+32])
+	}
+
+	// A tall wall ahead rises above the horizon and hides the ground behind it
+	for y := 0; y < 64; y++ {
+		ter.Height[y*64+20], ter.Color[y*64+20] = 60, 9
+	}
+	r.Render(ter, Camera{X: 10, Y: 32, Height: 20})
+	// At distance 10, height 60 is (20 - 60) * 32 / 10 = 128 px above the horizon
+	if r.Pix[0*64+32] != 9 || r.Pix[22*64+32] != 9 {
+		t.Errorf("wall: %d at the top, %d below the horizon", r.Pix[0*64+32], r.Pix[22*64+32])
+	}
+
+	// Pitching down moves the horizon up
+	ter = flat(64, 64, 3)
+	r.Render(ter, Camera{X: 10, Y: 32, Height: 20, Pitch: -math.Pi / 8})
+	if r.Pix[15*64+32] != 3 {
+		t.Errorf("pitched down: %d at row 15, want ground", r.Pix[15*64+32])
+	}
+
+	// Turning: the terrain wraps, so every direction shows ground below the horizon
+	r.Render(ter, Camera{X: 0, Y: 0, Height: 20, Yaw: 2})
+	if r.Pix[39*64] != 3 || r.Pix[39*64+63] != 3 {
+		t.Error("wrapped terrain not drawn")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	r := New(64, 40)
+	r.Sky, r.Distance = 7, 40
+	r.Render(flat(64, 64, 3), Camera{X: 0, Y: 0, Height: 20})
+	// Row 39 shows ground 34 map pixels away; row 21 would be 640 away, beyond the draw distance
+	if r.Pix[21*64+32] != 7 || r.Pix[39*64+32] != 3 {
+		t.Errorf("row 21 = %d, row 39 = %d", r.Pix[21*64+32], r.Pix[39*64+32])
+	}
+}
+
+func TestSpriteDepth(t *testing.T) {
+	r := New(64, 40)
+	ter := flat(64, 64, 3)
+	for y := 0; y < 64; y++ {
+		ter.Height[y*64+30] = 60 // Ridge across the view
+	}
+	r.Render(ter, Camera{X: 10, Y: 32, Height: 5})
+
+	// In front of the ridge, on the ground: centered, bottom below the horizon
+	pix, x, y, w, h, ok := r.Sprite(solid(5, 4, 4), 20, 32, 0)
+	if !ok || x+w/2 != 32 || y+h <= 20 {
+		t.Fatalf("near sprite: ok %v at (%d, %d) %d×%d", ok, x, y, w, h)
+	}
+	if pix[h/2*w+w/2] != 5 || r.Pix[(y+h/2)*64+32] != 5 {
+		t.Error("near sprite pixels not drawn")
+	}
+
+	// Behind the ridge: hidden. Behind the camera: skipped
+	if _, _, _, _, _, ok := r.Sprite(solid(6, 4, 4), 40, 32, 0); ok {
+		t.Error("sprite behind the ridge should be hidden")
+	}
+	if _, _, _, _, _, ok := r.Sprite(solid(6, 4, 4), 5, 32, 0); ok {
+		t.Error("sprite behind the camera should be skipped")
+	}
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeTerrain(t *testing.T) {
+	pal := []color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {200, 0, 0, 255}}
+
+	// Gray heightmap, RGB colormap matched to the nearest palette color
+	gray := image.NewGray(image.Rect(0, 0, 2, 2))
+	gray.SetGray(1, 0, color.Gray{Y: 120})
+	rgb := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	rgb.Set(1, 1, color.RGBA{210, 10, 10, 255})
+	ter, err := DecodeTerrain(encodePNG(t, gray), encodePNG(t, rgb), pal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ter.W != 2 || ter.HeightAt(1, 0) != 120 || ter.Color[3] != 2 || ter.Color[0] != 0 {
+		t.Errorf("decoded %d×%d, height %v, colors %v", ter.W, ter.H, ter.HeightAt(1, 0), ter.Color)
+	}
+	if ter.HeightAt(-1, -2) != 120 || ter.HeightAt(3, 2) != 120 {
+		t.Error("heights should wrap around the edges")
+	}
+
+	// Paletted images give their indices as they are
+	idx := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White, color.Gray{9}, color.Gray{200}})
+	idx.SetColorIndex(0, 1, 3)
+	ter, err = DecodeTerrain(encodePNG(t, idx), encodePNG(t, idx), pal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ter.Height[2] != 3 || ter.Color[2] != 3 {
+		t.Errorf("paletted: height %d, color %d", ter.Height[2], ter.Color[2])
+	}
+
+	// Sizes must match
+	if _, err := DecodeTerrain(encodePNG(t, gray), encodePNG(t, image.NewGray(image.Rect(0, 0, 3, 2))), pal); err == nil {
+		t.Error("expected an error for mismatched sizes")
+	}
+}
+
+// BenchmarkRender480x270 renders a 480×270 frame of a 1024×1024 rolling terrain
+func BenchmarkRender480x270(b *testing.B) {
+	ter := flat(1024, 1024, 3)
+	for y := 0; y < 1024; y++ {
+		for x := 0; x < 1024; x++ {
+			h := 60 + 50*math.Sin(float64(x)/40)*math.Cos(float64(y)/55)
+			ter.Height[y*1024+x] = uint8(h)
+			ter.Color[y*1024+x] = uint8(2 + int(h)%48)
+		}
+	}
+	r := New(480, 270)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		r.Render(ter, Camera{X: 512, Y: float64(n), Height: 150, Yaw: float64(n) * 0.01, Pitch: -0.2})
+	}
+}